@@ -0,0 +1,86 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/josimar-silva/gwaihir/internal/infrastructure"
+)
+
+func auditCommands() []command {
+	return []command{
+		{"verify", "Check the hash chain of the audit trail", runAuditVerify},
+	}
+}
+
+// runAudit runs a command on the audit trail of the configuration.
+func runAudit(args []string, stdout, stderr io.Writer) error {
+	if len(args) == 0 {
+		printAuditUsage(stderr)
+		return usageError("missing audit command")
+	}
+
+	switch args[0] {
+	case "-h", "-help", "--help", "help":
+		printAuditUsage(stdout)
+		return nil
+	}
+
+	for _, cmd := range auditCommands() {
+		if cmd.name == args[0] {
+			return cmd.run(args[1:], stdout, stderr)
+		}
+	}
+
+	printAuditUsage(stderr)
+	return usageError("unknown audit command %q", args[0])
+}
+
+func printAuditUsage(w io.Writer) {
+	_, _ = fmt.Fprintf(w, "Usage: gwaihir audit <command> [flags]\n\nCommands:\n")
+	for _, cmd := range auditCommands() {
+		_, _ = fmt.Fprintf(w, "  %-14s %s\n", cmd.name, cmd.summary)
+	}
+	_, _ = fmt.Fprintf(w, "\nRun 'gwaihir audit <command> --help' for the flags of a command.\n")
+}
+
+// runAuditVerify checks that no record of the retained audit trail was altered,
+// removed or reordered. A broken chain is a runtime failure.
+func runAuditVerify(args []string, stdout, stderr io.Writer) error {
+	var flags configFlags
+	fs := newFlagSet("audit verify", stderr)
+	flags.registerPath(fs)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	cfg, err := flags.load()
+	if err != nil {
+		return invalidConfig(err)
+	}
+
+	path := cfg.Audit.Path
+	if _, err := os.Stat(path); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("no audit trail at %s", path)
+		}
+		return fmt.Errorf("failed to open audit trail: %w", err)
+	}
+
+	auditLog, err := infrastructure.NewFileAuditLog(path, 0, cfg.Audit.MaxBackups)
+	if err != nil {
+		return fmt.Errorf("failed to open audit trail: %w", err)
+	}
+	defer func() {
+		_ = auditLog.Close()
+	}()
+
+	if err := auditLog.Verify(); err != nil {
+		return fmt.Errorf("audit trail %s failed verification: %w", path, err)
+	}
+
+	_, _ = fmt.Fprintf(stdout, "Audit trail %s is intact\n", path)
+	return nil
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/josimar-silva/gwaihir/internal/domain"
+	"github.com/josimar-silva/gwaihir/internal/infrastructure"
+)
+
+// setupAuditConfig writes a configuration whose audit trail is at auditPath.
+func setupAuditConfig(t *testing.T, auditPath string) string {
+	t.Helper()
+	return setupTestConfig(t, validTestConfig()+"audit:\n  enabled: true\n  path: "+auditPath+"\n")
+}
+
+func writeAuditTrail(t *testing.T, path string, events ...domain.AuditEvent) {
+	t.Helper()
+	auditLog, err := infrastructure.NewFileAuditLog(path, 0, 0)
+	require.NoError(t, err)
+	for _, event := range events {
+		require.NoError(t, auditLog.Record(event))
+	}
+	require.NoError(t, auditLog.Close())
+}
+
+func TestRunCLI_AuditVerify(t *testing.T) {
+	auditPath := filepath.Join(t.TempDir(), "audit.jsonl")
+	writeAuditTrail(t, auditPath,
+		domain.AuditEvent{Action: domain.AuditActionWake, MachineID: "server1", Outcome: domain.AuditOutcomeSuccess},
+		domain.AuditEvent{Action: domain.AuditActionWake, MachineID: "server2", Outcome: domain.AuditOutcomeSuccess},
+	)
+	configPath := setupAuditConfig(t, auditPath)
+
+	code, stdout, stderr := runTestCLI(t, "audit", "verify", "--config", configPath)
+	require.Equal(t, exitOK, code, stderr)
+	assert.Contains(t, stdout, "is intact")
+
+	data, err := os.ReadFile(auditPath)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(auditPath, []byte(strings.Replace(string(data), "server1", "server3", 1)), 0o600))
+
+	code, _, stderr = runTestCLI(t, "audit", "verify", "--config", configPath)
+	assert.Equal(t, exitFailure, code)
+	assert.Contains(t, stderr, "failed verification")
+	assert.Contains(t, stderr, "record 1 hash mismatch")
+}
+
+func TestRunCLI_AuditVerifyMissingTrail(t *testing.T) {
+	auditPath := filepath.Join(t.TempDir(), "audit.jsonl")
+
+	code, _, stderr := runTestCLI(t, "audit", "verify", "--config", setupAuditConfig(t, auditPath))
+	assert.Equal(t, exitFailure, code)
+	assert.Contains(t, stderr, "no audit trail at "+auditPath)
+	assert.NoFileExists(t, auditPath)
+}
+
+func TestRunCLI_AuditUsage(t *testing.T) {
+	code, _, stderr := runTestCLI(t, "audit")
+	assert.Equal(t, exitUsage, code)
+	assert.Contains(t, stderr, "verify")
+
+	code, _, _ = runTestCLI(t, "audit", "rewrite")
+	assert.Equal(t, exitUsage, code)
+}
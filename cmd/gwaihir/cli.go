@@ -0,0 +1,321 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/josimar-silva/gwaihir/internal/config"
+)
+
+// Exit codes.
+const (
+	exitOK            = 0
+	exitFailure       = 1
+	exitUsage         = 2
+	exitInvalidConfig = 3
+)
+
+// defaultConfigPath is used when neither --config nor GWAIHIR_CONFIG is set.
+const defaultConfigPath = "/etc/gwaihir/gwaihir.yaml"
+
+// exitError carries the exit code a command failed with.
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string {
+	return e.err.Error()
+}
+
+func (e *exitError) Unwrap() error {
+	return e.err
+}
+
+// invalidConfig marks err as a configuration error.
+func invalidConfig(err error) error {
+	return &exitError{code: exitInvalidConfig, err: err}
+}
+
+// usageError marks err as a command-line usage error.
+func usageError(format string, args ...any) error {
+	return &exitError{code: exitUsage, err: fmt.Errorf(format, args...)}
+}
+
+// command is a gwaihir subcommand.
+type command struct {
+	name    string
+	summary string
+	run     func(args []string, stdout, stderr io.Writer) error
+}
+
+func commands() []command {
+	return []command{
+		{"serve", "Run the HTTP server (default)", runServe},
+		{"validate", "Load and validate the configuration, reporting all errors", runValidate},
+		{"print-config", "Print the effective configuration with secrets redacted", runPrintConfig},
+		{"wake", "Send a magic packet to a machine without a running server", runWake},
+		{"list", "List the allowlisted machines without a running server", runList},
+		{"audit", "Check the audit trail for tampering", runAudit},
+		{"remote", "Talk to a running server over the HTTP API", runRemote},
+		{"version", "Print version information", runVersion},
+	}
+}
+
+// runCLI runs the subcommand selected by args and returns the process exit code.
+// Without a subcommand, or when args start with a flag, the server is started.
+func runCLI(args []string, stdout, stderr io.Writer) int {
+	name := "serve"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name, args = args[0], args[1:]
+	} else if len(args) > 0 {
+		switch args[0] {
+		case "-h", "-help", "--help":
+			printUsage(stdout)
+			return exitOK
+		case "-v", "-version", "--version":
+			name, args = "version", args[1:]
+		}
+	}
+
+	if name == "help" {
+		printUsage(stdout)
+		return exitOK
+	}
+
+	for _, cmd := range commands() {
+		if cmd.name == name {
+			return exitCode(cmd.run(args, stdout, stderr), stderr)
+		}
+	}
+
+	_, _ = fmt.Fprintf(stderr, "Unknown command %q\n\n", name)
+	printUsage(stderr)
+	return exitUsage
+}
+
+// exitCode reports err and maps it to an exit code.
+func exitCode(err error, stderr io.Writer) int {
+	if err == nil || errors.Is(err, flag.ErrHelp) {
+		return exitOK
+	}
+
+	_, _ = fmt.Fprintf(stderr, "Application failed: %v\n", err)
+
+	var exitErr *exitError
+	if errors.As(err, &exitErr) {
+		return exitErr.code
+	}
+	return exitFailure
+}
+
+func printUsage(w io.Writer) {
+	_, _ = fmt.Fprintf(w, "Usage: gwaihir [command] [flags]\n\nCommands:\n")
+	for _, cmd := range commands() {
+		_, _ = fmt.Fprintf(w, "  %-14s %s\n", cmd.name, cmd.summary)
+	}
+	_, _ = fmt.Fprintf(w, "\nRun 'gwaihir <command> --help' for the flags of a command.\n")
+	_, _ = fmt.Fprintf(w, "\nExit codes: %d success, %d runtime failure, %d usage error, %d invalid configuration.\n",
+		exitOK, exitFailure, exitUsage, exitInvalidConfig)
+}
+
+// newFlagSet creates a flag set for the named command that reports errors instead of exiting.
+func newFlagSet(name string, stderr io.Writer) *flag.FlagSet {
+	fs := flag.NewFlagSet("gwaihir "+name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	return fs
+}
+
+// parseFlags parses args, turning parse failures into usage errors.
+func parseFlags(fs *flag.FlagSet, args []string) error {
+	if err := parseFlagSet(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return usageError("unexpected arguments: %s", strings.Join(fs.Args(), " "))
+	}
+	return nil
+}
+
+// parseFlagsWithArg parses args holding exactly one positional argument, named name
+// in errors, which may appear before or after the flags.
+func parseFlagsWithArg(fs *flag.FlagSet, args []string, name string) (string, error) {
+	if err := parseFlagSet(fs, args); err != nil {
+		return "", err
+	}
+	if fs.NArg() == 0 {
+		return "", usageError("missing %s argument", name)
+	}
+
+	arg := fs.Arg(0)
+	if err := parseFlags(fs, fs.Args()[1:]); err != nil {
+		return "", err
+	}
+	return arg, nil
+}
+
+func parseFlagSet(fs *flag.FlagSet, args []string) error {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return &exitError{code: exitUsage, err: err}
+	}
+	return nil
+}
+
+// configFlags are the flags shared by the commands that load the configuration.
+// Flags take precedence over environment variables and the configuration file.
+type configFlags struct {
+	path      string
+	port      int
+	logLevel  string
+	logFormat string
+}
+
+func (f *configFlags) register(fs *flag.FlagSet) {
+	f.registerPath(fs)
+	fs.IntVar(&f.port, "port", 0, "HTTP server port, overrides server.port")
+	fs.StringVar(&f.logLevel, "log-level", "", "Log level (debug, info, warn, error), overrides server.log.level")
+	fs.StringVar(&f.logFormat, "log-format", "", "Log format (json, text), overrides server.log.format")
+}
+
+// registerPath registers only --config, for commands whose other flags differ from the server's.
+func (f *configFlags) registerPath(fs *flag.FlagSet) {
+	fs.StringVar(&f.path, "config", "", "Path to the configuration file (default $GWAIHIR_CONFIG or "+defaultConfigPath+")")
+	fs.StringVar(&f.path, "c", "", "Shorthand for --config")
+}
+
+// configPath returns the configuration file path from --config, GWAIHIR_CONFIG or the default location.
+func (f *configFlags) configPath() string {
+	if f.path != "" {
+		return f.path
+	}
+	return resolveConfigPath()
+}
+
+// load loads the configuration and applies the flag overrides.
+func (f *configFlags) load() (*config.Config, error) {
+	configPath := f.configPath()
+
+	cfg, err := config.LoadConfig(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config from %s: %w", configPath, err)
+	}
+
+	if f.port == 0 && f.logLevel == "" && f.logFormat == "" {
+		return cfg, nil
+	}
+
+	if f.port != 0 {
+		cfg.Server.Port = f.port
+	}
+	if f.logLevel != "" {
+		cfg.Server.Log.Level = f.logLevel
+	}
+	if f.logFormat != "" {
+		cfg.Server.Log.Format = f.logFormat
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid command-line flags: %w", err)
+	}
+	return cfg, nil
+}
+
+func runServe(args []string, _, stderr io.Writer) error {
+	var flags configFlags
+	fs := newFlagSet("serve", stderr)
+	flags.register(fs)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	return serve(&flags)
+}
+
+func runValidate(args []string, stdout, stderr io.Writer) error {
+	var flags configFlags
+	fs := newFlagSet("validate", stderr)
+	flags.register(fs)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	cfg, err := flags.load()
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "Configuration %s is invalid:\n", flags.configPath())
+		for _, e := range configErrors(err) {
+			_, _ = fmt.Fprintf(stderr, "  - %v\n", e)
+		}
+		return &exitError{code: exitInvalidConfig, err: errors.New("configuration is invalid")}
+	}
+
+	_, _ = fmt.Fprintf(stdout, "Configuration %s is valid (%d machines)\n", flags.configPath(), len(cfg.Machines))
+	return nil
+}
+
+// configErrors returns the individual validation errors joined in err, including
+// those joined by the validation of a section, or err itself.
+func configErrors(err error) []error {
+	var joined interface{ Unwrap() []error }
+	if !errors.As(err, &joined) {
+		return []error{err}
+	}
+
+	var errs []error
+	for _, e := range joined.Unwrap() {
+		errs = append(errs, configErrors(e)...)
+	}
+	return errs
+}
+
+func runPrintConfig(args []string, stdout, stderr io.Writer) error {
+	var flags configFlags
+	fs := newFlagSet("print-config", stderr)
+	flags.register(fs)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	cfg, err := flags.load()
+	if err != nil {
+		return invalidConfig(err)
+	}
+
+	_, _ = fmt.Fprintf(stdout, "# Effective configuration loaded from %s (secrets redacted)\n", flags.configPath())
+	if overrides := cfg.EnvOverrides(); len(overrides) > 0 {
+		_, _ = fmt.Fprintf(stdout, "# Set from environment: %s\n", strings.Join(overrides, ", "))
+	}
+
+	encoder := yaml.NewEncoder(stdout)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(cfg.Redacted()); err != nil {
+		return fmt.Errorf("failed to encode configuration: %w", err)
+	}
+	return encoder.Close()
+}
+
+func runVersion(args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet("version", stderr)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintf(stdout, "gwaihir %s\n  build time: %s\n  git commit: %s\n", Version, BuildTime, GitCommit)
+	return nil
+}
+
+// resolveConfigPath returns the configuration file path from GWAIHIR_CONFIG or the default location.
+func resolveConfigPath() string {
+	if configPath := os.Getenv("GWAIHIR_CONFIG"); configPath != "" {
+		return configPath
+	}
+	return defaultConfigPath
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"github.com/josimar-silva/gwaihir/internal/config"
+)
+
+func runTestCLI(t *testing.T, args ...string) (int, string, string) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	code := runCLI(args, &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestRunCLI_Help(t *testing.T) {
+	for _, args := range [][]string{{"--help"}, {"help"}} {
+		code, stdout, _ := runTestCLI(t, args...)
+		assert.Equal(t, exitOK, code)
+		assert.Contains(t, stdout, "print-config")
+		assert.Contains(t, stdout, "Exit codes")
+	}
+}
+
+func TestRunCLI_Version(t *testing.T) {
+	for _, args := range [][]string{{"version"}, {"--version"}} {
+		code, stdout, _ := runTestCLI(t, args...)
+		assert.Equal(t, exitOK, code)
+		assert.Contains(t, stdout, "gwaihir "+Version)
+		assert.Contains(t, stdout, "git commit: "+GitCommit)
+	}
+}
+
+func TestRunCLI_UsageErrors(t *testing.T) {
+	code, _, stderr := runTestCLI(t, "frobnicate")
+	assert.Equal(t, exitUsage, code)
+	assert.Contains(t, stderr, `Unknown command "frobnicate"`)
+
+	code, _, _ = runTestCLI(t, "validate", "--no-such-flag")
+	assert.Equal(t, exitUsage, code)
+
+	code, _, _ = runTestCLI(t, "version", "extra")
+	assert.Equal(t, exitUsage, code)
+}
+
+func TestRunCLI_ValidateValid(t *testing.T) {
+	configPath := setupTestConfig(t, validTestConfig())
+
+	code, stdout, _ := runTestCLI(t, "validate", "--config", configPath)
+	assert.Equal(t, exitOK, code)
+	assert.Contains(t, stdout, "is valid (2 machines)")
+}
+
+func TestRunCLI_ValidateReportsAllErrors(t *testing.T) {
+	configPath := setupTestConfig(t, `server:
+  port: 99999
+  log:
+    format: xml
+machines:
+  - id: m1
+    name: M1
+    mac: invalid
+    broadcast: "192.168.1.255"
+`)
+
+	code, _, stderr := runTestCLI(t, "validate", "-c", configPath)
+	assert.Equal(t, exitInvalidConfig, code)
+	assert.Contains(t, stderr, "invalid server port")
+	assert.Contains(t, stderr, "xml")
+	assert.Contains(t, stderr, "m1")
+}
+
+func TestRunCLI_ValidateListsSectionErrorsSeparately(t *testing.T) {
+	configPath := setupTestConfig(t, validTestConfig()+`audit:
+  max_size_mb: -1
+  max_backups: -1
+`)
+
+	code, _, stderr := runTestCLI(t, "validate", "-c", configPath)
+	assert.Equal(t, exitInvalidConfig, code)
+	assert.Contains(t, stderr, "  - invalid audit.max_size_mb")
+	assert.Contains(t, stderr, "  - invalid audit.max_backups")
+}
+
+func TestRunCLI_ServeInvalidConfig(t *testing.T) {
+	code, _, stderr := runTestCLI(t, "--config", "/nonexistent/gwaihir.yaml")
+	assert.Equal(t, exitInvalidConfig, code)
+	assert.Contains(t, stderr, "failed to load configuration")
+}
+
+func TestRunCLI_PrintConfigRedactsSecrets(t *testing.T) {
+	configPath := setupTestConfig(t, validTestConfig())
+	t.Setenv("GWAIHIR_LOG_LEVEL", "warn")
+
+	code, stdout, _ := runTestCLI(t, "print-config", "--config", configPath)
+	require.Equal(t, exitOK, code)
+	assert.NotContains(t, stdout, "test-key-12345")
+	assert.Contains(t, stdout, "# Set from environment: server.log.level")
+
+	var cfg config.Config
+	require.NoError(t, yaml.Unmarshal([]byte(stdout), &cfg))
+	assert.Equal(t, "[REDACTED]", cfg.Authentication.APIKey)
+	assert.Equal(t, "warn", cfg.Server.Log.Level)
+	assert.Len(t, cfg.Machines, 2)
+}
+
+func TestRunCLI_FlagsOverrideEnv(t *testing.T) {
+	configPath := setupTestConfig(t, validTestConfig())
+	t.Setenv("GWAIHIR_CONFIG", "/nonexistent/gwaihir.yaml")
+	t.Setenv("GWAIHIR_PORT", "7070")
+
+	code, stdout, _ := runTestCLI(t, "print-config", "--config", configPath, "--port", "6060", "--log-level", "debug")
+	require.Equal(t, exitOK, code)
+
+	var cfg config.Config
+	require.NoError(t, yaml.Unmarshal([]byte(stdout), &cfg))
+	assert.Equal(t, 6060, cfg.Server.Port)
+	assert.Equal(t, "debug", cfg.Server.Log.Level)
+
+	code, _, stderr := runTestCLI(t, "print-config", "--config", configPath, "--port", "70000")
+	assert.Equal(t, exitInvalidConfig, code)
+	assert.Contains(t, stderr, "invalid command-line flags")
+}
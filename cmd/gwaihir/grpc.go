@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/josimar-silva/gwaihir/internal/config"
+	grpcdelivery "github.com/josimar-silva/gwaihir/internal/delivery/grpc"
+	httpdelivery "github.com/josimar-silva/gwaihir/internal/delivery/http"
+	"github.com/josimar-silva/gwaihir/internal/infrastructure"
+	"github.com/josimar-silva/gwaihir/internal/usecase"
+)
+
+// grpcShutdownTimeout bounds how long in-flight gRPC calls may take to complete on shutdown.
+const grpcShutdownTimeout = 10 * time.Second
+
+// startGRPCServer serves the gRPC API on grpc.port and returns a function stopping it.
+// Like the HTTP API, calls are authenticated only when an API key is configured, and
+// failures count towards lockout, which is shared with the HTTP API.
+func startGRPCServer(cfg *config.Config, useCase *usecase.WoLUseCase, events grpcdelivery.EventSource, keys grpcdelivery.KeyAuthenticator, lockout *httpdelivery.AuthLockout, auditLog *infrastructure.FileAuditLog, logger *infrastructure.Logger, metrics *infrastructure.Metrics) (func(), error) {
+	server := grpcdelivery.NewServer(useCase, logger, metrics).WithEvents(events)
+	if cfg.Authentication.APIKey != "" {
+		server.WithAPIKeys(keys)
+		if lockout != nil {
+			server.WithLockout(lockout)
+		}
+	}
+	if auditLog != nil {
+		server.WithAuditLog(auditLog)
+	}
+	if cfg.Observability.Tracing.Enabled != nil && *cfg.Observability.Tracing.Enabled {
+		server.WithTracing()
+	}
+	reflection := cfg.GRPC.Reflection == nil || *cfg.GRPC.Reflection
+	if reflection {
+		server.WithReflection()
+	}
+
+	addr := fmt.Sprintf(":%d", cfg.GRPC.Port)
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("gRPC listen failed: %w", err)
+	}
+
+	grpcServer := server.NewGRPCServer()
+	go func() {
+		if err := grpcServer.Serve(listener); err != nil {
+			logger.Error("gRPC server error", infrastructure.Any("error", err))
+		}
+	}()
+
+	logger.Info("gRPC server starting",
+		infrastructure.String("address", listener.Addr().String()),
+		infrastructure.Any("reflection", reflection),
+	)
+
+	return func() {
+		server.Stop(grpcServer, grpcShutdownTimeout)
+		logger.Info("gRPC server stopped")
+	}, nil
+}
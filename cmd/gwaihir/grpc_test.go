@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/josimar-silva/gwaihir/internal/config"
+	httpdelivery "github.com/josimar-silva/gwaihir/internal/delivery/http"
+	"github.com/josimar-silva/gwaihir/internal/infrastructure"
+	gwaihirv1 "github.com/josimar-silva/gwaihir/pkg/api/gwaihir/v1"
+)
+
+func freePort(t *testing.T) int {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	port := listener.Addr().(*net.TCPAddr).Port
+	require.NoError(t, listener.Close())
+	return port
+}
+
+func TestStartGRPCServer(t *testing.T) {
+	cfg, err := config.LoadConfig(setupTestConfig(t, validTestConfig()))
+	require.NoError(t, err)
+	cfg.Authentication.APIKey = "grpc-key"
+	cfg.GRPC.Port = freePort(t)
+
+	logger := infrastructure.NewLogger("text", "error")
+	metrics := getTestMetrics(t)
+	repo, err := initializeRepository(cfg, logger)
+	require.NoError(t, err)
+	events := infrastructure.NewEventBus(0)
+	keys := httpdelivery.NewAPIKeySet(httpdelivery.ConfiguredAPIKeys(cfg.Authentication.APIKey, ""))
+
+	stop, err := startGRPCServer(cfg, initializeUseCase(repo, logger, metrics).WithEvents(events), events, keys, nil, nil, logger, metrics)
+	require.NoError(t, err)
+	defer stop()
+
+	conn, err := grpc.NewClient("127.0.0.1:"+strconv.Itoa(cfg.GRPC.Port), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	health, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, health.GetStatus())
+
+	client := gwaihirv1.NewWoLServiceClient(conn)
+	_, err = client.ListMachines(ctx, &gwaihirv1.ListMachinesRequest{})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	machines, err := client.ListMachines(metadata.AppendToOutgoingContext(ctx, "x-api-key", "grpc-key"), &gwaihirv1.ListMachinesRequest{})
+	require.NoError(t, err)
+	assert.Len(t, machines.GetMachines(), 2)
+
+	// The port is taken while the server runs.
+	_, err = startGRPCServer(cfg, initializeUseCase(repo, logger, metrics), events, keys, nil, nil, logger, metrics)
+	assert.ErrorContains(t, err, "gRPC listen failed")
+}
+
+func TestStartGRPCServer_SharesLockoutWithHTTP(t *testing.T) {
+	cfg, err := config.LoadConfig(setupTestConfig(t, validTestConfig()))
+	require.NoError(t, err)
+	cfg.Authentication.APIKey = "grpc-key"
+	cfg.GRPC.Port = freePort(t)
+
+	logger := infrastructure.NewLogger("text", "error")
+	metrics := getTestMetrics(t)
+	repo, err := initializeRepository(cfg, logger)
+	require.NoError(t, err)
+	events := infrastructure.NewEventBus(0)
+	useCase := initializeUseCase(repo, logger, metrics)
+	keys := httpdelivery.NewAPIKeySet(httpdelivery.ConfiguredAPIKeys(cfg.Authentication.APIKey, ""))
+	lockout := httpdelivery.NewAuthLockout(1, time.Minute, time.Hour, 10)
+
+	stop, err := startGRPCServer(cfg, useCase, events, keys, lockout, nil, logger, metrics)
+	require.NoError(t, err)
+	defer stop()
+
+	conn, err := grpc.NewClient("127.0.0.1:"+strconv.Itoa(cfg.GRPC.Port), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	client := gwaihirv1.NewWoLServiceClient(conn)
+	_, err = client.ListMachines(metadata.AppendToOutgoingContext(ctx, "x-api-key", "wrong"), &gwaihirv1.ListMachinesRequest{})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	_, err = client.ListMachines(metadata.AppendToOutgoingContext(ctx, "x-api-key", "grpc-key"), &gwaihirv1.ListMachinesRequest{})
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	// The failure over gRPC also locks the client out of the HTTP API
+	handler := initializeHandler(useCase, logger, metrics).WithAPIKeys(keys).WithAuthLockout(lockout)
+	router := httpdelivery.NewRouterWithConfig(handler, cfg)
+	req := httptest.NewRequestWithContext(ctx, http.MethodGet, "/api/v1/machines", nil)
+	req.RemoteAddr = "127.0.0.1:40000"
+	req.Header.Set("X-API-Key", "grpc-key")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/josimar-silva/gwaihir/internal/infrastructure"
+	"github.com/josimar-silva/gwaihir/internal/repository"
+)
+
+// machineImporter keeps the machines imported from lease and ethers files up to date.
+type machineImporter struct {
+	repo    *repository.ImportingMachineRepository
+	sources []*repository.MachineSource
+	logger  *infrastructure.Logger
+	metrics *infrastructure.Metrics
+	status  *infrastructure.SourceStatus
+}
+
+func newMachineImporter(repo *repository.ImportingMachineRepository, sources []*repository.MachineSource, logger *infrastructure.Logger, metrics *infrastructure.Metrics) *machineImporter {
+	status := infrastructure.NewSourceStatus()
+	for _, source := range sources {
+		status.Register(source.Name(), source.Format(), source.Path())
+	}
+
+	return &machineImporter{
+		repo:    repo,
+		sources: sources,
+		logger:  logger,
+		metrics: metrics,
+		status:  status,
+	}
+}
+
+// refresh re-parses the source. On failure the previously imported machines are kept.
+func (i *machineImporter) refresh(source *repository.MachineSource, trigger string) {
+	machines, err := source.Load()
+	if err != nil {
+		i.status.RecordFailure(source.Name(), err)
+		i.logger.Error("Machine import failed, keeping previously imported machines",
+			infrastructure.String("source", source.Name()),
+			infrastructure.String("path", source.Path()),
+			infrastructure.String("trigger", trigger),
+			infrastructure.Any("error", err),
+		)
+		return
+	}
+
+	i.repo.SetImported(source.Name(), machines)
+	i.status.RecordSuccess(source.Name(), len(machines), time.Now())
+	i.metrics.ImportedMachines.WithLabelValues(source.Name()).Set(float64(len(machines)))
+	if all, err := i.repo.GetAll(); err == nil {
+		i.metrics.SetConfiguredMachines(all)
+	}
+	i.logger.Info("Machines imported",
+		infrastructure.String("source", source.Name()),
+		infrastructure.String("path", source.Path()),
+		infrastructure.String("trigger", trigger),
+		infrastructure.Int("machines", len(machines)),
+	)
+}
+
+// refreshAll imports every source once.
+func (i *machineImporter) refreshAll(trigger string) {
+	for _, source := range i.sources {
+		i.refresh(source, trigger)
+	}
+}
+
+// run re-imports a source whenever its file changes. It blocks until ctx is done.
+func (i *machineImporter) run(ctx context.Context, interval time.Duration) {
+	for _, source := range i.sources {
+		watcher := infrastructure.NewFileWatcher(source.Path(), interval)
+		go watcher.Watch(ctx, func() {
+			i.refresh(source, "file_change")
+		})
+	}
+
+	<-ctx.Done()
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/josimar-silva/gwaihir/internal/config"
+	"github.com/josimar-silva/gwaihir/internal/infrastructure"
+	"github.com/josimar-silva/gwaihir/internal/repository"
+)
+
+const testLeases = `1767225600 aa:bb:cc:dd:ee:01 192.168.1.10 ws-saruman *
+1767225600 aa:bb:cc:dd:ee:02 192.168.1.11 printer *
+`
+
+func newTestImporter(t *testing.T, leasePath string) (*machineImporter, *repository.ImportingMachineRepository) {
+	t.Helper()
+	base, err := repository.NewInMemoryMachineRepository(&config.Config{})
+	require.NoError(t, err)
+
+	source, err := repository.NewMachineSource(config.MachineSourceConfig{
+		Name:            "office",
+		Format:          "dnsmasq",
+		Path:            leasePath,
+		HostnamePattern: "^ws-",
+		Subnets:         []config.SubnetBroadcastConfig{{Subnet: "192.168.1.0/24"}},
+	})
+	require.NoError(t, err)
+
+	repo := repository.NewImportingMachineRepository(base)
+	logger := infrastructure.NewLogger("text", "error")
+	return newMachineImporter(repo, []*repository.MachineSource{source}, logger, getTestMetrics(t)), repo
+}
+
+func TestMachineImporter_Refresh(t *testing.T) {
+	leasePath := filepath.Join(t.TempDir(), "dnsmasq.leases")
+	require.NoError(t, os.WriteFile(leasePath, []byte(testLeases), 0o600))
+	importer, repo := newTestImporter(t, leasePath)
+
+	importer.refreshAll("test")
+
+	assert.True(t, repo.Exists("ws-saruman"))
+	assert.False(t, repo.Exists("printer"))
+	states := importer.status.Snapshot()
+	require.Len(t, states, 1)
+	assert.Equal(t, 1, states[0].Machines)
+	assert.Empty(t, states[0].LastError)
+	assert.Equal(t, 1.0, testutil.ToFloat64(importer.metrics.ImportedMachines.WithLabelValues("office")))
+
+	// A failed import keeps the previously imported machines
+	require.NoError(t, os.Remove(leasePath))
+	importer.refreshAll("test")
+
+	assert.True(t, repo.Exists("ws-saruman"))
+	assert.NotEmpty(t, importer.status.Snapshot()[0].LastError)
+}
+
+func TestMachineImporter_RunReparsesOnChange(t *testing.T) {
+	leasePath := filepath.Join(t.TempDir(), "dnsmasq.leases")
+	require.NoError(t, os.WriteFile(leasePath, []byte(testLeases), 0o600))
+	importer, repo := newTestImporter(t, leasePath)
+	importer.refreshAll("startup")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go importer.run(ctx, 10*time.Millisecond)
+
+	// Give the watcher time to record its baseline before changing the file
+	time.Sleep(50 * time.Millisecond)
+	updated := testLeases + "1767225600 aa:bb:cc:dd:ee:03 192.168.1.12 ws-gandalf *\n"
+	require.NoError(t, os.WriteFile(leasePath, []byte(updated), 0o600))
+
+	assert.Eventually(t, func() bool {
+		return repo.Exists("ws-gandalf")
+	}, 2*time.Second, 10*time.Millisecond)
+}
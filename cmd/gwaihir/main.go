@@ -4,6 +4,7 @@ package main
 import (
 	"context"
 	"fmt"
+	"io"
 	"net/http"
 	"os"
 	"os/signal"
@@ -14,23 +15,23 @@ import (
 
 	"github.com/josimar-silva/gwaihir/internal/config"
 	httpdelivery "github.com/josimar-silva/gwaihir/internal/delivery/http"
+	"github.com/josimar-silva/gwaihir/internal/domain"
 	"github.com/josimar-silva/gwaihir/internal/infrastructure"
 	"github.com/josimar-silva/gwaihir/internal/repository"
 	"github.com/josimar-silva/gwaihir/internal/usecase"
 )
 
 func main() {
-	if err := run(); err != nil {
-		fmt.Fprintf(os.Stderr, "Application failed: %v\n", err)
-		os.Exit(1)
-	}
+	os.Exit(runCLI(os.Args[1:], os.Stdout, os.Stderr))
 }
 
-func run() error {
-	cfg, err := loadConfiguration()
+// serve starts the server and blocks until it shuts down.
+func serve(flags *configFlags) error {
+	cfg, err := flags.load()
 	if err != nil {
-		return fmt.Errorf("failed to load configuration: %w", err)
+		return invalidConfig(fmt.Errorf("failed to load configuration: %w", err))
 	}
+	configPath := flags.configPath()
 
 	logger := initializeLogger(cfg)
 
@@ -39,35 +40,115 @@ func run() error {
 		return fmt.Errorf("failed to initialize metrics: %w", err)
 	}
 
+	if cfg.Observability.Tracing.Enabled != nil && *cfg.Observability.Tracing.Enabled {
+		stopTracing, err := startTracing(cfg, logger)
+		if err != nil {
+			return err
+		}
+		defer stopTracing()
+	}
+
 	repo, err := initializeRepository(cfg, logger)
 	if err != nil {
 		return fmt.Errorf("failed to initialize repository: %w", err)
 	}
+	if closer, ok := repo.(io.Closer); ok {
+		defer func() {
+			_ = closer.Close()
+		}()
+	}
 
-	logMachineConfiguration(logger, metrics, repo)
-	useCase := initializeUseCase(repo, logger, metrics)
-	handler := initializeHandler(useCase, logger, metrics)
-	router := initializeRouter(handler, cfg, logger)
+	auditLog, err := initializeAuditLog(cfg, logger)
+	if err != nil {
+		return fmt.Errorf("failed to initialize audit log: %w", err)
+	}
+	if auditLog != nil {
+		defer func() {
+			_ = auditLog.Close()
+		}()
+	}
 
-	if err := startServer(cfg, router, logger); err != nil {
-		return fmt.Errorf("server error: %w", err)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	machines, sourceStatus, err := startMachineImporter(ctx, cfg, repo, logger, metrics)
+	if err != nil {
+		return fmt.Errorf("failed to initialize machine sources: %w", err)
 	}
 
-	return nil
-}
+	logMachineConfiguration(logger, metrics, machines)
+	events := infrastructure.NewEventBus(infrastructure.DefaultEventBuffer)
+	useCase := initializeUseCase(machines, logger, metrics).WithEvents(events)
+	history := infrastructure.NewEventHistory(cfg.Events.HistorySize)
+	historyEvents, unsubscribeHistory := events.Subscribe()
+	defer unsubscribeHistory()
+	go history.Consume(historyEvents)
 
-func loadConfiguration() (*config.Config, error) {
-	configPath := os.Getenv("GWAIHIR_CONFIG")
-	if configPath == "" {
-		configPath = "/etc/gwaihir/gwaihir.yaml"
+	handler := initializeHandler(useCase, logger, metrics).WithEventStream(history)
+	if auditLog != nil {
+		handler.WithAuditLog(auditLog)
+	}
+	if sourceStatus != nil {
+		handler.WithSourceStatus(sourceStatus)
+	}
+	if writable, ok := repo.(domain.WritableMachineRepository); ok {
+		handler.WithMachineManagement(usecase.NewMachineUseCase(writable, logger, metrics).WithAllowlist(machines).WithEvents(events))
+	}
+	if cfg.Discovery.Enabled != nil && *cfg.Discovery.Enabled {
+		handler.WithDiscovery(usecase.NewDiscoveryUseCase(infrastructure.NewARPTable(cfg.Discovery.ARPPath), machines, logger))
+		logger.Info("Neighbor discovery enabled", infrastructure.String("arp_path", cfg.Discovery.ARPPath))
 	}
 
-	cfg, err := config.LoadConfig(configPath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to load config from %s: %w", configPath, err)
+	keys := httpdelivery.NewAPIKeySet(httpdelivery.ConfiguredAPIKeys(cfg.Authentication.APIKey, cfg.Authentication.AdminAPIKey))
+	handler.WithAPIKeys(keys)
+	lockout := httpdelivery.NewAuthLockoutFromConfig(cfg)
+	if lockout != nil {
+		handler.WithAuthLockout(lockout)
 	}
 
-	return cfg, nil
+	var reloadable reloadableRepository
+	if r, ok := repo.(reloadableRepository); ok {
+		reloadable = r
+	} else {
+		logger.Info("Machine reload disabled, machines are managed by the storage driver",
+			infrastructure.String("driver", cfg.Storage.Driver),
+		)
+	}
+	handler.WithReloadStatus(startConfigReloader(ctx, cfg, configPath, reloadable, machines, keys, events, logger, metrics))
+
+	if cfg.GRPC.Enabled != nil && *cfg.GRPC.Enabled {
+		stopGRPC, err := startGRPCServer(cfg, useCase, events, keys, lockout, auditLog, logger, metrics)
+		if err != nil {
+			return fmt.Errorf("failed to start gRPC server: %w", err)
+		}
+		defer stopGRPC()
+	}
+
+	if cfg.MQTT.Enabled != nil && *cfg.MQTT.Enabled {
+		stopMQTT, err := startMQTTClient(cfg, useCase, events, auditLog, logger, metrics)
+		if err != nil {
+			return err
+		}
+		defer stopMQTT()
+	}
+
+	if len(cfg.Webhooks.Subscriptions) > 0 {
+		stopWebhooks, err := startWebhookDispatcher(cfg, events, logger, metrics)
+		if err != nil {
+			return err
+		}
+		defer stopWebhooks()
+	}
+
+	router := initializeRouter(handler, cfg, logger)
+
+	// Event streams never end on their own, so they are closed for the server to
+	// shut down gracefully.
+	if err := startServer(cfg, router, logger, history.Close); err != nil {
+		return fmt.Errorf("server error: %w", err)
+	}
+
+	return nil
 }
 
 func initializeLogger(cfg *config.Config) *infrastructure.Logger {
@@ -84,10 +165,36 @@ func initializeMetrics(logger *infrastructure.Logger) (*infrastructure.Metrics,
 		logger.Error("Failed to initialize metrics", infrastructure.Any("error", err))
 		return nil, fmt.Errorf("metrics initialization failed: %w", err)
 	}
+	metrics.SetBuildInfo(Version, GitCommit)
 	return metrics, nil
 }
 
-func initializeRepository(cfg *config.Config, logger *infrastructure.Logger) (*repository.InMemoryMachineRepository, error) {
+func initializeRepository(cfg *config.Config, logger *infrastructure.Logger) (domain.MachineRepository, error) {
+	if cfg.Storage.Driver == config.StorageDriverBolt {
+		repo, err := repository.NewBoltMachineRepository(cfg, cfg.Storage.Path)
+		if err != nil {
+			logger.Error("Failed to initialize machine repository", infrastructure.Any("error", err))
+			return nil, fmt.Errorf("repository initialization failed: %w", err)
+		}
+		logger.Info("Machine database opened",
+			infrastructure.String("driver", cfg.Storage.Driver),
+			infrastructure.String("path", cfg.Storage.Path),
+		)
+		return repo, nil
+	}
+
+	if cfg.Storage.MachinesFile != "" {
+		repo, err := repository.NewFileMachineRepository(cfg, cfg.Storage.MachinesFile)
+		if err != nil {
+			logger.Error("Failed to initialize machine repository", infrastructure.Any("error", err))
+			return nil, fmt.Errorf("repository initialization failed: %w", err)
+		}
+		logger.Info("Runtime machine management enabled",
+			infrastructure.String("machines_file", cfg.Storage.MachinesFile),
+		)
+		return repo, nil
+	}
+
 	repo, err := repository.NewInMemoryMachineRepository(cfg)
 	if err != nil {
 		logger.Error("Failed to initialize machine repository", infrastructure.Any("error", err))
@@ -96,16 +203,107 @@ func initializeRepository(cfg *config.Config, logger *infrastructure.Logger) (*r
 	return repo, nil
 }
 
-func logMachineConfiguration(logger *infrastructure.Logger, metrics *infrastructure.Metrics, repo *repository.InMemoryMachineRepository) {
+func initializeAuditLog(cfg *config.Config, logger *infrastructure.Logger) (*infrastructure.FileAuditLog, error) {
+	if cfg.Audit.Enabled == nil || !*cfg.Audit.Enabled {
+		return nil, nil
+	}
+
+	auditLog, err := infrastructure.NewFileAuditLog(cfg.Audit.Path, int64(cfg.Audit.MaxSizeMB)*1024*1024, cfg.Audit.MaxBackups)
+	if err != nil {
+		logger.Error("Failed to open audit log", infrastructure.Any("error", err))
+		return nil, fmt.Errorf("audit log initialization failed: %w", err)
+	}
+
+	if err := auditLog.Verify(); err != nil {
+		logger.Warn("Audit trail failed verification", infrastructure.String("path", cfg.Audit.Path), infrastructure.Any("error", err))
+	}
+
+	logger.Info("Audit log enabled", infrastructure.String("path", cfg.Audit.Path))
+	return auditLog, nil
+}
+
+// startMachineImporter imports machines from the configured lease and ethers sources and
+// keeps them up to date. It returns the repository serving both the machines of repo and
+// the imported machines, or repo itself when no sources are configured.
+func startMachineImporter(ctx context.Context, cfg *config.Config, repo domain.MachineRepository, logger *infrastructure.Logger, metrics *infrastructure.Metrics) (domain.MachineRepository, *infrastructure.SourceStatus, error) {
+	machines, importer, err := importMachines(cfg, repo, logger, metrics)
+	if err != nil || importer == nil {
+		return machines, nil, err
+	}
+
+	go importer.run(ctx, cfg.Reload.Interval)
+	return machines, importer.status, nil
+}
+
+// importMachines imports the configured lease and ethers sources once. Without sources,
+// repo is returned as is and the importer is nil.
+func importMachines(cfg *config.Config, repo domain.MachineRepository, logger *infrastructure.Logger, metrics *infrastructure.Metrics) (domain.MachineRepository, *machineImporter, error) {
+	if len(cfg.Sources) == 0 {
+		return repo, nil, nil
+	}
+
+	sources := make([]*repository.MachineSource, 0, len(cfg.Sources))
+	for _, sourceConfig := range cfg.Sources {
+		source, err := repository.NewMachineSource(sourceConfig)
+		if err != nil {
+			logger.Error("Failed to initialize machine source", infrastructure.Any("error", err))
+			return nil, nil, fmt.Errorf("machine source initialization failed: %w", err)
+		}
+		sources = append(sources, source)
+	}
+
+	importing := repository.NewImportingMachineRepository(repo)
+	importer := newMachineImporter(importing, sources, logger, metrics)
+	importer.refreshAll("startup")
+
+	return importing, importer, nil
+}
+
+func logMachineConfiguration(logger *infrastructure.Logger, metrics *infrastructure.Metrics, repo domain.MachineRepository) {
 	machines, _ := repo.GetAll()
 	logger.Info("Machine configuration loaded", infrastructure.Int("count", len(machines)))
 	for _, m := range machines {
 		logger.Debug("Machine registered", infrastructure.String("name", m.Name), infrastructure.String("id", m.ID))
 	}
-	metrics.ConfiguredMachines.Set(float64(len(machines)))
+	metrics.SetConfiguredMachines(machines)
+}
+
+// startConfigReloader starts reloading the configuration on SIGHUP and file changes.
+// A nil repo disables reloading of the machine allowlist, keeping API key rotation.
+// machines is the allowlist served to clients, including imported machines.
+func startConfigReloader(ctx context.Context, cfg *config.Config, configPath string, repo reloadableRepository, machines domain.MachineRepository, keys *httpdelivery.APIKeySet, events domain.EventPublisher, logger *infrastructure.Logger, metrics *infrastructure.Metrics) *infrastructure.ReloadStatus {
+	loadedAt := time.Now()
+	metrics.ConfigLastReload.Set(float64(loadedAt.Unix()))
+	status := infrastructure.NewReloadStatus(loadedAt)
+
+	sighup := make(chan os.Signal, 1)
+	signal.Notify(sighup, syscall.SIGHUP)
+	go func() {
+		<-ctx.Done()
+		signal.Stop(sighup)
+	}()
+
+	watch := cfg.Reload.Watch != nil && *cfg.Reload.Watch
+	reloader := newConfigReloader(configPath, repo, logger, metrics, status)
+	// Include patterns and secret files are taken from the startup configuration;
+	// changing them requires a restart.
+	reloader.includes = append(cfg.IncludePatterns(configPath), cfg.SecretFiles(configPath)...)
+	reloader.keys = keys
+	if repo != nil {
+		reloader.machines = machines
+	}
+	reloader.auth = cfg.Authentication
+	reloader.events = events
+	go reloader.run(ctx, sighup, watch, cfg.Reload.Interval)
+
+	logger.Info("Configuration reload enabled",
+		infrastructure.Any("watch", watch),
+		infrastructure.Duration("interval", cfg.Reload.Interval),
+	)
+	return status
 }
 
-func initializeUseCase(repo *repository.InMemoryMachineRepository, logger *infrastructure.Logger, metrics *infrastructure.Metrics) *usecase.WoLUseCase {
+func initializeUseCase(repo domain.MachineRepository, logger *infrastructure.Logger, metrics *infrastructure.Metrics) *usecase.WoLUseCase {
 	packetSender := repository.NewWoLPacketSender()
 	return usecase.NewWoLUseCase(repo, packetSender, logger, metrics)
 }
@@ -128,7 +326,9 @@ func initializeRouter(handler *httpdelivery.Handler, cfg *config.Config, logger
 	return httpdelivery.NewRouterWithConfig(handler, cfg)
 }
 
-func startServer(cfg *config.Config, router *gin.Engine, logger *infrastructure.Logger) error {
+// startServer serves router until a shutdown signal is received. onShutdown functions
+// are called when the shutdown starts, to end long-lived requests.
+func startServer(cfg *config.Config, router *gin.Engine, logger *infrastructure.Logger, onShutdown ...func()) error {
 	addr := fmt.Sprintf(":%d", cfg.Server.Port)
 	server := &http.Server{
 		Addr:              addr,
@@ -138,6 +338,9 @@ func startServer(cfg *config.Config, router *gin.Engine, logger *infrastructure.
 		WriteTimeout:      10 * time.Second,
 		IdleTimeout:       30 * time.Second,
 	}
+	for _, f := range onShutdown {
+		server.RegisterOnShutdown(f)
+	}
 
 	logger.Info("Server starting",
 		infrastructure.String("address", addr),
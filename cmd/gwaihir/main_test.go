@@ -1,50 +1,38 @@
 package main
 
 import (
+	"bytes"
+	"io"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/josimar-silva/gwaihir/internal/config"
+	"github.com/josimar-silva/gwaihir/internal/domain"
 	"github.com/josimar-silva/gwaihir/internal/infrastructure"
 )
 
-var (
-	// globalMetrics is initialized once for all tests to avoid Prometheus registration conflicts
-	globalMetrics    *infrastructure.Metrics
-	metricsInitOnce  bool
-	metricsInitError error
-)
-
 // TestMain sets up and tears down test environment
 func TestMain(m *testing.M) {
 	// Set Gin to test mode to reduce noise in test output
 	gin.SetMode(gin.TestMode)
 
-	// Initialize metrics once for all tests
-	logger := infrastructure.NewLogger("text", "error")
-	globalMetrics, metricsInitError = infrastructure.NewMetrics()
-	if metricsInitError == nil {
-		metricsInitOnce = true
-	}
-	// Suppress logger output during tests
-	_ = logger
-
 	code := m.Run()
 	os.Exit(code)
 }
 
-// getTestMetrics returns the global metrics instance for testing
+// getTestMetrics returns a metrics instance with its own registry for testing
 func getTestMetrics(t *testing.T) *infrastructure.Metrics {
 	t.Helper()
-	if !metricsInitOnce {
-		t.Fatalf("Failed to initialize metrics: %v", metricsInitError)
-	}
-	return globalMetrics
+	metrics, err := infrastructure.NewMetrics()
+	require.NoError(t, err)
+	return metrics
 }
 
 // setupTestConfig creates a temporary config file for testing
@@ -83,8 +71,8 @@ observability:
 `
 }
 
-// TestLoadConfiguration tests the loadConfiguration function
-func TestLoadConfiguration(t *testing.T) {
+// TestConfigFlagsLoad tests loading the configuration without flags
+func TestConfigFlagsLoad(t *testing.T) {
 	tests := []struct {
 		name        string
 		configPath  string
@@ -153,7 +141,7 @@ machines: []
 				defer tt.cleanupEnv()
 			}
 
-			cfg, err := loadConfiguration()
+			cfg, err := (&configFlags{}).load()
 
 			if tt.wantErr {
 				assert.Error(t, err)
@@ -174,10 +162,10 @@ machines: []
 	}
 }
 
-func TestLoadConfiguration_DefaultPath(t *testing.T) {
+func TestConfigFlagsLoad_DefaultPath(t *testing.T) {
 	require.NoError(t, os.Unsetenv("GWAIHIR_CONFIG"))
 
-	_, err := loadConfiguration()
+	_, err := (&configFlags{}).load()
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "/etc/gwaihir/gwaihir.yaml")
 }
@@ -233,14 +221,18 @@ func TestInitializeLogger(t *testing.T) {
 
 // TestInitializeMetrics tests that metrics are properly initialized
 func TestInitializeMetrics(t *testing.T) {
-	// Use the global metrics instance since Prometheus doesn't allow re-registration
-	metrics := getTestMetrics(t)
+	metrics, err := initializeMetrics(infrastructure.NewLogger("text", "error"))
+	require.NoError(t, err)
 
 	assert.NotNil(t, metrics)
-	assert.NotNil(t, metrics.WoLPacketsSent)
-	assert.NotNil(t, metrics.WoLPacketsFailed)
+	assert.NotNil(t, metrics.WoLPackets)
 	assert.NotNil(t, metrics.RequestDuration)
 	assert.NotNil(t, metrics.ConfiguredMachines)
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.BuildInfo.WithLabelValues(Version, GitCommit)))
+
+	// Every call owns a registry, so metrics can be initialized again.
+	_, err = initializeMetrics(infrastructure.NewLogger("text", "error"))
+	require.NoError(t, err)
 }
 
 // TestInitializeRepository tests the initializeRepository function
@@ -359,6 +351,35 @@ func TestLogMachineConfiguration(t *testing.T) {
 	assert.NotNil(t, metrics.ConfiguredMachines)
 }
 
+// TestInitializeRepository_BoltDriver tests that the bolt driver opens a writable database
+func TestInitializeRepository_BoltDriver(t *testing.T) {
+	cfg := &config.Config{
+		Machines: []config.MachineConfig{
+			{ID: "test", Name: "Test", MAC: "AA:BB:CC:DD:EE:FF", Broadcast: "192.168.1.255"},
+		},
+		Storage: config.StorageConfig{
+			Driver: config.StorageDriverBolt,
+			Path:   filepath.Join(t.TempDir(), "machines.db"),
+		},
+	}
+	logger := infrastructure.NewLogger("text", "error")
+
+	repo, err := initializeRepository(cfg, logger)
+	require.NoError(t, err)
+
+	closer, ok := repo.(io.Closer)
+	require.True(t, ok)
+	defer func() {
+		_ = closer.Close()
+	}()
+
+	_, writable := repo.(domain.WritableMachineRepository)
+	assert.True(t, writable)
+	_, reloadable := repo.(reloadableRepository)
+	assert.False(t, reloadable)
+	assert.True(t, repo.Exists("test"))
+}
+
 // TestInitializeUseCase tests the initializeUseCase function
 func TestInitializeUseCase(t *testing.T) {
 	cfg := &config.Config{
@@ -406,6 +427,39 @@ func TestInitializeHandler(t *testing.T) {
 	assert.NotNil(t, handler)
 }
 
+// TestInitializeAuditLog tests the initializeAuditLog function
+func TestInitializeAuditLog(t *testing.T) {
+	logger := infrastructure.NewLogger("text", "error")
+
+	disabled := false
+	auditLog, err := initializeAuditLog(&config.Config{Audit: config.AuditConfig{Enabled: &disabled}}, logger)
+	require.NoError(t, err)
+	assert.Nil(t, auditLog)
+
+	enabled := true
+	path := filepath.Join(t.TempDir(), "audit", "audit.jsonl")
+	auditLog, err = initializeAuditLog(&config.Config{Audit: config.AuditConfig{Enabled: &enabled, Path: path, MaxSizeMB: 1, MaxBackups: 1}}, logger)
+	require.NoError(t, err)
+	require.NotNil(t, auditLog)
+	assert.NoError(t, auditLog.Close())
+	assert.FileExists(t, path)
+}
+
+func TestInitializeAuditLog_WarnsOnBrokenChain(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	writeAuditTrail(t, path, domain.AuditEvent{Action: domain.AuditActionWake, MachineID: "server1"})
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, []byte(strings.Replace(string(data), "server1", "server2", 1)), 0o600))
+
+	var logs bytes.Buffer
+	enabled := true
+	auditLog, err := initializeAuditLog(&config.Config{Audit: config.AuditConfig{Enabled: &enabled, Path: path}}, infrastructure.NewLoggerWithWriter(&logs, "text", "warn"))
+	require.NoError(t, err)
+	assert.NoError(t, auditLog.Close())
+	assert.Contains(t, logs.String(), "Audit trail failed verification")
+}
+
 // TestInitializeRouter tests the initializeRouter function
 func TestInitializeRouter(t *testing.T) {
 	tests := []struct {
@@ -484,21 +538,20 @@ func TestInitializeRouter(t *testing.T) {
 // - Gin mode can only be set once per process
 // - Testing environment variable precedence for GIN_MODE would require subprocess testing
 
-// TestRun tests the run function with valid configuration
-func TestRun(t *testing.T) {
-	// This test is challenging because run() calls startServer() which blocks.
-	// We'll test that run returns error when config is missing
+// TestRunCLI_ServeMissingConfig tests serving without a configuration file.
+// Serving a valid configuration blocks until shutdown, so only failures are tested.
+func TestRunCLI_ServeMissingConfig(t *testing.T) {
 	require.NoError(t, os.Setenv("GWAIHIR_CONFIG", "/nonexistent/path/config.yaml"))
 	defer func() { require.NoError(t, os.Unsetenv("GWAIHIR_CONFIG")) }()
 
-	err := run()
+	code, _, stderr := runTestCLI(t)
 
-	assert.Error(t, err)
-	assert.Contains(t, err.Error(), "failed to load configuration")
+	assert.Equal(t, exitInvalidConfig, code)
+	assert.Contains(t, stderr, "failed to load configuration")
 }
 
-// TestRun_InvalidConfig tests run function with invalid configuration
-func TestRun_InvalidConfig(t *testing.T) {
+// TestRunCLI_ServeInvalidConfigFromEnv tests serving an invalid configuration
+func TestRunCLI_ServeInvalidConfigFromEnv(t *testing.T) {
 	invalidConfig := `server:
   port: 99999
   log:
@@ -510,10 +563,10 @@ machines: []
 	require.NoError(t, os.Setenv("GWAIHIR_CONFIG", configPath))
 	defer func() { require.NoError(t, os.Unsetenv("GWAIHIR_CONFIG")) }()
 
-	err := run()
+	code, _, stderr := runTestCLI(t)
 
-	assert.Error(t, err)
-	assert.Contains(t, err.Error(), "failed to load configuration")
+	assert.Equal(t, exitInvalidConfig, code)
+	assert.Contains(t, stderr, "failed to load configuration")
 }
 
 // Note: TestStartServer was removed because:
@@ -532,7 +585,7 @@ func TestVersion_GlobalVariables(t *testing.T) {
 }
 
 // Benchmark tests
-func BenchmarkLoadConfiguration(b *testing.B) {
+func BenchmarkConfigFlagsLoad(b *testing.B) {
 	configContent := validTestConfig()
 	tmpDir := b.TempDir()
 	configPath := filepath.Join(tmpDir, "bench-config.yaml")
@@ -544,7 +597,7 @@ func BenchmarkLoadConfiguration(b *testing.B) {
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		_, err := loadConfiguration()
+		_, err := (&configFlags{}).load()
 		if err != nil {
 			b.Fatal(err)
 		}
@@ -571,13 +624,11 @@ func BenchmarkInitializeLogger(b *testing.B) {
 }
 
 func BenchmarkInitializeMetrics(b *testing.B) {
-	// Metrics can only be initialized once due to Prometheus registration
-	// This benchmark tests the getTestMetrics helper performance
+	logger := infrastructure.NewLogger("text", "error")
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		metrics := globalMetrics
-		if metrics == nil {
-			b.Fatal("metrics is nil")
+		if _, err := initializeMetrics(logger); err != nil {
+			b.Fatal(err)
 		}
 	}
 }
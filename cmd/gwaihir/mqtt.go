@@ -0,0 +1,38 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/josimar-silva/gwaihir/internal/config"
+	mqttdelivery "github.com/josimar-silva/gwaihir/internal/delivery/mqtt"
+	"github.com/josimar-silva/gwaihir/internal/infrastructure"
+	"github.com/josimar-silva/gwaihir/internal/usecase"
+)
+
+// startMQTTClient connects to the configured MQTT broker in the background and returns
+// a function disconnecting from it. Home Assistant discovery payloads follow machine
+// changes at the configuration reload interval.
+func startMQTTClient(cfg *config.Config, useCase *usecase.WoLUseCase, events mqttdelivery.EventSource, auditLog *infrastructure.FileAuditLog, logger *infrastructure.Logger, metrics *infrastructure.Metrics) (func(), error) {
+	client := mqttdelivery.NewClient(cfg.MQTT, useCase, logger, metrics).WithEvents(events)
+	if auditLog != nil {
+		client.WithAuditLog(auditLog)
+	}
+	if cfg.Reload.Interval > 0 {
+		client.WithSyncInterval(cfg.Reload.Interval)
+	}
+
+	if err := client.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start MQTT client: %w", err)
+	}
+
+	logger.Info("MQTT client starting",
+		infrastructure.String("broker", config.RedactURL(cfg.MQTT.Broker)),
+		infrastructure.String("topic_prefix", cfg.MQTT.TopicPrefix),
+		infrastructure.Any("home_assistant", cfg.MQTT.HomeAssistant.Enabled != nil && *cfg.MQTT.HomeAssistant.Enabled),
+	)
+
+	return func() {
+		client.Stop()
+		logger.Info("MQTT client stopped")
+	}, nil
+}
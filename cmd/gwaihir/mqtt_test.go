@@ -0,0 +1,36 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/josimar-silva/gwaihir/internal/config"
+	"github.com/josimar-silva/gwaihir/internal/infrastructure"
+)
+
+func TestStartMQTTClient(t *testing.T) {
+	cfg, err := config.LoadConfig(setupTestConfig(t, validTestConfig()+`
+mqtt:
+  enabled: true
+  broker: tcp://127.0.0.1:1
+`))
+	require.NoError(t, err)
+
+	logger := infrastructure.NewLogger("text", "error")
+	metrics := getTestMetrics(t)
+	repo, err := initializeRepository(cfg, logger)
+	require.NoError(t, err)
+	useCase := initializeUseCase(repo, logger, metrics)
+	events := infrastructure.NewEventBus(0)
+
+	// An unreachable broker is retried in the background rather than failing startup.
+	stop, err := startMQTTClient(cfg, useCase, events, nil, logger, metrics)
+	require.NoError(t, err)
+	stop()
+
+	cfg.MQTT.TLS.CAFile = "/nonexistent/ca.pem"
+	_, err = startMQTTClient(cfg, useCase, events, nil, logger, metrics)
+	assert.ErrorContains(t, err, "failed to start MQTT client: failed to read mqtt.tls.ca_file")
+}
@@ -0,0 +1,236 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/josimar-silva/gwaihir/internal/domain"
+	"github.com/josimar-silva/gwaihir/internal/infrastructure"
+	"github.com/josimar-silva/gwaihir/internal/usecase"
+)
+
+// newPacketSender creates the packet sender used by the offline commands.
+var newPacketSender = func(port int) domain.WoLPacketSender {
+	return infrastructure.NewWoLPacketSender().WithPort(port)
+}
+
+// wakeResult is the outcome of the wake command.
+type wakeResult struct {
+	MachineID string `json:"machine_id,omitempty"`
+	MAC       string `json:"mac,omitempty"`
+	Broadcast string `json:"broadcast,omitempty"`
+	Port      int    `json:"port"`
+	Unlisted  bool   `json:"unlisted"`
+	Status    string `json:"status"`
+	Error     string `json:"error,omitempty"`
+}
+
+// openOffline loads the configuration and machines the same way the server does, for
+// commands that act without a running server. Logs go to stderr so that stdout only
+// carries the command output.
+func openOffline(flags *configFlags, stderr io.Writer, sender domain.WoLPacketSender) (*usecase.WoLUseCase, func(), error) {
+	cfg, err := flags.load()
+	if err != nil {
+		return nil, nil, invalidConfig(fmt.Errorf("failed to load configuration: %w", err))
+	}
+
+	logger := infrastructure.NewLoggerWithWriter(stderr, cfg.Server.Log.Format, "warn")
+
+	metrics, err := initializeMetrics(logger)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to initialize metrics: %w", err)
+	}
+
+	repo, err := initializeRepository(cfg, logger)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to initialize repository: %w", err)
+	}
+	closeRepo := func() {
+		if closer, ok := repo.(io.Closer); ok {
+			_ = closer.Close()
+		}
+	}
+
+	machines, _, err := importMachines(cfg, repo, logger, metrics)
+	if err != nil {
+		closeRepo()
+		return nil, nil, fmt.Errorf("failed to initialize machine sources: %w", err)
+	}
+
+	return usecase.NewWoLUseCase(machines, sender, logger, metrics), closeRepo, nil
+}
+
+func runWake(args []string, stdout, stderr io.Writer) error {
+	var (
+		flags         configFlags
+		broadcast     string
+		port          int
+		jsonOutput    bool
+		allowUnlisted bool
+	)
+	fs := newFlagSet("wake", stderr)
+	fs.Usage = func() {
+		_, _ = fmt.Fprintf(fs.Output(), "Usage: gwaihir wake <machine-id|MAC> [flags]\n\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	flags.registerPath(fs)
+	fs.StringVar(&broadcast, "broadcast", "", "Broadcast address to send the packet to, overrides the machine's broadcast")
+	fs.IntVar(&port, "port", infrastructure.DefaultWoLPort, "UDP port to send the packet to")
+	fs.BoolVar(&jsonOutput, "json", false, "Print the result as JSON")
+	fs.BoolVar(&allowUnlisted, "allow-unlisted", false, "Allow waking a MAC address that is not in the allowlist")
+
+	target, err := parseFlagsWithArg(fs, args, "machine ID or MAC address")
+	if err != nil {
+		return err
+	}
+	if port < 1 || port > 65535 {
+		return usageError("--port must be between 1 and 65535, got %d", port)
+	}
+
+	result := wakeResult{Port: port, Broadcast: broadcast}
+	if err := wake(&flags, target, allowUnlisted, stderr, &result); err != nil {
+		result.Status = "failed"
+		result.Error = err.Error()
+		if jsonOutput {
+			_ = writeJSON(stdout, result)
+		}
+		return err
+	}
+
+	result.Status = "sent"
+	if jsonOutput {
+		return writeJSON(stdout, result)
+	}
+
+	name := result.MachineID
+	if result.Unlisted {
+		name = "unlisted machine"
+	}
+	_, _ = fmt.Fprintf(stdout, "Magic packet sent to %s (%s) via %s\n",
+		name, result.MAC, net.JoinHostPort(result.Broadcast, strconv.Itoa(result.Port)))
+	return nil
+}
+
+// wake sends a magic packet to target and fills in result. target is either the ID
+// or the MAC address of an allowlisted machine, or any MAC address if allowUnlisted is set.
+func wake(flags *configFlags, target string, allowUnlisted bool, stderr io.Writer, result *wakeResult) error {
+	useCase, closeRepo, err := openOffline(flags, stderr, newPacketSender(result.Port))
+	if err != nil {
+		return err
+	}
+	defer closeRepo()
+
+	machine, err := resolveWakeTarget(useCase, target)
+	if err != nil {
+		return err
+	}
+
+	if machine != nil {
+		result.MachineID = machine.ID
+		result.MAC = machine.NormalizeMAC()
+		if result.Broadcast == "" {
+			result.Broadcast = machine.Broadcast
+		}
+		return useCase.SendWakePacketVia(context.Background(), machine.ID, result.Broadcast)
+	}
+
+	result.MAC = (&domain.Machine{MAC: target}).NormalizeMAC()
+	result.Unlisted = true
+	if !allowUnlisted {
+		return fmt.Errorf("MAC address %s is not in the allowlist, pass --allow-unlisted to wake it anyway", result.MAC)
+	}
+	if result.Broadcast == "" {
+		return usageError("--broadcast is required to wake a MAC address that is not in the allowlist")
+	}
+	return useCase.SendUnlistedWakePacket(context.Background(), target, result.Broadcast)
+}
+
+// resolveWakeTarget returns the allowlisted machine with the given ID or MAC address.
+// It returns a nil machine and no error when target is a MAC address outside the allowlist.
+func resolveWakeTarget(useCase *usecase.WoLUseCase, target string) (*domain.Machine, error) {
+	machine, err := useCase.GetMachine(target)
+	if err == nil {
+		return machine, nil
+	}
+	if !errors.Is(err, domain.ErrMachineNotFound) {
+		return nil, fmt.Errorf("failed to get machine: %w", err)
+	}
+	if domain.ValidateMAC(target) != nil {
+		return nil, fmt.Errorf("machine %q: %w", target, err)
+	}
+
+	machines, err := useCase.ListMachines()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list machines: %w", err)
+	}
+	mac := (&domain.Machine{MAC: target}).NormalizeMAC()
+	for _, machine := range machines {
+		if machine.NormalizeMAC() == mac {
+			return machine, nil
+		}
+	}
+	return nil, nil
+}
+
+func runList(args []string, stdout, stderr io.Writer) error {
+	var (
+		flags      configFlags
+		jsonOutput bool
+	)
+	fs := newFlagSet("list", stderr)
+	flags.registerPath(fs)
+	fs.BoolVar(&jsonOutput, "json", false, "Print the machines as JSON")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	useCase, closeRepo, err := openOffline(&flags, stderr, newPacketSender(infrastructure.DefaultWoLPort))
+	if err != nil {
+		return err
+	}
+	defer closeRepo()
+
+	machines, err := useCase.ListMachines()
+	if err != nil {
+		return fmt.Errorf("failed to list machines: %w", err)
+	}
+	if machines == nil {
+		machines = []*domain.Machine{}
+	}
+	sort.Slice(machines, func(i, j int) bool {
+		return machines[i].ID < machines[j].ID
+	})
+
+	if jsonOutput {
+		return writeJSON(stdout, machines)
+	}
+
+	if len(machines) == 0 {
+		_, _ = fmt.Fprintln(stdout, "No machines configured")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "ID\tNAME\tMAC\tBROADCAST")
+	for _, machine := range machines {
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", machine.ID, machine.Name, machine.NormalizeMAC(), machine.Broadcast)
+	}
+	return w.Flush()
+}
+
+// writeJSON writes v to w as indented JSON.
+func writeJSON(w io.Writer, v any) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(v); err != nil {
+		return fmt.Errorf("failed to encode output: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/josimar-silva/gwaihir/internal/domain"
+)
+
+type sentPacket struct {
+	mac       string
+	broadcast string
+	port      int
+}
+
+type fakePacketSender struct {
+	port    int
+	sent    *[]sentPacket
+	sendErr error
+}
+
+func (s *fakePacketSender) SendMagicPacket(_ context.Context, mac, broadcast string) error {
+	if s.sendErr != nil {
+		return s.sendErr
+	}
+	*s.sent = append(*s.sent, sentPacket{mac: mac, broadcast: broadcast, port: s.port})
+	return nil
+}
+
+// useFakePacketSender replaces the packet sender used by the offline commands for
+// the duration of the test and returns the packets sent.
+func useFakePacketSender(t *testing.T, sendErr error) *[]sentPacket {
+	t.Helper()
+	sent := &[]sentPacket{}
+
+	originalSender := newPacketSender
+	newPacketSender = func(port int) domain.WoLPacketSender {
+		return &fakePacketSender{port: port, sent: sent, sendErr: sendErr}
+	}
+	t.Cleanup(func() {
+		newPacketSender = originalSender
+	})
+	return sent
+}
+
+// runOfflineCLI runs an offline command.
+func runOfflineCLI(t *testing.T, args ...string) (int, string, string) {
+	t.Helper()
+	return runTestCLI(t, args...)
+}
+
+func TestRunCLI_WakeMachineByID(t *testing.T) {
+	sent := useFakePacketSender(t, nil)
+	configPath := setupTestConfig(t, validTestConfig())
+
+	code, stdout, stderr := runOfflineCLI(t, "wake", "--config", configPath, "server1")
+	require.Equal(t, exitOK, code, stderr)
+
+	assert.Contains(t, stdout, "Magic packet sent to server1 (AA:BB:CC:DD:EE:FF) via 192.168.1.255:9")
+	assert.Equal(t, []sentPacket{{mac: "AA:BB:CC:DD:EE:FF", broadcast: "192.168.1.255", port: 9}}, *sent)
+}
+
+func TestRunCLI_WakeOverridesBroadcastAndPort(t *testing.T) {
+	sent := useFakePacketSender(t, nil)
+	configPath := setupTestConfig(t, validTestConfig())
+
+	code, stdout, stderr := runOfflineCLI(t, "wake", "server2", "--config", configPath, "--broadcast", "10.0.0.255", "--port", "7", "--json")
+	require.Equal(t, exitOK, code, stderr)
+
+	var result wakeResult
+	require.NoError(t, json.Unmarshal([]byte(stdout), &result))
+	assert.Equal(t, wakeResult{
+		MachineID: "server2",
+		MAC:       "11:22:33:44:55:66",
+		Broadcast: "10.0.0.255",
+		Port:      7,
+		Status:    "sent",
+	}, result)
+	assert.Equal(t, []sentPacket{{mac: "11:22:33:44:55:66", broadcast: "10.0.0.255", port: 7}}, *sent)
+}
+
+func TestRunCLI_WakeAllowlistedMAC(t *testing.T) {
+	sent := useFakePacketSender(t, nil)
+	configPath := setupTestConfig(t, validTestConfig())
+
+	code, stdout, stderr := runOfflineCLI(t, "wake", "--config", configPath, "aa-bb-cc-dd-ee-ff")
+	require.Equal(t, exitOK, code, stderr)
+
+	assert.Contains(t, stdout, "server1")
+	assert.Len(t, *sent, 1)
+}
+
+func TestRunCLI_WakeUnlistedMAC(t *testing.T) {
+	sent := useFakePacketSender(t, nil)
+	configPath := setupTestConfig(t, validTestConfig())
+
+	code, stdout, stderr := runOfflineCLI(t, "wake", "--config", configPath, "--json", "02:00:00:00:00:01")
+	assert.Equal(t, exitFailure, code)
+	assert.Contains(t, stderr, "--allow-unlisted")
+	assert.Empty(t, *sent)
+
+	var result wakeResult
+	require.NoError(t, json.Unmarshal([]byte(stdout), &result))
+	assert.Equal(t, "failed", result.Status)
+	assert.True(t, result.Unlisted)
+
+	code, _, stderr = runOfflineCLI(t, "wake", "--config", configPath, "--allow-unlisted", "02:00:00:00:00:01")
+	assert.Equal(t, exitUsage, code)
+	assert.Contains(t, stderr, "--broadcast is required")
+	assert.Empty(t, *sent)
+
+	code, stdout, stderr = runOfflineCLI(t, "wake", "--config", configPath, "--allow-unlisted", "--broadcast", "192.168.1.255", "02:00:00:00:00:01")
+	require.Equal(t, exitOK, code, stderr)
+	assert.Contains(t, stdout, "unlisted machine (02:00:00:00:00:01)")
+	assert.Equal(t, []sentPacket{{mac: "02:00:00:00:00:01", broadcast: "192.168.1.255", port: 9}}, *sent)
+}
+
+func TestRunCLI_WakeFailures(t *testing.T) {
+	useFakePacketSender(t, errors.New("network unreachable"))
+	configPath := setupTestConfig(t, validTestConfig())
+
+	code, _, stderr := runOfflineCLI(t, "wake", "--config", configPath, "unknown")
+	assert.Equal(t, exitFailure, code)
+	assert.Contains(t, stderr, "machine not found")
+
+	code, stdout, stderr := runOfflineCLI(t, "wake", "--config", configPath, "--json", "server1")
+	assert.Equal(t, exitFailure, code)
+	assert.Contains(t, stderr, "network unreachable")
+	assert.Contains(t, stdout, `"status": "failed"`)
+
+	code, _, _ = runOfflineCLI(t, "wake", "--config", configPath)
+	assert.Equal(t, exitUsage, code)
+
+	code, _, _ = runOfflineCLI(t, "wake", "--config", configPath, "--port", "70000", "server1")
+	assert.Equal(t, exitUsage, code)
+
+	code, _, _ = runOfflineCLI(t, "wake", "--config", setupTestConfig(t, "server:\n  port: -1\n"), "server1")
+	assert.Equal(t, exitInvalidConfig, code)
+}
+
+func TestRunCLI_List(t *testing.T) {
+	useFakePacketSender(t, nil)
+	configPath := setupTestConfig(t, validTestConfig())
+
+	code, stdout, stderr := runOfflineCLI(t, "list", "--config", configPath)
+	require.Equal(t, exitOK, code, stderr)
+	assert.Contains(t, stdout, "ID")
+	assert.Contains(t, stdout, "server1  Test Server 1  AA:BB:CC:DD:EE:FF  192.168.1.255")
+	assert.NotContains(t, stdout, "level=")
+
+	code, stdout, stderr = runOfflineCLI(t, "list", "--config", configPath, "--json")
+	require.Equal(t, exitOK, code, stderr)
+
+	var machines []domain.Machine
+	require.NoError(t, json.Unmarshal([]byte(stdout), &machines))
+	require.Len(t, machines, 2)
+	assert.Equal(t, "server1", machines[0].ID)
+	assert.Equal(t, "server2", machines[1].ID)
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"context"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/josimar-silva/gwaihir/internal/config"
+	httpdelivery "github.com/josimar-silva/gwaihir/internal/delivery/http"
+	"github.com/josimar-silva/gwaihir/internal/domain"
+	"github.com/josimar-silva/gwaihir/internal/infrastructure"
+)
+
+// Reload results used as metric labels.
+const (
+	reloadResultSuccess = "success"
+	reloadResultFailure = "failure"
+)
+
+// reloadableRepository is a machine repository whose configuration-defined machines
+// can be swapped at runtime.
+type reloadableRepository interface {
+	domain.MachineRepository
+
+	// Reload replaces the configuration-defined machines, keeping the current set on error.
+	Reload(cfg *config.Config) error
+}
+
+// configReloader re-reads the configuration file and swaps the machine allowlist.
+// When keys is set, rotated API keys (including those read from *_file secrets) are
+// applied as well. Other settings (port, logging, enabling or disabling authentication)
+// still require a restart. A nil repo only reloads the API keys. When events is set,
+// the outcome of every reload is published to it. machines is the allowlist served
+// to clients, which adds imported machines to those of repo; it defaults to repo.
+type configReloader struct {
+	path     string
+	includes []string
+	repo     reloadableRepository
+	machines domain.MachineRepository
+	keys     *httpdelivery.APIKeySet
+	auth     config.AuthenticationConfig
+	logger   *infrastructure.Logger
+	metrics  *infrastructure.Metrics
+	status   *infrastructure.ReloadStatus
+	events   domain.EventPublisher
+
+	mu sync.Mutex
+}
+
+func newConfigReloader(path string, repo reloadableRepository, logger *infrastructure.Logger, metrics *infrastructure.Metrics, status *infrastructure.ReloadStatus) *configReloader {
+	return &configReloader{
+		path:     path,
+		repo:     repo,
+		machines: repo,
+		logger:   logger,
+		metrics:  metrics,
+		status:   status,
+	}
+}
+
+// reload loads and validates the configuration file and applies its machines.
+// On failure the current machine set is kept.
+func (r *configReloader) reload(trigger string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.logger.Info("Reloading configuration",
+		infrastructure.String("path", r.path),
+		infrastructure.String("trigger", trigger),
+	)
+
+	cfg, err := config.LoadConfig(r.path)
+	if err == nil && r.repo != nil {
+		err = r.repo.Reload(cfg)
+	}
+
+	if err != nil {
+		r.metrics.ConfigReloads.WithLabelValues(reloadResultFailure).Inc()
+		r.status.RecordFailure(err)
+		r.publish(domain.EventConfigReloadFailed, err.Error())
+		r.logger.Error("Configuration reload failed, keeping previous configuration",
+			infrastructure.String("trigger", trigger),
+			infrastructure.Any("error", err),
+		)
+		return
+	}
+
+	r.rotateAPIKeys(cfg.Authentication)
+
+	now := time.Now()
+	r.metrics.ConfigReloads.WithLabelValues(reloadResultSuccess).Inc()
+	r.metrics.ConfigLastReload.Set(float64(now.Unix()))
+	r.status.RecordSuccess(now)
+	r.publish(domain.EventConfigReloaded, trigger)
+
+	if r.repo == nil {
+		r.logger.Info("Configuration reloaded", infrastructure.String("trigger", trigger))
+		return
+	}
+
+	machines, _ := r.machines.GetAll()
+	r.metrics.SetConfiguredMachines(machines)
+	r.logger.Info("Configuration reloaded",
+		infrastructure.String("trigger", trigger),
+		infrastructure.Int("machines", len(machines)),
+	)
+}
+
+func (r *configReloader) publish(eventType, detail string) {
+	if r.events == nil {
+		return
+	}
+	r.events.Publish(domain.Event{Type: eventType, Detail: detail, Time: time.Now().UTC()})
+}
+
+// rotateAPIKeys applies the reloaded API keys. Keys can be rotated but not added or
+// removed, since the routes requiring them are registered at startup.
+func (r *configReloader) rotateAPIKeys(auth config.AuthenticationConfig) {
+	if r.keys == nil {
+		return
+	}
+
+	if (auth.APIKey == "") != (r.auth.APIKey == "") || (auth.AdminAPIKey == "") != (r.auth.AdminAPIKey == "") {
+		r.logger.Warn("Adding or removing API keys requires a restart, keeping previous API keys")
+		return
+	}
+
+	if auth.APIKey != r.auth.APIKey || auth.AdminAPIKey != r.auth.AdminAPIKey {
+		r.keys.Set(httpdelivery.ConfiguredAPIKeys(auth.APIKey, auth.AdminAPIKey))
+		r.auth.APIKey = auth.APIKey
+		r.auth.AdminAPIKey = auth.AdminAPIKey
+		r.logger.Info("API keys rotated")
+	}
+}
+
+// run reloads whenever a signal arrives on sighup and, when watch is enabled,
+// whenever the configuration file or an included file changes. It blocks until ctx is done.
+func (r *configReloader) run(ctx context.Context, sighup <-chan os.Signal, watch bool, interval time.Duration) {
+	if watch {
+		watcher := infrastructure.NewFileWatcher(r.path, interval).WithPatterns(r.includes...)
+		go watcher.Watch(ctx, func() {
+			r.reload("file_change")
+		})
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-sighup:
+			r.reload("sighup")
+		}
+	}
+}
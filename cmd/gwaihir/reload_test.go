@@ -0,0 +1,202 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/josimar-silva/gwaihir/internal/config"
+	httpdelivery "github.com/josimar-silva/gwaihir/internal/delivery/http"
+	"github.com/josimar-silva/gwaihir/internal/domain"
+	"github.com/josimar-silva/gwaihir/internal/infrastructure"
+	"github.com/josimar-silva/gwaihir/internal/repository"
+)
+
+const reloadedTestConfig = `machines:
+  - id: server3
+    name: Test Server 3
+    mac: "22:33:44:55:66:77"
+    broadcast: "10.0.0.255"
+`
+
+func newTestReloader(t *testing.T) (*configReloader, *repository.InMemoryMachineRepository, string) {
+	t.Helper()
+	configPath := setupTestConfig(t, validTestConfig())
+	cfg, err := config.LoadConfig(configPath)
+	require.NoError(t, err)
+
+	repo, err := repository.NewInMemoryMachineRepository(cfg)
+	require.NoError(t, err)
+
+	logger := infrastructure.NewLogger("text", "error")
+	status := infrastructure.NewReloadStatus(time.Now())
+	return newConfigReloader(configPath, repo, logger, getTestMetrics(t), status), repo, configPath
+}
+
+func TestConfigReloader_ReloadKeepsImportedMachineLabels(t *testing.T) {
+	reloader, repo, configPath := newTestReloader(t)
+	machines := repository.NewImportingMachineRepository(repo)
+	machines.SetImported("leases", []*domain.Machine{{ID: "imported", Name: "Imported", MAC: "11:22:33:44:55:66", Broadcast: "10.0.0.255"}})
+	reloader.machines = machines
+
+	require.NoError(t, os.WriteFile(configPath, []byte(reloadedTestConfig), 0o600))
+	reloader.reload("test")
+
+	assert.Equal(t, "imported", reloader.metrics.MachineLabel("imported"))
+	assert.Equal(t, "server3", reloader.metrics.MachineLabel("server3"))
+	assert.Equal(t, 2.0, testutil.ToFloat64(reloader.metrics.ConfiguredMachines))
+}
+
+func TestConfigReloader_ReloadSuccess(t *testing.T) {
+	reloader, repo, configPath := newTestReloader(t)
+	metrics := reloader.metrics
+	before := testutil.ToFloat64(metrics.ConfigReloads.WithLabelValues(reloadResultSuccess))
+
+	require.NoError(t, os.WriteFile(configPath, []byte(reloadedTestConfig), 0o600))
+	reloader.reload("test")
+
+	assert.True(t, repo.Exists("server3"))
+	assert.False(t, repo.Exists("server1"))
+	assert.Empty(t, reloader.status.LastError())
+	assert.Equal(t, before+1, testutil.ToFloat64(metrics.ConfigReloads.WithLabelValues(reloadResultSuccess)))
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.ConfiguredMachines))
+}
+
+func TestConfigReloader_ReloadInvalidKeepsPrevious(t *testing.T) {
+	reloader, repo, configPath := newTestReloader(t)
+	metrics := reloader.metrics
+	before := testutil.ToFloat64(metrics.ConfigReloads.WithLabelValues(reloadResultFailure))
+
+	require.NoError(t, os.WriteFile(configPath, []byte("machines: []\n"), 0o600))
+	reloader.reload("test")
+
+	assert.True(t, repo.Exists("server1"))
+	assert.NotEmpty(t, reloader.status.LastError())
+	assert.Equal(t, before+1, testutil.ToFloat64(metrics.ConfigReloads.WithLabelValues(reloadResultFailure)))
+}
+
+func TestConfigReloader_RunReloadsOnSIGHUP(t *testing.T) {
+	reloader, repo, configPath := newTestReloader(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sighup := make(chan os.Signal, 1)
+	go reloader.run(ctx, sighup, false, time.Second)
+
+	require.NoError(t, os.WriteFile(configPath, []byte(reloadedTestConfig), 0o600))
+	sighup <- syscall.SIGHUP
+
+	assert.Eventually(t, func() bool {
+		return repo.Exists("server3")
+	}, 2*time.Second, 10*time.Millisecond)
+}
+
+func TestConfigReloader_RunReloadsOnFileChange(t *testing.T) {
+	reloader, repo, configPath := newTestReloader(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go reloader.run(ctx, make(chan os.Signal), true, 10*time.Millisecond)
+
+	// Wait for the watcher to capture its baseline before changing the file.
+	time.Sleep(50 * time.Millisecond)
+	require.NoError(t, os.WriteFile(configPath, []byte(reloadedTestConfig), 0o600))
+
+	assert.Eventually(t, func() bool {
+		return repo.Exists("server3")
+	}, 2*time.Second, 10*time.Millisecond)
+}
+
+func TestConfigReloader_RotatesAPIKeyFromSecretFile(t *testing.T) {
+	configPath := setupTestConfig(t, `authentication:
+  api_key_file: api-key
+machines:
+  - id: server1
+    name: Test Server 1
+    mac: "AA:BB:CC:DD:EE:FF"
+    broadcast: "192.168.1.255"
+`)
+	keyPath := filepath.Join(filepath.Dir(configPath), "api-key")
+	require.NoError(t, os.WriteFile(keyPath, []byte("initial-key\n"), 0o600))
+
+	cfg, err := config.LoadConfig(configPath)
+	require.NoError(t, err)
+
+	logger := infrastructure.NewLogger("text", "error")
+	keys := httpdelivery.NewAPIKeySet(httpdelivery.ConfiguredAPIKeys(cfg.Authentication.APIKey, ""))
+	reloader := newConfigReloader(configPath, nil, logger, getTestMetrics(t), infrastructure.NewReloadStatus(time.Now()))
+	reloader.keys = keys
+	reloader.auth = cfg.Authentication
+
+	handler := newReloadTestRouter(t, keys)
+	assert.Equal(t, http.StatusOK, doReloadTestRequest(handler, "initial-key"))
+
+	require.NoError(t, os.WriteFile(keyPath, []byte("rotated-key\n"), 0o600))
+	reloader.reload("test")
+
+	assert.Empty(t, reloader.status.LastError())
+	assert.Equal(t, http.StatusUnauthorized, doReloadTestRequest(handler, "initial-key"))
+	assert.Equal(t, http.StatusOK, doReloadTestRequest(handler, "rotated-key"))
+}
+
+func TestConfigReloader_KeepsAPIKeysWhenAuthenticationIsRemoved(t *testing.T) {
+	reloader, _, configPath := newTestReloader(t)
+	keys := httpdelivery.NewAPIKeySet(httpdelivery.ConfiguredAPIKeys("test-key-12345", ""))
+	reloader.keys = keys
+	reloader.auth = config.AuthenticationConfig{APIKey: "test-key-12345"}
+
+	require.NoError(t, os.WriteFile(configPath, []byte(reloadedTestConfig), 0o600))
+	reloader.reload("test")
+
+	handler := newReloadTestRouter(t, keys)
+	assert.Equal(t, http.StatusOK, doReloadTestRequest(handler, "test-key-12345"))
+}
+
+func TestConfigReloader_PublishesEvents(t *testing.T) {
+	reloader, _, configPath := newTestReloader(t)
+	bus := infrastructure.NewEventBus(4)
+	events, unsubscribe := bus.Subscribe()
+	defer unsubscribe()
+	reloader.events = bus
+
+	require.NoError(t, os.WriteFile(configPath, []byte(reloadedTestConfig), 0o600))
+	reloader.reload("test")
+	require.NoError(t, os.WriteFile(configPath, []byte("machines: []\n"), 0o600))
+	reloader.reload("test")
+
+	reloaded := <-events
+	assert.Equal(t, domain.EventConfigReloaded, reloaded.Type)
+	assert.Equal(t, "test", reloaded.Detail)
+	failed := <-events
+	assert.Equal(t, domain.EventConfigReloadFailed, failed.Type)
+	assert.Equal(t, reloader.status.LastError(), failed.Detail)
+}
+
+func newReloadTestRouter(t *testing.T, keys *httpdelivery.APIKeySet) http.Handler {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(httpdelivery.APIKeySetAuthMiddleware(keys, nil, nil, nil, nil))
+	router.GET("/test", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	return router
+}
+
+func doReloadTestRequest(handler http.Handler, apiKey string) int {
+	req := httptest.NewRequestWithContext(context.Background(), http.MethodGet, "/test", nil)
+	req.Header.Set("X-API-Key", apiKey)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	return w.Code
+}
@@ -0,0 +1,318 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/josimar-silva/gwaihir/internal/domain"
+	"github.com/josimar-silva/gwaihir/pkg/client"
+)
+
+// remoteFlags are the flags shared by the remote commands. Flags take precedence over
+// environment variables and the client configuration profile.
+type remoteFlags struct {
+	server     string
+	apiKey     string
+	profile    string
+	configPath string
+	timeout    time.Duration
+	json       bool
+}
+
+func (f *remoteFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&f.server, "server", "", "Server URL (default $"+envRemoteURL+" or the profile's url)")
+	fs.StringVar(&f.apiKey, "api-key", "", "API key (default $"+envRemoteAPIKey+" or the profile's api_key)")
+	fs.StringVar(&f.profile, "profile", "", "Profile to use from the client configuration (default $"+envRemoteProfile+" or default_profile)")
+	fs.StringVar(&f.configPath, "client-config", "", "Client configuration file (default $"+envClientConfig+" or ~/.config/gwaihir/client.yaml)")
+	fs.DurationVar(&f.timeout, "timeout", 10*time.Second, "Request timeout")
+	fs.BoolVar(&f.json, "json", false, "Print the response as JSON")
+}
+
+// client creates a client for the server selected by the flags, environment and profile.
+func (f *remoteFlags) client() (*client.Client, error) {
+	profile, err := loadClientProfile(f.configPath, f.profile)
+	if err != nil {
+		return nil, invalidConfig(err)
+	}
+
+	server := firstNonEmpty(f.server, os.Getenv(envRemoteURL), profile.URL)
+	if server == "" {
+		return nil, usageError("no server URL, use --server, %s or a client configuration profile", envRemoteURL)
+	}
+
+	c, err := client.New(server)
+	if err != nil {
+		return nil, usageError("%v", err)
+	}
+	return c.WithAPIKey(firstNonEmpty(f.apiKey, os.Getenv(envRemoteAPIKey), profile.APIKey)).
+		WithUserAgent("gwaihir/" + Version).
+		WithTimeout(f.timeout), nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// remoteErrorOutput is printed instead of the response with --json when a request fails.
+type remoteErrorOutput struct {
+	Error      string `json:"error"`
+	StatusCode int    `json:"status_code,omitempty"`
+}
+
+// fail reports err as JSON on stdout when requested and returns it.
+func (f *remoteFlags) fail(stdout io.Writer, err error) error {
+	if f.json {
+		output := remoteErrorOutput{Error: err.Error()}
+		var apiErr *client.APIError
+		if errors.As(err, &apiErr) {
+			output.Error = apiErr.Message
+			output.StatusCode = apiErr.StatusCode
+		}
+		_ = writeJSON(stdout, output)
+	}
+	return err
+}
+
+func remoteCommands() []command {
+	return []command{
+		{"wake", "Wake an allowlisted machine", runRemoteWake},
+		{"list", "List the allowlisted machines", runRemoteList},
+		{"get", "Show one machine", runRemoteGet},
+		{"health", "Show the server health", runRemoteHealth},
+		{"version", "Show the server version", runRemoteVersion},
+	}
+}
+
+// runRemote runs a remote command against a running server's HTTP API.
+func runRemote(args []string, stdout, stderr io.Writer) error {
+	if len(args) == 0 {
+		printRemoteUsage(stderr)
+		return usageError("missing remote command")
+	}
+
+	switch args[0] {
+	case "-h", "-help", "--help", "help":
+		printRemoteUsage(stdout)
+		return nil
+	}
+
+	for _, cmd := range remoteCommands() {
+		if cmd.name == args[0] {
+			return cmd.run(args[1:], stdout, stderr)
+		}
+	}
+
+	printRemoteUsage(stderr)
+	return usageError("unknown remote command %q", args[0])
+}
+
+func printRemoteUsage(w io.Writer) {
+	_, _ = fmt.Fprintf(w, "Usage: gwaihir remote <command> [flags]\n\nCommands:\n")
+	for _, cmd := range remoteCommands() {
+		_, _ = fmt.Fprintf(w, "  %-14s %s\n", cmd.name, cmd.summary)
+	}
+	_, _ = fmt.Fprintf(w, "\nRun 'gwaihir remote <command> --help' for the flags of a command.\n")
+}
+
+// newRemoteCommand parses the flags of a remote command and creates its client.
+// With argName set, the command takes one positional argument, which is returned.
+func newRemoteCommand(name, argName string, args []string, stderr io.Writer) (*remoteFlags, *client.Client, string, error) {
+	flags := &remoteFlags{}
+	fs := newFlagSet("remote "+name, stderr)
+	flags.register(fs)
+
+	var arg string
+	var err error
+	if argName != "" {
+		arg, err = parseFlagsWithArg(fs, args, argName)
+	} else {
+		err = parseFlags(fs, args)
+	}
+	if err != nil {
+		return nil, nil, "", err
+	}
+
+	c, err := flags.client()
+	if err != nil {
+		return nil, nil, "", err
+	}
+	return flags, c, arg, nil
+}
+
+func (f *remoteFlags) context() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), f.timeout)
+}
+
+// remoteWakeOutput is printed by remote wake with --json.
+type remoteWakeOutput struct {
+	MachineID string `json:"machine_id"`
+	Status    string `json:"status"`
+}
+
+func runRemoteWake(args []string, stdout, stderr io.Writer) error {
+	flags, c, machineID, err := newRemoteCommand("wake", "machine ID", args, stderr)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := flags.context()
+	defer cancel()
+
+	if err := c.Wake(ctx, machineID); err != nil {
+		return flags.fail(stdout, err)
+	}
+
+	if flags.json {
+		return writeJSON(stdout, remoteWakeOutput{MachineID: machineID, Status: "sent"})
+	}
+	_, _ = fmt.Fprintf(stdout, "Magic packet sent to %s\n", machineID)
+	return nil
+}
+
+func runRemoteList(args []string, stdout, stderr io.Writer) error {
+	flags, c, _, err := newRemoteCommand("list", "", args, stderr)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := flags.context()
+	defer cancel()
+
+	machines, err := c.ListMachines(ctx)
+	if err != nil {
+		return flags.fail(stdout, err)
+	}
+	sort.Slice(machines, func(i, j int) bool {
+		return machines[i].ID < machines[j].ID
+	})
+
+	if flags.json {
+		return writeJSON(stdout, machines)
+	}
+	if len(machines) == 0 {
+		_, _ = fmt.Fprintln(stdout, "No machines configured")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "ID\tNAME\tMAC\tBROADCAST")
+	for _, machine := range machines {
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", machine.ID, machine.Name, normalizeMAC(machine.MAC), machine.Broadcast)
+	}
+	return w.Flush()
+}
+
+func runRemoteGet(args []string, stdout, stderr io.Writer) error {
+	flags, c, machineID, err := newRemoteCommand("get", "machine ID", args, stderr)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := flags.context()
+	defer cancel()
+
+	machine, err := c.GetMachine(ctx, machineID)
+	if err != nil {
+		return flags.fail(stdout, err)
+	}
+
+	if flags.json {
+		return writeJSON(stdout, machine)
+	}
+	w := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintf(w, "ID:\t%s\nName:\t%s\nMAC:\t%s\nBroadcast:\t%s\n", machine.ID, machine.Name, normalizeMAC(machine.MAC), machine.Broadcast)
+	if machine.Source != "" {
+		_, _ = fmt.Fprintf(w, "Source:\t%s\n", machine.Source)
+	}
+	return w.Flush()
+}
+
+func normalizeMAC(mac string) string {
+	return (&domain.Machine{MAC: mac}).NormalizeMAC()
+}
+
+func runRemoteHealth(args []string, stdout, stderr io.Writer) error {
+	flags, c, _, err := newRemoteCommand("health", "", args, stderr)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := flags.context()
+	defer cancel()
+
+	health, err := c.Health(ctx)
+	if health == nil {
+		return flags.fail(stdout, err)
+	}
+
+	if flags.json {
+		if writeErr := writeJSON(stdout, health); writeErr != nil {
+			return writeErr
+		}
+	} else {
+		printHealth(stdout, health)
+	}
+
+	if err != nil {
+		return fmt.Errorf("server is %s: %w", health.Status, err)
+	}
+	return nil
+}
+
+func printHealth(w io.Writer, health *client.Health) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintf(tw, "Status:\t%s\nVersion:\t%s\nUptime:\t%s\nMachines:\t%d\n",
+		health.Status, health.Version, time.Duration(health.UptimeSeconds)*time.Second, health.ConfiguredMachines)
+	if health.LastConfigReload != "" {
+		_, _ = fmt.Fprintf(tw, "Last reload:\t%s\n", health.LastConfigReload)
+	}
+
+	names := make([]string, 0, len(health.Checks))
+	for name := range health.Checks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	checks := make([]string, 0, len(names))
+	for _, name := range names {
+		checks = append(checks, name+"="+health.Checks[name])
+	}
+	_, _ = fmt.Fprintf(tw, "Checks:\t%s\n", strings.Join(checks, ", "))
+
+	for _, source := range health.Sources {
+		status := fmt.Sprintf("%d machines", source.Machines)
+		if source.LastUpdated != "" {
+			status += ", updated " + source.LastUpdated
+		}
+		_, _ = fmt.Fprintf(tw, "Source %s:\t%s\n", source.Name, status)
+	}
+	_ = tw.Flush()
+}
+
+func runRemoteVersion(args []string, stdout, stderr io.Writer) error {
+	flags, c, _, err := newRemoteCommand("version", "", args, stderr)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := flags.context()
+	defer cancel()
+
+	version, err := c.Version(ctx)
+	if err != nil {
+		return flags.fail(stdout, err)
+	}
+
+	if flags.json {
+		return writeJSON(stdout, version)
+	}
+	_, _ = fmt.Fprintf(stdout, "gwaihir %s (server)\n  build time: %s\n  git commit: %s\n", version.Version, version.BuildTime, version.GitCommit)
+	return nil
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Environment variables read by the remote commands.
+const (
+	envRemoteURL     = "GWAIHIR_REMOTE_URL"
+	envRemoteAPIKey  = "GWAIHIR_REMOTE_API_KEY"
+	envRemoteProfile = "GWAIHIR_REMOTE_PROFILE"
+	envClientConfig  = "GWAIHIR_CLIENT_CONFIG"
+)
+
+// clientConfig is the client configuration file holding the server profiles.
+type clientConfig struct {
+	DefaultProfile string                   `yaml:"default_profile"`
+	Profiles       map[string]clientProfile `yaml:"profiles"`
+}
+
+// clientProfile describes how to reach one server.
+type clientProfile struct {
+	URL        string `yaml:"url"`
+	APIKey     string `yaml:"api_key"`
+	APIKeyFile string `yaml:"api_key_file"`
+}
+
+// defaultClientConfigPath returns ~/.config/gwaihir/client.yaml.
+func defaultClientConfigPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".config", "gwaihir", "client.yaml")
+}
+
+// loadClientProfile reads the named profile, or the default one, from the client
+// configuration at path, $GWAIHIR_CLIENT_CONFIG or the default location. A missing
+// default file yields an empty profile, so that flags and environment suffice.
+func loadClientProfile(path, name string) (clientProfile, error) {
+	if path == "" {
+		path = os.Getenv(envClientConfig)
+	}
+	explicit := path != ""
+	if !explicit {
+		path = defaultClientConfigPath()
+	}
+	if name == "" {
+		name = os.Getenv(envRemoteProfile)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) && !explicit && name == "" {
+			return clientProfile{}, nil
+		}
+		return clientProfile{}, fmt.Errorf("failed to read client configuration: %w", err)
+	}
+
+	var cfg clientConfig
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&cfg); err != nil && !errors.Is(err, io.EOF) {
+		return clientProfile{}, fmt.Errorf("failed to parse client configuration %s: %w", path, err)
+	}
+
+	if name == "" {
+		name = cfg.DefaultProfile
+	}
+	if name == "" {
+		return clientProfile{}, nil
+	}
+
+	profile, exists := cfg.Profiles[name]
+	if !exists {
+		return clientProfile{}, fmt.Errorf("profile %q not found in %s", name, path)
+	}
+
+	if profile.APIKeyFile != "" {
+		if profile.APIKey != "" {
+			return clientProfile{}, fmt.Errorf("profile %q: api_key and api_key_file are mutually exclusive", name)
+		}
+		key, err := readAPIKeyFile(profile.APIKeyFile, path)
+		if err != nil {
+			return clientProfile{}, fmt.Errorf("profile %q: %w", name, err)
+		}
+		profile.APIKey = key
+	}
+	return profile, nil
+}
+
+// readAPIKeyFile reads a key file. "~/" expands to the home directory and relative
+// paths are resolved against the directory of the client configuration.
+func readAPIKeyFile(path, configPath string) (string, error) {
+	if rest, found := strings.CutPrefix(path, "~/"); found {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to resolve api_key_file: %w", err)
+		}
+		path = filepath.Join(home, rest)
+	} else if !filepath.IsAbs(path) {
+		path = filepath.Join(filepath.Dir(configPath), path)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read api_key_file: %w", err)
+	}
+	key := strings.TrimSpace(string(data))
+	if key == "" {
+		return "", fmt.Errorf("api_key_file %s is empty", path)
+	}
+	return key, nil
+}
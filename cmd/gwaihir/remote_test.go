@@ -0,0 +1,176 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/josimar-silva/gwaihir/internal/config"
+	httpdelivery "github.com/josimar-silva/gwaihir/internal/delivery/http"
+	"github.com/josimar-silva/gwaihir/internal/domain"
+	"github.com/josimar-silva/gwaihir/internal/infrastructure"
+	"github.com/josimar-silva/gwaihir/internal/repository"
+	"github.com/josimar-silva/gwaihir/internal/usecase"
+)
+
+const testRemoteAPIKey = "remote-test-key"
+
+// newRemoteTestServer starts the real router over the machines of validTestConfig.
+func newRemoteTestServer(t *testing.T) (*httptest.Server, *[]sentPacket) {
+	t.Helper()
+
+	cfg, err := config.LoadConfig(setupTestConfig(t, validTestConfig()))
+	require.NoError(t, err)
+	cfg.Authentication.APIKey = testRemoteAPIKey
+
+	repo, err := repository.NewInMemoryMachineRepository(cfg)
+	require.NoError(t, err)
+
+	sent := &[]sentPacket{}
+	logger := infrastructure.NewLogger("text", "error")
+	metrics := getTestMetrics(t)
+	useCase := usecase.NewWoLUseCase(repo, &fakePacketSender{port: infrastructure.DefaultWoLPort, sent: sent}, logger, metrics)
+	handler := httpdelivery.NewHandler(useCase, logger, metrics, "1.2.3", "2026-01-01", "abc123")
+
+	server := httptest.NewServer(httpdelivery.NewRouterWithConfig(handler, cfg))
+	t.Cleanup(server.Close)
+	return server, sent
+}
+
+// isolateRemoteEnv clears the remote environment variables and points the client
+// configuration at a path that does not exist.
+func isolateRemoteEnv(t *testing.T) {
+	t.Helper()
+	t.Setenv(envRemoteURL, "")
+	t.Setenv(envRemoteAPIKey, "")
+	t.Setenv(envRemoteProfile, "")
+	t.Setenv(envClientConfig, "")
+	t.Setenv("HOME", t.TempDir())
+}
+
+func TestRunCLI_RemoteWake(t *testing.T) {
+	isolateRemoteEnv(t)
+	server, sent := newRemoteTestServer(t)
+
+	code, stdout, stderr := runTestCLI(t, "remote", "wake", "server1", "--server", server.URL, "--api-key", testRemoteAPIKey)
+	require.Equal(t, exitOK, code, stderr)
+	assert.Equal(t, "Magic packet sent to server1\n", stdout)
+	assert.Len(t, *sent, 1)
+}
+
+func TestRunCLI_RemoteErrors(t *testing.T) {
+	isolateRemoteEnv(t)
+	server, _ := newRemoteTestServer(t)
+
+	code, _, stderr := runTestCLI(t, "remote", "wake", "unknown", "--server", server.URL, "--api-key", testRemoteAPIKey)
+	assert.Equal(t, exitFailure, code)
+	assert.Contains(t, stderr, "server returned 404 Not Found: Machine unknown is not in the allowlist")
+
+	code, stdout, _ := runTestCLI(t, "remote", "list", "--server", server.URL, "--api-key", "wrong", "--json")
+	assert.Equal(t, exitFailure, code)
+	var output remoteErrorOutput
+	require.NoError(t, json.Unmarshal([]byte(stdout), &output))
+	assert.Equal(t, 401, output.StatusCode)
+	assert.NotEmpty(t, output.Error)
+
+	code, _, stderr = runTestCLI(t, "remote", "list")
+	assert.Equal(t, exitUsage, code)
+	assert.Contains(t, stderr, "no server URL")
+
+	code, _, _ = runTestCLI(t, "remote", "list", "--server", "ftp://example.com")
+	assert.Equal(t, exitUsage, code)
+
+	code, _, _ = runTestCLI(t, "remote", "frobnicate")
+	assert.Equal(t, exitUsage, code)
+
+	code, _, _ = runTestCLI(t, "remote", "get", "--server", server.URL)
+	assert.Equal(t, exitUsage, code)
+}
+
+func TestRunCLI_RemoteListAndGet(t *testing.T) {
+	isolateRemoteEnv(t)
+	server, _ := newRemoteTestServer(t)
+	t.Setenv(envRemoteURL, server.URL)
+	t.Setenv(envRemoteAPIKey, testRemoteAPIKey)
+
+	code, stdout, stderr := runTestCLI(t, "remote", "list")
+	require.Equal(t, exitOK, code, stderr)
+	assert.Contains(t, stdout, "server2  Test Server 2  11:22:33:44:55:66  192.168.1.255")
+
+	code, stdout, stderr = runTestCLI(t, "remote", "get", "server1", "--json")
+	require.Equal(t, exitOK, code, stderr)
+	var machine domain.Machine
+	require.NoError(t, json.Unmarshal([]byte(stdout), &machine))
+	assert.Equal(t, "AA:BB:CC:DD:EE:FF", machine.MAC)
+}
+
+func TestRunCLI_RemoteHealthAndVersion(t *testing.T) {
+	isolateRemoteEnv(t)
+	server, _ := newRemoteTestServer(t)
+
+	code, stdout, stderr := runTestCLI(t, "remote", "health", "--server", server.URL)
+	require.Equal(t, exitOK, code, stderr)
+	assert.Contains(t, stdout, "healthy")
+	assert.Contains(t, stdout, "machines=ok")
+
+	code, stdout, stderr = runTestCLI(t, "remote", "version", "--server", server.URL, "--json")
+	require.Equal(t, exitOK, code, stderr)
+	var version httpdelivery.VersionResponse
+	require.NoError(t, json.Unmarshal([]byte(stdout), &version))
+	assert.Equal(t, "1.2.3", version.Version)
+}
+
+func TestRunCLI_RemoteProfile(t *testing.T) {
+	isolateRemoteEnv(t)
+	server, sent := newRemoteTestServer(t)
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "home.key"), []byte(testRemoteAPIKey+"\n"), 0o600))
+	clientConfig := filepath.Join(dir, "client.yaml")
+	require.NoError(t, os.WriteFile(clientConfig, []byte(`default_profile: home
+profiles:
+  home:
+    url: `+server.URL+`
+    api_key_file: home.key
+  lab:
+    url: http://127.0.0.1:1
+`), 0o600))
+
+	code, _, stderr := runTestCLI(t, "remote", "wake", "server2", "--client-config", clientConfig)
+	require.Equal(t, exitOK, code, stderr)
+	assert.Len(t, *sent, 1)
+
+	// Flags take precedence over the profile.
+	code, _, stderr = runTestCLI(t, "remote", "wake", "server2", "--client-config", clientConfig, "--api-key", "wrong")
+	assert.Equal(t, exitFailure, code)
+	assert.Contains(t, stderr, "401")
+
+	code, _, stderr = runTestCLI(t, "remote", "version", "--client-config", clientConfig, "--profile", "missing")
+	assert.Equal(t, exitInvalidConfig, code)
+	assert.Contains(t, stderr, `profile "missing" not found`)
+}
+
+func TestLoadClientProfile(t *testing.T) {
+	isolateRemoteEnv(t)
+
+	profile, err := loadClientProfile("", "")
+	require.NoError(t, err)
+	assert.Equal(t, clientProfile{}, profile)
+
+	_, err = loadClientProfile(filepath.Join(t.TempDir(), "missing.yaml"), "")
+	assert.Error(t, err)
+
+	path := filepath.Join(t.TempDir(), "client.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("profiles:\n  home:\n    url: http://a\n    api_key: k\n    api_key_file: f\n"), 0o600))
+	_, err = loadClientProfile(path, "home")
+	assert.ErrorContains(t, err, "mutually exclusive")
+
+	require.NoError(t, os.WriteFile(path, []byte("profiles:\n  home:\n    uri: http://a\n"), 0o600))
+	_, err = loadClientProfile(path, "home")
+	assert.ErrorContains(t, err, "failed to parse client configuration")
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+
+	"github.com/josimar-silva/gwaihir/internal/config"
+	"github.com/josimar-silva/gwaihir/internal/infrastructure"
+)
+
+// tracingShutdownTimeout bounds how long pending spans may take to export on shutdown.
+const tracingShutdownTimeout = 5 * time.Second
+
+// startTracing exports spans over OTLP as configured in observability.tracing and
+// returns a function flushing the pending spans.
+func startTracing(cfg *config.Config, logger *infrastructure.Logger) (func(), error) {
+	tracing := cfg.Observability.Tracing
+	exporter, err := newTraceExporter(tracing)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize tracing: %w", err)
+	}
+
+	sampleRatio := 1.0
+	if tracing.SampleRatio != nil {
+		sampleRatio = *tracing.SampleRatio
+	}
+	provider, err := infrastructure.SetupTracing(exporter, tracing.ServiceName, Version, sampleRatio)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize tracing: %w", err)
+	}
+	otel.SetErrorHandler(otel.ErrorHandlerFunc(func(err error) {
+		logger.Warn("Tracing error", infrastructure.Any("error", err))
+	}))
+
+	logger.Info("Tracing enabled",
+		infrastructure.String("endpoint", tracing.Endpoint),
+		infrastructure.String("protocol", tracing.Protocol),
+		infrastructure.Any("sample_ratio", sampleRatio),
+	)
+
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), tracingShutdownTimeout)
+		defer cancel()
+		if err := provider.Shutdown(ctx); err != nil {
+			logger.Warn("Failed to flush traces", infrastructure.Any("error", err))
+		}
+	}, nil
+}
+
+// newTraceExporter returns an OTLP exporter for tracing. Settings left empty fall back
+// to the OTEL_EXPORTER_OTLP_* environment variables.
+func newTraceExporter(tracing config.TracingConfig) (sdktrace.SpanExporter, error) {
+	insecure := tracing.Insecure != nil && *tracing.Insecure
+	isURL := strings.Contains(tracing.Endpoint, "://")
+
+	if tracing.Protocol == config.TracingProtocolHTTP {
+		var opts []otlptracehttp.Option
+		switch {
+		case isURL:
+			opts = append(opts, otlptracehttp.WithEndpointURL(tracing.Endpoint))
+		case tracing.Endpoint != "":
+			opts = append(opts, otlptracehttp.WithEndpoint(tracing.Endpoint))
+		}
+		if insecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+		return otlptracehttp.New(context.Background(), opts...)
+	}
+
+	var opts []otlptracegrpc.Option
+	switch {
+	case isURL:
+		opts = append(opts, otlptracegrpc.WithEndpointURL(tracing.Endpoint))
+	case tracing.Endpoint != "":
+		opts = append(opts, otlptracegrpc.WithEndpoint(tracing.Endpoint))
+	}
+	if insecure {
+		opts = append(opts, otlptracegrpc.WithInsecure())
+	}
+	return otlptracegrpc.New(context.Background(), opts...)
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace/noop"
+
+	"github.com/josimar-silva/gwaihir/internal/config"
+	"github.com/josimar-silva/gwaihir/internal/infrastructure"
+)
+
+// resetTracing replaces the tracer provider and propagator installed by startTracing.
+func resetTracing() {
+	otel.SetTracerProvider(noop.NewTracerProvider())
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator())
+}
+
+func TestStartTracing_ExportsOverOTLPHTTP(t *testing.T) {
+	t.Cleanup(resetTracing)
+
+	requests := make(chan *http.Request, 4)
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests <- r
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer collector.Close()
+
+	cfg, err := config.LoadConfig(setupTestConfig(t, validTestConfig()))
+	require.NoError(t, err)
+	cfg.Observability.Tracing.Protocol = config.TracingProtocolHTTP
+	cfg.Observability.Tracing.Endpoint = collector.URL + "/v1/traces"
+
+	stop, err := startTracing(cfg, infrastructure.NewLogger("text", "error"))
+	require.NoError(t, err)
+
+	_, span := infrastructure.Tracer().Start(context.Background(), "operation")
+	span.End()
+	stop()
+
+	select {
+	case r := <-requests:
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/v1/traces", r.URL.Path)
+		assert.Equal(t, "application/x-protobuf", r.Header.Get("Content-Type"))
+	default:
+		t.Fatal("expected the pending span to be exported on stop")
+	}
+}
+
+func TestStartTracing_GRPCExporter(t *testing.T) {
+	t.Cleanup(resetTracing)
+
+	cfg, err := config.LoadConfig(setupTestConfig(t, validTestConfig()))
+	require.NoError(t, err)
+	insecure := true
+	cfg.Observability.Tracing.Endpoint = "localhost:4317"
+	cfg.Observability.Tracing.Insecure = &insecure
+
+	// The gRPC exporter connects lazily, so starting without a collector succeeds.
+	stop, err := startTracing(cfg, infrastructure.NewLogger("text", "error"))
+	require.NoError(t, err)
+	stop()
+}
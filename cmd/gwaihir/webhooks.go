@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/josimar-silva/gwaihir/internal/config"
+	"github.com/josimar-silva/gwaihir/internal/infrastructure"
+)
+
+// webhookShutdownTimeout bounds how long queued webhook deliveries may take on shutdown.
+const webhookShutdownTimeout = 5 * time.Second
+
+// startWebhookDispatcher delivers the events of bus to the configured webhook
+// subscriptions and returns a function stopping the deliveries.
+func startWebhookDispatcher(cfg *config.Config, bus *infrastructure.EventBus, logger *infrastructure.Logger, metrics *infrastructure.Metrics) (func(), error) {
+	subscriptions := make([]infrastructure.WebhookSubscription, 0, len(cfg.Webhooks.Subscriptions))
+	for _, webhook := range cfg.Webhooks.Subscriptions {
+		subscriptions = append(subscriptions, infrastructure.WebhookSubscription{
+			Name:   webhook.Name,
+			URL:    webhook.URL,
+			Events: webhook.Events,
+			Secret: webhook.Secret,
+		})
+	}
+
+	dispatcher := infrastructure.NewWebhookDispatcher(subscriptions, logger, metrics).
+		WithRetries(cfg.Webhooks.MaxAttempts, cfg.Webhooks.InitialBackoff, cfg.Webhooks.MaxBackoff).
+		WithTimeout(cfg.Webhooks.Timeout).
+		WithQueueSize(cfg.Webhooks.QueueSize)
+
+	var deadLetters *infrastructure.WebhookDeadLetterLog
+	if cfg.Webhooks.DeadLetterPath != "" {
+		var err error
+		deadLetters, err = infrastructure.NewWebhookDeadLetterLog(cfg.Webhooks.DeadLetterPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize webhooks: %w", err)
+		}
+		dispatcher.WithDeadLetterLog(deadLetters)
+	}
+
+	dispatcher.Start()
+	events, unsubscribe := bus.Subscribe()
+	go dispatcher.Consume(events)
+
+	logger.Info("Webhooks enabled",
+		infrastructure.Int("subscriptions", len(subscriptions)),
+		infrastructure.String("dead_letter_path", cfg.Webhooks.DeadLetterPath),
+	)
+
+	return func() {
+		unsubscribe()
+		dispatcher.Stop(webhookShutdownTimeout)
+		if deadLetters != nil {
+			_ = deadLetters.Close()
+		}
+	}, nil
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/josimar-silva/gwaihir/internal/config"
+	"github.com/josimar-silva/gwaihir/internal/domain"
+	"github.com/josimar-silva/gwaihir/internal/infrastructure"
+	"github.com/josimar-silva/gwaihir/internal/usecase"
+)
+
+func TestStartWebhookDispatcher(t *testing.T) {
+	received := make(chan infrastructure.WebhookPayload, 4)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if r.Header.Get(infrastructure.WebhookSignatureHeader) != infrastructure.SignWebhook("hook-secret", r.Header.Get(infrastructure.WebhookTimestampHeader), body) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var payload infrastructure.WebhookPayload
+		_ = json.Unmarshal(body, &payload)
+		received <- payload
+	}))
+	defer server.Close()
+
+	cfg, err := config.LoadConfig(setupTestConfig(t, validTestConfig()+`
+webhooks:
+  dead_letter_path: `+filepath.Join(t.TempDir(), "dead.jsonl")+`
+  subscriptions:
+    - name: chat
+      url: `+server.URL+`
+      events: [wake.sent]
+      secret: hook-secret
+`))
+	require.NoError(t, err)
+
+	logger := infrastructure.NewLogger("text", "error")
+	metrics := getTestMetrics(t)
+	repo, err := initializeRepository(cfg, logger)
+	require.NoError(t, err)
+	bus := infrastructure.NewEventBus(infrastructure.DefaultEventBuffer)
+	useCase := usecase.NewWoLUseCase(repo, &fakePacketSender{sent: &[]sentPacket{}}, logger, metrics).WithEvents(bus)
+
+	stop, err := startWebhookDispatcher(cfg, bus, logger, metrics)
+	require.NoError(t, err)
+	defer stop()
+
+	machines, err := useCase.ListMachines()
+	require.NoError(t, err)
+	require.NoError(t, useCase.SendWakePacket(context.Background(), machines[0].ID))
+
+	select {
+	case payload := <-received:
+		assert.Equal(t, domain.EventWakeSent, payload.Type)
+		assert.Equal(t, machines[0].ID, payload.MachineID)
+	case <-time.After(5 * time.Second):
+		t.Fatal("webhook not delivered")
+	}
+}
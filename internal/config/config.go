@@ -1,13 +1,22 @@
 package config
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"io"
 	"net"
+	"net/url"
 	"os"
+	"path/filepath"
 	"regexp"
-	"strconv"
+	"slices"
+	"strings"
+	"time"
 
 	"gopkg.in/yaml.v3"
+
+	"github.com/josimar-silva/gwaihir/internal/domain"
 )
 
 var macRegexp = regexp.MustCompile(`^([0-9A-Fa-f]{2}:){5}[0-9A-Fa-f]{2}$`)
@@ -15,11 +24,21 @@ var macRegexp = regexp.MustCompile(`^([0-9A-Fa-f]{2}:){5}[0-9A-Fa-f]{2}$`)
 // LoadConfig loads and parses the configuration from a YAML file.
 // Returns a pointer to Config if successful, or an error if the file
 // cannot be read, contains invalid YAML, or the configuration fails validation.
-// Environment variables override file values with this precedence:
+// Environment variables override file values; see applyEnvOverrides for the mapping.
+// The short forms below are still supported:
 //   - GWAIHIR_PORT overrides server.port
 //   - GWAIHIR_LOG_FORMAT overrides server.log.format
 //   - GWAIHIR_LOG_LEVEL overrides server.log.level
 //   - GWAIHIR_API_KEY overrides authentication.api_key
+//   - GWAIHIR_ADMIN_API_KEY overrides authentication.admin_api_key
+//
+// Values may reference environment variables as ${NAME} or ${NAME:-default}, and
+// authentication keys may be read from files via api_key_file and admin_api_key_file.
+//
+// Machines from files matching the include globs are appended to the machines
+// section, in glob order and then file name order, after environment overrides
+// are applied. GWAIHIR_INCLUDE therefore sets the globs, while GWAIHIR_MACHINES and
+// GWAIHIR_MACHINES_<i>_* only address the machines of the configuration file.
 //
 // The configuration is validated after applying defaults and environment overrides.
 // Returns (*Config, nil) only if the entire configuration is valid.
@@ -32,16 +51,30 @@ func LoadConfig(path string) (*Config, error) {
 	}
 
 	var cfg Config
-	if err := yaml.Unmarshal(data, &cfg); err != nil {
+	if err := decodeExpanded(data, &cfg); err != nil {
 		return nil, fmt.Errorf("failed to parse config file: %w", err)
 	}
 
-	setDefaults(&cfg)
+	for i := range cfg.Machines {
+		cfg.Machines[i].Source = path
+	}
 
+	// Overrides apply to the main document only, so that they may set the include
+	// globs and replace the machines of the file without affecting included ones.
 	if err := applyEnvOverrides(&cfg); err != nil {
 		return nil, fmt.Errorf("invalid environment override: %w", err)
 	}
 
+	if err := loadIncludes(&cfg, path); err != nil {
+		return nil, err
+	}
+
+	if err := loadSecretFiles(&cfg, path); err != nil {
+		return nil, err
+	}
+
+	setDefaults(&cfg)
+
 	if err := cfg.Validate(); err != nil {
 		return nil, fmt.Errorf("invalid configuration: %w", err)
 	}
@@ -49,33 +82,154 @@ func LoadConfig(path string) (*Config, error) {
 	return &cfg, nil
 }
 
-// applyEnvOverrides applies environment variable overrides to the configuration.
-// Environment variables take precedence over file values.
-// Returns an error if any environment variable override has an invalid value.
-func applyEnvOverrides(cfg *Config) error {
-	if port := os.Getenv("GWAIHIR_PORT"); port != "" {
-		p, err := strconv.Atoi(port)
+// IncludePatterns returns the include globs resolved against the directory of
+// the configuration file at configPath.
+func (c *Config) IncludePatterns(configPath string) []string {
+	patterns := make([]string, 0, len(c.Include))
+	for _, pattern := range c.Include {
+		patterns = append(patterns, resolvePath(pattern, configPath))
+	}
+	return patterns
+}
+
+// SecretFiles returns the configured *_file secret paths resolved against the
+// directory of the configuration file at configPath.
+func (c *Config) SecretFiles(configPath string) []string {
+	files := []string{c.Authentication.APIKeyFile, c.Authentication.AdminAPIKeyFile, c.MQTT.PasswordFile}
+	for _, webhook := range c.Webhooks.Subscriptions {
+		files = append(files, webhook.SecretFile)
+	}
+
+	var resolved []string
+	for _, file := range files {
+		if file != "" {
+			resolved = append(resolved, resolvePath(file, configPath))
+		}
+	}
+	return resolved
+}
+
+// resolvePath resolves path against the directory of the configuration file at configPath.
+func resolvePath(path, configPath string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(filepath.Dir(configPath), path)
+}
+
+// loadIncludes appends the machines of every file matching the include globs.
+// A file matched by several globs is only loaded once.
+func loadIncludes(cfg *Config, configPath string) error {
+	loaded := map[string]bool{filepath.Clean(configPath): true}
+
+	for _, pattern := range cfg.IncludePatterns(configPath) {
+		matches, err := filepath.Glob(pattern)
 		if err != nil {
-			return fmt.Errorf("GWAIHIR_PORT must be a valid port number (1-65535), got '%s': %w", port, err)
+			return fmt.Errorf("invalid include pattern '%s': %w", pattern, err)
+		}
+
+		for _, match := range matches {
+			if loaded[filepath.Clean(match)] {
+				continue
+			}
+			loaded[filepath.Clean(match)] = true
+
+			machines, err := loadIncludeFile(match)
+			if err != nil {
+				return err
+			}
+			cfg.Machines = append(cfg.Machines, machines...)
 		}
-		cfg.Server.Port = p
 	}
 
-	if format := os.Getenv("GWAIHIR_LOG_FORMAT"); format != "" {
-		cfg.Server.Log.Format = format
+	return nil
+}
+
+// includeFile is the format of an included file, which may only contribute machines.
+type includeFile struct {
+	Machines []MachineConfig `yaml:"machines"`
+}
+
+// #nosec G304 - path is matched from the include globs of the configuration
+func loadIncludeFile(path string) ([]MachineConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read include file %s: %w", path, err)
+	}
+
+	// Reject unknown fields before expanding environment variable references.
+	var file includeFile
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&file); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to parse include file %s: %w", path, err)
+	}
+
+	file = includeFile{}
+	if err := decodeExpanded(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse include file %s: %w", path, err)
 	}
 
-	if level := os.Getenv("GWAIHIR_LOG_LEVEL"); level != "" {
-		cfg.Server.Log.Level = level
+	for i := range file.Machines {
+		file.Machines[i].Source = path
 	}
 
-	if apiKey := os.Getenv("GWAIHIR_API_KEY"); apiKey != "" {
-		cfg.Authentication.APIKey = apiKey
+	return file.Machines, nil
+}
+
+// loadSecretFiles reads the secrets configured through *_file settings, such as
+// Kubernetes-mounted secrets. Relative paths are resolved against the directory of
+// the configuration file and surrounding whitespace is trimmed.
+func loadSecretFiles(cfg *Config, configPath string) error {
+	type secretSetting struct {
+		name  string
+		file  string
+		value *string
+	}
+	secrets := []secretSetting{
+		{"authentication.api_key", cfg.Authentication.APIKeyFile, &cfg.Authentication.APIKey},
+		{"authentication.admin_api_key", cfg.Authentication.AdminAPIKeyFile, &cfg.Authentication.AdminAPIKey},
+		{"mqtt.password", cfg.MQTT.PasswordFile, &cfg.MQTT.Password},
+	}
+	for i := range cfg.Webhooks.Subscriptions {
+		webhook := &cfg.Webhooks.Subscriptions[i]
+		secrets = append(secrets, secretSetting{fmt.Sprintf("webhooks.subscriptions[%d].secret", i), webhook.SecretFile, &webhook.Secret})
+	}
+
+	for _, secret := range secrets {
+		// A key set in the environment takes precedence over a file from the configuration,
+		// and a file set in the environment over a key from the configuration.
+		if secret.file == "" || cfg.fromEnv(secret.name) {
+			continue
+		}
+		if *secret.value != "" && !cfg.fromEnv(secret.name+"_file") {
+			return fmt.Errorf("invalid configuration: %s and %s_file are mutually exclusive", secret.name, secret.name)
+		}
+
+		value, err := readSecretFile(resolvePath(secret.file, configPath))
+		if err != nil {
+			return fmt.Errorf("failed to read %s_file: %w", secret.name, err)
+		}
+		*secret.value = value
 	}
 
 	return nil
 }
 
+// #nosec G304 - path is controlled by application configuration, not user input
+func readSecretFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	value := strings.TrimSpace(string(data))
+	if value == "" {
+		return "", fmt.Errorf("%s is empty", path)
+	}
+	return value, nil
+}
+
 // setDefaults applies sensible default values for optional configuration fields.
 // Defaults are only applied when values are not already set.
 func setDefaults(cfg *Config) {
@@ -91,6 +245,8 @@ func setDefaults(cfg *Config) {
 		cfg.Server.Log.Level = "info"
 	}
 
+	setLockoutDefaults(&cfg.Authentication.Lockout)
+
 	if cfg.Observability.HealthCheck.Enabled == nil {
 		trueVal := true
 		cfg.Observability.HealthCheck.Enabled = &trueVal
@@ -100,55 +256,386 @@ func setDefaults(cfg *Config) {
 		trueVal := true
 		cfg.Observability.Metrics.Enabled = &trueVal
 	}
+
+	setTracingDefaults(&cfg.Observability.Tracing)
+
+	setAuditDefaults(&cfg.Audit)
+
+	if cfg.Reload.Watch == nil {
+		trueVal := true
+		cfg.Reload.Watch = &trueVal
+	}
+
+	if cfg.Reload.Interval == 0 {
+		cfg.Reload.Interval = 5 * time.Second
+	}
+
+	setStorageDefaults(&cfg.Storage)
+
+	if cfg.Discovery.Enabled == nil {
+		falseVal := false
+		cfg.Discovery.Enabled = &falseVal
+	}
+
+	if cfg.Discovery.ARPPath == "" {
+		cfg.Discovery.ARPPath = DefaultARPTablePath
+	}
+
+	setGRPCDefaults(&cfg.GRPC)
+	setMQTTDefaults(&cfg.MQTT)
+	setWebhookDefaults(&cfg.Webhooks)
+
+	if cfg.Events.HistorySize == 0 {
+		cfg.Events.HistorySize = 256
+	}
+
+	if cfg.UI.Enabled == nil {
+		trueVal := true
+		cfg.UI.Enabled = &trueVal
+	}
+}
+
+// setWebhookDefaults applies defaults for the webhook delivery settings.
+func setWebhookDefaults(webhooks *WebhooksConfig) {
+	if webhooks.Timeout == 0 {
+		webhooks.Timeout = 5 * time.Second
+	}
+
+	if webhooks.MaxAttempts == 0 {
+		webhooks.MaxAttempts = 5
+	}
+
+	if webhooks.InitialBackoff == 0 {
+		webhooks.InitialBackoff = time.Second
+	}
+
+	if webhooks.MaxBackoff == 0 {
+		webhooks.MaxBackoff = time.Minute
+	}
+
+	if webhooks.QueueSize == 0 {
+		webhooks.QueueSize = 100
+	}
+}
+
+// setGRPCDefaults applies defaults for the gRPC API settings.
+func setGRPCDefaults(grpc *GRPCConfig) {
+	if grpc.Enabled == nil {
+		falseVal := false
+		grpc.Enabled = &falseVal
+	}
+
+	if grpc.Port == 0 {
+		grpc.Port = 9090
+	}
+
+	if grpc.Reflection == nil {
+		trueVal := true
+		grpc.Reflection = &trueVal
+	}
+}
+
+// setTracingDefaults applies defaults for the OpenTelemetry tracing settings.
+func setTracingDefaults(tracing *TracingConfig) {
+	if tracing.Enabled == nil {
+		falseVal := false
+		tracing.Enabled = &falseVal
+	}
+
+	if tracing.Protocol == "" {
+		tracing.Protocol = TracingProtocolGRPC
+	}
+
+	if tracing.Insecure == nil {
+		falseVal := false
+		tracing.Insecure = &falseVal
+	}
+
+	if tracing.SampleRatio == nil {
+		ratio := 1.0
+		tracing.SampleRatio = &ratio
+	}
+
+	if tracing.ServiceName == "" {
+		tracing.ServiceName = "gwaihir"
+	}
+}
+
+// setMQTTDefaults applies defaults for the MQTT integration settings.
+func setMQTTDefaults(mqtt *MQTTConfig) {
+	if mqtt.Enabled == nil {
+		falseVal := false
+		mqtt.Enabled = &falseVal
+	}
+
+	if mqtt.ClientID == "" {
+		mqtt.ClientID = "gwaihir"
+	}
+
+	if mqtt.TopicPrefix == "" {
+		mqtt.TopicPrefix = "gwaihir"
+	}
+
+	if mqtt.QoS == nil {
+		qos := 1
+		mqtt.QoS = &qos
+	}
+
+	if mqtt.ConnectTimeout == 0 {
+		mqtt.ConnectTimeout = 10 * time.Second
+	}
+
+	if mqtt.MaxReconnectInterval == 0 {
+		mqtt.MaxReconnectInterval = 2 * time.Minute
+	}
+
+	if mqtt.HomeAssistant.Enabled == nil {
+		trueVal := true
+		mqtt.HomeAssistant.Enabled = &trueVal
+	}
+
+	if mqtt.HomeAssistant.DiscoveryPrefix == "" {
+		mqtt.HomeAssistant.DiscoveryPrefix = "homeassistant"
+	}
+}
+
+// setLockoutDefaults applies defaults for the authentication lockout settings.
+func setLockoutDefaults(lockout *LockoutConfig) {
+	if lockout.Enabled == nil {
+		trueVal := true
+		lockout.Enabled = &trueVal
+	}
+
+	if lockout.MaxFailures == 0 {
+		lockout.MaxFailures = DefaultLockoutMaxFailures
+	}
+
+	if lockout.BaseDuration == 0 {
+		lockout.BaseDuration = DefaultLockoutBaseDuration
+	}
+
+	if lockout.MaxDuration == 0 {
+		lockout.MaxDuration = DefaultLockoutMaxDuration
+	}
+
+	if lockout.MaxClients == 0 {
+		lockout.MaxClients = DefaultLockoutMaxClients
+	}
+}
+
+// setAuditDefaults applies defaults for the audit trail settings.
+// The audit trail is disabled unless explicitly enabled.
+func setAuditDefaults(audit *AuditConfig) {
+	if audit.Enabled == nil {
+		falseVal := false
+		audit.Enabled = &falseVal
+	}
+
+	if audit.Path == "" {
+		audit.Path = "/var/lib/gwaihir/audit.jsonl"
+	}
+
+	if audit.MaxSizeMB == 0 {
+		audit.MaxSizeMB = 10
+	}
+
+	if audit.MaxBackups == 0 {
+		audit.MaxBackups = 5
+	}
+}
+
+// setStorageDefaults applies defaults for the machine storage settings.
+func setStorageDefaults(storage *StorageConfig) {
+	if storage.Driver == "" {
+		storage.Driver = StorageDriverYAML
+	}
+
+	if storage.Path == "" {
+		storage.Path = "/var/lib/gwaihir/machines.db"
+	}
+
+	if storage.Seed == nil {
+		trueVal := true
+		storage.Seed = &trueVal
+	}
 }
 
 // Validate validates all configuration fields and returns an error if any validation fails.
+// All failures are reported at once, joined with errors.Join.
 // Validation checks:
 // - server.port: must be in range 1-65535
 // - server.log.format: must be "json" or "text"
 // - server.log.level: must be "debug", "info", "warn", or "error"
+// - server.trusted_proxies: each entry must be an IP address or CIDR range
 // - authentication.api_key: optional (empty key means public endpoints)
-// - machines: must have at least 1 machine, each must be valid (MAC, broadcast IP)
+// - authentication.admin_api_key: required when storage.machines_file is set, must differ from api_key
+// - authentication.lockout: values must not be negative, base_duration <= max_duration
+// - audit: max_size_mb and max_backups must not be negative
+// - reload.interval: must not be negative
+// - storage.driver: must be "yaml" or "bolt"; machines_file is only supported with "yaml"
+// - sources: unique names, supported format, a path, a hostname selector and a broadcast rule
+// - grpc.port: when enabled, must be in range 1-65535 and differ from server.port
+// - mqtt: when enabled, a tcp, ssl, tls, ws or wss broker URL, qos 0-2 and cert_file with key_file
+// - webhooks: settings must not be negative; unique names, an http(s) url, a secret and known event types per subscription
+// - events.history_size: must not be negative
+// - observability.tracing: when enabled, protocol "grpc" or "http/protobuf" and sample_ratio between 0 and 1
+// - machines: at least 1 required unless sources, storage.machines_file or the "bolt" driver are used, each must be valid
+// - machine ids: must be unique across the configuration file and all included files
+// - machine ids: must start with a letter or digit and contain only letters, digits, '.', '_' and '-'
 func (cfg *Config) Validate() error {
+	var errs []error
+
 	if cfg.Server.Port < 1 || cfg.Server.Port > 65535 {
-		return fmt.Errorf("invalid server port: must be between 1 and 65535, got %d", cfg.Server.Port)
+		errs = append(errs, fmt.Errorf("invalid server port: must be between 1 and 65535, got %d", cfg.Server.Port))
 	}
 
-	if err := validateLogFormat(cfg.Server.Log.Format); err != nil {
-		return err
+	errs = append(errs,
+		validateLogFormat(cfg.Server.Log.Format),
+		validateLogLevel(cfg.Server.Log.Level),
+		validateTrustedProxies(cfg.Server.TrustedProxies),
+		validateLockout(cfg.Authentication.Lockout),
+		validateAudit(cfg.Audit),
+	)
+
+	if cfg.Reload.Interval < 0 {
+		errs = append(errs, fmt.Errorf("invalid reload.interval: must not be negative, got %s", cfg.Reload.Interval))
 	}
 
-	if err := validateLogLevel(cfg.Server.Log.Level); err != nil {
-		return err
+	errs = append(errs,
+		validateStorage(cfg),
+		validateSources(cfg.Sources),
+		validateGRPC(cfg),
+		validateMQTT(cfg.MQTT),
+		validateWebhooks(cfg.Webhooks),
+		validateTracing(cfg.Observability.Tracing),
+	)
+
+	if cfg.Events.HistorySize < 0 {
+		errs = append(errs, fmt.Errorf("invalid events.history_size: must not be negative, got %d", cfg.Events.HistorySize))
 	}
 
-	if len(cfg.Machines) == 0 {
-		return fmt.Errorf("at least one machine must be configured")
+	if len(cfg.Machines) == 0 && len(cfg.Sources) == 0 && cfg.Storage.MachinesFile == "" && cfg.Storage.Driver != StorageDriverBolt {
+		errs = append(errs, fmt.Errorf("at least one machine must be configured"))
 	}
 
-	seenIDs := make(map[string]bool)
+	seen := make(map[string]MachineConfig)
 	for i, machine := range cfg.Machines {
 		if machine.ID == "" {
-			return fmt.Errorf("machine %d: id cannot be empty", i)
+			errs = append(errs, fmt.Errorf("%s: id cannot be empty", machine.describe(i)))
+			continue
 		}
 
 		if machine.Name == "" {
-			return fmt.Errorf("machine %d (%s): name cannot be empty", i, machine.ID)
+			errs = append(errs, fmt.Errorf("%s: name cannot be empty", machine.describe(i)))
+			continue
 		}
 
-		if seenIDs[machine.ID] {
-			return fmt.Errorf("duplicate machine id: '%s'", machine.ID)
+		if first, exists := seen[machine.ID]; exists {
+			if first.Source != "" || machine.Source != "" {
+				errs = append(errs, fmt.Errorf("duplicate machine id: '%s' (defined at %s and %s)", machine.ID, first.position(), machine.position()))
+			} else {
+				errs = append(errs, fmt.Errorf("duplicate machine id: '%s'", machine.ID))
+			}
+			continue
 		}
-		seenIDs[machine.ID] = true
+		seen[machine.ID] = machine
 
 		if err := validateMachine(machine); err != nil {
-			return fmt.Errorf("machine %d (%s): %w", i, machine.ID, err)
+			errs = append(errs, fmt.Errorf("%s: %w", machine.describe(i), err))
 		}
 	}
 
+	return errors.Join(errs...)
+}
+
+func validateGRPC(cfg *Config) error {
+	if cfg.GRPC.Enabled == nil || !*cfg.GRPC.Enabled {
+		return nil
+	}
+	if cfg.GRPC.Port < 1 || cfg.GRPC.Port > 65535 {
+		return fmt.Errorf("invalid grpc.port: must be between 1 and 65535, got %d", cfg.GRPC.Port)
+	}
+	if cfg.GRPC.Port == cfg.Server.Port {
+		return fmt.Errorf("invalid grpc.port: must differ from server.port, both are %d", cfg.GRPC.Port)
+	}
 	return nil
 }
 
+func validateMQTT(mqtt MQTTConfig) error {
+	if mqtt.Enabled == nil || !*mqtt.Enabled {
+		return nil
+	}
+
+	var errs []error
+	if mqtt.Broker == "" {
+		errs = append(errs, fmt.Errorf("invalid mqtt.broker: must be set when mqtt is enabled"))
+	} else if u, err := url.Parse(mqtt.Broker); err != nil || !slices.Contains(mqttSchemes, u.Scheme) || u.Host == "" {
+		errs = append(errs, fmt.Errorf("invalid mqtt.broker: must be a URL with scheme %s, got %q", strings.Join(mqttSchemes, ", "), RedactURL(mqtt.Broker)))
+	}
+	if mqtt.QoS != nil && (*mqtt.QoS < 0 || *mqtt.QoS > 2) {
+		errs = append(errs, fmt.Errorf("invalid mqtt.qos: must be 0, 1 or 2, got %d", *mqtt.QoS))
+	}
+	if strings.ContainsAny(mqtt.TopicPrefix, "+#") || strings.HasSuffix(mqtt.TopicPrefix, "/") {
+		errs = append(errs, fmt.Errorf("invalid mqtt.topic_prefix: must not contain wildcards or end with /, got %q", mqtt.TopicPrefix))
+	}
+	if (mqtt.TLS.CertFile == "") != (mqtt.TLS.KeyFile == "") {
+		errs = append(errs, fmt.Errorf("invalid mqtt.tls: cert_file and key_file must be set together"))
+	}
+	if mqtt.MaxReconnectInterval < 0 {
+		errs = append(errs, fmt.Errorf("invalid mqtt.max_reconnect_interval: must not be negative, got %s", mqtt.MaxReconnectInterval))
+	}
+	return errors.Join(errs...)
+}
+
+func validateTracing(tracing TracingConfig) error {
+	if tracing.Enabled == nil || !*tracing.Enabled {
+		return nil
+	}
+
+	var errs []error
+	if tracing.Protocol != TracingProtocolGRPC && tracing.Protocol != TracingProtocolHTTP {
+		errs = append(errs, fmt.Errorf("invalid observability.tracing.protocol: must be '%s' or '%s', got '%s'", TracingProtocolGRPC, TracingProtocolHTTP, tracing.Protocol))
+	}
+	if tracing.SampleRatio != nil && (*tracing.SampleRatio < 0 || *tracing.SampleRatio > 1) {
+		errs = append(errs, fmt.Errorf("invalid observability.tracing.sample_ratio: must be between 0 and 1, got %g", *tracing.SampleRatio))
+	}
+	return errors.Join(errs...)
+}
+
+func validateWebhooks(webhooks WebhooksConfig) error {
+	var errs []error
+	if webhooks.Timeout < 0 || webhooks.InitialBackoff < 0 || webhooks.MaxBackoff < 0 || webhooks.MaxAttempts < 0 || webhooks.QueueSize < 0 {
+		errs = append(errs, fmt.Errorf("invalid webhooks: timeout, max_attempts, initial_backoff, max_backoff and queue_size must not be negative"))
+	}
+	if webhooks.InitialBackoff > 0 && webhooks.MaxBackoff > 0 && webhooks.InitialBackoff > webhooks.MaxBackoff {
+		errs = append(errs, fmt.Errorf("invalid webhooks: initial_backoff (%s) must not exceed max_backoff (%s)", webhooks.InitialBackoff, webhooks.MaxBackoff))
+	}
+
+	names := make(map[string]bool, len(webhooks.Subscriptions))
+	for i, webhook := range webhooks.Subscriptions {
+		prefix := fmt.Sprintf("webhooks.subscriptions[%d]", i)
+		if webhook.Name == "" {
+			errs = append(errs, fmt.Errorf("%s: name cannot be empty", prefix))
+		} else if names[webhook.Name] {
+			errs = append(errs, fmt.Errorf("%s: duplicate name '%s'", prefix, webhook.Name))
+		}
+		names[webhook.Name] = true
+
+		if u, err := url.Parse(webhook.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errs = append(errs, fmt.Errorf("%s: url of subscription '%s' must be an http or https URL", prefix, webhook.Name))
+		}
+		if webhook.Secret == "" {
+			errs = append(errs, fmt.Errorf("%s: secret or secret_file is required to sign payloads", prefix))
+		}
+		for _, event := range webhook.Events {
+			if !slices.Contains(domain.EventTypes, event) {
+				errs = append(errs, fmt.Errorf("%s: unknown event '%s', must be one of %s", prefix, event, strings.Join(domain.EventTypes, ", ")))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
 func validateLogFormat(format string) error {
 	validFormats := map[string]bool{"json": true, "text": true}
 	if !validFormats[format] {
@@ -165,7 +652,143 @@ func validateLogLevel(level string) error {
 	return nil
 }
 
+func validateTrustedProxies(proxies []string) error {
+	var errs []error
+	for _, proxy := range proxies {
+		if net.ParseIP(proxy) != nil {
+			continue
+		}
+		if _, _, err := net.ParseCIDR(proxy); err != nil {
+			errs = append(errs, fmt.Errorf("invalid server.trusted_proxies: must be an IP address or CIDR range, got '%s'", proxy))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func validateLockout(lockout LockoutConfig) error {
+	var errs []error
+
+	if lockout.MaxFailures < 0 {
+		errs = append(errs, fmt.Errorf("invalid authentication.lockout.max_failures: must not be negative, got %d", lockout.MaxFailures))
+	}
+
+	if lockout.BaseDuration < 0 {
+		errs = append(errs, fmt.Errorf("invalid authentication.lockout.base_duration: must not be negative, got %s", lockout.BaseDuration))
+	}
+
+	if lockout.MaxDuration < 0 {
+		errs = append(errs, fmt.Errorf("invalid authentication.lockout.max_duration: must not be negative, got %s", lockout.MaxDuration))
+	}
+
+	if lockout.BaseDuration > 0 && lockout.MaxDuration > 0 && lockout.MaxDuration < lockout.BaseDuration {
+		errs = append(errs, fmt.Errorf("invalid authentication.lockout.max_duration: must be at least base_duration (%s), got %s", lockout.BaseDuration, lockout.MaxDuration))
+	}
+
+	if lockout.MaxClients < 0 {
+		errs = append(errs, fmt.Errorf("invalid authentication.lockout.max_clients: must not be negative, got %d", lockout.MaxClients))
+	}
+
+	return errors.Join(errs...)
+}
+
+func validateAudit(audit AuditConfig) error {
+	var errs []error
+
+	if audit.MaxSizeMB < 0 {
+		errs = append(errs, fmt.Errorf("invalid audit.max_size_mb: must not be negative, got %d", audit.MaxSizeMB))
+	}
+
+	if audit.MaxBackups < 0 {
+		errs = append(errs, fmt.Errorf("invalid audit.max_backups: must not be negative, got %d", audit.MaxBackups))
+	}
+
+	return errors.Join(errs...)
+}
+
+func validateStorage(cfg *Config) error {
+	var errs []error
+
+	switch cfg.Storage.Driver {
+	case "", StorageDriverYAML:
+	case StorageDriverBolt:
+		if cfg.Storage.MachinesFile != "" {
+			errs = append(errs, fmt.Errorf("storage.machines_file is only supported with the %s driver", StorageDriverYAML))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("invalid storage.driver: '%s' (must be '%s' or '%s')", cfg.Storage.Driver, StorageDriverYAML, StorageDriverBolt))
+	}
+
+	if cfg.Authentication.AdminAPIKey != "" && cfg.Authentication.AdminAPIKey == cfg.Authentication.APIKey {
+		errs = append(errs, fmt.Errorf("authentication.admin_api_key must differ from authentication.api_key"))
+	}
+
+	if cfg.Storage.MachinesFile != "" && cfg.Authentication.AdminAPIKey == "" {
+		errs = append(errs, fmt.Errorf("authentication.admin_api_key is required when storage.machines_file is set"))
+	}
+
+	return errors.Join(errs...)
+}
+
+func validateSources(sources []MachineSourceConfig) error {
+	validFormats := map[string]bool{"dnsmasq": true, "dhcpd": true, "ethers": true}
+	seenNames := make(map[string]bool)
+
+	var errs []error
+	for i, source := range sources {
+		if source.Name == "" {
+			errs = append(errs, fmt.Errorf("sources[%d]: name cannot be empty", i))
+			continue
+		}
+		if seenNames[source.Name] {
+			errs = append(errs, fmt.Errorf("duplicate source name: '%s'", source.Name))
+			continue
+		}
+		seenNames[source.Name] = true
+
+		if !validFormats[source.Format] {
+			errs = append(errs, fmt.Errorf("source %s: invalid format '%s' (must be 'dnsmasq', 'dhcpd' or 'ethers')", source.Name, source.Format))
+		}
+
+		if source.Path == "" {
+			errs = append(errs, fmt.Errorf("source %s: path cannot be empty", source.Name))
+		}
+
+		if len(source.Hostnames) == 0 && source.HostnamePattern == "" {
+			errs = append(errs, fmt.Errorf("source %s: hostnames or hostname_pattern is required", source.Name))
+		}
+
+		if source.HostnamePattern != "" {
+			if _, err := regexp.Compile(source.HostnamePattern); err != nil {
+				errs = append(errs, fmt.Errorf("source %s: invalid hostname_pattern: %w", source.Name, err))
+			}
+		}
+
+		if len(source.Subnets) == 0 && source.Broadcast == "" {
+			errs = append(errs, fmt.Errorf("source %s: subnets or broadcast is required", source.Name))
+		}
+
+		for _, subnet := range source.Subnets {
+			if _, network, err := net.ParseCIDR(subnet.Subnet); err != nil || network.IP.To4() == nil {
+				errs = append(errs, fmt.Errorf("source %s: invalid subnet '%s' (must be an IPv4 CIDR)", source.Name, subnet.Subnet))
+			}
+			if subnet.Broadcast != "" && !isValidIP(subnet.Broadcast) {
+				errs = append(errs, fmt.Errorf("source %s: invalid broadcast IP address '%s' for subnet %s", source.Name, subnet.Broadcast, subnet.Subnet))
+			}
+		}
+
+		if source.Broadcast != "" && !isValidIP(source.Broadcast) {
+			errs = append(errs, fmt.Errorf("source %s: invalid broadcast IP address: '%s'", source.Name, source.Broadcast))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
 func validateMachine(machine MachineConfig) error {
+	if err := domain.ValidateMachineID(machine.ID); err != nil {
+		return err
+	}
+
 	if !isValidMAC(machine.MAC) {
 		return fmt.Errorf("invalid MAC address format: '%s' (must be XX:XX:XX:XX:XX:XX)", machine.MAC)
 	}
@@ -190,16 +813,35 @@ func isValidIP(ip string) bool {
 // It contains all application settings in a single structure that can be
 // loaded from YAML with environment variable overrides.
 type Config struct {
-	Server         ServerConfig         `yaml:"server"`
-	Authentication AuthenticationConfig `yaml:"authentication"`
-	Machines       []MachineConfig      `yaml:"machines"`
-	Observability  ObservabilityConfig  `yaml:"observability"`
+	Server         ServerConfig          `yaml:"server"`
+	Authentication AuthenticationConfig  `yaml:"authentication"`
+	Include        []string              `yaml:"include"`
+	Machines       []MachineConfig       `yaml:"machines"`
+	Sources        []MachineSourceConfig `yaml:"sources"`
+	Observability  ObservabilityConfig   `yaml:"observability"`
+	Audit          AuditConfig           `yaml:"audit"`
+	Reload         ReloadConfig          `yaml:"reload"`
+	Storage        StorageConfig         `yaml:"storage"`
+	Discovery      DiscoveryConfig       `yaml:"discovery"`
+	GRPC           GRPCConfig            `yaml:"grpc"`
+	MQTT           MQTTConfig            `yaml:"mqtt"`
+	Webhooks       WebhooksConfig        `yaml:"webhooks"`
+	Events         EventsConfig          `yaml:"events"`
+	UI             UIConfig              `yaml:"ui"`
+
+	// envOverrides maps the configuration paths set from environment variables
+	// to the variable that set them.
+	envOverrides map[string]string
 }
 
 // ServerConfig contains HTTP server configuration.
+// TrustedProxies lists the IP addresses or CIDR ranges of reverse proxies whose
+// X-Forwarded-For and X-Real-IP headers are trusted for the client address. When
+// empty, the address of the connecting peer is used.
 type ServerConfig struct {
-	Port int       `yaml:"port"`
-	Log  LogConfig `yaml:"log"`
+	Port           int       `yaml:"port"`
+	Log            LogConfig `yaml:"log"`
+	TrustedProxies []string  `yaml:"trusted_proxies"`
 }
 
 // LogConfig contains logging configuration.
@@ -209,22 +851,237 @@ type LogConfig struct {
 }
 
 // AuthenticationConfig contains authentication settings.
+// APIKeyFile and AdminAPIKeyFile read the keys from files instead.
 type AuthenticationConfig struct {
-	APIKey string `yaml:"api_key"`
+	APIKey          string        `yaml:"api_key"`
+	APIKeyFile      string        `yaml:"api_key_file"`
+	AdminAPIKey     string        `yaml:"admin_api_key"`
+	AdminAPIKeyFile string        `yaml:"admin_api_key_file"`
+	Lockout         LockoutConfig `yaml:"lockout"`
+}
+
+// Default lockout settings, applied to the settings left unset.
+const (
+	DefaultLockoutMaxFailures  = 5
+	DefaultLockoutBaseDuration = 30 * time.Second
+	DefaultLockoutMaxDuration  = 15 * time.Minute
+	DefaultLockoutMaxClients   = 10000
+)
+
+// LockoutConfig controls temporary blocking of clients that repeatedly fail authentication.
+// After MaxFailures consecutive failures a client is locked out for BaseDuration, doubling
+// on every subsequent lockout up to MaxDuration. At most MaxClients are tracked at once.
+type LockoutConfig struct {
+	Enabled      *bool         `yaml:"enabled"`
+	MaxFailures  int           `yaml:"max_failures"`
+	BaseDuration time.Duration `yaml:"base_duration"`
+	MaxDuration  time.Duration `yaml:"max_duration"`
+	MaxClients   int           `yaml:"max_clients"`
 }
 
 // MachineConfig represents a machine that can receive WoL packets.
+// Source and Line record where the machine was defined; they are set by
+// LoadConfig and empty for configurations built in code.
 type MachineConfig struct {
 	ID        string `yaml:"id"`
 	Name      string `yaml:"name"`
 	MAC       string `yaml:"mac"`
 	Broadcast string `yaml:"broadcast"`
+	Source    string `yaml:"-"`
+	Line      int    `yaml:"-"`
+}
+
+// UnmarshalYAML decodes the machine and records the line it starts on.
+func (m *MachineConfig) UnmarshalYAML(value *yaml.Node) error {
+	type plain MachineConfig
+	if err := value.Decode((*plain)(m)); err != nil {
+		return err
+	}
+	m.Line = value.Line
+	return nil
+}
+
+// describe identifies the machine at index i in validation errors.
+func (m MachineConfig) describe(i int) string {
+	if m.Source == "" {
+		if m.ID == "" {
+			return fmt.Sprintf("machine %d", i)
+		}
+		return fmt.Sprintf("machine %d (%s)", i, m.ID)
+	}
+	if m.ID == "" {
+		return fmt.Sprintf("%s: machine", m.position())
+	}
+	return fmt.Sprintf("%s: machine %s", m.position(), m.ID)
+}
+
+// position returns the file and line the machine was defined at.
+func (m MachineConfig) position() string {
+	if m.Source == "" {
+		return "configuration"
+	}
+	return fmt.Sprintf("%s:%d", m.Source, m.Line)
+}
+
+// MachineSourceConfig imports machines from a DHCP lease or ethers file.
+// Only entries whose hostname is listed in Hostnames or matches HostnamePattern
+// are imported. The broadcast address is taken from the first subnet containing
+// the entry's IP address, falling back to Broadcast.
+type MachineSourceConfig struct {
+	Name            string                  `yaml:"name"`
+	Format          string                  `yaml:"format"` // dnsmasq, dhcpd or ethers
+	Path            string                  `yaml:"path"`
+	Hostnames       []string                `yaml:"hostnames"`
+	HostnamePattern string                  `yaml:"hostname_pattern"`
+	Subnets         []SubnetBroadcastConfig `yaml:"subnets"`
+	Broadcast       string                  `yaml:"broadcast"`
+}
+
+// SubnetBroadcastConfig maps a subnet to the broadcast address used for its machines.
+// An empty Broadcast uses the subnet's own broadcast address.
+type SubnetBroadcastConfig struct {
+	Subnet    string `yaml:"subnet"`
+	Broadcast string `yaml:"broadcast"`
+}
+
+// AuditConfig controls the persistent append-only audit trail.
+type AuditConfig struct {
+	Enabled    *bool  `yaml:"enabled"`
+	Path       string `yaml:"path"`
+	MaxSizeMB  int    `yaml:"max_size_mb"`
+	MaxBackups int    `yaml:"max_backups"`
+}
+
+// ReloadConfig controls hot reloading of the machine allowlist.
+// A reload is always triggered by SIGHUP; Watch additionally polls the
+// configuration file every Interval and reloads when its content changes.
+type ReloadConfig struct {
+	Watch    *bool         `yaml:"watch"`
+	Interval time.Duration `yaml:"interval"`
+}
+
+// Storage drivers.
+const (
+	// StorageDriverYAML serves machines from the configuration file, optionally
+	// persisting runtime-managed machines to MachinesFile.
+	StorageDriverYAML = "yaml"
+
+	// StorageDriverBolt stores machines in an embedded bbolt database at Path.
+	StorageDriverBolt = "bolt"
+)
+
+// StorageConfig controls where machines are stored.
+// With the yaml driver, setting MachinesFile enables the machine management API
+// and machines created at runtime are written back to that file. With the bolt
+// driver, machines live in the database at Path; when Seed is enabled the
+// machines section of the configuration is imported on first start.
+type StorageConfig struct {
+	Driver       string `yaml:"driver"`
+	Path         string `yaml:"path"`
+	Seed         *bool  `yaml:"seed"`
+	MachinesFile string `yaml:"machines_file"`
+}
+
+// DefaultARPTablePath is the location of the Linux IPv4 neighbor table, read by
+// discovery when no ARPPath is set.
+const DefaultARPTablePath = "/proc/net/arp"
+
+// DiscoveryConfig controls the neighbor discovery endpoint, which lists the hosts
+// found in the neighbor table read from ARPPath. It is disabled by default since it
+// exposes the local network layout.
+type DiscoveryConfig struct {
+	Enabled *bool  `yaml:"enabled"`
+	ARPPath string `yaml:"arp_path"`
+}
+
+// EventsConfig controls the GET /events stream. The last HistorySize events are kept
+// in memory, so clients reconnecting with Last-Event-ID receive the events they missed.
+type EventsConfig struct {
+	HistorySize int `yaml:"history_size"`
+}
+
+// UIConfig controls the web dashboard served at /ui. The dashboard only holds static
+// files; the API calls it makes are authenticated like any other client.
+type UIConfig struct {
+	Enabled *bool `yaml:"enabled"`
+}
+
+// GRPCConfig controls the gRPC API, served on its own Port next to the HTTP API.
+// Reflection lets tools such as grpcurl discover the services without the proto files.
+type GRPCConfig struct {
+	Enabled    *bool `yaml:"enabled"`
+	Port       int   `yaml:"port"`
+	Reflection *bool `yaml:"reflection"`
+}
+
+// mqttSchemes are the broker URL schemes supported by the MQTT client.
+var mqttSchemes = []string{"tcp", "ssl", "tls", "ws", "wss"}
+
+// MQTTConfig controls the MQTT integration, which wakes machines on messages to
+// <topic_prefix>/<machine_id>/wake and publishes wake results and availability.
+// Broker is a URL such as tcp://broker:1883 or ssl://broker:8883. Lost connections
+// are retried with exponential backoff up to MaxReconnectInterval.
+type MQTTConfig struct {
+	Enabled              *bool               `yaml:"enabled"`
+	Broker               string              `yaml:"broker"`
+	ClientID             string              `yaml:"client_id"`
+	Username             string              `yaml:"username"`
+	Password             string              `yaml:"password"`
+	PasswordFile         string              `yaml:"password_file"`
+	TopicPrefix          string              `yaml:"topic_prefix"`
+	QoS                  *int                `yaml:"qos"`
+	ConnectTimeout       time.Duration       `yaml:"connect_timeout"`
+	MaxReconnectInterval time.Duration       `yaml:"max_reconnect_interval"`
+	TLS                  MQTTTLSConfig       `yaml:"tls"`
+	HomeAssistant        HomeAssistantConfig `yaml:"home_assistant"`
+}
+
+// MQTTTLSConfig configures TLS for ssl://, tls:// and wss:// brokers. CAFile adds a
+// certificate authority to the system pool; CertFile and KeyFile enable client
+// certificate authentication.
+type MQTTTLSConfig struct {
+	CAFile             string `yaml:"ca_file"`
+	CertFile           string `yaml:"cert_file"`
+	KeyFile            string `yaml:"key_file"`
+	InsecureSkipVerify bool   `yaml:"insecure_skip_verify"`
+}
+
+// HomeAssistantConfig controls the Home Assistant MQTT discovery payloads announcing
+// every machine as a button entity under DiscoveryPrefix.
+type HomeAssistantConfig struct {
+	Enabled         *bool  `yaml:"enabled"`
+	DiscoveryPrefix string `yaml:"discovery_prefix"`
+}
+
+// WebhooksConfig controls the webhooks notified of machine events. Deliveries are
+// queued per subscription, up to QueueSize, and retried up to MaxAttempts times with
+// exponential backoff from InitialBackoff to MaxBackoff. Deliveries that still fail
+// are logged and, when DeadLetterPath is set, appended to that JSON Lines file.
+type WebhooksConfig struct {
+	Subscriptions  []WebhookConfig `yaml:"subscriptions"`
+	Timeout        time.Duration   `yaml:"timeout"`
+	MaxAttempts    int             `yaml:"max_attempts"`
+	InitialBackoff time.Duration   `yaml:"initial_backoff"`
+	MaxBackoff     time.Duration   `yaml:"max_backoff"`
+	QueueSize      int             `yaml:"queue_size"`
+	DeadLetterPath string          `yaml:"dead_letter_path"`
+}
+
+// WebhookConfig is a webhook subscription. Payloads are signed with Secret; Events
+// filters the event types delivered, all of them when empty.
+type WebhookConfig struct {
+	Name       string   `yaml:"name"`
+	URL        string   `yaml:"url"`
+	Events     []string `yaml:"events"`
+	Secret     string   `yaml:"secret"`
+	SecretFile string   `yaml:"secret_file"`
 }
 
 // ObservabilityConfig contains observability settings.
 type ObservabilityConfig struct {
 	HealthCheck HealthCheckConfig `yaml:"health_check"`
 	Metrics     MetricsConfig     `yaml:"metrics"`
+	Tracing     TracingConfig     `yaml:"tracing"`
 }
 
 // HealthCheckConfig controls health check endpoint exposure.
@@ -237,6 +1094,68 @@ type MetricsConfig struct {
 	Enabled *bool `yaml:"enabled"`
 }
 
+// OTLP protocols supported by the trace exporter.
+const (
+	TracingProtocolGRPC = "grpc"
+	TracingProtocolHTTP = "http/protobuf"
+)
+
+// TracingConfig controls OpenTelemetry tracing. Spans are exported over OTLP to
+// Endpoint, either a host:port or a URL; when empty the standard
+// OTEL_EXPORTER_OTLP_* environment variables apply, which is also how exporter
+// headers are set. SampleRatio is the fraction of new traces recorded; requests
+// carrying a W3C traceparent follow the sampling decision of the caller.
+type TracingConfig struct {
+	Enabled     *bool    `yaml:"enabled"`
+	Endpoint    string   `yaml:"endpoint"`
+	Protocol    string   `yaml:"protocol"`
+	Insecure    *bool    `yaml:"insecure"`
+	SampleRatio *float64 `yaml:"sample_ratio"`
+	ServiceName string   `yaml:"service_name"`
+}
+
+// redactedValue replaces secrets in Redacted.
+const redactedValue = "[REDACTED]"
+
+// Redacted returns a copy of the configuration with secrets replaced, suitable for
+// printing. Webhook and MQTT broker URLs keep only their scheme and host, as their
+// credentials, path and query often carry tokens. Only the redacted values are copied; the remaining
+// fields share memory with c.
+func (c *Config) Redacted() *Config {
+	redacted := *c
+	redacted.Webhooks.Subscriptions = slices.Clone(c.Webhooks.Subscriptions)
+	redacted.MQTT.Broker = RedactURL(c.MQTT.Broker)
+
+	secrets := []*string{&redacted.Authentication.APIKey, &redacted.Authentication.AdminAPIKey, &redacted.MQTT.Password}
+	for i := range redacted.Webhooks.Subscriptions {
+		subscription := &redacted.Webhooks.Subscriptions[i]
+		subscription.URL = RedactURL(subscription.URL)
+		secrets = append(secrets, &subscription.Secret)
+	}
+	for _, secret := range secrets {
+		if *secret != "" {
+			*secret = redactedValue
+		}
+	}
+	return &redacted
+}
+
+// RedactURL keeps the scheme and host of rawURL and replaces its credentials, path,
+// query and fragment. URLs that cannot be parsed are replaced entirely.
+func RedactURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		if rawURL == "" {
+			return ""
+		}
+		return redactedValue
+	}
+	if u.User == nil && (u.Path == "" || u.Path == "/") && u.RawQuery == "" && u.Fragment == "" {
+		return rawURL
+	}
+	return u.Scheme + "://" + u.Host + "/" + redactedValue
+}
+
 // String returns a human-readable summary of the configuration.
 func (c *Config) String() string {
 	const enabled = "enabled"
@@ -247,6 +1166,16 @@ func (c *Config) String() string {
 		authStatus = enabled
 	}
 
+	adminStatus := disabled
+	if c.Storage.MachinesFile != "" || (c.Storage.Driver == StorageDriverBolt && c.Authentication.AdminAPIKey != "") {
+		adminStatus = enabled
+	}
+
+	storageDriver := c.Storage.Driver
+	if storageDriver == "" {
+		storageDriver = StorageDriverYAML
+	}
+
 	healthStatus := enabled
 	if c.Observability.HealthCheck.Enabled != nil && !*c.Observability.HealthCheck.Enabled {
 		healthStatus = disabled
@@ -257,12 +1186,39 @@ func (c *Config) String() string {
 		metricsStatus = disabled
 	}
 
+	auditStatus := disabled
+	if c.Audit.Enabled != nil && *c.Audit.Enabled {
+		auditStatus = enabled
+	}
+
+	discoveryStatus := disabled
+	if c.Discovery.Enabled != nil && *c.Discovery.Enabled {
+		discoveryStatus = enabled
+	}
+
+	grpcStatus := disabled
+	if c.GRPC.Enabled != nil && *c.GRPC.Enabled {
+		grpcStatus = fmt.Sprintf("port=%d", c.GRPC.Port)
+	}
+
+	mqttStatus := disabled
+	if c.MQTT.Enabled != nil && *c.MQTT.Enabled {
+		mqttStatus = "broker=" + RedactURL(c.MQTT.Broker)
+	}
+
+	envOverrides := "none"
+	if paths := c.EnvOverrides(); len(paths) > 0 {
+		envOverrides = strings.Join(paths, ", ")
+	}
+
 	return fmt.Sprintf(
 		"Server [port=%d, log_format=%s, log_level=%s] "+
 			"Auth [%s] Observability [health=%s, metrics=%s] "+
-			"Machines [count=%d]",
+			"Audit [%s] Machines [count=%d] Management [%s] Storage [driver=%s] Sources [count=%d] "+
+			"Discovery [%s] GRPC [%s] MQTT [%s] Webhooks [count=%d] Env [%s]",
 		c.Server.Port, c.Server.Log.Format, c.Server.Log.Level,
 		authStatus, healthStatus, metricsStatus,
-		len(c.Machines),
+		auditStatus, len(c.Machines), adminStatus, storageDriver, len(c.Sources),
+		discoveryStatus, grpcStatus, mqttStatus, len(c.Webhooks.Subscriptions), envOverrides,
 	)
 }
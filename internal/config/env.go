@@ -0,0 +1,242 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// envPrefix prefixes every environment variable that overrides a configuration value.
+const envPrefix = "GWAIHIR"
+
+// envAliases maps the short variable names supported before the generic mapping
+// to their canonical names. The canonical name wins when both are set.
+var envAliases = map[string]string{
+	"GWAIHIR_PORT":          "GWAIHIR_SERVER_PORT",
+	"GWAIHIR_LOG_FORMAT":    "GWAIHIR_SERVER_LOG_FORMAT",
+	"GWAIHIR_LOG_LEVEL":     "GWAIHIR_SERVER_LOG_LEVEL",
+	"GWAIHIR_API_KEY":       "GWAIHIR_AUTHENTICATION_API_KEY",
+	"GWAIHIR_ADMIN_API_KEY": "GWAIHIR_AUTHENTICATION_ADMIN_API_KEY",
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// applyEnvOverrides applies environment variable overrides to the configuration.
+// Environment variables take precedence over file values.
+// Returns an error if any environment variable override has an invalid value.
+//
+// Every field is mapped to GWAIHIR_<SECTION>_<FIELD>, derived from the yaml tags of
+// the Config struct, e.g. GWAIHIR_OBSERVABILITY_METRICS_ENABLED. Lists of structs
+// are set as a JSON array (GWAIHIR_MACHINES='[{"id": ...}]') or per element and
+// field (GWAIHIR_MACHINES_0_MAC); indexed elements override or append to the list.
+// Lists of strings are comma-separated. Empty variables are ignored.
+func applyEnvOverrides(cfg *Config) error {
+	overrider := &envOverrider{
+		env:     make(map[string]string),
+		names:   make(map[string]string),
+		applied: make(map[string]string),
+	}
+	for _, entry := range os.Environ() {
+		name, value, _ := strings.Cut(entry, "=")
+		if strings.HasPrefix(name, envPrefix+"_") && value != "" {
+			overrider.env[name] = value
+		}
+	}
+	for alias, canonical := range envAliases {
+		if value, exists := overrider.env[alias]; exists {
+			if _, set := overrider.env[canonical]; !set {
+				overrider.env[canonical] = value
+				overrider.names[canonical] = alias
+			}
+		}
+	}
+
+	if err := overrider.applyStruct(reflect.ValueOf(cfg).Elem(), envPrefix, ""); err != nil {
+		return err
+	}
+
+	cfg.envOverrides = overrider.applied
+	return nil
+}
+
+// envOverrider sets configuration values from environment variables and records
+// which configuration paths were set from which variable. names maps canonical
+// variable names to the alias they were read from.
+type envOverrider struct {
+	env     map[string]string
+	names   map[string]string
+	applied map[string]string
+}
+
+// source returns the name of the variable that provided the canonical variable name.
+func (o *envOverrider) source(name string) string {
+	if alias, exists := o.names[name]; exists {
+		return alias
+	}
+	return name
+}
+
+func (o *envOverrider) applyStruct(v reflect.Value, name, path string) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		if !field.IsExported() || tag == "" || tag == "-" {
+			continue
+		}
+
+		fieldPath := tag
+		if path != "" {
+			fieldPath = path + "." + tag
+		}
+		if err := o.applyValue(v.Field(i), name+"_"+strings.ToUpper(tag), fieldPath); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (o *envOverrider) applyValue(v reflect.Value, name, path string) error {
+	switch {
+	case v.Kind() == reflect.Struct:
+		return o.applyStruct(v, name, path)
+	case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Struct:
+		return o.applyStructSlice(v, name, path)
+	}
+
+	raw, set := o.env[name]
+	if !set {
+		return nil
+	}
+
+	if err := setEnvValue(v, o.source(name), raw); err != nil {
+		return err
+	}
+	o.applied[path] = o.source(name)
+	return nil
+}
+
+// applyStructSlice applies a JSON array from name, then the indexed name_<i>_ variables.
+func (o *envOverrider) applyStructSlice(v reflect.Value, name, path string) error {
+	if raw, set := o.env[name]; set {
+		target := reflect.New(v.Type())
+		if err := yaml.Unmarshal([]byte(raw), target.Interface()); err != nil {
+			return fmt.Errorf("%s must be a JSON array: %w", name, err)
+		}
+		v.Set(target.Elem())
+		o.applied[path] = name
+	}
+
+	for _, index := range o.indices(name + "_") {
+		if index > v.Len() {
+			return fmt.Errorf("%s_%d: index %d skips index %d", name, index, index, v.Len())
+		}
+		if index == v.Len() {
+			v.Set(reflect.Append(v, reflect.Zero(v.Type().Elem())))
+		}
+		if err := o.applyStruct(v.Index(index), fmt.Sprintf("%s_%d", name, index), fmt.Sprintf("%s[%d]", path, index)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// indices returns the sorted list indices of the variables named prefix<i>_<FIELD>.
+func (o *envOverrider) indices(prefix string) []int {
+	seen := make(map[int]bool)
+	for name := range o.env {
+		rest, found := strings.CutPrefix(name, prefix)
+		if !found {
+			continue
+		}
+		digits, _, found := strings.Cut(rest, "_")
+		if !found {
+			continue
+		}
+		if index, err := strconv.Atoi(digits); err == nil && index >= 0 && strconv.Itoa(index) == digits {
+			seen[index] = true
+		}
+	}
+
+	indices := make([]int, 0, len(seen))
+	for index := range seen {
+		indices = append(indices, index)
+	}
+	sort.Ints(indices)
+	return indices
+}
+
+// setEnvValue parses raw according to the type of v.
+func setEnvValue(v reflect.Value, name, raw string) error {
+	if v.Kind() == reflect.Pointer {
+		elem := reflect.New(v.Type().Elem())
+		if err := setEnvValue(elem.Elem(), name, raw); err != nil {
+			return err
+		}
+		v.Set(elem)
+		return nil
+	}
+
+	switch {
+	case v.Type() == durationType:
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return fmt.Errorf("%s must be a duration such as 30s or 5m, got '%s'", name, raw)
+		}
+		v.SetInt(int64(d))
+	case v.Kind() == reflect.String:
+		v.SetString(raw)
+	case v.Kind() == reflect.Int:
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return fmt.Errorf("%s must be an integer, got '%s'", name, raw)
+		}
+		v.SetInt(int64(n))
+	case v.Kind() == reflect.Float64:
+		f, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return fmt.Errorf("%s must be a number, got '%s'", name, raw)
+		}
+		v.SetFloat(f)
+	case v.Kind() == reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("%s must be true or false, got '%s'", name, raw)
+		}
+		v.SetBool(b)
+	case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.String:
+		values := reflect.MakeSlice(v.Type(), 0, 0)
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				values = reflect.Append(values, reflect.ValueOf(item))
+			}
+		}
+		v.Set(values)
+	default:
+		return fmt.Errorf("%s: unsupported configuration type %s", name, v.Type())
+	}
+	return nil
+}
+
+// EnvOverrides returns the configuration paths that were set from environment
+// variables, in sorted order.
+func (c *Config) EnvOverrides() []string {
+	paths := make([]string, 0, len(c.envOverrides))
+	for path := range c.envOverrides {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// fromEnv reports whether the configuration path was set from an environment variable.
+func (c *Config) fromEnv(path string) bool {
+	_, set := c.envOverrides[path]
+	return set
+}
@@ -0,0 +1,185 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadConfig_GenericEnvOverrides(t *testing.T) {
+	filename := createTempConfigFile(t, basicConfigContent)
+
+	t.Setenv("GWAIHIR_SERVER_PORT", "9191")
+	t.Setenv("GWAIHIR_OBSERVABILITY_METRICS_ENABLED", "false")
+	t.Setenv("GWAIHIR_AUTHENTICATION_LOCKOUT_BASE_DURATION", "1m")
+	t.Setenv("GWAIHIR_INCLUDE", "a.yaml, b/*.yaml")
+
+	cfg, err := LoadConfig(filename)
+	require.NoError(t, err)
+	assert.Equal(t, 9191, cfg.Server.Port)
+	assert.Equal(t, boolPtr(false), cfg.Observability.Metrics.Enabled)
+	assert.Equal(t, time.Minute, cfg.Authentication.Lockout.BaseDuration)
+	assert.Equal(t, []string{"a.yaml", "b/*.yaml"}, cfg.Include)
+	assert.Equal(t, []string{
+		"authentication.lockout.base_duration",
+		"include",
+		"observability.metrics.enabled",
+		"server.port",
+	}, cfg.EnvOverrides())
+	assert.Contains(t, cfg.String(), "Env [authentication.lockout.base_duration, include, observability.metrics.enabled, server.port]")
+}
+
+func TestLoadConfig_EnvOverridesNone(t *testing.T) {
+	filename := createTempConfigFile(t, basicConfigContent)
+
+	cfg, err := LoadConfig(filename)
+	require.NoError(t, err)
+	assert.Empty(t, cfg.EnvOverrides())
+	assert.Contains(t, cfg.String(), "Env [none]")
+}
+
+func TestLoadConfig_CanonicalEnvWinsOverAlias(t *testing.T) {
+	filename := createTempConfigFile(t, basicConfigContent)
+
+	t.Setenv("GWAIHIR_PORT", "9090")
+	t.Setenv("GWAIHIR_SERVER_PORT", "9191")
+
+	cfg, err := LoadConfig(filename)
+	require.NoError(t, err)
+	assert.Equal(t, 9191, cfg.Server.Port)
+}
+
+func TestLoadConfig_IndexedMachineEnvOverrides(t *testing.T) {
+	filename := createTempConfigFile(t, basicConfigContent)
+
+	t.Setenv("GWAIHIR_MACHINES_0_BROADCAST", "10.0.0.255")
+	t.Setenv("GWAIHIR_MACHINES_1_ID", "m2")
+	t.Setenv("GWAIHIR_MACHINES_1_NAME", "Machine 2")
+	t.Setenv("GWAIHIR_MACHINES_1_MAC", "11:22:33:44:55:66")
+	t.Setenv("GWAIHIR_MACHINES_1_BROADCAST", "10.0.0.255")
+
+	cfg, err := LoadConfig(filename)
+	require.NoError(t, err)
+	require.Len(t, cfg.Machines, 2)
+	assert.Equal(t, "m1", cfg.Machines[0].ID)
+	assert.Equal(t, "10.0.0.255", cfg.Machines[0].Broadcast)
+	assert.Equal(t, MachineConfig{ID: "m2", Name: "Machine 2", MAC: "11:22:33:44:55:66", Broadcast: "10.0.0.255"}, cfg.Machines[1])
+	assert.Contains(t, cfg.EnvOverrides(), "machines[1].mac")
+}
+
+func TestLoadConfig_MachinesFromJSONEnv(t *testing.T) {
+	filename := createTempConfigFile(t, "server:\n  port: 8080\n")
+
+	t.Setenv("GWAIHIR_MACHINES", `[{"id": "m1", "name": "Machine 1", "mac": "00:11:22:33:44:55", "broadcast": "192.168.1.255"}]`)
+
+	cfg, err := LoadConfig(filename)
+	require.NoError(t, err)
+	require.Len(t, cfg.Machines, 1)
+	assert.Equal(t, "00:11:22:33:44:55", cfg.Machines[0].MAC)
+	assert.Equal(t, []string{"machines"}, cfg.EnvOverrides())
+}
+
+func TestLoadConfig_EnvOverrideErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		variable string
+		value    string
+		expected string
+	}{
+		{"integer", "GWAIHIR_SERVER_PORT", "abc", "GWAIHIR_SERVER_PORT must be an integer, got 'abc'"},
+		{"boolean", "GWAIHIR_AUDIT_ENABLED", "maybe", "GWAIHIR_AUDIT_ENABLED must be true or false, got 'maybe'"},
+		{"duration", "GWAIHIR_RELOAD_INTERVAL", "5", "GWAIHIR_RELOAD_INTERVAL must be a duration such as 30s or 5m, got '5'"},
+		{"json", "GWAIHIR_MACHINES", "{not json", "GWAIHIR_MACHINES must be a JSON array"},
+		{"index gap", "GWAIHIR_MACHINES_3_ID", "m4", "GWAIHIR_MACHINES_3: index 3 skips index 1"},
+		{"alias", "GWAIHIR_PORT", "abc", "GWAIHIR_PORT must be an integer"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filename := createTempConfigFile(t, basicConfigContent)
+			t.Setenv(tt.variable, tt.value)
+
+			_, err := LoadConfig(filename)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.expected)
+		})
+	}
+}
+
+func TestLoadConfig_EnvAPIKeyOverridesSecretFile(t *testing.T) {
+	filename := createTempConfigFile(t, `
+authentication:
+  api_key_file: /nonexistent/api-key
+machines:
+  - id: m1
+    name: "M"
+    mac: "AA:BB:CC:DD:EE:FF"
+    broadcast: "192.168.1.255"
+`)
+	t.Setenv("GWAIHIR_API_KEY", "env-key")
+
+	cfg, err := LoadConfig(filename)
+	require.NoError(t, err)
+	assert.Equal(t, "env-key", cfg.Authentication.APIKey)
+}
+
+const includedTeamFile = `
+machines:
+  - id: alpha
+    name: "Alpha"
+    mac: "00:11:22:33:44:77"
+    broadcast: "10.0.0.255"
+`
+
+func TestLoadConfig_IncludeFromEnv(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "extra.d"), 0o750))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "extra.d", "team.yaml"), []byte(includedTeamFile), 0o600))
+	configPath := filepath.Join(dir, "gwaihir.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte(basicConfigContent), 0o600))
+
+	t.Setenv("GWAIHIR_INCLUDE", "extra.d/*.yaml")
+
+	cfg, err := LoadConfig(configPath)
+	require.NoError(t, err)
+	require.Len(t, cfg.Machines, 2)
+	assert.Equal(t, "m1", cfg.Machines[0].ID)
+	assert.Equal(t, "alpha", cfg.Machines[1].ID)
+}
+
+func TestLoadConfig_MachinesFromJSONEnvKeepsIncludedMachines(t *testing.T) {
+	configPath := writeIncludeTree(t, map[string]string{"team.yaml": includedTeamFile})
+
+	t.Setenv("GWAIHIR_MACHINES", `[{"id": "m1", "name": "Machine 1", "mac": "00:11:22:33:44:55", "broadcast": "192.168.1.255"}]`)
+
+	cfg, err := LoadConfig(configPath)
+	require.NoError(t, err)
+	require.Len(t, cfg.Machines, 2)
+	assert.Equal(t, "m1", cfg.Machines[0].ID)
+	assert.Equal(t, "alpha", cfg.Machines[1].ID)
+}
+
+func TestLoadConfig_IndexedMachineEnvOverridesIgnoreIncludes(t *testing.T) {
+	configPath := writeIncludeTree(t, map[string]string{"team.yaml": includedTeamFile})
+
+	// Index 1 appends to the machines of the file instead of addressing alpha.
+	t.Setenv("GWAIHIR_MACHINES_0_BROADCAST", "10.1.1.255")
+	t.Setenv("GWAIHIR_MACHINES_1_ID", "m2")
+	t.Setenv("GWAIHIR_MACHINES_1_NAME", "Machine 2")
+	t.Setenv("GWAIHIR_MACHINES_1_MAC", "11:22:33:44:55:66")
+	t.Setenv("GWAIHIR_MACHINES_1_BROADCAST", "10.0.0.255")
+
+	cfg, err := LoadConfig(configPath)
+	require.NoError(t, err)
+	require.Len(t, cfg.Machines, 3)
+	assert.Equal(t, "main", cfg.Machines[0].ID)
+	assert.Equal(t, "10.1.1.255", cfg.Machines[0].Broadcast)
+	assert.Equal(t, "m2", cfg.Machines[1].ID)
+	assert.Equal(t, "alpha", cfg.Machines[2].ID)
+	assert.Equal(t, "10.0.0.255", cfg.Machines[2].Broadcast)
+	assert.Equal(t, "00:11:22:33:44:77", cfg.Machines[2].MAC)
+}
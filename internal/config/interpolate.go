@@ -0,0 +1,165 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	envNameRegexp   = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	yamlErrorLine   = regexp.MustCompile(`^line (\d+): `)
+	yamlErrorQuoted = regexp.MustCompile("`[^`]*`")
+)
+
+// expandEnv replaces ${NAME} and ${NAME:-default} references in the scalar values
+// of the document rooted at node. A reference to an unset variable without a default
+// is an error; the default is also used when the variable is set but empty. "$${"
+// produces a literal "${". Mapping keys are left untouched.
+//
+// The returned lines are those where a substitution happened, so that decoding errors
+// can be redacted with redactLines. Errors name the variable but never its value.
+func expandEnv(node *yaml.Node) (map[int]bool, error) {
+	lines := make(map[int]bool)
+	err := walkScalars(node, func(scalar *yaml.Node) error {
+		expanded, substituted, err := expandString(scalar.Value)
+		if err != nil {
+			return fmt.Errorf("line %d: %w", scalar.Line, err)
+		}
+		if !substituted {
+			return nil
+		}
+
+		lines[scalar.Line] = true
+		scalar.Value = expanded
+		if scalar.Style&(yaml.SingleQuotedStyle|yaml.DoubleQuotedStyle|yaml.LiteralStyle|yaml.FoldedStyle) == 0 {
+			// Let plain scalars resolve to numbers, booleans, etc. after expansion.
+			scalar.Tag = ""
+		}
+		return nil
+	})
+	return lines, err
+}
+
+// walkScalars calls fn for every scalar value node. In mappings, only values are visited.
+func walkScalars(node *yaml.Node, fn func(*yaml.Node) error) error {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		return fn(node)
+	case yaml.MappingNode:
+		for i := 1; i < len(node.Content); i += 2 {
+			if err := walkScalars(node.Content[i], fn); err != nil {
+				return err
+			}
+		}
+	case yaml.DocumentNode, yaml.SequenceNode:
+		for _, child := range node.Content {
+			if err := walkScalars(child, fn); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// expandString expands the variable references in s. substituted reports whether s
+// contained any reference or escape.
+func expandString(s string) (string, bool, error) {
+	if !strings.Contains(s, "${") {
+		return s, false, nil
+	}
+
+	var b strings.Builder
+	for {
+		start := strings.Index(s, "${")
+		if start < 0 {
+			b.WriteString(s)
+			return b.String(), true, nil
+		}
+
+		if start > 0 && s[start-1] == '$' {
+			b.WriteString(s[:start-1])
+			b.WriteString("${")
+			s = s[start+2:]
+			continue
+		}
+
+		end := strings.Index(s[start:], "}")
+		if end < 0 {
+			return "", false, errors.New("unterminated variable reference")
+		}
+		end += start
+
+		value, err := lookupEnv(s[start+2 : end])
+		if err != nil {
+			return "", false, err
+		}
+		b.WriteString(s[:start])
+		b.WriteString(value)
+		s = s[end+1:]
+	}
+}
+
+// lookupEnv resolves a "NAME" or "NAME:-default" reference.
+func lookupEnv(reference string) (string, error) {
+	name, fallback, hasDefault := strings.Cut(reference, ":-")
+	if !envNameRegexp.MatchString(name) {
+		return "", fmt.Errorf("invalid variable reference '${%s}'", name)
+	}
+
+	value, set := os.LookupEnv(name)
+	if hasDefault && value == "" {
+		return fallback, nil
+	}
+	if !set {
+		return "", fmt.Errorf("environment variable %s is not set", name)
+	}
+	return value, nil
+}
+
+// redactLines hides the values quoted by YAML type errors on lines where environment
+// variables were substituted, so that decoding errors never reveal their values.
+func redactLines(err error, lines map[int]bool) error {
+	var typeErr *yaml.TypeError
+	if len(lines) == 0 || !errors.As(err, &typeErr) {
+		return err
+	}
+
+	redacted := &yaml.TypeError{Errors: make([]string, len(typeErr.Errors))}
+	for i, message := range typeErr.Errors {
+		if match := yamlErrorLine.FindStringSubmatch(message); match != nil {
+			if line, convErr := strconv.Atoi(match[1]); convErr == nil && lines[line] {
+				message = yamlErrorQuoted.ReplaceAllString(message, "`[REDACTED]`")
+			}
+		}
+		redacted.Errors[i] = message
+	}
+	return redacted
+}
+
+// decodeExpanded parses data, expands environment variable references and decodes
+// the result into out.
+func decodeExpanded(data []byte, out any) error {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return err
+	}
+	if root.Kind == 0 {
+		return nil
+	}
+
+	lines, err := expandEnv(&root)
+	if err != nil {
+		return err
+	}
+
+	if err := root.Decode(out); err != nil {
+		return redactLines(err, lines)
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpandString(t *testing.T) {
+	t.Setenv("GWAIHIR_TEST_HOST", "gandalf")
+	t.Setenv("GWAIHIR_TEST_EMPTY", "")
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"plain", "plain"},
+		{"${GWAIHIR_TEST_HOST}", "gandalf"},
+		{"host-${GWAIHIR_TEST_HOST}-01", "host-gandalf-01"},
+		{"${GWAIHIR_TEST_UNSET:-fallback}", "fallback"},
+		{"${GWAIHIR_TEST_EMPTY:-fallback}", "fallback"},
+		{"${GWAIHIR_TEST_EMPTY}", ""},
+		{"${GWAIHIR_TEST_HOST:-fallback}", "gandalf"},
+		{"$${GWAIHIR_TEST_HOST}", "${GWAIHIR_TEST_HOST}"},
+		{"${GWAIHIR_TEST_UNSET:-}", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result, _, err := expandString(tt.input)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestExpandString_Errors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"${GWAIHIR_TEST_UNSET}", "environment variable GWAIHIR_TEST_UNSET is not set"},
+		{"${GWAIHIR_TEST_UNSET", "unterminated variable reference"},
+		{"${1INVALID}", "invalid variable reference '${1INVALID}'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			_, _, err := expandString(tt.input)
+			require.Error(t, err)
+			assert.Equal(t, tt.expected, err.Error())
+		})
+	}
+}
+
+func TestLoadConfig_EnvInterpolation(t *testing.T) {
+	t.Setenv("GWAIHIR_TEST_PORT", "9191")
+	t.Setenv("GWAIHIR_TEST_KEY", "interpolated-key")
+	t.Setenv("GWAIHIR_TEST_MAC", "AA:BB:CC:DD:EE:FF")
+
+	filename := createTempConfigFile(t, `
+server:
+  port: ${GWAIHIR_TEST_PORT}
+  log:
+    level: ${GWAIHIR_TEST_LEVEL:-warn}
+authentication:
+  api_key: "${GWAIHIR_TEST_KEY}"
+machines:
+  - id: m1
+    name: "M"
+    mac: ${GWAIHIR_TEST_MAC}
+    broadcast: "192.168.1.255"
+`)
+
+	cfg, err := LoadConfig(filename)
+	require.NoError(t, err)
+	assert.Equal(t, 9191, cfg.Server.Port)
+	assert.Equal(t, "warn", cfg.Server.Log.Level)
+	assert.Equal(t, "interpolated-key", cfg.Authentication.APIKey)
+	assert.Equal(t, "AA:BB:CC:DD:EE:FF", cfg.Machines[0].MAC)
+	assert.Equal(t, 9, cfg.Machines[0].Line)
+}
+
+func TestLoadConfig_EnvInterpolationMissingVariable(t *testing.T) {
+	filename := createTempConfigFile(t, basicConfigContent+`
+observability:
+  metrics:
+    enabled: ${GWAIHIR_TEST_UNSET}
+`)
+
+	_, err := LoadConfig(filename)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "environment variable GWAIHIR_TEST_UNSET is not set")
+}
+
+func TestLoadConfig_EnvInterpolationDoesNotLeakValues(t *testing.T) {
+	t.Setenv("GWAIHIR_TEST_SECRET", "s3cr3t-value")
+
+	filename := createTempConfigFile(t, `
+server:
+  port: ${GWAIHIR_TEST_SECRET}
+machines:
+  - id: m1
+    name: "M"
+    mac: "AA:BB:CC:DD:EE:FF"
+    broadcast: "192.168.1.255"
+`)
+
+	_, err := LoadConfig(filename)
+	require.Error(t, err)
+	assert.NotContains(t, err.Error(), "s3cr3t-value")
+	assert.Contains(t, err.Error(), "[REDACTED]")
+}
+
+func TestLoadConfig_EnvInterpolationInIncludeFile(t *testing.T) {
+	t.Setenv("GWAIHIR_TEST_BROADCAST", "10.0.0.255")
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "extra.yaml"), []byte(`
+machines:
+  - id: m2
+    name: "M2"
+    mac: "11:22:33:44:55:66"
+    broadcast: ${GWAIHIR_TEST_BROADCAST}
+`), 0o600))
+	configPath := filepath.Join(dir, "gwaihir.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte(basicConfigContent+"include:\n  - extra.yaml\n"), 0o600))
+
+	cfg, err := LoadConfig(configPath)
+	require.NoError(t, err)
+	require.Len(t, cfg.Machines, 2)
+	assert.Equal(t, "10.0.0.255", cfg.Machines[1].Broadcast)
+}
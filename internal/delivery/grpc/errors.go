@@ -0,0 +1,89 @@
+// Package grpc provides the gRPC delivery layer.
+package grpc
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"github.com/josimar-silva/gwaihir/internal/domain"
+	"github.com/josimar-silva/gwaihir/internal/infrastructure"
+)
+
+// ErrorDomain is the domain of the google.rpc.ErrorInfo details of errors.
+const ErrorDomain = "gwaihir"
+
+// Codes of errors raised by the gRPC layer, matching those of the HTTP API.
+const (
+	codeInvalidRequest domain.ErrorCode = "invalid_request"
+	codeUnauthorized   domain.ErrorCode = "unauthorized"
+	codeRateLimited    domain.ErrorCode = "rate_limited"
+	codeInternal       domain.ErrorCode = "internal_error"
+)
+
+var statusCodes = map[domain.ErrorCode]codes.Code{
+	domain.CodeMachineNotFound:      codes.NotFound,
+	domain.CodeMachineNotAllowed:    codes.NotFound,
+	domain.CodeMachineAlreadyExists: codes.AlreadyExists,
+	domain.CodeMachineReadOnly:      codes.FailedPrecondition,
+	domain.CodeInvalidMachine:       codes.InvalidArgument,
+	domain.CodeNeighborNotFound:     codes.NotFound,
+	domain.CodeWakeFailed:           codes.Internal,
+	domain.CodeInvalidConfiguration: codes.Internal,
+}
+
+// newStatusError builds an error with a google.rpc.ErrorInfo detail whose reason is code.
+func newStatusError(grpcCode codes.Code, code domain.ErrorCode, message string) error {
+	st := status.New(grpcCode, message)
+	if detailed, err := st.WithDetails(&errdetails.ErrorInfo{Reason: string(code), Domain: ErrorDomain}); err == nil {
+		st = detailed
+	}
+	return st.Err()
+}
+
+// lockedOutError reports that the client is locked out, with a google.rpc.RetryInfo
+// detail telling when it may retry.
+func lockedOutError(remaining time.Duration) error {
+	st := status.New(codes.ResourceExhausted, "too many failed authentication attempts")
+	details := []protoadapt.MessageV1{
+		&errdetails.ErrorInfo{Reason: string(codeRateLimited), Domain: ErrorDomain},
+		&errdetails.RetryInfo{RetryDelay: durationpb.New(remaining.Round(time.Second))},
+	}
+	if detailed, err := st.WithDetails(details...); err == nil {
+		st = detailed
+	}
+	return st.Err()
+}
+
+func invalidArgument(message string) error {
+	return newStatusError(codes.InvalidArgument, codeInvalidRequest, message)
+}
+
+// statusError maps a use case error to a gRPC status. Client errors are reported with
+// notFound as message; server errors are logged and reported without their cause.
+func (s *Server) statusError(ctx context.Context, err error, notFound string) error {
+	code := domain.CodeOf(err)
+	grpcCode, ok := statusCodes[code]
+	if !ok {
+		code, grpcCode = codeInternal, codes.Internal
+	}
+
+	if grpcCode != codes.Internal {
+		return newStatusError(grpcCode, code, notFound)
+	}
+
+	s.logger.WithTrace(ctx).Error("gRPC call failed",
+		infrastructure.String("request_id", requestIDFromContext(ctx)),
+		infrastructure.Any("error", err),
+	)
+	message := "internal error"
+	if code == domain.CodeWakeFailed {
+		message = "the magic packet could not be sent"
+	}
+	return newStatusError(grpcCode, code, message)
+}
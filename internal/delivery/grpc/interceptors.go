@@ -0,0 +1,234 @@
+// Package grpc provides the gRPC delivery layer.
+package grpc
+
+import (
+	"context"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/josimar-silva/gwaihir/internal/domain"
+	"github.com/josimar-silva/gwaihir/internal/infrastructure"
+)
+
+// Metadata keys.
+const (
+	// APIKeyMetadata carries the API key of a call.
+	APIKeyMetadata = "x-api-key"
+	// RequestIDMetadata is the response header carrying the request ID of a call.
+	RequestIDMetadata = "x-request-id"
+)
+
+// Authentication failure reasons used as metric labels, matching the HTTP API.
+const (
+	authFailureMissingKey = "missing_key"
+	authFailureInvalidKey = "invalid_key"
+	authFailureLockedOut  = "locked_out"
+)
+
+// anonymousCaller identifies calls that were not authenticated.
+const anonymousCaller = "anonymous"
+
+type contextKey string
+
+const (
+	requestIDKey contextKey = "request_id"
+	callerKey    contextKey = "caller"
+)
+
+// unauthenticatedServices are reachable without an API key, as they are consumed by
+// infrastructure and tooling rather than API clients.
+var unauthenticatedServices = []string{
+	"/grpc.health.v1.Health/",
+	"/grpc.reflection.",
+}
+
+func requestIDFromContext(ctx context.Context) string {
+	if id, ok := ctx.Value(requestIDKey).(string); ok {
+		return id
+	}
+	return ""
+}
+
+func callerFromContext(ctx context.Context) string {
+	if caller, ok := ctx.Value(callerKey).(string); ok {
+		return caller
+	}
+	return anonymousCaller
+}
+
+func peerIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+	return host
+}
+
+// wrappedStream replaces the context of a server stream.
+type wrappedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (w *wrappedStream) Context() context.Context {
+	return w.ctx
+}
+
+// withRequestID generates a request ID for the call, stores it in the context and
+// returns it in the response headers for correlation with the logs.
+func withRequestID(ctx context.Context) context.Context {
+	requestID := uuid.New().String()
+	_ = grpc.SetHeader(ctx, metadata.Pairs(RequestIDMetadata, requestID))
+	return context.WithValue(ctx, requestIDKey, requestID)
+}
+
+func (s *Server) requestIDUnary(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	return handler(withRequestID(ctx), req)
+}
+
+func (s *Server) requestIDStream(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	return handler(srv, &wrappedStream{ServerStream: ss, ctx: withRequestID(ss.Context())})
+}
+
+// observe logs a finished call and records its metrics.
+func (s *Server) observe(ctx context.Context, method string, startTime time.Time, err error) {
+	duration := time.Since(startTime)
+	code := status.Code(err)
+
+	s.metrics.GRPCRequests.WithLabelValues(method, code.String()).Inc()
+	s.metrics.GRPCDuration.WithLabelValues(method).Observe(duration.Seconds())
+
+	s.logger.WithTrace(ctx).Info("gRPC call",
+		infrastructure.String("request_id", requestIDFromContext(ctx)),
+		infrastructure.String("method", method),
+		infrastructure.String("code", code.String()),
+		infrastructure.Duration("duration", duration),
+		infrastructure.String("client_ip", peerIP(ctx)),
+	)
+}
+
+func (s *Server) observeUnary(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	startTime := time.Now()
+	resp, err := handler(ctx, req)
+	s.observe(ctx, info.FullMethod, startTime, err)
+	return resp, err
+}
+
+func (s *Server) observeStream(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	startTime := time.Now()
+	err := handler(srv, ss)
+	s.observe(ss.Context(), info.FullMethod, startTime, err)
+	return err
+}
+
+// authenticate validates the API key in the call metadata and returns the context
+// with the caller identity. Peers locked out after repeated failures are rejected
+// before their key is checked.
+func (s *Server) authenticate(ctx context.Context, method string) (context.Context, error) {
+	if s.keys == nil {
+		return ctx, nil
+	}
+	for _, prefix := range unauthenticatedServices {
+		if strings.HasPrefix(method, prefix) {
+			return ctx, nil
+		}
+	}
+
+	clientIP := peerIP(ctx)
+	if s.lockout != nil {
+		if locked, remaining := s.lockout.IsLocked(clientIP); locked {
+			s.reportAuthFailure(ctx, authFailureLockedOut)
+			return nil, lockedOutError(remaining)
+		}
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	apiKeys := md.Get(APIKeyMetadata)
+	if len(apiKeys) == 0 || apiKeys[0] == "" {
+		return nil, s.rejectUnauthenticated(ctx, authFailureMissingKey, "missing "+APIKeyMetadata+" metadata")
+	}
+
+	caller, ok := s.keys.Authenticate(apiKeys[0])
+	if !ok {
+		return nil, s.rejectUnauthenticated(ctx, authFailureInvalidKey, "invalid API key")
+	}
+
+	if s.lockout != nil {
+		s.lockout.RecordSuccess(clientIP)
+	}
+	return context.WithValue(ctx, callerKey, caller), nil
+}
+
+// rejectUnauthenticated records the failed attempt, locks out the peer if the
+// threshold is reached and returns the codes.Unauthenticated error.
+func (s *Server) rejectUnauthenticated(ctx context.Context, reason, message string) error {
+	s.reportAuthFailure(ctx, reason)
+
+	if s.lockout != nil {
+		if locked, duration := s.lockout.RecordFailure(peerIP(ctx)); locked {
+			s.logger.WithTrace(ctx).Warn("Client locked out after repeated authentication failures",
+				infrastructure.String("request_id", requestIDFromContext(ctx)),
+				infrastructure.String("client_ip", peerIP(ctx)),
+				infrastructure.Duration("lockout", duration),
+			)
+		}
+	}
+
+	return newStatusError(codes.Unauthenticated, codeUnauthorized, message)
+}
+
+func (s *Server) authUnary(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	ctx, err := s.authenticate(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func (s *Server) authStream(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := s.authenticate(ss.Context(), info.FullMethod)
+	if err != nil {
+		return err
+	}
+	return handler(srv, &wrappedStream{ServerStream: ss, ctx: ctx})
+}
+
+// reportAuthFailure logs, counts and audits a failed authentication attempt.
+func (s *Server) reportAuthFailure(ctx context.Context, reason string) {
+	s.metrics.AuthFailures.WithLabelValues(reason).Inc()
+	s.logger.WithTrace(ctx).Warn("Authentication failed",
+		infrastructure.String("request_id", requestIDFromContext(ctx)),
+		infrastructure.String("client_ip", peerIP(ctx)),
+		infrastructure.String("reason", reason),
+	)
+
+	if s.audit == nil {
+		return
+	}
+	err := s.audit.Record(domain.AuditEvent{
+		Action:    domain.AuditActionAuthFailure,
+		Caller:    anonymousCaller,
+		SourceIP:  peerIP(ctx),
+		RequestID: requestIDFromContext(ctx),
+		Outcome:   domain.AuditOutcomeDenied,
+		Detail:    reason,
+	})
+	if err != nil {
+		s.logger.WithTrace(ctx).Error("Failed to record audit event",
+			infrastructure.String("request_id", requestIDFromContext(ctx)),
+			infrastructure.Any("error", err),
+		)
+	}
+}
@@ -0,0 +1,301 @@
+// Package grpc provides the gRPC delivery layer.
+package grpc
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/josimar-silva/gwaihir/internal/domain"
+	"github.com/josimar-silva/gwaihir/internal/infrastructure"
+	"github.com/josimar-silva/gwaihir/internal/usecase"
+	gwaihirv1 "github.com/josimar-silva/gwaihir/pkg/api/gwaihir/v1"
+)
+
+// EventSource provides the machine events streamed by WatchMachineState.
+type EventSource interface {
+	// Subscribe returns a channel receiving the events published from now on and a
+	// function that ends the subscription.
+	Subscribe() (<-chan domain.Event, func())
+}
+
+// KeyAuthenticator validates API keys, returning the caller identity of a valid key.
+type KeyAuthenticator interface {
+	Authenticate(apiKey string) (string, bool)
+}
+
+// ClientLockout temporarily blocks clients that repeatedly fail authentication. It is
+// shared with the HTTP API, so failures on either count towards the same lockout.
+type ClientLockout interface {
+	IsLocked(client string) (bool, time.Duration)
+	RecordFailure(client string) (bool, time.Duration)
+	RecordSuccess(client string)
+}
+
+// Server implements the gwaihir.v1.WoLService gRPC service on top of the same use
+// case as the HTTP API.
+type Server struct {
+	gwaihirv1.UnimplementedWoLServiceServer
+
+	wolUseCase *usecase.WoLUseCase
+	logger     *infrastructure.Logger
+	metrics    *infrastructure.Metrics
+	events     EventSource
+	audit      domain.AuditLog
+	keys       KeyAuthenticator
+	lockout    ClientLockout
+	reflection bool
+	tracing    bool
+
+	health   *health.Server
+	stopping chan struct{}
+	stopOnce sync.Once
+}
+
+// NewServer creates a gRPC server for the WoL use case.
+func NewServer(wolUseCase *usecase.WoLUseCase, logger *infrastructure.Logger, metrics *infrastructure.Metrics) *Server {
+	return &Server{
+		wolUseCase: wolUseCase,
+		logger:     logger,
+		metrics:    metrics,
+		health:     health.NewServer(),
+		stopping:   make(chan struct{}),
+	}
+}
+
+// WithEvents enables WatchMachineState, streaming the events of source.
+func (s *Server) WithEvents(source EventSource) *Server {
+	s.events = source
+	return s
+}
+
+// WithAuditLog enables recording of wake attempts and machine reads to the audit trail.
+func (s *Server) WithAuditLog(audit domain.AuditLog) *Server {
+	s.audit = audit
+	return s
+}
+
+// WithAPIKeys requires calls to carry one of keys in the x-api-key metadata. Health
+// checks and reflection stay unauthenticated.
+func (s *Server) WithAPIKeys(keys KeyAuthenticator) *Server {
+	s.keys = keys
+	return s
+}
+
+// WithLockout rejects calls with codes.ResourceExhausted while the calling peer is
+// locked out after repeated authentication failures.
+func (s *Server) WithLockout(lockout ClientLockout) *Server {
+	s.lockout = lockout
+	return s
+}
+
+// WithReflection enables the gRPC server reflection service.
+func (s *Server) WithReflection() *Server {
+	s.reflection = true
+	return s
+}
+
+// WithTracing starts a server span for every call, continuing the trace of a W3C
+// traceparent metadata entry when present. Log records of the call carry its IDs.
+func (s *Server) WithTracing() *Server {
+	s.tracing = true
+	return s
+}
+
+// NewGRPCServer creates a gRPC server serving the WoL service, the standard health
+// checking service and, if enabled, reflection.
+func (s *Server) NewGRPCServer(opts ...grpc.ServerOption) *grpc.Server {
+	unary := []grpc.UnaryServerInterceptor{s.requestIDUnary, s.observeUnary, s.authUnary}
+	stream := []grpc.StreamServerInterceptor{s.requestIDStream, s.observeStream, s.authStream}
+	if s.tracing {
+		unary = append([]grpc.UnaryServerInterceptor{s.tracingUnary}, unary...)
+		stream = append([]grpc.StreamServerInterceptor{s.tracingStream}, stream...)
+	}
+
+	opts = append(opts,
+		grpc.ChainUnaryInterceptor(unary...),
+		grpc.ChainStreamInterceptor(stream...),
+	)
+	server := grpc.NewServer(opts...)
+
+	gwaihirv1.RegisterWoLServiceServer(server, s)
+	healthpb.RegisterHealthServer(server, s.health)
+	s.health.SetServingStatus("", healthpb.HealthCheckResponse_SERVING)
+	s.health.SetServingStatus(gwaihirv1.WoLService_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_SERVING)
+
+	if s.reflection {
+		reflection.Register(server)
+	}
+	return server
+}
+
+// Stop reports the server as not serving, ends the open streams and stops server,
+// waiting up to timeout for in-flight calls to complete.
+func (s *Server) Stop(server *grpc.Server, timeout time.Duration) {
+	s.stopOnce.Do(func() {
+		s.health.Shutdown()
+		close(s.stopping)
+	})
+
+	stopped := make(chan struct{})
+	go func() {
+		server.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-time.After(timeout):
+		server.Stop()
+	}
+}
+
+// Wake sends a Wake-on-LAN packet to an allowlisted machine.
+func (s *Server) Wake(ctx context.Context, req *gwaihirv1.WakeRequest) (*gwaihirv1.WakeResponse, error) {
+	if req.GetMachineId() == "" {
+		s.recordAudit(ctx, domain.AuditActionWake, "", domain.AuditOutcomeInvalid)
+		return nil, invalidArgument("machine_id is required")
+	}
+
+	if err := s.wolUseCase.SendWakePacket(ctx, req.GetMachineId()); err != nil {
+		if errors.Is(err, domain.ErrMachineNotFound) {
+			s.recordAudit(ctx, domain.AuditActionWake, req.GetMachineId(), domain.AuditOutcomeNotFound)
+		} else {
+			s.recordAudit(ctx, domain.AuditActionWake, req.GetMachineId(), domain.AuditOutcomeFailure)
+		}
+		return nil, s.statusError(ctx, err, "machine "+req.GetMachineId()+" is not in the allowlist")
+	}
+
+	s.recordAudit(ctx, domain.AuditActionWake, req.GetMachineId(), domain.AuditOutcomeSuccess)
+	return &gwaihirv1.WakeResponse{MachineId: req.GetMachineId()}, nil
+}
+
+// ListMachines returns the machines in the allowlist.
+func (s *Server) ListMachines(ctx context.Context, _ *gwaihirv1.ListMachinesRequest) (*gwaihirv1.ListMachinesResponse, error) {
+	machines, err := s.wolUseCase.ListMachines()
+	if err != nil {
+		return nil, s.statusError(ctx, err, "")
+	}
+
+	s.metrics.MachinesListed.Inc()
+	s.recordAudit(ctx, domain.AuditActionMachineList, "", domain.AuditOutcomeSuccess)
+
+	resp := &gwaihirv1.ListMachinesResponse{Machines: make([]*gwaihirv1.Machine, 0, len(machines))}
+	for _, machine := range machines {
+		resp.Machines = append(resp.Machines, toMachine(machine))
+	}
+	return resp, nil
+}
+
+// GetMachine returns a machine of the allowlist.
+func (s *Server) GetMachine(ctx context.Context, req *gwaihirv1.GetMachineRequest) (*gwaihirv1.Machine, error) {
+	if req.GetMachineId() == "" {
+		return nil, invalidArgument("machine_id is required")
+	}
+
+	machine, err := s.wolUseCase.GetMachine(req.GetMachineId())
+	if err != nil {
+		if errors.Is(err, domain.ErrMachineNotFound) {
+			s.recordAudit(ctx, domain.AuditActionMachineRead, req.GetMachineId(), domain.AuditOutcomeNotFound)
+		} else {
+			s.recordAudit(ctx, domain.AuditActionMachineRead, req.GetMachineId(), domain.AuditOutcomeFailure)
+		}
+		return nil, s.statusError(ctx, err, "machine "+req.GetMachineId()+" not found")
+	}
+
+	s.metrics.MachinesRetrieved.WithLabelValues(s.metrics.MachineLabel(machine.ID)).Inc()
+	s.recordAudit(ctx, domain.AuditActionMachineRead, req.GetMachineId(), domain.AuditOutcomeSuccess)
+	return toMachine(machine), nil
+}
+
+// WatchMachineState streams the wake events of the requested machines until the
+// client cancels the call or the server stops.
+func (s *Server) WatchMachineState(req *gwaihirv1.WatchMachineStateRequest, stream grpc.ServerStreamingServer[gwaihirv1.MachineStateEvent]) error {
+	if s.events == nil {
+		return status.Error(codes.Unimplemented, "machine events are not enabled")
+	}
+
+	watched := make(map[string]bool, len(req.GetMachineIds()))
+	for _, id := range req.GetMachineIds() {
+		watched[id] = true
+	}
+
+	events, unsubscribe := s.events.Subscribe()
+	defer unsubscribe()
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return status.FromContextError(stream.Context().Err()).Err()
+		case <-s.stopping:
+			return status.Error(codes.Unavailable, "server is shutting down")
+		case event, ok := <-events:
+			if !ok {
+				return nil
+			}
+			// Only wakes change the machine state, and wakes outside the allowlist have
+			// no machine to report.
+			if _, ok := machineStates[event.Type]; !ok || event.MachineID == "" || (len(watched) > 0 && !watched[event.MachineID]) {
+				continue
+			}
+			if err := stream.Send(toMachineStateEvent(event)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func toMachine(machine *domain.Machine) *gwaihirv1.Machine {
+	return &gwaihirv1.Machine{
+		Id:        machine.ID,
+		Name:      machine.Name,
+		Mac:       machine.MAC,
+		Broadcast: machine.Broadcast,
+		Source:    machine.Source,
+	}
+}
+
+var machineStates = map[string]gwaihirv1.MachineState{
+	domain.EventWakeRequested: gwaihirv1.MachineState_MACHINE_STATE_WAKE_REQUESTED,
+	domain.EventWakeSent:      gwaihirv1.MachineState_MACHINE_STATE_WAKE_SENT,
+	domain.EventWakeFailed:    gwaihirv1.MachineState_MACHINE_STATE_WAKE_FAILED,
+}
+
+func toMachineStateEvent(event domain.Event) *gwaihirv1.MachineStateEvent {
+	return &gwaihirv1.MachineStateEvent{
+		MachineId: event.MachineID,
+		State:     machineStates[event.Type],
+		Time:      timestamppb.New(event.Time),
+	}
+}
+
+func (s *Server) recordAudit(ctx context.Context, action, machineID, outcome string) {
+	if s.audit == nil {
+		return
+	}
+
+	err := s.audit.Record(domain.AuditEvent{
+		Action:    action,
+		Caller:    callerFromContext(ctx),
+		SourceIP:  peerIP(ctx),
+		RequestID: requestIDFromContext(ctx),
+		MachineID: machineID,
+		Outcome:   outcome,
+	})
+	if err != nil {
+		s.logger.WithTrace(ctx).Error("Failed to record audit event",
+			infrastructure.String("request_id", requestIDFromContext(ctx)),
+			infrastructure.String("action", action),
+			infrastructure.Any("error", err),
+		)
+	}
+}
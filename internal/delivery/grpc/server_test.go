@@ -0,0 +1,373 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/josimar-silva/gwaihir/internal/domain"
+	"github.com/josimar-silva/gwaihir/internal/infrastructure"
+	"github.com/josimar-silva/gwaihir/internal/usecase"
+	gwaihirv1 "github.com/josimar-silva/gwaihir/pkg/api/gwaihir/v1"
+)
+
+const testAPIKey = "grpc-test-key"
+
+type mockRepository struct {
+	machines map[string]*domain.Machine
+}
+
+func (m *mockRepository) GetByID(id string) (*domain.Machine, error) {
+	machine, ok := m.machines[id]
+	if !ok {
+		return nil, domain.ErrMachineNotFound
+	}
+	return machine, nil
+}
+
+func (m *mockRepository) GetAll() ([]*domain.Machine, error) {
+	machines := make([]*domain.Machine, 0, len(m.machines))
+	for _, machine := range m.machines {
+		machines = append(machines, machine)
+	}
+	return machines, nil
+}
+
+func (m *mockRepository) Exists(id string) bool {
+	_, ok := m.machines[id]
+	return ok
+}
+
+type mockPacketSender struct {
+	mu  sync.Mutex
+	err error
+}
+
+func (m *mockPacketSender) SendMagicPacket(_ context.Context, _, _ string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.err
+}
+
+type mockAuditLog struct {
+	mu     sync.Mutex
+	events []domain.AuditEvent
+}
+
+func (m *mockAuditLog) Record(event domain.AuditEvent) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.events = append(m.events, event)
+	return nil
+}
+
+func (m *mockAuditLog) Query(domain.AuditFilter) ([]domain.AuditRecord, error) {
+	return nil, nil
+}
+
+type keySet map[string]string
+
+func (k keySet) Authenticate(apiKey string) (string, bool) {
+	caller, ok := k[apiKey]
+	return caller, ok
+}
+
+// fakeLockout locks a client out after maxFailures consecutive failures.
+type fakeLockout struct {
+	mu          sync.Mutex
+	maxFailures int
+	failures    map[string]int
+}
+
+func (f *fakeLockout) IsLocked(client string) (bool, time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.failures[client] >= f.maxFailures {
+		return true, 90 * time.Second
+	}
+	return false, 0
+}
+
+func (f *fakeLockout) RecordFailure(client string) (bool, time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.failures[client]++
+	return f.failures[client] >= f.maxFailures, 90 * time.Second
+}
+
+func (f *fakeLockout) RecordSuccess(client string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.failures, client)
+}
+
+type testEnv struct {
+	client  gwaihirv1.WoLServiceClient
+	conn    *grpc.ClientConn
+	sender  *mockPacketSender
+	audit   *mockAuditLog
+	metrics *infrastructure.Metrics
+	events  *infrastructure.EventBus
+}
+
+// newTestEnv serves a gRPC server over an in-process bufconn listener.
+func newTestEnv(t *testing.T) *testEnv {
+	t.Helper()
+	return newTestEnvWith(t, nil)
+}
+
+// newTestEnvWith serves a gRPC server further set up by configure, when not nil.
+func newTestEnvWith(t *testing.T, configure func(*Server)) *testEnv {
+	t.Helper()
+	metrics, err := infrastructure.NewMetrics()
+	require.NoError(t, err)
+	logger := infrastructure.NewLogger("text", "error")
+
+	repo := &mockRepository{machines: map[string]*domain.Machine{
+		"saruman": {ID: "saruman", Name: "Saruman", MAC: "AA:BB:CC:DD:EE:FF", Broadcast: "192.168.1.255"},
+		"gandalf": {ID: "gandalf", Name: "Gandalf", MAC: "11:22:33:44:55:66", Broadcast: "192.168.1.255"},
+	}}
+	sender := &mockPacketSender{}
+	events := infrastructure.NewEventBus(16)
+	audit := &mockAuditLog{}
+	useCase := usecase.NewWoLUseCase(repo, sender, logger, metrics).WithEvents(events)
+
+	server := NewServer(useCase, logger, metrics).
+		WithEvents(events).
+		WithAuditLog(audit).
+		WithAPIKeys(keySet{testAPIKey: "api-key:test"}).
+		WithReflection()
+	if configure != nil {
+		configure(server)
+	}
+	grpcServer := server.NewGRPCServer()
+
+	listener := bufconn.Listen(1 << 20)
+	go func() {
+		_ = grpcServer.Serve(listener)
+	}()
+	t.Cleanup(func() {
+		server.Stop(grpcServer, time.Second)
+	})
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+
+	return &testEnv{
+		client:  gwaihirv1.NewWoLServiceClient(conn),
+		conn:    conn,
+		sender:  sender,
+		audit:   audit,
+		metrics: metrics,
+		events:  events,
+	}
+}
+
+func authContext(t *testing.T) context.Context {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	t.Cleanup(cancel)
+	return metadata.AppendToOutgoingContext(ctx, APIKeyMetadata, testAPIKey)
+}
+
+// errorReason returns the ErrorInfo reason of a status error.
+func errorReason(t *testing.T, err error) string {
+	t.Helper()
+	st, ok := status.FromError(err)
+	require.True(t, ok, "expected a status error, got %v", err)
+	for _, detail := range st.Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok {
+			assert.Equal(t, ErrorDomain, info.GetDomain())
+			return info.GetReason()
+		}
+	}
+	t.Fatalf("status %v has no ErrorInfo detail", st)
+	return ""
+}
+
+func TestServer_Wake(t *testing.T) {
+	env := newTestEnv(t)
+
+	var header metadata.MD
+	resp, err := env.client.Wake(authContext(t), &gwaihirv1.WakeRequest{MachineId: "saruman"}, grpc.Header(&header))
+	require.NoError(t, err)
+	assert.Equal(t, "saruman", resp.GetMachineId())
+	assert.Len(t, header.Get(RequestIDMetadata), 1)
+
+	_, err = env.client.Wake(authContext(t), &gwaihirv1.WakeRequest{MachineId: "sauron"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	assert.Equal(t, string(domain.CodeMachineNotAllowed), errorReason(t, err))
+
+	_, err = env.client.Wake(authContext(t), &gwaihirv1.WakeRequest{})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	env.audit.mu.Lock()
+	defer env.audit.mu.Unlock()
+	require.Len(t, env.audit.events, 3)
+	assert.Equal(t, domain.AuditOutcomeSuccess, env.audit.events[0].Outcome)
+	assert.Equal(t, "api-key:test", env.audit.events[0].Caller)
+	assert.Equal(t, domain.AuditOutcomeNotFound, env.audit.events[1].Outcome)
+	assert.Equal(t, domain.AuditOutcomeInvalid, env.audit.events[2].Outcome)
+}
+
+func TestServer_WakeFailureDoesNotLeakError(t *testing.T) {
+	env := newTestEnv(t)
+	env.sender.err = errors.New("sendto 192.168.1.255:9: network is unreachable")
+
+	_, err := env.client.Wake(authContext(t), &gwaihirv1.WakeRequest{MachineId: "saruman"})
+	assert.Equal(t, codes.Internal, status.Code(err))
+	assert.Equal(t, string(domain.CodeWakeFailed), errorReason(t, err))
+	assert.NotContains(t, err.Error(), "unreachable")
+}
+
+func TestServer_ListAndGetMachine(t *testing.T) {
+	env := newTestEnv(t)
+
+	list, err := env.client.ListMachines(authContext(t), &gwaihirv1.ListMachinesRequest{})
+	require.NoError(t, err)
+	assert.Len(t, list.GetMachines(), 2)
+
+	machine, err := env.client.GetMachine(authContext(t), &gwaihirv1.GetMachineRequest{MachineId: "gandalf"})
+	require.NoError(t, err)
+	assert.Equal(t, "11:22:33:44:55:66", machine.GetMac())
+
+	_, err = env.client.GetMachine(authContext(t), &gwaihirv1.GetMachineRequest{MachineId: "sauron"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	assert.Equal(t, string(domain.CodeMachineNotFound), errorReason(t, err))
+}
+
+func TestServer_Authentication(t *testing.T) {
+	env := newTestEnv(t)
+
+	_, err := env.client.ListMachines(context.Background(), &gwaihirv1.ListMachinesRequest{})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	assert.Equal(t, "unauthorized", errorReason(t, err))
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), APIKeyMetadata, "wrong")
+	_, err = env.client.ListMachines(ctx, &gwaihirv1.ListMachinesRequest{})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	stream, err := env.client.WatchMachineState(context.Background(), &gwaihirv1.WatchMachineStateRequest{})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	assert.Equal(t, 2.0, testutil.ToFloat64(env.metrics.AuthFailures.WithLabelValues(authFailureMissingKey)))
+	assert.Equal(t, 1.0, testutil.ToFloat64(env.metrics.AuthFailures.WithLabelValues(authFailureInvalidKey)))
+}
+
+func TestServer_AuthenticationLockout(t *testing.T) {
+	lockout := &fakeLockout{maxFailures: 2, failures: make(map[string]int)}
+	env := newTestEnvWith(t, func(s *Server) { s.WithLockout(lockout) })
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), APIKeyMetadata, "wrong")
+	for range 2 {
+		_, err := env.client.ListMachines(ctx, &gwaihirv1.ListMachinesRequest{})
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	}
+
+	// Locked out even with the correct key
+	_, err := env.client.ListMachines(authContext(t), &gwaihirv1.ListMachinesRequest{})
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	assert.Equal(t, "rate_limited", errorReason(t, err))
+
+	st, _ := status.FromError(err)
+	var retry *errdetails.RetryInfo
+	for _, detail := range st.Details() {
+		if info, ok := detail.(*errdetails.RetryInfo); ok {
+			retry = info
+		}
+	}
+	require.NotNil(t, retry)
+	assert.Equal(t, 90*time.Second, retry.GetRetryDelay().AsDuration())
+	assert.Equal(t, 1.0, testutil.ToFloat64(env.metrics.AuthFailures.WithLabelValues(authFailureLockedOut)))
+
+	lockout.RecordSuccess("bufconn")
+	_, err = env.client.ListMachines(authContext(t), &gwaihirv1.ListMachinesRequest{})
+	require.NoError(t, err)
+}
+
+func TestServer_HealthAndReflectionAreUnauthenticated(t *testing.T) {
+	env := newTestEnv(t)
+
+	resp, err := healthpb.NewHealthClient(env.conn).Check(context.Background(), &healthpb.HealthCheckRequest{
+		Service: gwaihirv1.WoLService_ServiceDesc.ServiceName,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.GetStatus())
+
+	stream, err := reflectionpb.NewServerReflectionClient(env.conn).ServerReflectionInfo(context.Background())
+	require.NoError(t, err)
+	require.NoError(t, stream.Send(&reflectionpb.ServerReflectionRequest{
+		MessageRequest: &reflectionpb.ServerReflectionRequest_ListServices{},
+	}))
+	reply, err := stream.Recv()
+	require.NoError(t, err)
+
+	var services []string
+	for _, service := range reply.GetListServicesResponse().GetService() {
+		services = append(services, service.GetName())
+	}
+	assert.Contains(t, services, gwaihirv1.WoLService_ServiceDesc.ServiceName)
+}
+
+func TestServer_WatchMachineState(t *testing.T) {
+	env := newTestEnv(t)
+
+	stream, err := env.client.WatchMachineState(authContext(t), &gwaihirv1.WatchMachineStateRequest{MachineIds: []string{"saruman"}})
+	require.NoError(t, err)
+	require.Eventually(t, func() bool { return env.events.Subscribers() == 1 }, time.Second, 5*time.Millisecond)
+
+	_, err = env.client.Wake(authContext(t), &gwaihirv1.WakeRequest{MachineId: "gandalf"})
+	require.NoError(t, err)
+	_, err = env.client.Wake(authContext(t), &gwaihirv1.WakeRequest{MachineId: "saruman"})
+	require.NoError(t, err)
+
+	for _, want := range []gwaihirv1.MachineState{
+		gwaihirv1.MachineState_MACHINE_STATE_WAKE_REQUESTED,
+		gwaihirv1.MachineState_MACHINE_STATE_WAKE_SENT,
+	} {
+		event, err := stream.Recv()
+		require.NoError(t, err)
+		assert.Equal(t, "saruman", event.GetMachineId())
+		assert.Equal(t, want, event.GetState())
+		assert.False(t, event.GetTime().AsTime().IsZero())
+	}
+}
+
+func TestServer_Metrics(t *testing.T) {
+	env := newTestEnv(t)
+
+	_, err := env.client.ListMachines(authContext(t), &gwaihirv1.ListMachinesRequest{})
+	require.NoError(t, err)
+	_, err = env.client.GetMachine(authContext(t), &gwaihirv1.GetMachineRequest{MachineId: "sauron"})
+	require.Error(t, err)
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(env.metrics.GRPCRequests.WithLabelValues(gwaihirv1.WoLService_ListMachines_FullMethodName, "OK")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(env.metrics.GRPCRequests.WithLabelValues(gwaihirv1.WoLService_GetMachine_FullMethodName, "NotFound")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(env.metrics.MachinesListed))
+}
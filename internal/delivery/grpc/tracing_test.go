@@ -0,0 +1,91 @@
+package grpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/josimar-silva/gwaihir/internal/infrastructure"
+	gwaihirv1 "github.com/josimar-silva/gwaihir/pkg/api/gwaihir/v1"
+)
+
+const testTraceParent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+// recordSpans installs a tracer provider recording spans in memory for the duration
+// of the test.
+func recordSpans(t *testing.T) *tracetest.InMemoryExporter {
+	t.Helper()
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+
+	previousProvider, previousPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		_ = provider.Shutdown(t.Context())
+		otel.SetTracerProvider(previousProvider)
+		otel.SetTextMapPropagator(previousPropagator)
+	})
+	return exporter
+}
+
+func TestServer_TracingContinuesIncomingTrace(t *testing.T) {
+	exporter := recordSpans(t)
+	var logs bytes.Buffer
+	env := newTestEnvWith(t, func(s *Server) {
+		s.logger = infrastructure.NewLoggerWithWriter(&logs, "json", "info")
+		s.WithTracing()
+	})
+
+	ctx := metadata.AppendToOutgoingContext(authContext(t), "traceparent", testTraceParent)
+	_, err := env.client.Wake(ctx, &gwaihirv1.WakeRequest{MachineId: "saruman"})
+	require.NoError(t, err)
+
+	ctx = metadata.AppendToOutgoingContext(t.Context(), "traceparent", testTraceParent, APIKeyMetadata, "wrong")
+	_, err = env.client.ListMachines(ctx, &gwaihirv1.ListMachinesRequest{})
+	require.Error(t, err)
+
+	var server *tracetest.SpanStub
+	spans := exporter.GetSpans()
+	for i := range spans {
+		if spans[i].Name == "gwaihir.v1.WoLService/Wake" {
+			server = &spans[i]
+		}
+	}
+	require.NotNil(t, server)
+	assert.Equal(t, trace.SpanKindServer, server.SpanKind)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", server.SpanContext.TraceID().String())
+	assert.True(t, server.Parent.IsRemote())
+	assert.Equal(t, otelcodes.Unset, server.Status.Code)
+
+	lines := strings.Split(strings.TrimSpace(logs.String()), "\n")
+	require.Len(t, lines, 3)
+	for _, line := range lines {
+		var record map[string]any
+		require.NoError(t, json.Unmarshal([]byte(line), &record))
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", record["trace_id"], record["msg"])
+	}
+}
+
+func TestServer_TracingDisabledByDefault(t *testing.T) {
+	exporter := recordSpans(t)
+	env := newTestEnv(t)
+
+	_, err := env.client.ListMachines(authContext(t), &gwaihirv1.ListMachinesRequest{})
+	require.NoError(t, err)
+
+	for _, span := range exporter.GetSpans() {
+		assert.NotEqual(t, trace.SpanKindServer, span.SpanKind, span.Name)
+	}
+}
@@ -0,0 +1,125 @@
+// Package http provides HTTP delivery layer.
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/josimar-silva/gwaihir/internal/domain"
+	"github.com/josimar-silva/gwaihir/internal/infrastructure"
+)
+
+// defaultAuditQueryLimit bounds the number of records returned by GET /audit
+// when no explicit limit is given.
+const defaultAuditQueryLimit = 100
+
+// maxAuditQueryLimit is the largest limit accepted by GET /audit.
+const maxAuditQueryLimit = 10000
+
+// AuditResponse represents the response of GET /audit.
+type AuditResponse struct {
+	Count   int                  `json:"count"`
+	Records []domain.AuditRecord `json:"records"`
+}
+
+// QueryAudit handles GET /audit requests.
+// Supported query parameters: machine_id, caller, since, until (RFC 3339) and limit.
+func (h *Handler) QueryAudit(c *gin.Context) {
+	requestID := GetRequestID(c)
+
+	filter, err := parseAuditFilter(c)
+	if err != nil {
+		h.requestLogger(c).Warn("Invalid audit query",
+			infrastructure.String("request_id", requestID),
+			infrastructure.Any("error", err),
+		)
+		writeProblem(c, CodeInvalidRequest, requestErrorDetail(nil, err))
+		return
+	}
+
+	records, err := h.audit.Query(filter)
+	if err != nil {
+		h.requestLogger(c).Error("Failed to query audit log",
+			infrastructure.String("request_id", requestID),
+			infrastructure.Any("error", err),
+		)
+		writeProblem(c, CodeInternal, "Failed to query audit log")
+		return
+	}
+
+	if records == nil {
+		records = []domain.AuditRecord{}
+	}
+
+	h.requestLogger(c).Info("Audit log queried",
+		infrastructure.String("request_id", requestID),
+		infrastructure.Int("count", len(records)),
+	)
+
+	c.JSON(http.StatusOK, AuditResponse{
+		Count:   len(records),
+		Records: records,
+	})
+}
+
+func parseAuditFilter(c *gin.Context) (domain.AuditFilter, error) {
+	filter := domain.AuditFilter{
+		MachineID: c.Query("machine_id"),
+		Caller:    c.Query("caller"),
+		Limit:     defaultAuditQueryLimit,
+	}
+
+	if since := c.Query("since"); since != "" {
+		t, err := time.Parse(time.RFC3339, since)
+		if err != nil {
+			return filter, &invalidRequestError{detail: "since must be an RFC 3339 timestamp", value: since}
+		}
+		filter.Since = t
+	}
+
+	if until := c.Query("until"); until != "" {
+		t, err := time.Parse(time.RFC3339, until)
+		if err != nil {
+			return filter, &invalidRequestError{detail: "until must be an RFC 3339 timestamp", value: until}
+		}
+		filter.Until = t
+	}
+
+	if limit := c.Query("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 1 || n > maxAuditQueryLimit {
+			return filter, &invalidRequestError{detail: fmt.Sprintf("limit must be between 1 and %d", maxAuditQueryLimit), value: limit}
+		}
+		filter.Limit = n
+	}
+
+	return filter, nil
+}
+
+// recordAudit appends an event for the current request to the audit trail, if enabled.
+// Failures to write the audit trail are logged but never fail the request.
+func (h *Handler) recordAudit(c *gin.Context, action, machineID, outcome string) {
+	if h.audit == nil {
+		return
+	}
+
+	err := h.audit.Record(domain.AuditEvent{
+		Action:    action,
+		Caller:    GetCaller(c),
+		SourceIP:  c.ClientIP(),
+		RequestID: GetRequestID(c),
+		MachineID: machineID,
+		Outcome:   outcome,
+	})
+	if err != nil {
+		h.requestLogger(c).Error("Failed to record audit event",
+			infrastructure.String("request_id", GetRequestID(c)),
+			infrastructure.String("action", action),
+			infrastructure.Any("error", err),
+		)
+	}
+}
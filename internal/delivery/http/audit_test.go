@@ -0,0 +1,182 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/josimar-silva/gwaihir/internal/config"
+	"github.com/josimar-silva/gwaihir/internal/domain"
+)
+
+// Mock audit log for testing
+type mockAuditLog struct {
+	mu      sync.Mutex
+	records []domain.AuditRecord
+}
+
+func (m *mockAuditLog) Record(event domain.AuditEvent) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.records = append(m.records, domain.AuditRecord{AuditEvent: event})
+	return nil
+}
+
+func (m *mockAuditLog) Query(filter domain.AuditFilter) ([]domain.AuditRecord, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var result []domain.AuditRecord
+	for i := range m.records {
+		if filter.Matches(&m.records[i]) {
+			result = append(result, m.records[i])
+		}
+	}
+	return result, nil
+}
+
+func (m *mockAuditLog) actions() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	actions := make([]string, 0, len(m.records))
+	for _, r := range m.records {
+		actions = append(actions, r.Action+":"+r.Outcome)
+	}
+	return actions
+}
+
+func TestAudit_RecordsWakeReadAndAuthFailure(t *testing.T) {
+	handler, _, _ := newHandlerForTesting(nil)
+	auditLog := &mockAuditLog{}
+	handler.WithAuditLog(auditLog)
+	router := NewRouterWithAuth(handler, testAPIKey)
+
+	body, _ := json.Marshal(WakeRequest{MachineID: "saruman"})
+	req := httptest.NewRequestWithContext(context.Background(), http.MethodPost, "/wol", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-API-Key", testAPIKey)
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	req = httptest.NewRequestWithContext(context.Background(), http.MethodGet, "/machines/unknown", nil)
+	req.Header.Set("X-API-Key", testAPIKey)
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	req = httptest.NewRequestWithContext(context.Background(), http.MethodGet, "/machines", nil)
+	req.Header.Set("X-API-Key", "wrong-key")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.Equal(t, []string{
+		"wake:success",
+		"machine_read:not_found",
+		"auth_failure:denied",
+	}, auditLog.actions())
+
+	wake := auditLog.records[0]
+	assert.Equal(t, "saruman", wake.MachineID)
+	assert.Equal(t, apiKeyCallerID(testAPIKey), wake.Caller)
+	assert.NotEmpty(t, wake.RequestID)
+	assert.NotEmpty(t, wake.SourceIP)
+
+	assert.Equal(t, anonymousCaller, auditLog.records[2].Caller)
+	assert.Equal(t, authFailureInvalidKey, auditLog.records[2].Detail)
+}
+
+func TestAudit_RecordsMalformedWake(t *testing.T) {
+	handler, _, sender := newHandlerForTesting(nil)
+	auditLog := &mockAuditLog{}
+	handler.WithAuditLog(auditLog)
+	router := NewRouterWithAuth(handler, testAPIKey)
+
+	for _, body := range []string{`{"machine_id":`, `{}`} {
+		w := doRawWakeRequest(router, body)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	}
+
+	assert.Equal(t, []string{"wake:invalid", "wake:invalid"}, auditLog.actions())
+	assert.Equal(t, apiKeyCallerID(testAPIKey), auditLog.records[0].Caller)
+	assert.NotEmpty(t, auditLog.records[0].RequestID)
+	assert.Zero(t, sender.callCount)
+}
+
+func doRawWakeRequest(router http.Handler, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequestWithContext(context.Background(), http.MethodPost, "/api/v1/wol", bytes.NewReader([]byte(body)))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-API-Key", testAPIKey)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// newAuditRouter serves handler with an API key and an admin API key configured.
+func newAuditRouter(handler *Handler) http.Handler {
+	cfg := &config.Config{Authentication: config.AuthenticationConfig{APIKey: testAPIKey, AdminAPIKey: testAdminAPIKey}}
+	return NewRouterWithAuthAndConfig(handler, testAPIKey, cfg)
+}
+
+func doAuditQuery(router http.Handler, query, apiKey string) *httptest.ResponseRecorder {
+	req := httptest.NewRequestWithContext(context.Background(), http.MethodGet, "/audit"+query, nil)
+	req.Header.Set("X-API-Key", apiKey)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestAudit_QueryEndpoint(t *testing.T) {
+	handler, _, _ := newHandlerForTesting(nil)
+	auditLog := &mockAuditLog{}
+	handler.WithAuditLog(auditLog)
+	router := newAuditRouter(handler)
+
+	_ = auditLog.Record(domain.AuditEvent{Action: domain.AuditActionWake, MachineID: "saruman", Caller: "api-key:aaaa"})
+	_ = auditLog.Record(domain.AuditEvent{Action: domain.AuditActionWake, MachineID: "morgoth", Caller: "api-key:aaaa"})
+
+	w := doAuditQuery(router, "?machine_id=saruman", testAdminAPIKey)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var response AuditResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, 1, response.Count)
+	assert.Equal(t, "saruman", response.Records[0].MachineID)
+}
+
+func TestAudit_QueryEndpoint_InvalidParams(t *testing.T) {
+	handler, _, _ := newHandlerForTesting(nil)
+	handler.WithAuditLog(&mockAuditLog{})
+	router := newAuditRouter(handler)
+
+	for _, query := range []string{"since=yesterday", "until=2026-13-01", "limit=0", "limit=abc"} {
+		w := doAuditQuery(router, "?"+query, testAdminAPIKey)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+}
+
+func TestAudit_QueryEndpointRequiresAdminScope(t *testing.T) {
+	handler, _, _ := newHandlerForTesting(nil)
+	handler.WithAuditLog(&mockAuditLog{})
+	router := newAuditRouter(handler)
+
+	assert.Equal(t, http.StatusUnauthorized, doAuditQuery(router, "", "").Code)
+	assert.Equal(t, http.StatusForbidden, doAuditQuery(router, "", testAPIKey).Code)
+	assert.Equal(t, http.StatusOK, doAuditQuery(router, "", testAdminAPIKey).Code)
+}
+
+func TestAudit_QueryEndpointNotRegisteredWithoutAdminKey(t *testing.T) {
+	handler, _, _ := newHandlerForTesting(nil)
+	handler.WithAuditLog(&mockAuditLog{})
+
+	assert.Equal(t, http.StatusNotFound, doAuditQuery(NewRouter(handler), "", "").Code)
+	assert.Equal(t, http.StatusNotFound, doAuditQuery(NewRouterWithAuth(handler, testAPIKey), "", testAPIKey).Code)
+}
+
+func TestAudit_QueryEndpointNotRegisteredWithoutAuditLog(t *testing.T) {
+	handler, _, _ := newHandlerForTesting(nil)
+
+	assert.Equal(t, http.StatusNotFound, doAuditQuery(newAuditRouter(handler), "", testAdminAPIKey).Code)
+}
@@ -2,31 +2,277 @@
 package http
 
 import (
-	"net/http"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"math"
+	"strconv"
+	"sync"
 
 	"github.com/gin-gonic/gin"
+
+	"github.com/josimar-silva/gwaihir/internal/domain"
+	"github.com/josimar-silva/gwaihir/internal/infrastructure"
+)
+
+// Authentication failure reasons used as metric labels.
+const (
+	authFailureMissingKey = "missing_key"
+	authFailureInvalidKey = "invalid_key"
+	authFailureLockedOut  = "locked_out"
 )
 
+const callerKey = "caller"
+const scopesKey = "scopes"
+
+// ScopeAdmin grants access to the machine management endpoints.
+const ScopeAdmin = "admin"
+
+// APIKey associates an API key with the scopes it grants.
+type APIKey struct {
+	Key    string
+	Scopes []string
+}
+
+// APIKeySet is a set of API keys that can be replaced at runtime, so that rotated
+// secrets take effect on configuration reload without restarting the server.
+type APIKeySet struct {
+	mu        sync.RWMutex
+	keys      []APIKey
+	callerIDs []string
+}
+
+// NewAPIKeySet creates a key set holding keys.
+func NewAPIKeySet(keys []APIKey) *APIKeySet {
+	set := &APIKeySet{}
+	set.Set(keys)
+	return set
+}
+
+// ConfiguredAPIKeys returns the keys for the configured API key and admin API key.
+// Empty keys are omitted; the admin key grants ScopeAdmin.
+func ConfiguredAPIKeys(apiKey, adminAPIKey string) []APIKey {
+	keys := make([]APIKey, 0, 2)
+	if apiKey != "" {
+		keys = append(keys, APIKey{Key: apiKey})
+	}
+	if adminAPIKey != "" {
+		keys = append(keys, APIKey{Key: adminAPIKey, Scopes: []string{ScopeAdmin}})
+	}
+	return keys
+}
+
+// Set replaces the keys of the set.
+func (s *APIKeySet) Set(keys []APIKey) {
+	callerIDs := make([]string, len(keys))
+	for i, key := range keys {
+		callerIDs[i] = apiKeyCallerID(key.Key)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keys = keys
+	s.callerIDs = callerIDs
+}
+
+// Authenticate returns the caller identity of apiKey and whether it is one of the
+// keys of the set. It lets other transports share the keys of the HTTP API.
+func (s *APIKeySet) Authenticate(apiKey string) (string, bool) {
+	_, callerID, ok := s.match(apiKey)
+	return callerID, ok
+}
+
+// match returns the key matching apiKey and its caller identity.
+func (s *APIKeySet) match(apiKey string) (APIKey, string, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	i := matchAPIKey(s.keys, apiKey)
+	if i < 0 {
+		return APIKey{}, "", false
+	}
+	return s.keys[i], s.callerIDs[i], true
+}
+
+// anonymousCaller identifies requests that were not authenticated.
+const anonymousCaller = "anonymous"
+
 // APIKeyAuthMiddleware validates the X-API-Key header against the expected API key.
 func APIKeyAuthMiddleware(expectedAPIKey string) gin.HandlerFunc {
+	return APIKeyAuthMiddlewareWithLockout(expectedAPIKey, nil, nil, nil, nil)
+}
+
+// APIKeyAuthMiddlewareWithLockout validates the X-API-Key header and blocks clients
+// that repeatedly fail authentication. Clients are identified by their IP address.
+// A nil lockout disables blocking; nil logger, metrics or audit log disable the
+// corresponding reporting of failures.
+func APIKeyAuthMiddlewareWithLockout(expectedAPIKey string, lockout *AuthLockout, logger *infrastructure.Logger, metrics *infrastructure.Metrics, audit domain.AuditLog) gin.HandlerFunc {
+	return APIKeysAuthMiddleware([]APIKey{{Key: expectedAPIKey}}, lockout, logger, metrics, audit)
+}
+
+// APIKeysAuthMiddleware validates the X-API-Key header against a set of API keys,
+// recording the matching key's caller identity and scopes in the Gin context.
+// Lockout, logger, metrics and audit log behave as in APIKeyAuthMiddlewareWithLockout.
+func APIKeysAuthMiddleware(keys []APIKey, lockout *AuthLockout, logger *infrastructure.Logger, metrics *infrastructure.Metrics, audit domain.AuditLog) gin.HandlerFunc {
+	return APIKeySetAuthMiddleware(NewAPIKeySet(keys), lockout, logger, metrics, audit)
+}
+
+// APIKeySetAuthMiddleware behaves like APIKeysAuthMiddleware, validating against the
+// current keys of a set that may be replaced at runtime. The AuthLockedOut gauge of
+// metrics reports the clients locked out by lockout.
+func APIKeySetAuthMiddleware(keys *APIKeySet, lockout *AuthLockout, logger *infrastructure.Logger, metrics *infrastructure.Metrics, audit domain.AuditLog) gin.HandlerFunc {
+	reporter := authFailureReporter{logger: logger, metrics: metrics, audit: audit}
+	if lockout != nil && metrics != nil {
+		metrics.TrackLockedOutClients(lockout.LockedOutCount)
+	}
+
 	return func(c *gin.Context) {
+		clientIP := c.ClientIP()
+
+		if lockout != nil {
+			if locked, remaining := lockout.IsLocked(clientIP); locked {
+				reporter.report(c, authFailureLockedOut)
+				c.Header("Retry-After", strconv.Itoa(int(math.Ceil(remaining.Seconds()))))
+				writeProblem(c, CodeRateLimited, "Too many failed authentication attempts")
+				return
+			}
+		}
+
 		apiKey := c.GetHeader("X-API-Key")
 		if apiKey == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error": "Missing X-API-Key header",
-			})
-			c.Abort()
+			rejectUnauthorized(c, lockout, reporter, authFailureMissingKey, "Missing X-API-Key header")
 			return
 		}
 
-		if apiKey != expectedAPIKey {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error": "Invalid API key",
-			})
-			c.Abort()
+		key, callerID, ok := keys.match(apiKey)
+		if !ok {
+			rejectUnauthorized(c, lockout, reporter, authFailureInvalidKey, "Invalid API key")
 			return
 		}
 
+		if lockout != nil {
+			lockout.RecordSuccess(clientIP)
+		}
+
+		c.Set(callerKey, callerID)
+		c.Set(scopesKey, key.Scopes)
 		c.Next()
 	}
 }
+
+// RequireScope rejects authenticated requests whose API key does not grant scope.
+// It must run after APIKeysAuthMiddleware.
+func RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !HasScope(c, scope) {
+			writeProblem(c, CodeForbidden, "API key does not grant the required scope: "+scope)
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// HasScope reports whether the authenticated API key grants scope.
+func HasScope(c *gin.Context, scope string) bool {
+	value, exists := c.Get(scopesKey)
+	if !exists {
+		return false
+	}
+	scopes, ok := value.([]string)
+	if !ok {
+		return false
+	}
+	for _, s := range scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// matchAPIKey returns the index of the key matching apiKey, or -1.
+// Every key is compared in constant time to avoid leaking which key was close.
+func matchAPIKey(keys []APIKey, apiKey string) int {
+	match := -1
+	for i, key := range keys {
+		if subtle.ConstantTimeCompare([]byte(key.Key), []byte(apiKey)) == 1 && match < 0 {
+			match = i
+		}
+	}
+	return match
+}
+
+// GetCaller returns the identity of the authenticated caller, or "anonymous"
+// when the request did not go through API key authentication.
+func GetCaller(c *gin.Context) string {
+	if caller, exists := c.Get(callerKey); exists {
+		if str, ok := caller.(string); ok {
+			return str
+		}
+	}
+	return anonymousCaller
+}
+
+// apiKeyCallerID derives a stable, non-reversible caller identity from an API key
+// so that audit records can tell keys apart without storing them.
+func apiKeyCallerID(apiKey string) string {
+	sum := sha256.Sum256([]byte(apiKey))
+	return "api-key:" + hex.EncodeToString(sum[:4])
+}
+
+// rejectUnauthorized records the failed attempt, locks out the client if the threshold
+// is reached and responds with 401.
+func rejectUnauthorized(c *gin.Context, lockout *AuthLockout, reporter authFailureReporter, reason, message string) {
+	reporter.report(c, reason)
+
+	if lockout != nil {
+		if locked, duration := lockout.RecordFailure(c.ClientIP()); locked && reporter.logger != nil {
+			reporter.logger.WithTrace(c.Request.Context()).Warn("Client locked out after repeated authentication failures",
+				infrastructure.String("request_id", GetRequestID(c)),
+				infrastructure.String("client_ip", c.ClientIP()),
+				infrastructure.Duration("lockout", duration),
+			)
+		}
+	}
+
+	writeProblem(c, CodeUnauthorized, message)
+}
+
+// authFailureReporter logs, counts and audits failed authentication attempts.
+type authFailureReporter struct {
+	logger  *infrastructure.Logger
+	metrics *infrastructure.Metrics
+	audit   domain.AuditLog
+}
+
+func (r authFailureReporter) report(c *gin.Context, reason string) {
+	if r.metrics != nil && r.metrics.AuthFailures != nil {
+		r.metrics.AuthFailures.WithLabelValues(reason).Inc()
+	}
+
+	if r.logger != nil {
+		r.logger.WithTrace(c.Request.Context()).Warn("Authentication failed",
+			infrastructure.String("request_id", GetRequestID(c)),
+			infrastructure.String("client_ip", c.ClientIP()),
+			infrastructure.String("reason", reason),
+		)
+	}
+
+	if r.audit != nil {
+		err := r.audit.Record(domain.AuditEvent{
+			Action:    domain.AuditActionAuthFailure,
+			Caller:    anonymousCaller,
+			SourceIP:  c.ClientIP(),
+			RequestID: GetRequestID(c),
+			Outcome:   domain.AuditOutcomeDenied,
+			Detail:    reason,
+		})
+		if err != nil && r.logger != nil {
+			r.logger.WithTrace(c.Request.Context()).Error("Failed to record audit event",
+				infrastructure.String("request_id", GetRequestID(c)),
+				infrastructure.Any("error", err),
+			)
+		}
+	}
+}
@@ -0,0 +1,209 @@
+// Package http provides HTTP delivery layer.
+package http
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"github.com/josimar-silva/gwaihir/internal/config"
+)
+
+// AuthLockout tracks failed authentication attempts per client and temporarily
+// blocks clients that exceed the allowed number of consecutive failures.
+// Each subsequent lockout of the same client doubles the block duration up to a maximum.
+// The number of tracked clients is bounded; the least recently seen client that is not
+// locked out is evicted first.
+type AuthLockout struct {
+	maxFailures  int
+	baseDuration time.Duration
+	maxDuration  time.Duration
+	maxClients   int
+
+	mu      sync.Mutex
+	clients map[string]*lockoutEntry
+	recency *list.List // client names, most recently seen first
+	now     func() time.Time
+}
+
+type lockoutEntry struct {
+	element     *list.Element
+	failures    int
+	lockouts    int
+	lockedUntil time.Time
+	lastSeen    time.Time
+}
+
+// NewAuthLockout creates a lockout tracker with the given settings.
+// Non-positive values fall back to the configuration defaults.
+func NewAuthLockout(maxFailures int, baseDuration, maxDuration time.Duration, maxClients int) *AuthLockout {
+	if maxFailures <= 0 {
+		maxFailures = config.DefaultLockoutMaxFailures
+	}
+	if baseDuration <= 0 {
+		baseDuration = config.DefaultLockoutBaseDuration
+	}
+	if maxDuration <= 0 {
+		maxDuration = config.DefaultLockoutMaxDuration
+	}
+	if maxDuration < baseDuration {
+		maxDuration = baseDuration
+	}
+	if maxClients <= 0 {
+		maxClients = config.DefaultLockoutMaxClients
+	}
+
+	return &AuthLockout{
+		maxFailures:  maxFailures,
+		baseDuration: baseDuration,
+		maxDuration:  maxDuration,
+		maxClients:   maxClients,
+		clients:      make(map[string]*lockoutEntry),
+		recency:      list.New(),
+		now:          time.Now,
+	}
+}
+
+// NewAuthLockoutFromConfig creates a lockout tracker from the authentication config.
+// Returns nil when lockout is explicitly disabled.
+func NewAuthLockoutFromConfig(cfg *config.Config) *AuthLockout {
+	if cfg == nil {
+		return NewAuthLockout(0, 0, 0, 0)
+	}
+
+	lockout := cfg.Authentication.Lockout
+	if lockout.Enabled != nil && !*lockout.Enabled {
+		return nil
+	}
+
+	return NewAuthLockout(lockout.MaxFailures, lockout.BaseDuration, lockout.MaxDuration, lockout.MaxClients)
+}
+
+// IsLocked reports whether the client is currently locked out and, if so,
+// how long until the lockout expires.
+func (l *AuthLockout) IsLocked(client string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	entry, ok := l.clients[client]
+	if !ok {
+		return false, 0
+	}
+
+	remaining := entry.lockedUntil.Sub(l.now())
+	if remaining <= 0 {
+		return false, 0
+	}
+
+	return true, remaining
+}
+
+// RecordFailure registers a failed attempt for the client.
+// Returns true and the lockout duration if this failure caused the client to be locked out.
+func (l *AuthLockout) RecordFailure(client string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	entry := l.touch(client, now)
+	if entry == nil {
+		return false, 0
+	}
+	entry.failures++
+
+	if entry.failures < l.maxFailures {
+		return false, 0
+	}
+
+	duration := l.lockoutDuration(entry.lockouts)
+	entry.lockouts++
+	entry.failures = 0
+	entry.lockedUntil = now.Add(duration)
+
+	return true, duration
+}
+
+// RecordSuccess clears any failure history for the client.
+func (l *AuthLockout) RecordSuccess(client string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if entry, ok := l.clients[client]; ok {
+		l.recency.Remove(entry.element)
+		delete(l.clients, client)
+	}
+}
+
+// LockedOutCount returns the number of clients that are currently locked out.
+func (l *AuthLockout) LockedOutCount() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	count := 0
+	for _, entry := range l.clients {
+		if entry.lockedUntil.After(now) {
+			count++
+		}
+	}
+
+	return count
+}
+
+// touch returns the entry for the client, creating it if needed, and marks it as recently seen.
+// Entries idle for longer than the maximum lockout duration are reset so that old offences expire.
+// Returns nil when the client is new and every tracked client is locked out.
+// Must be called with the mutex held.
+func (l *AuthLockout) touch(client string, now time.Time) *lockoutEntry {
+	if entry, ok := l.clients[client]; ok {
+		if now.After(entry.lockedUntil) && now.Sub(entry.lastSeen) > l.maxDuration {
+			entry.failures = 0
+			entry.lockouts = 0
+		}
+		entry.lastSeen = now
+		l.recency.MoveToFront(entry.element)
+		return entry
+	}
+
+	if len(l.clients) >= l.maxClients && !l.evictOldest(now) {
+		return nil
+	}
+
+	entry := &lockoutEntry{lastSeen: now}
+	entry.element = l.recency.PushFront(client)
+	l.clients[client] = entry
+	return entry
+}
+
+// evictOldest removes the least recently seen client that is not locked out to keep
+// memory bounded, so that flooding the tracker cannot release locked out clients.
+// Locked out clients passed over are moved to the front to keep later evictions cheap.
+// Returns false when every tracked client is locked out.
+// Must be called with the mutex held.
+func (l *AuthLockout) evictOldest(now time.Time) bool {
+	for range l.recency.Len() {
+		element := l.recency.Back()
+		client := element.Value.(string)
+		if l.clients[client].lockedUntil.After(now) {
+			l.recency.MoveToFront(element)
+			continue
+		}
+
+		l.recency.Remove(element)
+		delete(l.clients, client)
+		return true
+	}
+	return false
+}
+
+// lockoutDuration computes the exponential backoff for the given number of previous lockouts.
+func (l *AuthLockout) lockoutDuration(previousLockouts int) time.Duration {
+	duration := l.baseDuration
+	for i := 0; i < previousLockouts; i++ {
+		duration *= 2
+		if duration >= l.maxDuration {
+			return l.maxDuration
+		}
+	}
+	return duration
+}
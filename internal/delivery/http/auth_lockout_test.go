@@ -0,0 +1,177 @@
+package http
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/josimar-silva/gwaihir/internal/config"
+)
+
+type fakeClock struct {
+	current time.Time
+}
+
+func (f *fakeClock) now() time.Time {
+	return f.current
+}
+
+func (f *fakeClock) advance(d time.Duration) {
+	f.current = f.current.Add(d)
+}
+
+func newLockoutForTesting(maxFailures int, base, maxDuration time.Duration, maxClients int) (*AuthLockout, *fakeClock) {
+	clock := &fakeClock{current: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+	lockout := NewAuthLockout(maxFailures, base, maxDuration, maxClients)
+	lockout.now = clock.now
+	return lockout, clock
+}
+
+func TestAuthLockout_LocksAfterMaxFailures(t *testing.T) {
+	lockout, _ := newLockoutForTesting(3, time.Minute, time.Hour, 10)
+
+	for i := 0; i < 2; i++ {
+		locked, _ := lockout.RecordFailure("10.0.0.1")
+		assert.False(t, locked)
+	}
+
+	locked, duration := lockout.RecordFailure("10.0.0.1")
+	assert.True(t, locked)
+	assert.Equal(t, time.Minute, duration)
+
+	isLocked, remaining := lockout.IsLocked("10.0.0.1")
+	assert.True(t, isLocked)
+	assert.Equal(t, time.Minute, remaining)
+	assert.Equal(t, 1, lockout.LockedOutCount())
+
+	isLocked, _ = lockout.IsLocked("10.0.0.2")
+	assert.False(t, isLocked)
+}
+
+func TestAuthLockout_LockExpires(t *testing.T) {
+	lockout, clock := newLockoutForTesting(1, time.Minute, time.Hour, 10)
+
+	lockout.RecordFailure("10.0.0.1")
+	clock.advance(time.Minute + time.Second)
+
+	isLocked, _ := lockout.IsLocked("10.0.0.1")
+	assert.False(t, isLocked)
+	assert.Equal(t, 0, lockout.LockedOutCount())
+}
+
+func TestAuthLockout_ExponentialBackoff(t *testing.T) {
+	lockout, clock := newLockoutForTesting(1, time.Minute, 5*time.Minute, 10)
+
+	expected := []time.Duration{time.Minute, 2 * time.Minute, 4 * time.Minute, 5 * time.Minute, 5 * time.Minute}
+	for _, want := range expected {
+		locked, duration := lockout.RecordFailure("10.0.0.1")
+		assert.True(t, locked)
+		assert.Equal(t, want, duration)
+		clock.advance(duration)
+	}
+}
+
+func TestAuthLockout_HistoryExpiresAfterIdle(t *testing.T) {
+	lockout, clock := newLockoutForTesting(1, time.Minute, 5*time.Minute, 10)
+
+	lockout.RecordFailure("10.0.0.1")
+	clock.advance(time.Minute)
+	_, duration := lockout.RecordFailure("10.0.0.1")
+	assert.Equal(t, 2*time.Minute, duration)
+
+	clock.advance(time.Hour)
+	_, duration = lockout.RecordFailure("10.0.0.1")
+	assert.Equal(t, time.Minute, duration)
+}
+
+func TestAuthLockout_SuccessResetsFailures(t *testing.T) {
+	lockout, _ := newLockoutForTesting(2, time.Minute, time.Hour, 10)
+
+	lockout.RecordFailure("10.0.0.1")
+	lockout.RecordSuccess("10.0.0.1")
+
+	locked, _ := lockout.RecordFailure("10.0.0.1")
+	assert.False(t, locked)
+}
+
+func TestAuthLockout_BoundedClients(t *testing.T) {
+	lockout, clock := newLockoutForTesting(5, time.Minute, time.Hour, 2)
+
+	lockout.RecordFailure("10.0.0.1")
+	clock.advance(time.Second)
+	lockout.RecordFailure("10.0.0.2")
+	clock.advance(time.Second)
+	lockout.RecordFailure("10.0.0.3")
+
+	assert.Len(t, lockout.clients, 2)
+	assert.NotContains(t, lockout.clients, "10.0.0.1")
+	assert.Contains(t, lockout.clients, "10.0.0.3")
+}
+
+func TestAuthLockout_EvictionKeepsLockedClients(t *testing.T) {
+	lockout, clock := newLockoutForTesting(1, time.Minute, time.Hour, 2)
+
+	locked, _ := lockout.RecordFailure("10.0.0.1")
+	assert.True(t, locked)
+	clock.advance(time.Second)
+	lockout.RecordFailure("10.0.0.2")
+	clock.advance(time.Second)
+
+	// Both tracked clients are locked out; 10.0.0.3 cannot displace either of them
+	lockout.RecordFailure("10.0.0.3")
+	assert.Len(t, lockout.clients, 2)
+	assert.NotContains(t, lockout.clients, "10.0.0.3")
+
+	locked, _ = lockout.IsLocked("10.0.0.1")
+	assert.True(t, locked)
+	assert.Equal(t, lockout.recency.Len(), len(lockout.clients))
+
+	// Once a lockout expires its client can be evicted again
+	clock.advance(time.Minute)
+	lockout.RecordFailure("10.0.0.3")
+	assert.Contains(t, lockout.clients, "10.0.0.3")
+	assert.Len(t, lockout.clients, 2)
+}
+
+func TestAuthLockout_EvictionSkipsLockedOldestClient(t *testing.T) {
+	lockout, clock := newLockoutForTesting(2, time.Minute, time.Hour, 2)
+
+	lockout.RecordFailure("10.0.0.1")
+	lockout.RecordFailure("10.0.0.1")
+	clock.advance(time.Second)
+	lockout.RecordFailure("10.0.0.2")
+	clock.advance(time.Second)
+	lockout.RecordFailure("10.0.0.3")
+
+	assert.Contains(t, lockout.clients, "10.0.0.1")
+	assert.NotContains(t, lockout.clients, "10.0.0.2")
+	assert.Contains(t, lockout.clients, "10.0.0.3")
+}
+
+func TestAuthLockout_SuccessRemovesClientFromRecency(t *testing.T) {
+	lockout, _ := newLockoutForTesting(5, time.Minute, time.Hour, 2)
+
+	lockout.RecordFailure("10.0.0.1")
+	lockout.RecordSuccess("10.0.0.1")
+
+	assert.Empty(t, lockout.clients)
+	assert.Zero(t, lockout.recency.Len())
+}
+
+func TestNewAuthLockoutFromConfig(t *testing.T) {
+	assert.NotNil(t, NewAuthLockoutFromConfig(nil))
+
+	disabled := false
+	cfg := &config.Config{Authentication: config.AuthenticationConfig{
+		Lockout: config.LockoutConfig{Enabled: &disabled},
+	}}
+	assert.Nil(t, NewAuthLockoutFromConfig(cfg))
+
+	enabled := true
+	cfg.Authentication.Lockout = config.LockoutConfig{Enabled: &enabled, MaxFailures: 7, MaxClients: 3}
+	lockout := NewAuthLockoutFromConfig(cfg)
+	assert.Equal(t, 7, lockout.maxFailures)
+	assert.Equal(t, 3, lockout.maxClients)
+	assert.Equal(t, config.DefaultLockoutBaseDuration, lockout.baseDuration)
+}
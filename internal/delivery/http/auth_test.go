@@ -5,10 +5,14 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
 
 	"github.com/josimar-silva/gwaihir/internal/config"
+	"github.com/josimar-silva/gwaihir/internal/infrastructure"
 )
 
 const testAPIKey = "test-secret-key-123"
@@ -247,6 +251,203 @@ func TestRouterWithConfig_NoAuthRequired(t *testing.T) {
 	}
 }
 
+func TestAPIKeyAuthMiddlewareWithLockout_BlocksRepeatedFailures(t *testing.T) {
+	handler, _, _ := newHandlerForTesting(nil)
+	gin.SetMode(gin.ReleaseMode)
+	router := gin.New()
+	router.Use(RequestIDMiddleware())
+	router.Use(APIKeyAuthMiddlewareWithLockout(testAPIKey, NewAuthLockout(2, time.Minute, time.Hour, 10), handler.logger, handler.metrics, nil))
+	router.GET("/test", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"status": "ok"})
+	})
+
+	send := func(key string) *httptest.ResponseRecorder {
+		req := httptest.NewRequestWithContext(context.Background(), http.MethodGet, "/test", nil)
+		req.Header.Set("X-API-Key", key)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, send("wrong-key").Code)
+	assert.Equal(t, http.StatusUnauthorized, send("wrong-key").Code)
+
+	// Locked out even with the correct key
+	w := send(testAPIKey)
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "60", w.Header().Get("Retry-After"))
+
+	assert.Equal(t, 2.0, testutil.ToFloat64(handler.metrics.AuthFailures.WithLabelValues(authFailureInvalidKey)))
+	assert.Equal(t, 1.0, testutil.ToFloat64(handler.metrics.AuthFailures.WithLabelValues(authFailureLockedOut)))
+	assert.Equal(t, 1.0, testutil.ToFloat64(handler.metrics.AuthLockedOut))
+}
+
+func TestAPIKeyAuthMiddlewareWithLockout_LockedOutGaugeFollowsExpiry(t *testing.T) {
+	handler, _, _ := newHandlerForTesting(nil)
+	lockout, clock := newLockoutForTesting(1, time.Minute, time.Hour, 10)
+	gin.SetMode(gin.ReleaseMode)
+	router := gin.New()
+	router.Use(APIKeyAuthMiddlewareWithLockout(testAPIKey, lockout, nil, handler.metrics, nil))
+	router.GET("/test", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"status": "ok"})
+	})
+
+	req := httptest.NewRequestWithContext(context.Background(), http.MethodGet, "/test", nil)
+	req.Header.Set("X-API-Key", "wrong-key")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+	assert.Equal(t, 1.0, testutil.ToFloat64(handler.metrics.AuthLockedOut))
+
+	clock.advance(time.Minute + time.Second)
+	assert.Equal(t, 0.0, testutil.ToFloat64(handler.metrics.AuthLockedOut))
+}
+
+func TestAPIKeyAuthMiddlewareWithLockout_PartialMetricsDoNotPanic(t *testing.T) {
+	gin.SetMode(gin.ReleaseMode)
+	router := gin.New()
+	router.Use(APIKeyAuthMiddlewareWithLockout(testAPIKey, NewAuthLockout(1, time.Minute, time.Hour, 10), nil, &infrastructure.Metrics{}, nil))
+	router.GET("/test", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"status": "ok"})
+	})
+
+	for _, want := range []int{http.StatusUnauthorized, http.StatusTooManyRequests} {
+		req := httptest.NewRequestWithContext(context.Background(), http.MethodGet, "/test", nil)
+		req.Header.Set("X-API-Key", "wrong-key")
+		w := httptest.NewRecorder()
+
+		assert.NotPanics(t, func() { router.ServeHTTP(w, req) })
+		assert.Equal(t, want, w.Code)
+	}
+}
+
+func TestAPIKeyAuthMiddlewareWithLockout_SuccessResetsFailures(t *testing.T) {
+	gin.SetMode(gin.ReleaseMode)
+	router := gin.New()
+	router.Use(APIKeyAuthMiddlewareWithLockout(testAPIKey, NewAuthLockout(2, time.Minute, time.Hour, 10), nil, nil, nil))
+	router.GET("/test", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"status": "ok"})
+	})
+
+	for _, key := range []string{"wrong-key", testAPIKey, "wrong-key", testAPIKey} {
+		req := httptest.NewRequestWithContext(context.Background(), http.MethodGet, "/test", nil)
+		req.Header.Set("X-API-Key", key)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.NotEqual(t, http.StatusTooManyRequests, w.Code)
+	}
+}
+
+func TestRouterWithConfig_LockoutDisabled(t *testing.T) {
+	cfg := &config.Config{
+		Authentication: config.AuthenticationConfig{
+			APIKey:  testAPIKey,
+			Lockout: config.LockoutConfig{Enabled: boolPtr(false), MaxFailures: 1},
+		},
+	}
+
+	handler, _, _ := newHandlerForTesting(nil)
+	router := NewRouterWithConfig(handler, cfg)
+
+	for i := 0; i < 3; i++ {
+		req := httptest.NewRequestWithContext(context.Background(), http.MethodGet, "/machines", nil)
+		req.Header.Set("X-API-Key", "wrong-key")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	}
+}
+
+func TestRouterWithConfig_LockoutIgnoresForwardedForFromUntrustedPeers(t *testing.T) {
+	cfg := &config.Config{
+		Authentication: config.AuthenticationConfig{
+			APIKey:  testAPIKey,
+			Lockout: config.LockoutConfig{MaxFailures: 1, BaseDuration: time.Minute, MaxDuration: time.Hour},
+		},
+	}
+
+	handler, _, _ := newHandlerForTesting(nil)
+	router := NewRouterWithConfig(handler, cfg)
+
+	for i, forwardedFor := range []string{"203.0.113.1", "203.0.113.2"} {
+		req := httptest.NewRequestWithContext(context.Background(), http.MethodGet, "/machines", nil)
+		req.Header.Set("X-API-Key", "wrong-key")
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if i == 0 {
+			assert.Equal(t, http.StatusUnauthorized, w.Code)
+		} else {
+			assert.Equal(t, http.StatusTooManyRequests, w.Code, "rotating X-Forwarded-For must not reset the lockout")
+		}
+	}
+}
+
+func TestRouterWithConfig_LockoutUsesForwardedForFromTrustedProxies(t *testing.T) {
+	cfg := &config.Config{
+		Server: config.ServerConfig{TrustedProxies: []string{"192.0.2.0/24"}},
+		Authentication: config.AuthenticationConfig{
+			APIKey:  testAPIKey,
+			Lockout: config.LockoutConfig{MaxFailures: 1, BaseDuration: time.Minute, MaxDuration: time.Hour},
+		},
+	}
+
+	handler, _, _ := newHandlerForTesting(nil)
+	router := NewRouterWithConfig(handler, cfg)
+
+	for _, forwardedFor := range []string{"203.0.113.1", "203.0.113.2"} {
+		req := httptest.NewRequestWithContext(context.Background(), http.MethodGet, "/machines", nil)
+		req.Header.Set("X-API-Key", "wrong-key")
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code, "clients behind a trusted proxy are tracked separately")
+	}
+}
+
 func boolPtr(b bool) *bool {
 	return &b
 }
+
+func TestAPIKeySetAuthMiddleware_RotatesKeys(t *testing.T) {
+	gin.SetMode(gin.ReleaseMode)
+	keys := NewAPIKeySet(ConfiguredAPIKeys(testAPIKey, ""))
+	router := gin.New()
+	router.Use(APIKeySetAuthMiddleware(keys, nil, nil, nil, nil))
+	router.GET("/test", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"caller": GetCaller(c)})
+	})
+
+	request := func(apiKey string) int {
+		req := httptest.NewRequestWithContext(context.Background(), http.MethodGet, "/test", nil)
+		req.Header.Set("X-API-Key", apiKey)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusOK, request(testAPIKey))
+
+	keys.Set(ConfiguredAPIKeys("rotated-key", ""))
+
+	assert.Equal(t, http.StatusUnauthorized, request(testAPIKey))
+	assert.Equal(t, http.StatusOK, request("rotated-key"))
+}
+
+func TestConfiguredAPIKeys(t *testing.T) {
+	assert.Empty(t, ConfiguredAPIKeys("", ""))
+	assert.Equal(t, []APIKey{{Key: "user"}, {Key: "admin", Scopes: []string{ScopeAdmin}}}, ConfiguredAPIKeys("user", "admin"))
+}
+
+func TestAPIKeySet_Authenticate(t *testing.T) {
+	keys := NewAPIKeySet(ConfiguredAPIKeys(testAPIKey, ""))
+
+	caller, ok := keys.Authenticate(testAPIKey)
+	assert.True(t, ok)
+	assert.Equal(t, apiKeyCallerID(testAPIKey), caller)
+
+	_, ok = keys.Authenticate("wrong")
+	assert.False(t, ok)
+}
@@ -0,0 +1,48 @@
+// Package http provides HTTP delivery layer using Gin.
+package http
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/josimar-silva/gwaihir/internal/infrastructure"
+)
+
+// Deprecation and removal dates of the unversioned API paths.
+var (
+	LegacyPathsDeprecatedAt = time.Date(2026, time.October, 18, 0, 0, 0, 0, time.UTC)
+	LegacyPathsSunsetAt     = time.Date(2027, time.April, 30, 0, 0, 0, 0, time.UTC)
+)
+
+// DeprecatedAliasMiddleware marks responses of routes kept as aliases of the routes
+// under successorPrefix as deprecated. It sets the Deprecation (RFC 9745) and Sunset
+// (RFC 8594) headers, links to the successor route and counts the request by route.
+func DeprecatedAliasMiddleware(successorPrefix string, metrics *infrastructure.Metrics) gin.HandlerFunc {
+	deprecation := "@" + strconv.FormatInt(LegacyPathsDeprecatedAt.Unix(), 10)
+	sunset := LegacyPathsSunsetAt.Format(http.TimeFormat)
+
+	return func(c *gin.Context) {
+		c.Header("Deprecation", deprecation)
+		c.Header("Sunset", sunset)
+		c.Header("Link", "<"+successorPrefix+successorPath(c)+`>; rel="successor-version"`)
+
+		if metrics != nil && metrics.LegacyAPIRequests != nil {
+			metrics.LegacyAPIRequests.WithLabelValues(c.FullPath()).Inc()
+		}
+
+		c.Next()
+	}
+}
+
+// successorPath returns the request path, with the query string, for use in the Link header.
+func successorPath(c *gin.Context) string {
+	path := c.Request.URL.EscapedPath()
+	if query := c.Request.URL.RawQuery; query != "" {
+		path += "?" + query
+	}
+	return strings.ReplaceAll(path, ">", "%3E")
+}
@@ -0,0 +1,81 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/josimar-silva/gwaihir/internal/infrastructure"
+)
+
+func TestAPIV1_RoutesAreNotDeprecated(t *testing.T) {
+	handler, _, _ := newHandlerForTesting(nil)
+	router := NewRouterWithAuth(handler, testAPIKey)
+
+	w := doMachineRequest(router, http.MethodGet, "/api/v1/machines/saruman", testAPIKey, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get("Deprecation"))
+	assert.Empty(t, w.Header().Get("Sunset"))
+	assert.Zero(t, testutil.CollectAndCount(handler.metrics.LegacyAPIRequests))
+}
+
+func TestLegacyAliases_AreDeprecated(t *testing.T) {
+	handler, _, sender := newHandlerForTesting(nil)
+	router := NewRouterWithAuth(handler, testAPIKey)
+
+	w := doMachineRequest(router, http.MethodPost, "/wol", testAPIKey, WakeRequest{MachineID: "saruman"})
+	require.Equal(t, http.StatusAccepted, w.Code)
+	assert.Equal(t, 1, sender.callCount)
+
+	assert.Equal(t, "@1792281600", w.Header().Get("Deprecation"))
+	assert.Equal(t, "Fri, 30 Apr 2027 00:00:00 GMT", w.Header().Get("Sunset"))
+	assert.Equal(t, `</api/v1/wol>; rel="successor-version"`, w.Header().Get("Link"))
+	assert.Equal(t, 1.0, testutil.ToFloat64(handler.metrics.LegacyAPIRequests.WithLabelValues("/wol")))
+
+	w = doMachineRequest(router, http.MethodGet, "/machines/saruman?fields=all", testAPIKey, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `</api/v1/machines/saruman?fields=all>; rel="successor-version"`, w.Header().Get("Link"))
+	assert.Equal(t, 1.0, testutil.ToFloat64(handler.metrics.LegacyAPIRequests.WithLabelValues("/machines/:id")))
+}
+
+func TestDeprecatedAliasMiddleware_WithoutLegacyCounter(t *testing.T) {
+	gin.SetMode(gin.ReleaseMode)
+	router := gin.New()
+	router.GET("/machines", DeprecatedAliasMiddleware("/api/v1", &infrastructure.Metrics{}), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	var w *httptest.ResponseRecorder
+	require.NotPanics(t, func() { w = doMachineRequest(router, http.MethodGet, "/machines", "", nil) })
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotEmpty(t, w.Header().Get("Deprecation"))
+}
+
+func TestLegacyAliases_RequireAuthentication(t *testing.T) {
+	handler, _, _ := newHandlerForTesting(nil)
+	router := NewRouterWithAuth(handler, testAPIKey)
+
+	for _, path := range []string{"/machines", "/api/v1/machines"} {
+		w := doMachineRequest(router, http.MethodGet, path, "", nil)
+		assert.Equal(t, http.StatusUnauthorized, w.Code, path)
+	}
+}
+
+func TestProbesAndMetrics_StayAtRoot(t *testing.T) {
+	handler, _, _ := newHandlerForTesting(nil)
+	router := NewRouterWithAuth(handler, testAPIKey)
+
+	for _, path := range []string{"/live", "/ready", "/metrics"} {
+		w := doMachineRequest(router, http.MethodGet, path, "", nil)
+		assert.Equal(t, http.StatusOK, w.Code, path)
+		assert.Empty(t, w.Header().Get("Deprecation"), path)
+	}
+
+	w := doMachineRequest(router, http.MethodGet, "/api/v1/live", "", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
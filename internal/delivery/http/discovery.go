@@ -0,0 +1,128 @@
+// Package http provides HTTP delivery layer.
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/josimar-silva/gwaihir/internal/domain"
+	"github.com/josimar-silva/gwaihir/internal/infrastructure"
+	"github.com/josimar-silva/gwaihir/internal/usecase"
+)
+
+// NeighborResponse represents a host found in the neighbor table.
+type NeighborResponse struct {
+	IP          string `json:"ip"`
+	MAC         string `json:"mac"`
+	Interface   string `json:"interface"`
+	Broadcast   string `json:"broadcast,omitempty"`
+	Allowlisted bool   `json:"allowlisted"`
+	MachineID   string `json:"machine_id,omitempty"`
+}
+
+// NeighborsResponse represents the list of discovered neighbors.
+type NeighborsResponse struct {
+	Neighbors []NeighborResponse `json:"neighbors"`
+	Count     int                `json:"count"`
+}
+
+// AdoptNeighborRequest represents the JSON request to add a neighbor to the allowlist.
+// Name defaults to the ID and Broadcast to the broadcast address of the network the
+// neighbor was seen on.
+type AdoptNeighborRequest struct {
+	ID        string `json:"id" binding:"required"`
+	Name      string `json:"name"`
+	Broadcast string `json:"broadcast"`
+}
+
+// ListNeighbors handles GET /discovery/neighbors requests.
+func (h *Handler) ListNeighbors(c *gin.Context) {
+	neighbors, err := h.discovery.ListNeighbors()
+	if err != nil {
+		h.writeDiscoveryError(c, err)
+		return
+	}
+
+	response := NeighborsResponse{
+		Neighbors: make([]NeighborResponse, 0, len(neighbors)),
+		Count:     len(neighbors),
+	}
+	for _, neighbor := range neighbors {
+		response.Neighbors = append(response.Neighbors, toNeighborResponse(neighbor))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// AdoptNeighbor handles POST /discovery/neighbors/:mac/adopt requests.
+func (h *Handler) AdoptNeighbor(c *gin.Context) {
+	var req AdoptNeighborRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.rejectMachineRequest(c, domain.AuditActionMachineCreate, req.ID, &req, err)
+		return
+	}
+
+	neighbor, err := h.discovery.FindNeighbor(c.Param("mac"))
+	if err != nil {
+		h.writeDiscoveryError(c, err)
+		return
+	}
+
+	if neighbor.Allowlisted() {
+		h.recordAudit(c, domain.AuditActionMachineCreate, req.ID, domain.AuditOutcomeConflict)
+		writeProblem(c, domain.CodeMachineAlreadyExists, "Neighbor is already allowlisted as machine "+neighbor.MachineID)
+		return
+	}
+
+	machine := &domain.Machine{
+		ID:        req.ID,
+		Name:      req.Name,
+		MAC:       neighbor.MAC,
+		Broadcast: req.Broadcast,
+	}
+	if machine.Name == "" {
+		machine.Name = machine.ID
+	}
+	if machine.Broadcast == "" {
+		machine.Broadcast = neighbor.Broadcast
+	}
+	if machine.Broadcast == "" {
+		h.rejectMachineRequest(c, domain.AuditActionMachineCreate, req.ID, &req, &invalidRequestError{detail: "broadcast is required: it cannot be determined for this neighbor"})
+		return
+	}
+
+	if err := h.machines.CreateMachine(c.Request.Context(), machine); err != nil {
+		h.writeMachineError(c, domain.AuditActionMachineCreate, machine.ID, err)
+		return
+	}
+
+	h.logMachineChange(c, "Neighbor adopted via API", domain.AuditActionMachineCreate, machine.ID)
+	c.JSON(http.StatusCreated, machine)
+}
+
+// writeDiscoveryError maps neighbor discovery errors to HTTP responses.
+func (h *Handler) writeDiscoveryError(c *gin.Context, err error) {
+	if errors.Is(err, domain.ErrNeighborNotFound) {
+		writeProblem(c, domain.CodeNeighborNotFound, "Neighbor not found")
+		return
+	}
+
+	h.requestLogger(c).Error("Failed to read neighbor table",
+		infrastructure.String("request_id", GetRequestID(c)),
+		infrastructure.Any("error", err),
+	)
+	writeProblem(c, CodeInternal, "Failed to read neighbor table")
+}
+
+func toNeighborResponse(neighbor usecase.DiscoveredNeighbor) NeighborResponse {
+	return NeighborResponse{
+		IP:          neighbor.IP,
+		MAC:         neighbor.MAC,
+		Interface:   neighbor.Interface,
+		Broadcast:   neighbor.Broadcast,
+		Allowlisted: neighbor.Allowlisted(),
+		MachineID:   neighbor.MachineID,
+	}
+}
@@ -0,0 +1,137 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/josimar-silva/gwaihir/internal/config"
+	"github.com/josimar-silva/gwaihir/internal/domain"
+	"github.com/josimar-silva/gwaihir/internal/infrastructure"
+	"github.com/josimar-silva/gwaihir/internal/repository"
+	"github.com/josimar-silva/gwaihir/internal/usecase"
+)
+
+const testARPTable = `IP address       HW type     Flags       HW address            Mask     Device
+192.168.1.10     0x1         0x2         aa:bb:cc:dd:ee:ff     *        eth0
+192.168.1.20     0x1         0x2         11:22:33:44:55:66     *        eth0
+192.168.1.30     0x1         0x0         00:00:00:00:00:00     *        eth0
+`
+
+func newDiscoveryRouter(t *testing.T, management bool) (*gin.Engine, *mockAuditLog) {
+	t.Helper()
+
+	dir := t.TempDir()
+	arpPath := filepath.Join(dir, "arp")
+	require.NoError(t, os.WriteFile(arpPath, []byte(testARPTable), 0o600))
+
+	cfg := &config.Config{
+		Authentication: config.AuthenticationConfig{
+			APIKey:      testAPIKey,
+			AdminAPIKey: testAdminAPIKey,
+		},
+		Machines: []config.MachineConfig{
+			{ID: "saruman", Name: "Saruman Server", MAC: "AA:BB:CC:DD:EE:FF", Broadcast: "192.168.1.255"},
+		},
+	}
+	repo, err := repository.NewFileMachineRepository(cfg, filepath.Join(dir, "machines.yaml"))
+	require.NoError(t, err)
+
+	logger := infrastructure.NewLogger("text", "debug")
+	metrics, _ := infrastructure.NewMetrics()
+	auditLog := &mockAuditLog{}
+	handler := NewHandler(usecase.NewWoLUseCase(repo, &mockPacketSender{}, logger, metrics), logger, metrics, "0.1.0", "2024-01-01T00:00:00Z", "abc123").
+		WithAuditLog(auditLog).
+		WithDiscovery(usecase.NewDiscoveryUseCase(infrastructure.NewARPTable(arpPath), repo, logger)).
+		WithEventStream(infrastructure.NewEventHistory(infrastructure.DefaultEventHistorySize))
+	if management {
+		handler.WithMachineManagement(usecase.NewMachineUseCase(repo, logger, metrics))
+	}
+
+	return NewRouterWithAuthAndConfig(handler, testAPIKey, cfg), auditLog
+}
+
+func TestListNeighbors(t *testing.T) {
+	router, _ := newDiscoveryRouter(t, false)
+
+	w := doMachineRequest(router, http.MethodGet, "/discovery/neighbors", testAPIKey, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var response NeighborsResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, 2, response.Count)
+	assert.Equal(t, NeighborResponse{
+		IP: "192.168.1.10", MAC: "AA:BB:CC:DD:EE:FF", Interface: "eth0", Allowlisted: true, MachineID: "saruman",
+	}, response.Neighbors[0])
+	assert.False(t, response.Neighbors[1].Allowlisted)
+
+	w = doMachineRequest(router, http.MethodGet, "/discovery/neighbors", "", nil)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestListNeighbors_NotRegisteredWithoutDiscovery(t *testing.T) {
+	router, _ := newMachineManagementRouter(t)
+
+	w := doMachineRequest(router, http.MethodGet, "/discovery/neighbors", testAPIKey, nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestAdoptNeighbor(t *testing.T) {
+	router, auditLog := newDiscoveryRouter(t, true)
+
+	w := doMachineRequest(router, http.MethodPost, "/discovery/neighbors/11-22-33-44-55-66/adopt", testAdminAPIKey, AdoptNeighborRequest{
+		ID: "gandalf", Broadcast: "192.168.1.255",
+	})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	var machine domain.Machine
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &machine))
+	assert.Equal(t, "gandalf", machine.Name)
+	assert.Equal(t, "11:22:33:44:55:66", machine.MAC)
+
+	w = doMachineRequest(router, http.MethodGet, "/discovery/neighbors", testAPIKey, nil)
+	var response NeighborsResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "gandalf", response.Neighbors[1].MachineID)
+
+	assert.Contains(t, auditLog.actions(), domain.AuditActionMachineCreate+":"+domain.AuditOutcomeSuccess)
+}
+
+func TestAdoptNeighbor_Errors(t *testing.T) {
+	router, _ := newDiscoveryRouter(t, true)
+
+	tests := []struct {
+		name   string
+		path   string
+		apiKey string
+		body   any
+		status int
+	}{
+		{"non-admin key", "/discovery/neighbors/11:22:33:44:55:66/adopt", testAPIKey, AdoptNeighborRequest{ID: "gandalf", Broadcast: "192.168.1.255"}, http.StatusForbidden},
+		{"missing id", "/discovery/neighbors/11:22:33:44:55:66/adopt", testAdminAPIKey, AdoptNeighborRequest{}, http.StatusBadRequest},
+		{"unknown neighbor", "/discovery/neighbors/de:ad:be:ef:00:01/adopt", testAdminAPIKey, AdoptNeighborRequest{ID: "gandalf", Broadcast: "192.168.1.255"}, http.StatusNotFound},
+		{"already allowlisted", "/discovery/neighbors/aa:bb:cc:dd:ee:ff/adopt", testAdminAPIKey, AdoptNeighborRequest{ID: "gandalf", Broadcast: "192.168.1.255"}, http.StatusConflict},
+		{"no broadcast", "/discovery/neighbors/11:22:33:44:55:66/adopt", testAdminAPIKey, AdoptNeighborRequest{ID: "gandalf"}, http.StatusBadRequest},
+		{"duplicate id", "/discovery/neighbors/11:22:33:44:55:66/adopt", testAdminAPIKey, AdoptNeighborRequest{ID: "saruman", Broadcast: "192.168.1.255"}, http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := doMachineRequest(router, http.MethodPost, tt.path, tt.apiKey, tt.body)
+			assert.Equal(t, tt.status, w.Code, w.Body.String())
+		})
+	}
+}
+
+func TestAdoptNeighbor_NotRegisteredWithoutManagement(t *testing.T) {
+	router, _ := newDiscoveryRouter(t, false)
+
+	w := doMachineRequest(router, http.MethodPost, "/discovery/neighbors/11:22:33:44:55:66/adopt", testAdminAPIKey, AdoptNeighborRequest{ID: "gandalf"})
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,117 @@
+// Package http provides HTTP delivery layer.
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/josimar-silva/gwaihir/internal/infrastructure"
+)
+
+// LastEventIDHeader is the header an EventSource sends when reconnecting, carrying
+// the ID of the last event it received.
+const LastEventIDHeader = "Last-Event-ID"
+
+// eventKeepAliveInterval is how often an idle event stream sends a comment, so
+// proxies and clients do not time out the connection.
+const eventKeepAliveInterval = 15 * time.Second
+
+// StreamEvents handles GET /events requests, streaming domain events as
+// server-sent events. A client reconnecting with Last-Event-ID first receives the
+// events it missed that are still in the history.
+func (h *Handler) StreamEvents(c *gin.Context) {
+	requestID := GetRequestID(c)
+
+	lastEventID, err := parseLastEventID(c.GetHeader(LastEventIDHeader))
+	if err != nil {
+		h.requestLogger(c).Warn("Invalid event stream request",
+			infrastructure.String("request_id", requestID),
+			infrastructure.Any("error", err),
+		)
+		writeProblem(c, CodeInvalidRequest, requestErrorDetail(nil, err))
+		return
+	}
+
+	replay, live, unsubscribe := h.events.Subscribe(lastEventID)
+	defer unsubscribe()
+
+	// Streams outlive the write timeout of the server.
+	if err := http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{}); err != nil {
+		h.requestLogger(c).Debug("Failed to clear event stream write deadline",
+			infrastructure.String("request_id", requestID),
+			infrastructure.Any("error", err),
+		)
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Writer.WriteHeaderNow()
+	c.Writer.Flush()
+
+	h.requestLogger(c).Info("Event stream opened",
+		infrastructure.String("request_id", requestID),
+		infrastructure.Any("last_event_id", lastEventID),
+		infrastructure.Int("replayed", len(replay)),
+	)
+
+	for _, event := range replay {
+		if err := writeEvent(c.Writer, event); err != nil {
+			return
+		}
+	}
+	c.Writer.Flush()
+
+	keepAlive := time.NewTicker(eventKeepAliveInterval)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			h.requestLogger(c).Info("Event stream closed", infrastructure.String("request_id", requestID))
+			return
+		case event, ok := <-live:
+			// The history disconnects subscribers that fall behind and closes every
+			// stream on shutdown; clients reconnect with their last event ID.
+			if !ok {
+				h.requestLogger(c).Info("Event stream ended by server", infrastructure.String("request_id", requestID))
+				return
+			}
+			if err := writeEvent(c.Writer, event); err != nil {
+				return
+			}
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(c.Writer, ": keepalive\n\n"); err != nil {
+				return
+			}
+		}
+		c.Writer.Flush()
+	}
+}
+
+// writeEvent writes event as a server-sent event named after its type.
+func writeEvent(w gin.ResponseWriter, event infrastructure.RecordedEvent) error {
+	data, err := json.Marshal(event.Event)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+	return err
+}
+
+func parseLastEventID(value string) (uint64, error) {
+	if value == "" {
+		return 0, nil
+	}
+	id, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return 0, &invalidRequestError{detail: LastEventIDHeader + " must be a non-negative integer", value: value}
+	}
+	return id, nil
+}
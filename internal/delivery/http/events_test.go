@@ -0,0 +1,147 @@
+package http
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/josimar-silva/gwaihir/internal/domain"
+	"github.com/josimar-silva/gwaihir/internal/infrastructure"
+)
+
+// sseFrame is a server-sent event read from a stream.
+type sseFrame struct {
+	id    string
+	event string
+	data  string
+}
+
+func newEventsServer(t *testing.T) (*httptest.Server, *infrastructure.EventHistory) {
+	t.Helper()
+	history := infrastructure.NewEventHistory(8)
+	handler, _, _ := newHandlerForTesting(nil)
+	handler.WithEventStream(history)
+
+	server := httptest.NewServer(NewRouterWithAuth(handler, testAPIKey))
+	t.Cleanup(func() {
+		history.Close()
+		server.Close()
+	})
+	return server, history
+}
+
+func openEventStream(t *testing.T, ctx context.Context, url, apiKey, lastEventID string) *http.Response {
+	t.Helper()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url+"/api/v1/events", nil)
+	require.NoError(t, err)
+	req.Header.Set("X-API-Key", apiKey)
+	if lastEventID != "" {
+		req.Header.Set(LastEventIDHeader, lastEventID)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = resp.Body.Close()
+	})
+	return resp
+}
+
+func readFrame(t *testing.T, reader *bufio.Reader) sseFrame {
+	t.Helper()
+	var frame sseFrame
+	for {
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+		line = strings.TrimSuffix(line, "\n")
+		switch {
+		case line == "":
+			return frame
+		case strings.HasPrefix(line, "id: "):
+			frame.id = strings.TrimPrefix(line, "id: ")
+		case strings.HasPrefix(line, "event: "):
+			frame.event = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			frame.data = strings.TrimPrefix(line, "data: ")
+		}
+	}
+}
+
+func TestStreamEvents_ResumesAndStreamsLive(t *testing.T) {
+	server, history := newEventsServer(t)
+	wokenAt := time.Date(2026, 2, 9, 15, 30, 45, 0, time.UTC)
+	history.Publish(domain.Event{Type: domain.EventWakeRequested, MachineID: "saruman", Time: wokenAt})
+	history.Publish(domain.Event{Type: domain.EventWakeSent, MachineID: "saruman", MAC: "AA:BB:CC:DD:EE:FF", Broadcast: "192.168.1.255", Time: wokenAt})
+
+	resp := openEventStream(t, context.Background(), server.URL, testAPIKey, "1")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+	assert.Equal(t, "no-cache", resp.Header.Get("Cache-Control"))
+	reader := bufio.NewReader(resp.Body)
+
+	replayed := readFrame(t, reader)
+	assert.Equal(t, sseFrame{
+		id:    "2",
+		event: domain.EventWakeSent,
+		data:  `{"type":"wake.sent","machine_id":"saruman","mac":"AA:BB:CC:DD:EE:FF","broadcast":"192.168.1.255","time":"2026-02-09T15:30:45Z"}`,
+	}, replayed)
+
+	history.Publish(domain.Event{Type: domain.EventConfigReloadFailed, Detail: "invalid machine", Time: wokenAt})
+	live := readFrame(t, reader)
+	assert.Equal(t, "3", live.id)
+	assert.Equal(t, domain.EventConfigReloadFailed, live.event)
+
+	var event domain.Event
+	require.NoError(t, json.Unmarshal([]byte(live.data), &event))
+	assert.Equal(t, "invalid machine", event.Detail)
+}
+
+func TestStreamEvents_EndsWhenHistoryCloses(t *testing.T) {
+	server, history := newEventsServer(t)
+	resp := openEventStream(t, context.Background(), server.URL, testAPIKey, "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	history.Close()
+	_, err := io.ReadAll(resp.Body)
+	assert.NoError(t, err)
+}
+
+func TestStreamEvents_UnsubscribesOnDisconnect(t *testing.T) {
+	server, history := newEventsServer(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	resp := openEventStream(t, ctx, server.URL, testAPIKey, "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	cancel()
+
+	// Publishing to the closed stream must not block or panic.
+	for range infrastructure.DefaultEventBuffer + 1 {
+		history.Publish(domain.Event{Type: domain.EventWakeSent})
+	}
+}
+
+func TestStreamEvents_Errors(t *testing.T) {
+	server, _ := newEventsServer(t)
+
+	resp := openEventStream(t, context.Background(), server.URL, "", "")
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	resp = openEventStream(t, context.Background(), server.URL, testAPIKey, "latest")
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Equal(t, "application/problem+json", resp.Header.Get("Content-Type"))
+}
+
+func TestStreamEvents_NotRegisteredWithoutHistory(t *testing.T) {
+	handler, _, _ := newHandlerForTesting(nil)
+	router := NewRouterWithAuth(handler, testAPIKey)
+
+	w := doMachineRequest(router, http.MethodGet, "/api/v1/events", testAPIKey, nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
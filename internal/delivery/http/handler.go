@@ -4,7 +4,6 @@ package http
 import (
 	"errors"
 	"net/http"
-	"time"
 
 	"github.com/gin-gonic/gin"
 
@@ -18,6 +17,14 @@ type Handler struct {
 	wolUseCase *usecase.WoLUseCase
 	logger     *infrastructure.Logger
 	metrics    *infrastructure.Metrics
+	audit      domain.AuditLog
+	reload     *infrastructure.ReloadStatus
+	sources    *infrastructure.SourceStatus
+	machines   *usecase.MachineUseCase
+	discovery  *usecase.DiscoveryUseCase
+	apiKeys    *APIKeySet
+	lockout    *AuthLockout
+	events     *infrastructure.EventHistory
 	version    string
 	buildTime  string
 	gitCommit  string
@@ -35,16 +42,69 @@ func NewHandler(wolUseCase *usecase.WoLUseCase, logger *infrastructure.Logger, m
 	}
 }
 
+// WithAuditLog enables recording of wake attempts and machine reads to the audit trail.
+func (h *Handler) WithAuditLog(audit domain.AuditLog) *Handler {
+	h.audit = audit
+	return h
+}
+
+// WithReloadStatus enables reporting of configuration reload outcomes in health checks.
+func (h *Handler) WithReloadStatus(status *infrastructure.ReloadStatus) *Handler {
+	h.reload = status
+	return h
+}
+
+// WithSourceStatus enables reporting of machine source imports in health checks.
+func (h *Handler) WithSourceStatus(status *infrastructure.SourceStatus) *Handler {
+	h.sources = status
+	return h
+}
+
+// WithMachineManagement enables the runtime machine management endpoints.
+func (h *Handler) WithMachineManagement(machines *usecase.MachineUseCase) *Handler {
+	h.machines = machines
+	return h
+}
+
+// WithDiscovery enables the neighbor discovery endpoints.
+func (h *Handler) WithDiscovery(discovery *usecase.DiscoveryUseCase) *Handler {
+	h.discovery = discovery
+	return h
+}
+
+// WithAPIKeys makes the router authenticate against keys, which may be replaced at
+// runtime. The router still decides from the configured keys which endpoints require
+// authentication.
+func (h *Handler) WithAPIKeys(keys *APIKeySet) *Handler {
+	h.apiKeys = keys
+	return h
+}
+
+// WithAuthLockout makes the router track authentication failures in lockout, so that
+// it can be shared with the other APIs. Without it the router creates its own from
+// the configuration.
+func (h *Handler) WithAuthLockout(lockout *AuthLockout) *Handler {
+	h.lockout = lockout
+	return h
+}
+
+// WithEventStream enables GET /events, streaming the events recorded in history.
+func (h *Handler) WithEventStream(history *infrastructure.EventHistory) *Handler {
+	h.events = history
+	return h
+}
+
+// requestLogger returns the logger for the request, whose records carry the trace_id
+// and span_id of the request span.
+func (h *Handler) requestLogger(c *gin.Context) *infrastructure.Logger {
+	return h.logger.WithTrace(c.Request.Context())
+}
+
 // WakeRequest represents the JSON request to wake a machine.
 type WakeRequest struct {
 	MachineID string `json:"machine_id" binding:"required"`
 }
 
-// ErrorResponse represents an error response.
-type ErrorResponse struct {
-	Error string `json:"error"`
-}
-
 // SuccessResponse represents a success response.
 type SuccessResponse struct {
 	Message string `json:"message"`
@@ -59,53 +119,46 @@ type VersionResponse struct {
 
 // Wake handles POST /wol requests.
 func (h *Handler) Wake(c *gin.Context) {
-	startTime := time.Now()
 	requestID := GetRequestID(c)
+	logger := h.requestLogger(c)
 
 	var req WakeRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		h.logger.Warn("Invalid WoL request",
+		logger.Warn("Invalid WoL request",
 			infrastructure.String("request_id", requestID),
 			infrastructure.Any("error", err),
 		)
-		c.JSON(http.StatusBadRequest, ErrorResponse{
-			Error: "Invalid request: " + err.Error(),
-		})
-		h.metrics.RequestDuration.Observe(time.Since(startTime).Seconds())
+		h.recordAudit(c, domain.AuditActionWake, req.MachineID, domain.AuditOutcomeInvalid)
+		writeProblem(c, CodeInvalidRequest, requestErrorDetail(&req, err))
 		return
 	}
 
-	if err := h.wolUseCase.SendWakePacket(req.MachineID); err != nil {
-		duration := time.Since(startTime).Seconds()
-		h.metrics.RequestDuration.Observe(duration)
-
+	if err := h.wolUseCase.SendWakePacket(c.Request.Context(), req.MachineID); err != nil {
 		if errors.Is(err, domain.ErrMachineNotFound) {
-			h.logger.Warn("Machine not found",
+			logger.Warn("Machine not found",
 				infrastructure.String("request_id", requestID),
 				infrastructure.String("machine_id", req.MachineID),
 			)
-			c.JSON(http.StatusNotFound, ErrorResponse{
-				Error: "Machine not found or not allowed",
-			})
+			h.recordAudit(c, domain.AuditActionWake, req.MachineID, domain.AuditOutcomeNotFound)
+			writeProblem(c, domain.CodeOf(err), "Machine "+req.MachineID+" is not in the allowlist")
 			return
 		}
 
-		h.logger.Error("Failed to send WoL packet",
+		logger.Error("Failed to send WoL packet",
 			infrastructure.String("request_id", requestID),
 			infrastructure.String("machine_id", req.MachineID),
 			infrastructure.Any("error", err),
 		)
-		c.JSON(http.StatusInternalServerError, ErrorResponse{
-			Error: "Failed to send WoL packet: " + err.Error(),
-		})
+		h.recordAudit(c, domain.AuditActionWake, req.MachineID, domain.AuditOutcomeFailure)
+		writeProblem(c, domain.CodeWakeFailed, "The magic packet could not be sent")
 		return
 	}
 
-	h.logger.Info("WoL packet sent successfully",
+	logger.Info("WoL packet sent successfully",
 		infrastructure.String("request_id", requestID),
 		infrastructure.String("machine_id", req.MachineID),
 	)
-	h.metrics.RequestDuration.Observe(time.Since(startTime).Seconds())
+	h.recordAudit(c, domain.AuditActionWake, req.MachineID, domain.AuditOutcomeSuccess)
 
 	c.JSON(http.StatusAccepted, SuccessResponse{
 		Message: "WoL packet sent successfully",
@@ -114,71 +167,61 @@ func (h *Handler) Wake(c *gin.Context) {
 
 // ListMachines handles GET /machines requests.
 func (h *Handler) ListMachines(c *gin.Context) {
-	startTime := time.Now()
 	requestID := GetRequestID(c)
 
 	machines, err := h.wolUseCase.ListMachines()
-	duration := time.Since(startTime).Seconds()
-	h.metrics.RequestDuration.Observe(duration)
-
 	if err != nil {
-		h.logger.Error("Failed to retrieve machines",
+		h.requestLogger(c).Error("Failed to retrieve machines",
 			infrastructure.String("request_id", requestID),
 			infrastructure.Any("error", err),
 		)
-		c.JSON(http.StatusInternalServerError, ErrorResponse{
-			Error: "Failed to retrieve machines: " + err.Error(),
-		})
+		writeProblem(c, CodeInternal, "Failed to retrieve machines")
 		return
 	}
 
 	h.metrics.MachinesListed.Inc()
-	h.logger.Info("Machines list retrieved",
+	h.requestLogger(c).Info("Machines list retrieved",
 		infrastructure.String("request_id", requestID),
 		infrastructure.Int("count", len(machines)),
 	)
+	h.recordAudit(c, domain.AuditActionMachineList, "", domain.AuditOutcomeSuccess)
 
 	c.JSON(http.StatusOK, machines)
 }
 
 // GetMachine handles GET /machines/:id requests.
 func (h *Handler) GetMachine(c *gin.Context) {
-	startTime := time.Now()
 	requestID := GetRequestID(c)
 	machineID := c.Param("id")
 
 	machine, err := h.wolUseCase.GetMachine(machineID)
-	duration := time.Since(startTime).Seconds()
-	h.metrics.RequestDuration.Observe(duration)
-
 	if err != nil {
 		if errors.Is(err, domain.ErrMachineNotFound) {
-			h.logger.Warn("Machine not found",
+			h.requestLogger(c).Warn("Machine not found",
 				infrastructure.String("request_id", requestID),
 				infrastructure.String("machine_id", machineID),
 			)
-			c.JSON(http.StatusNotFound, ErrorResponse{
-				Error: "Machine not found",
-			})
+			h.recordAudit(c, domain.AuditActionMachineRead, machineID, domain.AuditOutcomeNotFound)
+			writeProblem(c, domain.CodeMachineNotFound, "Machine "+machineID+" not found")
 			return
 		}
 
-		h.logger.Error("Failed to retrieve machine",
+		h.requestLogger(c).Error("Failed to retrieve machine",
 			infrastructure.String("request_id", requestID),
 			infrastructure.String("machine_id", machineID),
 			infrastructure.Any("error", err),
 		)
-		c.JSON(http.StatusInternalServerError, ErrorResponse{
-			Error: "Failed to retrieve machine: " + err.Error(),
-		})
+		h.recordAudit(c, domain.AuditActionMachineRead, machineID, domain.AuditOutcomeFailure)
+		writeProblem(c, CodeInternal, "Failed to retrieve machine")
 		return
 	}
 
-	h.metrics.MachinesRetrieved.Inc()
-	h.logger.Info("Machine retrieved",
+	h.metrics.MachinesRetrieved.WithLabelValues(h.metrics.MachineLabel(machine.ID)).Inc()
+	h.requestLogger(c).Info("Machine retrieved",
 		infrastructure.String("request_id", requestID),
 		infrastructure.String("machine_id", machineID),
 	)
+	h.recordAudit(c, domain.AuditActionMachineRead, machineID, domain.AuditOutcomeSuccess)
 
 	c.JSON(http.StatusOK, machine)
 }
@@ -186,7 +229,7 @@ func (h *Handler) GetMachine(c *gin.Context) {
 // Health handles GET /health requests.
 func (h *Handler) Health(c *gin.Context) {
 	requestID := GetRequestID(c)
-	h.logger.Debug("Health check requested",
+	h.requestLogger(c).Debug("Health check requested",
 		infrastructure.String("request_id", requestID),
 	)
 	c.JSON(http.StatusOK, gin.H{
@@ -197,7 +240,7 @@ func (h *Handler) Health(c *gin.Context) {
 // Version handles GET /version requests.
 func (h *Handler) Version(c *gin.Context) {
 	requestID := GetRequestID(c)
-	h.logger.Debug("Version info requested",
+	h.requestLogger(c).Debug("Version info requested",
 		infrastructure.String("request_id", requestID),
 	)
 	c.JSON(http.StatusOK, VersionResponse{
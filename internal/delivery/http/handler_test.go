@@ -11,7 +11,6 @@ import (
 	"testing"
 
 	"github.com/gin-gonic/gin"
-	"github.com/prometheus/client_golang/prometheus"
 
 	"github.com/josimar-silva/gwaihir/internal/domain"
 	"github.com/josimar-silva/gwaihir/internal/infrastructure"
@@ -53,7 +52,7 @@ type mockPacketSender struct {
 	shouldFailCount int
 }
 
-func (m *mockPacketSender) SendMagicPacket(mac, broadcast string) error {
+func (m *mockPacketSender) SendMagicPacket(_ context.Context, mac, broadcast string) error {
 	m.callCount++
 	m.lastMac = mac
 	m.lastBroadcast = broadcast
@@ -87,7 +86,6 @@ func newHandlerForTesting(machines map[string]*domain.Machine) (*Handler, *mockR
 	repo := &mockRepository{machines: machines}
 	sender := &mockPacketSender{}
 	logger := infrastructure.NewLogger("text", "debug")
-	prometheus.DefaultRegisterer = prometheus.NewRegistry()
 	metrics, _ := infrastructure.NewMetrics()
 	wolUseCase := usecase.NewWoLUseCase(repo, sender, logger, metrics)
 	handler := NewHandler(wolUseCase, logger, metrics, "0.1.0", "2024-01-01T00:00:00Z", "abc123")
@@ -122,9 +120,9 @@ func TestHandler_Response_Types(t *testing.T) {
 		t.Errorf("Expected SuccessResponse.Message to be 'test', got %s", successResp.Message)
 	}
 
-	errorResp := ErrorResponse{Error: testResponseError}
-	if errorResp.Error != testResponseError {
-		t.Errorf("Expected ErrorResponse.Error to be '%s', got %s", testResponseError, errorResp.Error)
+	problem := NewProblem(CodeInvalidRequest, testResponseError)
+	if problem.Detail != testResponseError || problem.Status != http.StatusBadRequest {
+		t.Errorf("Expected a 400 problem with detail '%s', got %+v", testResponseError, problem)
 	}
 
 	versionResp := VersionResponse{
@@ -249,12 +247,12 @@ func TestHTTP_Wake_MachineNotFound(t *testing.T) {
 		t.Errorf("Expected status %d, got %d", http.StatusNotFound, w.Code)
 	}
 
-	var resp ErrorResponse
+	var resp ProblemDetails
 	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
 		t.Errorf("Failed to parse response: %v", err)
 	}
-	if resp.Error != "Machine not found or not allowed" {
-		t.Errorf("Expected not found error, got %s", resp.Error)
+	if resp.Code != domain.CodeMachineNotAllowed {
+		t.Errorf("Expected code %s, got %s", domain.CodeMachineNotAllowed, resp.Code)
 	}
 }
 
@@ -362,6 +360,32 @@ func TestHTTP_GetMachine(t *testing.T) {
 	}
 }
 
+func TestHTTP_GetMachine_IncludesSource(t *testing.T) {
+	handler, _, _ := newHandlerForTesting(map[string]*domain.Machine{
+		"gandalf": {
+			ID:        "gandalf",
+			Name:      "Gandalf Server",
+			MAC:       "AA:BB:CC:DD:EE:01",
+			Broadcast: "192.168.1.255",
+			Source:    "/etc/gwaihir/machines.d/wizards.yaml",
+		},
+	})
+	router := NewRouter(handler)
+
+	req := httptest.NewRequestWithContext(context.Background(), http.MethodGet, "/machines/gandalf", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	var machine domain.Machine
+	if err := json.Unmarshal(w.Body.Bytes(), &machine); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+	if machine.Source != "/etc/gwaihir/machines.d/wizards.yaml" {
+		t.Errorf("Expected source of the defining file, got %q", machine.Source)
+	}
+}
+
 func TestHTTP_GetMachine_NotFound(t *testing.T) {
 	handler, _, _ := newHandlerForTesting(nil)
 	router := NewRouter(handler)
@@ -375,12 +399,12 @@ func TestHTTP_GetMachine_NotFound(t *testing.T) {
 		t.Errorf("Expected status %d, got %d", http.StatusNotFound, w.Code)
 	}
 
-	var resp ErrorResponse
+	var resp ProblemDetails
 	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
 		t.Errorf("Failed to parse response: %v", err)
 	}
-	if resp.Error != "Machine not found" {
-		t.Errorf("Expected 'Machine not found', got %s", resp.Error)
+	if resp.Code != domain.CodeMachineNotFound || resp.Title != "Machine not found" {
+		t.Errorf("Expected a machine_not_found problem, got %+v", resp)
 	}
 }
 
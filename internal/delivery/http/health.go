@@ -19,9 +19,21 @@ type HealthCheckResponse struct {
 	Timestamp          string            `json:"timestamp"`
 	UptimeSeconds      int64             `json:"uptime_seconds"`
 	ConfiguredMachines int               `json:"configured_machines"`
+	LastConfigReload   string            `json:"last_config_reload,omitempty"`
+	Sources            []SourceHealth    `json:"sources,omitempty"`
 	Checks             map[string]string `json:"checks"`
 }
 
+// SourceHealth reports the machines imported from a lease or ethers source. The
+// health endpoint is public, so the path of the source and import errors are only
+// logged.
+type SourceHealth struct {
+	Name        string `json:"name"`
+	Format      string `json:"format"`
+	Machines    int    `json:"machines"`
+	LastUpdated string `json:"last_updated,omitempty"`
+}
+
 // HealthHandler tracks the start time for uptime calculation.
 type HealthHandler struct {
 	handler   *Handler
@@ -39,7 +51,7 @@ func NewHealthHandler(handler *Handler) *HealthHandler {
 // HealthCheckLive handles GET /live requests (liveness probe).
 func (h *HealthHandler) HealthCheckLive(c *gin.Context) {
 	requestID := GetRequestID(c)
-	h.handler.logger.Debug("Liveness probe requested",
+	h.handler.requestLogger(c).Debug("Liveness probe requested",
 		infrastructure.String("request_id", requestID),
 	)
 
@@ -55,7 +67,7 @@ func (h *HealthHandler) HealthCheckReady(c *gin.Context) {
 	// Get machine count from usecase
 	machines, err := h.handler.wolUseCase.ListMachines()
 	if err != nil {
-		h.handler.logger.Error("Readiness check failed: unable to list machines",
+		h.handler.requestLogger(c).Error("Readiness check failed: unable to list machines",
 			infrastructure.String("request_id", requestID),
 			infrastructure.Any("error", err),
 		)
@@ -67,7 +79,7 @@ func (h *HealthHandler) HealthCheckReady(c *gin.Context) {
 	}
 
 	if len(machines) == 0 {
-		h.handler.logger.Warn("Readiness check: no machines configured",
+		h.handler.requestLogger(c).Warn("Readiness check: no machines configured",
 			infrastructure.String("request_id", requestID),
 		)
 		c.JSON(http.StatusServiceUnavailable, gin.H{
@@ -77,7 +89,7 @@ func (h *HealthHandler) HealthCheckReady(c *gin.Context) {
 		return
 	}
 
-	h.handler.logger.Debug("Readiness check passed",
+	h.handler.requestLogger(c).Debug("Readiness check passed",
 		infrastructure.String("request_id", requestID),
 		infrastructure.Int("machines", len(machines)),
 	)
@@ -110,6 +122,37 @@ func (h *HealthHandler) HealthCheckFull(c *gin.Context) {
 		"machines":      machineCheckStatus,
 	}
 
+	lastConfigReload := ""
+	if h.handler.reload != nil {
+		// A failed reload keeps the previous configuration active, so it is only a warning.
+		checks["config_reload"] = "ok"
+		if h.handler.reload.LastError() != "" {
+			checks["config_reload"] = "warning"
+		}
+		lastConfigReload = h.handler.reload.LastSuccess().UTC().Format(time.RFC3339)
+	}
+
+	var sources []SourceHealth
+	if h.handler.sources != nil {
+		sources = make([]SourceHealth, 0)
+		// A failed import keeps the previously imported machines, so it is only a warning.
+		checks["machine_sources"] = "ok"
+		for _, state := range h.handler.sources.Snapshot() {
+			source := SourceHealth{
+				Name:     state.Name,
+				Format:   state.Format,
+				Machines: state.Machines,
+			}
+			if !state.UpdatedAt.IsZero() {
+				source.LastUpdated = state.UpdatedAt.UTC().Format(time.RFC3339)
+			}
+			if state.LastError != "" {
+				checks["machine_sources"] = "warning"
+			}
+			sources = append(sources, source)
+		}
+	}
+
 	response := HealthCheckResponse{
 		Status:             "healthy",
 		Version:            h.handler.version,
@@ -118,6 +161,8 @@ func (h *HealthHandler) HealthCheckFull(c *gin.Context) {
 		Timestamp:          time.Now().UTC().Format(time.RFC3339),
 		UptimeSeconds:      int64(time.Since(h.startTime).Seconds()),
 		ConfiguredMachines: machineCount,
+		LastConfigReload:   lastConfigReload,
+		Sources:            sources,
 		Checks:             checks,
 	}
 
@@ -127,7 +172,7 @@ func (h *HealthHandler) HealthCheckFull(c *gin.Context) {
 		statusCode = http.StatusServiceUnavailable
 	}
 
-	h.handler.logger.Debug("Health check requested",
+	h.handler.requestLogger(c).Debug("Health check requested",
 		infrastructure.String("request_id", requestID),
 		infrastructure.String("status", response.Status),
 	)
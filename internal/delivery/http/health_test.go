@@ -3,13 +3,17 @@ package http
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 
 	"github.com/josimar-silva/gwaihir/internal/domain"
+	"github.com/josimar-silva/gwaihir/internal/infrastructure"
 )
 
 func TestHealthCheckLive(t *testing.T) {
@@ -152,6 +156,49 @@ func TestHealthCheckFull_Success(t *testing.T) {
 	}
 }
 
+func TestHealthCheckFull_ConfigReloadStatus(t *testing.T) {
+	handler, _, _ := newHandlerForTesting(nil)
+	loadedAt := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	status := infrastructure.NewReloadStatus(loadedAt)
+	handler.WithReloadStatus(status)
+	healthHandler := NewHealthHandler(handler)
+
+	gin.SetMode(gin.ReleaseMode)
+	router := gin.New()
+	router.GET("/health", healthHandler.HealthCheckFull)
+
+	get := func() HealthCheckResponse {
+		req := httptest.NewRequestWithContext(context.Background(), http.MethodGet, "/health", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", w.Code)
+		}
+		var resp HealthCheckResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("Failed to parse response: %v", err)
+		}
+		return resp
+	}
+
+	resp := get()
+	if resp.Checks["config_reload"] != "ok" {
+		t.Errorf("Expected config_reload check to be 'ok', got %s", resp.Checks["config_reload"])
+	}
+	if resp.LastConfigReload != "2026-01-01T00:00:00Z" {
+		t.Errorf("Expected last_config_reload to be set, got %s", resp.LastConfigReload)
+	}
+
+	status.RecordFailure(errors.New("invalid configuration"))
+	resp = get()
+	if resp.Checks["config_reload"] != "warning" {
+		t.Errorf("Expected config_reload check to be 'warning', got %s", resp.Checks["config_reload"])
+	}
+	if resp.Status != "healthy" {
+		t.Errorf("Expected failed reload to keep status 'healthy', got %s", resp.Status)
+	}
+}
+
 func TestHealthCheckFull_NoMachines(t *testing.T) {
 	handler, _, _ := newHandlerForTesting(map[string]*domain.Machine{})
 	healthHandler := NewHealthHandler(handler)
@@ -216,3 +263,42 @@ func TestRouterHealthEndpoints(t *testing.T) {
 		})
 	}
 }
+
+func TestHealthCheckFull_MachineSources(t *testing.T) {
+	handler, _, _ := newHandlerForTesting(nil)
+	status := infrastructure.NewSourceStatus()
+	status.Register("office", infrastructure.LeaseFormatDnsmasq, "/var/lib/misc/dnsmasq.leases")
+	status.Register("lab", infrastructure.LeaseFormatEthers, "/etc/ethers")
+	status.RecordSuccess("office", 4, time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	status.RecordFailure("lab", errors.New("file not found"))
+	handler.WithSourceStatus(status)
+	healthHandler := NewHealthHandler(handler)
+
+	gin.SetMode(gin.ReleaseMode)
+	router := gin.New()
+	router.GET("/health", healthHandler.HealthCheckFull)
+
+	req := httptest.NewRequestWithContext(context.Background(), http.MethodGet, "/health", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var resp HealthCheckResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+	if resp.Checks["machine_sources"] != "warning" {
+		t.Errorf("Expected machine_sources check to be 'warning', got %s", resp.Checks["machine_sources"])
+	}
+	if len(resp.Sources) != 2 {
+		t.Fatalf("Expected 2 sources, got %d", len(resp.Sources))
+	}
+	if resp.Sources[0].Name != "office" || resp.Sources[0].Machines != 4 || resp.Sources[0].LastUpdated != "2026-01-01T00:00:00Z" {
+		t.Errorf("Unexpected office source: %+v", resp.Sources[0])
+	}
+	if body := w.Body.String(); strings.Contains(body, "/etc/ethers") || strings.Contains(body, "file not found") {
+		t.Errorf("Expected source path and error to stay out of the public response, got %s", body)
+	}
+	if resp.Status != "healthy" {
+		t.Errorf("Expected failed import to keep status 'healthy', got %s", resp.Status)
+	}
+}
@@ -0,0 +1,185 @@
+// Package http provides HTTP delivery layer.
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/josimar-silva/gwaihir/internal/domain"
+	"github.com/josimar-silva/gwaihir/internal/infrastructure"
+)
+
+// MachineRequest represents the JSON request to create or replace a machine.
+// For PUT requests the ID is taken from the path and may be omitted from the body.
+type MachineRequest struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	MAC       string `json:"mac"`
+	Broadcast string `json:"broadcast"`
+}
+
+// MachinePatchRequest represents the JSON request to partially update a machine.
+type MachinePatchRequest struct {
+	Name      *string `json:"name"`
+	MAC       *string `json:"mac"`
+	Broadcast *string `json:"broadcast"`
+}
+
+// CreateMachine handles POST /machines requests.
+func (h *Handler) CreateMachine(c *gin.Context) {
+	var req MachineRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.rejectMachineRequest(c, domain.AuditActionMachineCreate, req.ID, &req, err)
+		return
+	}
+
+	machine := req.toMachine()
+	if err := h.machines.CreateMachine(c.Request.Context(), machine); err != nil {
+		h.writeMachineError(c, domain.AuditActionMachineCreate, machine.ID, err)
+		return
+	}
+
+	h.logMachineChange(c, "Machine created via API", domain.AuditActionMachineCreate, machine.ID)
+	c.JSON(http.StatusCreated, machine)
+}
+
+// ReplaceMachine handles PUT /machines/:id requests.
+func (h *Handler) ReplaceMachine(c *gin.Context) {
+	machineID := c.Param("id")
+
+	var req MachineRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.rejectMachineRequest(c, domain.AuditActionMachineUpdate, machineID, &req, err)
+		return
+	}
+
+	if req.ID != "" && req.ID != machineID {
+		h.rejectMachineRequest(c, domain.AuditActionMachineUpdate, machineID, &req, &invalidRequestError{detail: "id in body does not match id in path"})
+		return
+	}
+	req.ID = machineID
+
+	machine := req.toMachine()
+	if err := h.machines.UpdateMachine(c.Request.Context(), machine); err != nil {
+		h.writeMachineError(c, domain.AuditActionMachineUpdate, machineID, err)
+		return
+	}
+
+	h.logMachineChange(c, "Machine replaced via API", domain.AuditActionMachineUpdate, machineID)
+	c.JSON(http.StatusOK, machine)
+}
+
+// PatchMachine handles PATCH /machines/:id requests.
+func (h *Handler) PatchMachine(c *gin.Context) {
+	machineID := c.Param("id")
+
+	var req MachinePatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.rejectMachineRequest(c, domain.AuditActionMachineUpdate, machineID, &req, err)
+		return
+	}
+
+	machine, err := h.machines.PatchMachine(c.Request.Context(), machineID, domain.MachinePatch{
+		Name:      req.Name,
+		MAC:       req.MAC,
+		Broadcast: req.Broadcast,
+	})
+	if err != nil {
+		h.writeMachineError(c, domain.AuditActionMachineUpdate, machineID, err)
+		return
+	}
+
+	h.logMachineChange(c, "Machine patched via API", domain.AuditActionMachineUpdate, machineID)
+	c.JSON(http.StatusOK, machine)
+}
+
+// DeleteMachine handles DELETE /machines/:id requests.
+func (h *Handler) DeleteMachine(c *gin.Context) {
+	machineID := c.Param("id")
+
+	if err := h.machines.DeleteMachine(c.Request.Context(), machineID); err != nil {
+		h.writeMachineError(c, domain.AuditActionMachineDelete, machineID, err)
+		return
+	}
+
+	h.logMachineChange(c, "Machine deleted via API", domain.AuditActionMachineDelete, machineID)
+	c.Status(http.StatusNoContent)
+}
+
+func (r *MachineRequest) toMachine() *domain.Machine {
+	return &domain.Machine{
+		ID:        r.ID,
+		Name:      r.Name,
+		MAC:       r.MAC,
+		Broadcast: r.Broadcast,
+	}
+}
+
+func (h *Handler) logMachineChange(c *gin.Context, msg, action, machineID string) {
+	h.requestLogger(c).Info(msg,
+		infrastructure.String("request_id", GetRequestID(c)),
+		infrastructure.String("caller", GetCaller(c)),
+		infrastructure.String("client_ip", c.ClientIP()),
+		infrastructure.String("machine_id", machineID),
+	)
+	h.recordAudit(c, action, machineID, domain.AuditOutcomeSuccess)
+}
+
+// rejectMachineRequest reports an invalid machine request. req is the request body
+// the error was raised for, if any; it names the offending field in the response.
+func (h *Handler) rejectMachineRequest(c *gin.Context, action, machineID string, req any, err error) {
+	h.requestLogger(c).Warn("Invalid machine request",
+		infrastructure.String("request_id", GetRequestID(c)),
+		infrastructure.String("caller", GetCaller(c)),
+		infrastructure.String("machine_id", machineID),
+		infrastructure.Any("error", err),
+	)
+	h.recordAudit(c, action, machineID, domain.AuditOutcomeInvalid)
+	code := domain.CodeOf(err)
+	if code == "" {
+		code = CodeInvalidRequest
+	}
+	writeProblem(c, code, requestErrorDetail(req, err))
+}
+
+// writeMachineError maps machine management errors to HTTP responses.
+func (h *Handler) writeMachineError(c *gin.Context, action, machineID string, err error) {
+	requestID := GetRequestID(c)
+
+	switch {
+	case errors.Is(err, domain.ErrInvalidMachine):
+		h.rejectMachineRequest(c, action, machineID, nil, err)
+	case errors.Is(err, domain.ErrMachineNotFound):
+		h.requestLogger(c).Warn("Machine not found",
+			infrastructure.String("request_id", requestID),
+			infrastructure.String("machine_id", machineID),
+		)
+		h.recordAudit(c, action, machineID, domain.AuditOutcomeNotFound)
+		writeProblem(c, domain.CodeMachineNotFound, "Machine "+machineID+" not found")
+	case errors.Is(err, domain.ErrMachineAlreadyExists):
+		h.requestLogger(c).Warn("Machine already exists",
+			infrastructure.String("request_id", requestID),
+			infrastructure.String("machine_id", machineID),
+		)
+		h.recordAudit(c, action, machineID, domain.AuditOutcomeConflict)
+		writeProblem(c, domain.CodeMachineAlreadyExists, "Machine "+machineID+" already exists")
+	case errors.Is(err, domain.ErrMachineReadOnly):
+		h.requestLogger(c).Warn("Machine is read-only",
+			infrastructure.String("request_id", requestID),
+			infrastructure.String("machine_id", machineID),
+		)
+		h.recordAudit(c, action, machineID, domain.AuditOutcomeConflict)
+		writeProblem(c, domain.CodeMachineReadOnly, "Machine is defined in the configuration file and cannot be changed at runtime")
+	default:
+		h.requestLogger(c).Error("Failed to modify machine",
+			infrastructure.String("request_id", requestID),
+			infrastructure.String("machine_id", machineID),
+			infrastructure.String("action", action),
+			infrastructure.Any("error", err),
+		)
+		h.recordAudit(c, action, machineID, domain.AuditOutcomeFailure)
+		writeProblem(c, CodeInternal, "Failed to persist machine changes")
+	}
+}
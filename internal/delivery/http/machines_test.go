@@ -0,0 +1,155 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/josimar-silva/gwaihir/internal/config"
+	"github.com/josimar-silva/gwaihir/internal/domain"
+	"github.com/josimar-silva/gwaihir/internal/infrastructure"
+	"github.com/josimar-silva/gwaihir/internal/repository"
+	"github.com/josimar-silva/gwaihir/internal/usecase"
+)
+
+const testAdminAPIKey = "test-admin-key-456"
+
+func newMachineManagementRouter(t *testing.T) (*gin.Engine, *mockAuditLog) {
+	t.Helper()
+
+	cfg := &config.Config{
+		Authentication: config.AuthenticationConfig{
+			APIKey:      testAPIKey,
+			AdminAPIKey: testAdminAPIKey,
+		},
+		Machines: []config.MachineConfig{
+			{ID: "saruman", Name: "Saruman Server", MAC: "AA:BB:CC:DD:EE:FF", Broadcast: "192.168.1.255"},
+		},
+	}
+	repo, err := repository.NewFileMachineRepository(cfg, filepath.Join(t.TempDir(), "machines.yaml"))
+	require.NoError(t, err)
+
+	logger := infrastructure.NewLogger("text", "debug")
+	metrics, _ := infrastructure.NewMetrics()
+	wolUseCase := usecase.NewWoLUseCase(repo, &mockPacketSender{}, logger, metrics)
+	auditLog := &mockAuditLog{}
+	handler := NewHandler(wolUseCase, logger, metrics, "0.1.0", "2024-01-01T00:00:00Z", "abc123").
+		WithAuditLog(auditLog).
+		WithMachineManagement(usecase.NewMachineUseCase(repo, logger, metrics))
+
+	return NewRouterWithAuthAndConfig(handler, testAPIKey, cfg), auditLog
+}
+
+func doMachineRequest(router *gin.Engine, method, path, apiKey string, body any) *httptest.ResponseRecorder {
+	var reader *bytes.Reader
+	if body != nil {
+		data, _ := json.Marshal(body)
+		reader = bytes.NewReader(data)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+
+	req := httptest.NewRequestWithContext(context.Background(), method, path, reader)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-API-Key", apiKey)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestMachines_Lifecycle(t *testing.T) {
+	router, auditLog := newMachineManagementRouter(t)
+
+	w := doMachineRequest(router, http.MethodPost, "/machines", testAdminAPIKey, MachineRequest{
+		ID: "gandalf", Name: "Gandalf", MAC: "11:22:33:44:55:66", Broadcast: "10.0.0.255",
+	})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	w = doMachineRequest(router, http.MethodGet, "/machines/gandalf", testAPIKey, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = doMachineRequest(router, http.MethodPut, "/machines/gandalf", testAdminAPIKey, MachineRequest{
+		Name: "Gandalf the White", MAC: "11:22:33:44:55:66", Broadcast: "10.0.0.255",
+	})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	w = doMachineRequest(router, http.MethodPatch, "/machines/gandalf", testAdminAPIKey, map[string]string{
+		"broadcast": "10.0.1.255",
+	})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var patched domain.Machine
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &patched))
+	assert.Equal(t, "Gandalf the White", patched.Name)
+	assert.Equal(t, "10.0.1.255", patched.Broadcast)
+
+	w = doMachineRequest(router, http.MethodDelete, "/machines/gandalf", testAdminAPIKey, nil)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	w = doMachineRequest(router, http.MethodGet, "/machines/gandalf", testAPIKey, nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	assert.Contains(t, auditLog.actions(), "machine_create:success")
+	assert.Contains(t, auditLog.actions(), "machine_update:success")
+	assert.Contains(t, auditLog.actions(), "machine_delete:success")
+}
+
+func TestMachines_RequiresAdminScope(t *testing.T) {
+	router, _ := newMachineManagementRouter(t)
+
+	w := doMachineRequest(router, http.MethodPost, "/machines", testAPIKey, MachineRequest{
+		ID: "gandalf", Name: "Gandalf", MAC: "11:22:33:44:55:66", Broadcast: "10.0.0.255",
+	})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w = doMachineRequest(router, http.MethodDelete, "/machines/saruman", "wrong-key", nil)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestMachines_Errors(t *testing.T) {
+	router, _ := newMachineManagementRouter(t)
+
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		body     any
+		expected int
+	}{
+		{"invalid mac", http.MethodPost, "/machines", MachineRequest{ID: "x", Name: "X", MAC: "bad", Broadcast: "10.0.0.255"}, http.StatusBadRequest},
+		{"invalid id", http.MethodPost, "/machines", MachineRequest{ID: "home/office", Name: "X", MAC: "11:22:33:44:55:66", Broadcast: "10.0.0.255"}, http.StatusBadRequest},
+		{"duplicate id", http.MethodPost, "/machines", MachineRequest{ID: "saruman", Name: "S", MAC: "11:22:33:44:55:66", Broadcast: "10.0.0.255"}, http.StatusConflict},
+		{"config machine read-only", http.MethodDelete, "/machines/saruman", nil, http.StatusConflict},
+		{"unknown machine", http.MethodPatch, "/machines/unknown", map[string]string{"name": "U"}, http.StatusNotFound},
+		{"id mismatch", http.MethodPut, "/machines/other", MachineRequest{ID: "saruman", Name: "S", MAC: "11:22:33:44:55:66", Broadcast: "10.0.0.255"}, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := doMachineRequest(router, tt.method, tt.path, testAdminAPIKey, tt.body)
+			assert.Equal(t, tt.expected, w.Code, w.Body.String())
+		})
+	}
+}
+
+func TestMachines_NotRegisteredWithoutAdminKey(t *testing.T) {
+	handler, _, _ := newHandlerForTesting(nil)
+	repo, err := repository.NewFileMachineRepository(&config.Config{}, filepath.Join(t.TempDir(), "machines.yaml"))
+	require.NoError(t, err)
+	handler.WithMachineManagement(usecase.NewMachineUseCase(repo, handler.logger, handler.metrics))
+	router := NewRouterWithAuth(handler, testAPIKey)
+
+	w := doMachineRequest(router, http.MethodPost, "/machines", testAPIKey, MachineRequest{
+		ID: "gandalf", Name: "Gandalf", MAC: "11:22:33:44:55:66", Broadcast: "10.0.0.255",
+	})
+	// Only GET /machines is registered.
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	assert.Contains(t, w.Body.String(), `"code":"method_not_allowed"`)
+}
@@ -4,12 +4,14 @@ package http
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 
 	"github.com/josimar-silva/gwaihir/internal/config"
+	"github.com/josimar-silva/gwaihir/internal/infrastructure"
 )
 
 type contextKey string
@@ -42,6 +44,28 @@ func RequestLoggingMiddleware() gin.HandlerFunc {
 	}
 }
 
+// unmatchedRoute is the route label of requests that match no route, so scans of
+// arbitrary paths cannot create series.
+const unmatchedRoute = "unmatched"
+
+// MetricsMiddleware records the duration of every request by method, route template
+// and status code.
+func MetricsMiddleware(metrics *infrastructure.Metrics) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		startTime := time.Now()
+
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+		metrics.RequestDuration.
+			WithLabelValues(c.Request.Method, route, strconv.Itoa(c.Writer.Status())).
+			Observe(time.Since(startTime).Seconds())
+	}
+}
+
 // GetRequestID retrieves the request ID from the Gin context.
 func GetRequestID(c *gin.Context) string {
 	return getRequestID(c)
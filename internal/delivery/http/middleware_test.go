@@ -7,6 +7,9 @@ import (
 	"testing"
 
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
 
 	"github.com/josimar-silva/gwaihir/internal/config"
 )
@@ -200,3 +203,35 @@ func TestRequestLoggingMiddlewareWithConfig_WithConfig(t *testing.T) {
 		t.Fatal("Expected duration to be set in context")
 	}
 }
+
+func TestMetricsMiddleware_LabelsByRouteTemplate(t *testing.T) {
+	handler, _, _ := newHandlerForTesting(nil)
+	router := NewRouterWithAuth(handler, testAPIKey)
+
+	for _, path := range []string{"/api/v1/machines/saruman", "/api/v1/machines/morgoth", "/api/v1/machines/sauron", "/wp-login.php"} {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("X-API-Key", testAPIKey)
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	durations := handler.metrics.RequestDuration
+	if got := testutil.CollectAndCount(durations); got != 3 {
+		t.Errorf("Expected 3 series, got %d", got)
+	}
+	for _, tt := range []struct {
+		route, status string
+		count         uint64
+	}{
+		{"/api/v1/machines/:id", "200", 2},
+		{"/api/v1/machines/:id", "404", 1},
+		{"unmatched", "404", 1},
+	} {
+		var metric dto.Metric
+		if err := durations.WithLabelValues(http.MethodGet, tt.route, tt.status).(prometheus.Histogram).Write(&metric); err != nil {
+			t.Fatalf("Failed to read histogram: %v", err)
+		}
+		if got := metric.GetHistogram().GetSampleCount(); got != tt.count {
+			t.Errorf("%s %s: expected %d observations, got %d", tt.route, tt.status, tt.count, got)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+// Package http provides HTTP delivery layer using Gin.
+package http
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/josimar-silva/gwaihir/internal/infrastructure"
+)
+
+// openAPIDocument describes every route the router may register. Routes that depend
+// on configuration are documented as such.
+//
+//go:embed openapi.json
+var openAPIDocument []byte
+
+// OpenAPISpec returns the OpenAPI document of the API with info.version set to version.
+func OpenAPISpec(version string) ([]byte, error) {
+	var spec map[string]any
+	if err := json.Unmarshal(openAPIDocument, &spec); err != nil {
+		return nil, fmt.Errorf("invalid OpenAPI document: %w", err)
+	}
+
+	if info, ok := spec["info"].(map[string]any); ok && version != "" {
+		info["version"] = version
+	}
+	return json.Marshal(spec)
+}
+
+// OpenAPI handles GET /openapi.json requests.
+func (h *Handler) OpenAPI(c *gin.Context) {
+	spec, err := OpenAPISpec(h.version)
+	if err != nil {
+		h.requestLogger(c).Error("Failed to render OpenAPI document",
+			infrastructure.String("request_id", GetRequestID(c)),
+			infrastructure.Any("error", err),
+		)
+		writeProblem(c, CodeInternal, "Failed to render OpenAPI document")
+		return
+	}
+
+	c.Data(http.StatusOK, "application/json", spec)
+}
@@ -0,0 +1,102 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var ginPathParam = regexp.MustCompile(`[:*]([A-Za-z_]+)`)
+
+func loadOpenAPISpec(t *testing.T) map[string]any {
+	t.Helper()
+	data, err := OpenAPISpec("")
+	require.NoError(t, err)
+
+	var spec map[string]any
+	require.NoError(t, json.Unmarshal(data, &spec))
+	return spec
+}
+
+func TestOpenAPI_ServesDocument(t *testing.T) {
+	router, _ := newDiscoveryRouter(t, true)
+
+	req := httptest.NewRequest(http.MethodGet, "/openapi.json", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+	var spec map[string]any
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &spec))
+	assert.Equal(t, "3.1.0", spec["openapi"])
+	assert.Equal(t, "0.1.0", spec["info"].(map[string]any)["version"])
+}
+
+// TestOpenAPI_DocumentsEveryRoute fails when a route is registered without being
+// documented, or documented without being registered. The router is built with
+// every optional feature enabled. Deprecated unversioned aliases are documented
+// through their /api/v1 route.
+func TestOpenAPI_DocumentsEveryRoute(t *testing.T) {
+	router, _ := newDiscoveryRouter(t, true)
+	paths := loadOpenAPISpec(t)["paths"].(map[string]any)
+
+	registered := make(map[string]bool)
+	for _, route := range router.Routes() {
+		path := ginPathParam.ReplaceAllString(route.Path, "{$1}")
+		method := strings.ToLower(route.Method)
+		if _, documented := paths[path]; !documented && !strings.HasPrefix(path, APIV1Prefix) {
+			path = APIV1Prefix + path
+		}
+		registered[method+" "+path] = true
+
+		item, ok := paths[path].(map[string]any)
+		if !assert.True(t, ok, "route %s %s is missing from the OpenAPI document", route.Method, path) {
+			continue
+		}
+		assert.Contains(t, item, method, "route %s %s is missing from the OpenAPI document", route.Method, path)
+	}
+
+	for path, item := range paths {
+		for method := range item.(map[string]any) {
+			if method == "parameters" {
+				continue
+			}
+			assert.True(t, registered[method+" "+path], "documented operation %s %s is not registered", strings.ToUpper(method), path)
+		}
+	}
+}
+
+func TestOpenAPI_ReferencesResolve(t *testing.T) {
+	spec := loadOpenAPISpec(t)
+	components := spec["components"].(map[string]any)
+
+	var walk func(node any)
+	walk = func(node any) {
+		switch v := node.(type) {
+		case map[string]any:
+			if ref, ok := v["$ref"].(string); ok {
+				parts := strings.Split(strings.TrimPrefix(ref, "#/components/"), "/")
+				require.Len(t, parts, 2, ref)
+				section, _ := components[parts[0]].(map[string]any)
+				assert.Contains(t, section, parts[1], "unresolved reference %s", ref)
+			}
+			for _, child := range v {
+				walk(child)
+			}
+		case []any:
+			for _, child := range v {
+				walk(child)
+			}
+		}
+	}
+	walk(spec["paths"])
+	walk(components)
+}
@@ -0,0 +1,177 @@
+// Package http provides HTTP delivery layer handlers and routes.
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+
+	"github.com/josimar-silva/gwaihir/internal/domain"
+)
+
+// ProblemContentType is the media type of error responses (RFC 7807).
+const ProblemContentType = "application/problem+json"
+
+// ProblemTypeBaseURI is the base of the problem type URIs; the error code is appended
+// as the fragment, pointing at its documentation.
+const ProblemTypeBaseURI = "https://github.com/josimar-silva/gwaihir/blob/main/docs/errors.md#"
+
+// Codes of the errors raised by the HTTP layer rather than the domain.
+const (
+	CodeInvalidRequest   domain.ErrorCode = "invalid_request"
+	CodeUnauthorized     domain.ErrorCode = "unauthorized"
+	CodeForbidden        domain.ErrorCode = "forbidden"
+	CodeRateLimited      domain.ErrorCode = "rate_limited"
+	CodeNotFound         domain.ErrorCode = "not_found"
+	CodeMethodNotAllowed domain.ErrorCode = "method_not_allowed"
+	CodeInternal         domain.ErrorCode = "internal_error"
+)
+
+// ProblemDetails is the body of an error response (RFC 7807). Code is stable and meant
+// for clients to switch on; Detail is a human-readable explanation of this occurrence.
+type ProblemDetails struct {
+	Type      string           `json:"type"`
+	Title     string           `json:"title"`
+	Status    int              `json:"status"`
+	Detail    string           `json:"detail,omitempty"`
+	Instance  string           `json:"instance,omitempty"`
+	Code      domain.ErrorCode `json:"code"`
+	RequestID string           `json:"request_id,omitempty"`
+}
+
+// problemType is how an error code is reported over HTTP.
+type problemType struct {
+	status int
+	title  string
+}
+
+var problemTypes = map[domain.ErrorCode]problemType{
+	domain.CodeMachineNotFound:      {http.StatusNotFound, "Machine not found"},
+	domain.CodeMachineNotAllowed:    {http.StatusNotFound, "Machine not allowed"},
+	domain.CodeMachineAlreadyExists: {http.StatusConflict, "Machine already exists"},
+	domain.CodeMachineReadOnly:      {http.StatusConflict, "Machine is read-only"},
+	domain.CodeInvalidMachine:       {http.StatusBadRequest, "Invalid machine"},
+	domain.CodeNeighborNotFound:     {http.StatusNotFound, "Neighbor not found"},
+	domain.CodeWakeFailed:           {http.StatusInternalServerError, "Wake-on-LAN packet could not be sent"},
+	domain.CodeInvalidConfiguration: {http.StatusInternalServerError, "Invalid configuration"},
+
+	CodeInvalidRequest:   {http.StatusBadRequest, "Invalid request"},
+	CodeUnauthorized:     {http.StatusUnauthorized, "Unauthorized"},
+	CodeForbidden:        {http.StatusForbidden, "Forbidden"},
+	CodeRateLimited:      {http.StatusTooManyRequests, "Too many requests"},
+	CodeNotFound:         {http.StatusNotFound, "Not found"},
+	CodeMethodNotAllowed: {http.StatusMethodNotAllowed, "Method not allowed"},
+	CodeInternal:         {http.StatusInternalServerError, "Internal server error"},
+}
+
+// NewProblem builds the problem details for code. Unknown codes are reported as
+// internal errors so that a missing mapping never leaks as a success.
+func NewProblem(code domain.ErrorCode, detail string) ProblemDetails {
+	pt, ok := problemTypes[code]
+	if !ok {
+		code = CodeInternal
+		pt = problemTypes[CodeInternal]
+	}
+
+	return ProblemDetails{
+		Type:   ProblemTypeBaseURI + string(code),
+		Title:  pt.title,
+		Status: pt.status,
+		Detail: detail,
+		Code:   code,
+	}
+}
+
+// writeProblem aborts the request with the problem response for code.
+func writeProblem(c *gin.Context, code domain.ErrorCode, detail string) {
+	problem := NewProblem(code, detail)
+	problem.Instance = c.Request.URL.Path
+	problem.RequestID = GetRequestID(c)
+
+	c.Header("Content-Type", ProblemContentType)
+	c.AbortWithStatusJSON(problem.Status, problem)
+}
+
+// notFoundHandler reports requests for unknown routes.
+func notFoundHandler(c *gin.Context) {
+	writeProblem(c, CodeNotFound, "No route matches "+c.Request.URL.Path)
+}
+
+// methodNotAllowedHandler reports requests for known routes with an unsupported method.
+func methodNotAllowedHandler(c *gin.Context) {
+	writeProblem(c, CodeMethodNotAllowed, "Method "+c.Request.Method+" is not allowed on "+c.Request.URL.Path)
+}
+
+// invalidRequestError is a client error whose detail is safe to return. The rejected
+// value, if any, is only part of Error, which is logged.
+type invalidRequestError struct {
+	detail string
+	value  string
+}
+
+func (e *invalidRequestError) Error() string {
+	if e.value == "" {
+		return e.detail
+	}
+	return fmt.Sprintf("%s, got '%s'", e.detail, e.value)
+}
+
+// requestErrorDetail maps the error of an invalid request to the detail returned to
+// the client. Binding and decoding errors are reduced to the offending JSON field of
+// req, so that Go type names and struct paths stay in the log.
+func requestErrorDetail(req any, err error) string {
+	var (
+		requestErr  *invalidRequestError
+		syntaxErr   *json.SyntaxError
+		typeErr     *json.UnmarshalTypeError
+		validateErr validator.ValidationErrors
+	)
+
+	switch {
+	case errors.As(err, &requestErr):
+		return requestErr.detail
+	case errors.Is(err, domain.ErrInvalidMachine):
+		return err.Error()
+	case errors.Is(err, io.EOF):
+		return "Request body must not be empty"
+	case errors.As(err, &syntaxErr), errors.Is(err, io.ErrUnexpectedEOF):
+		return "Request body must be valid JSON"
+	case errors.As(err, &typeErr) && typeErr.Field != "":
+		return typeErr.Field + " has an invalid type"
+	case errors.As(err, &validateErr) && len(validateErr) > 0:
+		field := jsonFieldName(req, validateErr[0].StructField())
+		if validateErr[0].Tag() == "required" {
+			return field + " is required"
+		}
+		return field + " is invalid"
+	default:
+		return "Request body is invalid"
+	}
+}
+
+// jsonFieldName returns the JSON name of the field of the struct req points to.
+func jsonFieldName(req any, structField string) string {
+	t := reflect.TypeOf(req)
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return structField
+	}
+
+	field, ok := t.FieldByName(structField)
+	if !ok {
+		return structField
+	}
+	if name, _, _ := strings.Cut(field.Tag.Get("json"), ","); name != "" && name != "-" {
+		return name
+	}
+	return structField
+}
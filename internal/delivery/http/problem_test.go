@@ -0,0 +1,198 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/josimar-silva/gwaihir/internal/domain"
+)
+
+// decodeProblem checks that w is a problem response and decodes it.
+func decodeProblem(t *testing.T, w *httptest.ResponseRecorder) ProblemDetails {
+	t.Helper()
+	assert.Equal(t, ProblemContentType, w.Header().Get("Content-Type"))
+
+	var problem ProblemDetails
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem), w.Body.String())
+	assert.Equal(t, w.Code, problem.Status)
+	assert.Equal(t, ProblemTypeBaseURI+string(problem.Code), problem.Type)
+	assert.Equal(t, w.Header().Get("X-Request-ID"), problem.RequestID)
+	assert.NotEmpty(t, problem.Title)
+	return problem
+}
+
+func TestProblem_Responses(t *testing.T) {
+	handler, _, _ := newHandlerForTesting(nil)
+	router := NewRouterWithAuth(handler, testAPIKey)
+
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		apiKey   string
+		body     any
+		expected int
+		code     domain.ErrorCode
+	}{
+		{"unknown route", http.MethodGet, "/api/v1/nothing", testAPIKey, nil, http.StatusNotFound, CodeNotFound},
+		{"unsupported method", http.MethodDelete, "/api/v1/wol", testAPIKey, nil, http.StatusMethodNotAllowed, CodeMethodNotAllowed},
+		{"missing key", http.MethodGet, "/api/v1/machines", "", nil, http.StatusUnauthorized, CodeUnauthorized},
+		{"invalid key", http.MethodGet, "/api/v1/machines", "wrong", nil, http.StatusUnauthorized, CodeUnauthorized},
+		{"invalid body", http.MethodPost, "/api/v1/wol", testAPIKey, map[string]string{}, http.StatusBadRequest, CodeInvalidRequest},
+		{"machine not allowed", http.MethodPost, "/api/v1/wol", testAPIKey, WakeRequest{MachineID: "sauron"}, http.StatusNotFound, domain.CodeMachineNotAllowed},
+		{"machine not found", http.MethodGet, "/api/v1/machines/sauron", testAPIKey, nil, http.StatusNotFound, domain.CodeMachineNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := doMachineRequest(router, tt.method, tt.path, tt.apiKey, tt.body)
+			require.Equal(t, tt.expected, w.Code, w.Body.String())
+
+			problem := decodeProblem(t, w)
+			assert.Equal(t, tt.code, problem.Code)
+			assert.Equal(t, tt.path, problem.Instance)
+		})
+	}
+}
+
+func TestProblem_WakeFailureDoesNotLeakError(t *testing.T) {
+	handler, _, sender := newHandlerForTesting(nil)
+	sender.sendError = errors.New("sendto 192.168.1.255:9: network is unreachable")
+	sender.shouldFailCount = 1
+	router := NewRouter(handler)
+
+	w := doMachineRequest(router, http.MethodPost, "/api/v1/wol", "", WakeRequest{MachineID: testMachineSaruman})
+	require.Equal(t, http.StatusInternalServerError, w.Code)
+
+	problem := decodeProblem(t, w)
+	assert.Equal(t, domain.CodeWakeFailed, problem.Code)
+	assert.NotContains(t, w.Body.String(), "unreachable")
+}
+
+func TestProblem_ScopeAndLockout(t *testing.T) {
+	router, _ := newDiscoveryRouter(t, true)
+
+	w := doMachineRequest(router, http.MethodDelete, "/api/v1/machines/saruman", testAPIKey, nil)
+	require.Equal(t, http.StatusForbidden, w.Code)
+	assert.Equal(t, CodeForbidden, decodeProblem(t, w).Code)
+
+	gin.SetMode(gin.TestMode)
+	lockout := NewAuthLockout(1, 0, 0, 0)
+	locked := gin.New()
+	locked.Use(RequestIDMiddleware(), APIKeyAuthMiddlewareWithLockout(testAPIKey, lockout, nil, nil, nil))
+	locked.GET("/machines", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	_ = doMachineRequest(locked, http.MethodGet, "/machines", "wrong", nil)
+	w = doMachineRequest(locked, http.MethodGet, "/machines", testAPIKey, nil)
+	require.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, CodeRateLimited, decodeProblem(t, w).Code)
+	assert.NotEmpty(t, w.Header().Get("Retry-After"))
+}
+
+func TestNewProblem_UnknownCodeIsInternal(t *testing.T) {
+	problem := NewProblem("frobnicated", "detail")
+	assert.Equal(t, CodeInternal, problem.Code)
+	assert.Equal(t, http.StatusInternalServerError, problem.Status)
+}
+
+// TestProblem_CodesAreDocumented keeps the error codes, the OpenAPI document and the
+// error code reference in sync.
+func TestProblem_CodesAreDocumented(t *testing.T) {
+	var spec struct {
+		Components struct {
+			Schemas struct {
+				ProblemDetails struct {
+					Properties struct {
+						Code struct {
+							Enum []string `json:"enum"`
+						} `json:"code"`
+					} `json:"properties"`
+				} `json:"ProblemDetails"`
+			} `json:"schemas"`
+		} `json:"components"`
+	}
+	require.NoError(t, json.Unmarshal(openAPIDocument, &spec))
+	enum := spec.Components.Schemas.ProblemDetails.Properties.Code.Enum
+
+	reference, err := os.ReadFile("../../../docs/errors.md")
+	require.NoError(t, err)
+
+	for code := range problemTypes {
+		assert.Contains(t, enum, string(code), "OpenAPI document")
+		assert.True(t, strings.Contains(string(reference), "### "+string(code)+"\n"), "docs/errors.md is missing %s", code)
+	}
+	assert.Len(t, enum, len(problemTypes))
+}
+
+func TestProblem_InvalidRequestDetails(t *testing.T) {
+	handler, _, _ := newHandlerForTesting(nil)
+	wakeRouter := NewRouterWithAuth(handler, testAPIKey)
+	router, _ := newDiscoveryRouter(t, true)
+
+	wake := func(body string) func() *httptest.ResponseRecorder {
+		return func() *httptest.ResponseRecorder { return doRawWakeRequest(wakeRouter, body) }
+	}
+	request := func(method, path, apiKey string, body any) func() *httptest.ResponseRecorder {
+		return func() *httptest.ResponseRecorder { return doMachineRequest(router, method, path, apiKey, body) }
+	}
+	eventStream := func(lastEventID string) func() *httptest.ResponseRecorder {
+		return func() *httptest.ResponseRecorder {
+			req := httptest.NewRequestWithContext(context.Background(), http.MethodGet, "/api/v1/events", nil)
+			req.Header.Set("X-API-Key", testAPIKey)
+			req.Header.Set(LastEventIDHeader, lastEventID)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			return w
+		}
+	}
+
+	tests := []struct {
+		name     string
+		do       func() *httptest.ResponseRecorder
+		expected string
+		rejected string
+	}{
+		{"empty body", wake(""), "Request body must not be empty", "EOF"},
+		{"malformed json", wake(`{"machine_id":`), "Request body must be valid JSON", "unexpected"},
+		{"wrong type", wake(`{"machine_id": 42}`), "machine_id has an invalid type", "WakeRequest"},
+		{"missing field", wake(`{}`), "machine_id is required", "MachineID"},
+		{"machine wrong type", request(http.MethodPatch, "/api/v1/machines/saruman", testAdminAPIKey, json.RawMessage(`{"mac": 1}`)), "mac has an invalid type", "MachinePatchRequest"},
+		{"adopt missing id", request(http.MethodPost, "/api/v1/discovery/neighbors/aa:bb:cc:dd:ee:ff/adopt", testAdminAPIKey, json.RawMessage(`{}`)), "id is required", "AdoptNeighborRequest"},
+		{"audit limit", request(http.MethodGet, "/api/v1/audit?limit=abc", testAdminAPIKey, nil), "limit must be between 1 and 10000", "abc"},
+		{"event id", eventStream("abc"), LastEventIDHeader + " must be a non-negative integer", "abc"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := tt.do()
+			require.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+
+			problem := decodeProblem(t, w)
+			assert.Equal(t, CodeInvalidRequest, problem.Code)
+			assert.Equal(t, tt.expected, problem.Detail)
+			assert.NotContains(t, problem.Detail, tt.rejected)
+		})
+	}
+}
+
+func TestRequestErrorDetail_KeepsDomainValidation(t *testing.T) {
+	err := fmt.Errorf("%w: %w", domain.ErrInvalidMachine, errors.New("machine name cannot be empty"))
+
+	if detail := requestErrorDetail(nil, err); detail != err.Error() {
+		t.Errorf("Expected detail '%s', got '%s'", err.Error(), detail)
+	}
+	if detail := requestErrorDetail(nil, errors.New("json: cannot unmarshal")); detail != "Request body is invalid" {
+		t.Errorf("Expected a generic detail, got '%s'", detail)
+	}
+}
@@ -23,13 +23,40 @@ func NewRouterWithAuth(handler *Handler, apiKey string) *gin.Engine {
 	return NewRouterWithAuthAndConfig(handler, apiKey, nil)
 }
 
+// APIV1Prefix is the path prefix of version 1 of the API.
+const APIV1Prefix = "/api/v1"
+
+// routeOptions carries the router-wide settings the API versions register their routes with.
+type routeOptions struct {
+	health         *HealthHandler
+	requireAuth    bool
+	adminAPIKey    string
+	authMiddleware gin.HandlerFunc
+}
+
 // NewRouterWithAuthAndConfig creates and configures the Gin router with config-based endpoint registration.
+//
+// The API is served under APIV1Prefix. The unversioned paths it was served at before
+// remain available as deprecated aliases. Probes and metrics stay at the root as they
+// are consumed by infrastructure rather than API clients. A future version is added by
+// mounting its own register function under its prefix. The web dashboard is served
+// at UIPath.
 func NewRouterWithAuthAndConfig(handler *Handler, apiKey string, cfg *config.Config) *gin.Engine {
 	router := gin.Default()
+	setTrustedProxies(router, cfg, handler.logger)
+	router.HandleMethodNotAllowed = true
+	router.NoRoute(notFoundHandler)
+	router.NoMethod(methodNotAllowedHandler)
 
 	// Middleware
+	if cfg != nil && cfg.Observability.Tracing.Enabled != nil && *cfg.Observability.Tracing.Enabled {
+		router.Use(TracingMiddleware())
+	}
 	router.Use(RequestIDMiddleware())
 	router.Use(RequestLoggingMiddlewareWithConfig(cfg))
+	if handler.metrics != nil {
+		router.Use(MetricsMiddleware(handler.metrics))
+	}
 
 	healthEnabled := true
 	if cfg != nil && cfg.Observability.HealthCheck.Enabled != nil {
@@ -41,23 +68,80 @@ func NewRouterWithAuthAndConfig(handler *Handler, apiKey string, cfg *config.Con
 		metricsEnabled = *cfg.Observability.Metrics.Enabled
 	}
 
-	healthHandler := NewHealthHandler(handler)
-
+	var healthHandler *HealthHandler
 	if healthEnabled {
-		router.GET("/health", healthHandler.HealthCheckFull)
+		healthHandler = NewHealthHandler(handler)
 		router.GET("/live", healthHandler.HealthCheckLive)
 		router.GET("/ready", healthHandler.HealthCheckReady)
 	}
 
-	if metricsEnabled {
-		router.GET("/metrics", gin.WrapH(infrastructure.MetricsHandler()))
+	if metricsEnabled && handler.metrics != nil {
+		router.GET("/metrics", gin.WrapH(handler.metrics.Handler()))
 	}
 
-	router.GET("/version", handler.Version)
+	// Requests for UIPath itself are redirected to UIPath/ by gin.
+	if cfg == nil || cfg.UI.Enabled == nil || *cfg.UI.Enabled {
+		router.GET(UIPath+"/*filepath", ServeUI)
+	}
+
+	adminAPIKey := ""
+	if cfg != nil {
+		adminAPIKey = cfg.Authentication.AdminAPIKey
+	}
+
+	keys := handler.apiKeys
+	if keys == nil {
+		keys = NewAPIKeySet(ConfiguredAPIKeys(apiKey, adminAPIKey))
+	}
 
-	protected := router.Group("")
-	if apiKey != "" {
-		protected.Use(APIKeyAuthMiddleware(apiKey))
+	lockout := handler.lockout
+	if lockout == nil {
+		lockout = NewAuthLockoutFromConfig(cfg)
+	}
+
+	opts := routeOptions{
+		health:         healthHandler,
+		requireAuth:    apiKey != "",
+		adminAPIKey:    adminAPIKey,
+		authMiddleware: APIKeySetAuthMiddleware(keys, lockout, handler.logger, handler.metrics, handler.audit),
+	}
+
+	registerAPIV1(router.Group(APIV1Prefix), handler, opts)
+	registerAPIV1(router.Group("", DeprecatedAliasMiddleware(APIV1Prefix, handler.metrics)), handler, opts)
+
+	return router
+}
+
+// setTrustedProxies restricts the proxies whose forwarding headers gin trusts to
+// the configured ones, so that the client address used for lockouts, audit records
+// and logs cannot be spoofed by clients. Without configured proxies no header is
+// trusted and the address of the connecting peer is used.
+func setTrustedProxies(router *gin.Engine, cfg *config.Config, logger *infrastructure.Logger) {
+	var proxies []string
+	if cfg != nil {
+		proxies = cfg.Server.TrustedProxies
+	}
+
+	if err := router.SetTrustedProxies(proxies); err != nil {
+		if logger != nil {
+			logger.Warn("Ignoring invalid trusted proxies", infrastructure.Any("trusted_proxies", proxies), infrastructure.Any("error", err))
+		}
+		_ = router.SetTrustedProxies(nil)
+	}
+}
+
+// registerAPIV1 registers the routes of version 1 of the API on group.
+func registerAPIV1(group *gin.RouterGroup, handler *Handler, opts routeOptions) {
+	if opts.health != nil {
+		group.GET("/health", opts.health.HealthCheckFull)
+	}
+
+	group.GET("/version", handler.Version)
+	group.GET("/openapi.json", handler.OpenAPI)
+
+	protected := group.Group("")
+	if opts.requireAuth {
+		protected.Use(opts.authMiddleware)
 	}
 
 	protected.POST("/wol", handler.Wake)
@@ -65,5 +149,34 @@ func NewRouterWithAuthAndConfig(handler *Handler, apiKey string, cfg *config.Con
 	protected.GET("/machines", handler.ListMachines)
 	protected.GET("/machines/:id", handler.GetMachine)
 
-	return router
+	if handler.discovery != nil {
+		protected.GET("/discovery/neighbors", handler.ListNeighbors)
+	}
+
+	if handler.events != nil {
+		protected.GET("/events", handler.StreamEvents)
+	}
+
+	// The audit trail and machine management are only exposed with a dedicated
+	// admin key.
+	if opts.adminAPIKey == "" {
+		return
+	}
+	admin := group.Group("")
+	admin.Use(opts.authMiddleware, RequireScope(ScopeAdmin))
+
+	if handler.audit != nil {
+		admin.GET("/audit", handler.QueryAudit)
+	}
+
+	if handler.machines != nil {
+		admin.POST("/machines", handler.CreateMachine)
+		admin.PUT("/machines/:id", handler.ReplaceMachine)
+		admin.PATCH("/machines/:id", handler.PatchMachine)
+		admin.DELETE("/machines/:id", handler.DeleteMachine)
+
+		if handler.discovery != nil {
+			admin.POST("/discovery/neighbors/:mac/adopt", handler.AdoptNeighbor)
+		}
+	}
 }
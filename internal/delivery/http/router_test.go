@@ -2,6 +2,8 @@ package http
 
 import (
 	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/josimar-silva/gwaihir/internal/config"
@@ -192,3 +194,29 @@ func TestNewRouterWithConfig_ProtectedEndpointsAlwaysRegistered(t *testing.T) {
 func ptrBool(b bool) *bool {
 	return &b
 }
+
+func TestMetricsEndpoint_ServesHandlerRegistry(t *testing.T) {
+	handler, _, _ := newHandlerForTesting(nil)
+	handler.metrics.SetBuildInfo("0.1.0", "abc123")
+	router := NewRouterWithAuth(handler, testAPIKey)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/machines", nil)
+	req.Header.Set("X-API-Key", testAPIKey)
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	for _, want := range []string{
+		`gwaihir_build_info{commit="abc123",version="0.1.0"} 1`,
+		`gwaihir_request_duration_seconds_count{method="GET",route="/api/v1/machines",status="200"} 1`,
+		"go_goroutines",
+	} {
+		if !strings.Contains(w.Body.String(), want) {
+			t.Errorf("Expected metrics to contain %q", want)
+		}
+	}
+}
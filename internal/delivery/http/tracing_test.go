@@ -0,0 +1,179 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/josimar-silva/gwaihir/internal/config"
+	"github.com/josimar-silva/gwaihir/internal/infrastructure"
+)
+
+const testTraceParent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+// recordSpans installs a tracer provider recording spans in memory for the duration
+// of the test.
+func recordSpans(t *testing.T) *tracetest.InMemoryExporter {
+	t.Helper()
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+
+	previousProvider, previousPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		_ = provider.Shutdown(t.Context())
+		otel.SetTracerProvider(previousProvider)
+		otel.SetTextMapPropagator(previousPropagator)
+	})
+	return exporter
+}
+
+func newTracingRouter() (*mockPacketSender, http.Handler) {
+	enabled := true
+	cfg := &config.Config{Observability: config.ObservabilityConfig{Tracing: config.TracingConfig{Enabled: &enabled}}}
+	handler, _, sender := newHandlerForTesting(nil)
+	return sender, NewRouterWithAuthAndConfig(handler, testAPIKey, cfg)
+}
+
+func findSpan(t *testing.T, spans tracetest.SpanStubs, name string) tracetest.SpanStub {
+	t.Helper()
+	for _, span := range spans {
+		if span.Name == name {
+			return span
+		}
+	}
+	require.Failf(t, "span not recorded", "no span named %q in %d spans", name, len(spans))
+	return tracetest.SpanStub{}
+}
+
+func spanAttribute(span tracetest.SpanStub, key attribute.Key) attribute.Value {
+	for _, attr := range span.Attributes {
+		if attr.Key == key {
+			return attr.Value
+		}
+	}
+	return attribute.Value{}
+}
+
+func TestTracingMiddleware_ContinuesIncomingTrace(t *testing.T) {
+	exporter := recordSpans(t)
+	_, router := newTracingRouter()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/wol", bytes.NewBufferString(`{"machine_id":"saruman"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-API-Key", testAPIKey)
+	req.Header.Set("traceparent", testTraceParent)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusAccepted, w.Code)
+
+	spans := exporter.GetSpans()
+	server := findSpan(t, spans, "POST /api/v1/wol")
+	assert.Equal(t, trace.SpanKindServer, server.SpanKind)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", server.SpanContext.TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", server.Parent.SpanID().String())
+	assert.True(t, server.Parent.IsRemote())
+	assert.Equal(t, "/api/v1/wol", spanAttribute(server, "http.route").AsString())
+	assert.Equal(t, int64(http.StatusAccepted), spanAttribute(server, "http.response.status_code").AsInt64())
+
+	wake := findSpan(t, spans, "WoLUseCase.SendWakePacket")
+	assert.Equal(t, server.SpanContext.SpanID(), wake.Parent.SpanID())
+	assert.Equal(t, "saruman", spanAttribute(wake, infrastructure.AttrMachineID).AsString())
+	assert.Equal(t, "AA:BB:CC:DD:EE:FF", spanAttribute(wake, infrastructure.AttrMachineMAC).AsString())
+}
+
+func TestTracingMiddleware_StartsTraceWithoutParent(t *testing.T) {
+	exporter := recordSpans(t)
+	_, router := newTracingRouter()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/machines/saruman", nil)
+	req.Header.Set("X-API-Key", testAPIKey)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	server := findSpan(t, exporter.GetSpans(), "GET /api/v1/machines/:id")
+	assert.False(t, server.Parent.IsValid())
+	assert.Equal(t, "/api/v1/machines/saruman", spanAttribute(server, "url.path").AsString())
+}
+
+func TestTracingMiddleware_MarksServerErrors(t *testing.T) {
+	exporter := recordSpans(t)
+	sender, router := newTracingRouter()
+	sender.sendError = errors.New("network unreachable")
+	sender.shouldFailCount = 10
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/wol", bytes.NewBufferString(`{"machine_id":"saruman"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-API-Key", testAPIKey)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusInternalServerError, w.Code)
+
+	spans := exporter.GetSpans()
+	assert.Equal(t, codes.Error, findSpan(t, spans, "POST /api/v1/wol").Status.Code)
+	assert.Equal(t, codes.Error, findSpan(t, spans, "WoLUseCase.SendWakePacket").Status.Code)
+}
+
+func TestTracingMiddleware_DisabledByDefault(t *testing.T) {
+	exporter := recordSpans(t)
+	handler, _, _ := newHandlerForTesting(nil)
+	router := NewRouterWithAuthAndConfig(handler, testAPIKey, &config.Config{})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/machines/saruman", nil)
+	req.Header.Set("X-API-Key", testAPIKey)
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	for _, span := range exporter.GetSpans() {
+		assert.NotEqual(t, trace.SpanKindServer, span.SpanKind, span.Name)
+	}
+}
+
+func TestTracingMiddleware_CorrelatesEveryLogRecord(t *testing.T) {
+	recordSpans(t)
+	enabled := true
+	cfg := &config.Config{Observability: config.ObservabilityConfig{Tracing: config.TracingConfig{Enabled: &enabled}}}
+	handler, _, _ := newHandlerForTesting(nil)
+	var logs bytes.Buffer
+	handler.logger = infrastructure.NewLoggerWithWriter(&logs, "json", "debug")
+	router := NewRouterWithAuthAndConfig(handler, testAPIKey, cfg)
+
+	for _, tc := range []struct {
+		path   string
+		apiKey string
+	}{
+		{"/api/v1/machines", testAPIKey},
+		{"/api/v1/machines/saruman", testAPIKey},
+		{"/api/v1/machines/sauron", testAPIKey},
+		{"/api/v1/machines", "wrong-key"},
+	} {
+		req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+		req.Header.Set("X-API-Key", tc.apiKey)
+		req.Header.Set("traceparent", testTraceParent)
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	lines := strings.Split(strings.TrimSpace(logs.String()), "\n")
+	require.Len(t, lines, 4)
+	for _, line := range lines {
+		var record map[string]any
+		require.NoError(t, json.Unmarshal([]byte(line), &record))
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", record["trace_id"], record["msg"])
+		assert.NotEmpty(t, record["span_id"], record["msg"])
+	}
+}
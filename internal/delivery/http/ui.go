@@ -0,0 +1,48 @@
+// Package http provides HTTP delivery layer.
+package http
+
+import (
+	"embed"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UIPath is the path the web dashboard is served at.
+const UIPath = "/ui"
+
+// uiContentSecurityPolicy only lets the dashboard load its own scripts and styles and
+// call the API of its own origin. Inline scripts and styles are not allowed.
+const uiContentSecurityPolicy = "default-src 'none'; script-src 'self'; style-src 'self'; img-src 'self' data:; " +
+	"connect-src 'self'; base-uri 'none'; form-action 'none'; frame-ancestors 'none'"
+
+// uiFiles holds the static dashboard. It calls the API from the browser with the key
+// entered by the user, so the files themselves are served without authentication.
+//
+//go:embed ui
+var uiFiles embed.FS
+
+// ServeUI handles GET /ui/*filepath requests, serving the embedded dashboard.
+func ServeUI(c *gin.Context) {
+	name := strings.TrimPrefix(path.Clean(c.Param("filepath")), "/")
+	if name == "" {
+		name = "index.html"
+	}
+
+	data, err := fs.ReadFile(uiFiles, "ui/"+name)
+	if err != nil {
+		notFoundHandler(c)
+		return
+	}
+
+	c.Header("Content-Security-Policy", uiContentSecurityPolicy)
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Header("X-Frame-Options", "DENY")
+	c.Header("Referrer-Policy", "no-referrer")
+	c.Header("Cache-Control", "no-cache")
+	c.Data(http.StatusOK, mime.TypeByExtension(path.Ext(name)), data)
+}
@@ -0,0 +1,312 @@
+// Gwaihir dashboard: lists the allowlisted machines and wakes them through the API.
+// The API key is only kept in sessionStorage, so it is scoped to the browser tab.
+"use strict";
+
+const API = "/api/v1";
+const KEY_STORAGE = "gwaihir.apiKey";
+const RECONNECT_DELAY = 5000;
+
+const state = {
+  machines: [],
+  // Latest wake result per machine ID: {status: "pending" | "sent" | "failed", time, detail}.
+  results: new Map(),
+  confirming: null,
+  stream: null,
+  lastEventID: "",
+};
+
+const $ = (id) => document.getElementById(id);
+
+class UnauthorizedError extends Error {}
+
+function apiKey() {
+  return sessionStorage.getItem(KEY_STORAGE) || "";
+}
+
+// api calls the API with the stored key, throwing UnauthorizedError on 401.
+async function api(path, options = {}) {
+  const headers = new Headers(options.headers);
+  const key = apiKey();
+  if (key) {
+    headers.set("X-API-Key", key);
+  }
+  const response = await fetch(API + path, { ...options, headers, cache: "no-store" });
+  if (response.status === 401) {
+    throw new UnauthorizedError();
+  }
+  return response;
+}
+
+// problemDetail returns the explanation of a problem details error response.
+async function problemDetail(response) {
+  try {
+    const problem = await response.json();
+    return problem.detail || problem.title || response.statusText;
+  } catch {
+    return response.statusText;
+  }
+}
+
+function setStatus(message, isError = false) {
+  const status = $("status");
+  status.textContent = message;
+  status.classList.toggle("error", isError);
+}
+
+function askForKey(message) {
+  stopStream();
+  $("machines").hidden = true;
+  $("forget-key").hidden = true;
+  $("key-form").hidden = false;
+  setStatus(message, message !== "");
+  $("api-key").focus();
+}
+
+// fetchMachines refreshes the machine list, returning false when it could not.
+async function fetchMachines() {
+  let response;
+  try {
+    response = await api("/machines");
+  } catch (err) {
+    if (err instanceof UnauthorizedError) {
+      askForKey(apiKey() ? "The API key was rejected." : "");
+    } else {
+      setStatus("Gwaihir is unreachable.", true);
+    }
+    return false;
+  }
+  if (!response.ok) {
+    setStatus(await problemDetail(response), true);
+    return false;
+  }
+
+  state.machines = await response.json();
+  state.machines.sort((a, b) => a.name.localeCompare(b.name));
+  render();
+  setStatus(state.machines.length === 0 ? "No machines are configured." : "");
+  return true;
+}
+
+async function start() {
+  setStatus("Loading machines…");
+  if (await fetchMachines()) {
+    $("key-form").hidden = true;
+    $("forget-key").hidden = apiKey() === "";
+    startStream();
+  }
+}
+
+function render() {
+  const table = $("machines");
+  table.tBodies[0].replaceChildren(...state.machines.map(machineRow));
+  table.hidden = state.machines.length === 0;
+}
+
+function machineRow(machine) {
+  const row = document.createElement("tr");
+
+  const name = textCell(machine.name);
+  const id = document.createElement("small");
+  id.textContent = machine.id;
+  name.append(id);
+
+  const button = document.createElement("button");
+  button.type = "button";
+  button.className = "primary";
+  button.textContent = "Wake";
+  button.disabled = state.results.get(machine.id)?.status === "pending";
+  button.addEventListener("click", () => confirmWake(machine));
+  const action = document.createElement("td");
+  action.append(button);
+
+  row.append(name, textCell(machine.mac, "mono"), textCell(machine.broadcast, "mono"), resultCell(machine.id), action);
+  return row;
+}
+
+function textCell(text, className = "") {
+  const cell = document.createElement("td");
+  cell.textContent = text;
+  cell.className = className;
+  return cell;
+}
+
+function resultCell(machineID) {
+  const result = state.results.get(machineID);
+  if (!result) {
+    return textCell("—", "muted");
+  }
+  if (result.status === "pending") {
+    return textCell("Sending…", "muted");
+  }
+
+  const outcome = result.status === "sent" ? "Sent" : "Failed";
+  const detail = result.detail ? `: ${result.detail}` : "";
+  const cell = textCell(`${outcome} at ${result.time.toLocaleTimeString()}${detail}`, result.status);
+  cell.title = result.time.toLocaleString();
+  return cell;
+}
+
+function record(machineID, status, detail = "", time = new Date()) {
+  state.results.set(machineID, { status, detail, time });
+  render();
+}
+
+function confirmWake(machine) {
+  state.confirming = machine;
+  $("confirm-name").textContent = machine.name;
+  $("confirm-mac").textContent = machine.mac;
+  $("confirm-wake").showModal();
+  $("confirm-cancel").focus();
+}
+
+async function wake(machine) {
+  record(machine.id, "pending");
+
+  let response;
+  try {
+    response = await api("/wol", {
+      method: "POST",
+      headers: { "Content-Type": "application/json" },
+      body: JSON.stringify({ machine_id: machine.id }),
+    });
+  } catch (err) {
+    if (err instanceof UnauthorizedError) {
+      state.results.delete(machine.id);
+      askForKey("The API key was rejected.");
+    } else {
+      record(machine.id, "failed", "Gwaihir is unreachable");
+    }
+    return;
+  }
+
+  if (response.ok) {
+    record(machine.id, "sent");
+  } else {
+    record(machine.id, "failed", await problemDetail(response));
+  }
+}
+
+// startStream follows GET /events, so wakes from other clients and changes to the
+// allowlist show up without reloading. EventSource cannot send the API key header,
+// so the stream is read with fetch.
+async function startStream() {
+  stopStream();
+  const controller = new AbortController();
+  state.stream = controller;
+
+  try {
+    const headers = {};
+    if (state.lastEventID) {
+      headers["Last-Event-ID"] = state.lastEventID;
+    }
+    const response = await api("/events", { headers, signal: controller.signal });
+    if (!response.ok) {
+      // The event stream is optional; the dashboard works without it.
+      return;
+    }
+    await readEvents(response.body, controller.signal);
+  } catch (err) {
+    if (err instanceof UnauthorizedError) {
+      askForKey("The API key was rejected.");
+      return;
+    }
+  }
+
+  if (state.stream === controller && !controller.signal.aborted) {
+    setTimeout(() => {
+      if (state.stream === controller) {
+        startStream();
+      }
+    }, RECONNECT_DELAY);
+  }
+}
+
+function stopStream() {
+  if (state.stream) {
+    state.stream.abort();
+    state.stream = null;
+  }
+}
+
+async function readEvents(body, signal) {
+  const reader = body.pipeThrough(new TextDecoderStream()).getReader();
+  let buffer = "";
+  while (!signal.aborted) {
+    const { value, done } = await reader.read();
+    if (done) {
+      return;
+    }
+    buffer += value;
+    let end;
+    while ((end = buffer.indexOf("\n\n")) >= 0) {
+      handleFrame(buffer.slice(0, end));
+      buffer = buffer.slice(end + 2);
+    }
+  }
+}
+
+function handleFrame(frame) {
+  let data = "";
+  for (const line of frame.split("\n")) {
+    if (line.startsWith("id: ")) {
+      state.lastEventID = line.slice(4);
+    } else if (line.startsWith("data: ")) {
+      data += line.slice(6);
+    }
+  }
+  if (data === "") {
+    return;
+  }
+
+  const event = JSON.parse(data);
+  if (event.type.startsWith("wake.") && !event.machine_id) {
+    // Wakes outside the allowlist have no row to update.
+    return;
+  }
+  switch (event.type) {
+    case "wake.sent":
+      record(event.machine_id, "sent", "", new Date(event.time));
+      break;
+    case "wake.failed":
+      record(event.machine_id, "failed", "The magic packet could not be sent", new Date(event.time));
+      break;
+    case "machine.created":
+    case "machine.updated":
+    case "machine.deleted":
+    case "config.reloaded":
+      fetchMachines();
+      break;
+  }
+}
+
+document.addEventListener("DOMContentLoaded", () => {
+  $("key-form").addEventListener("submit", (event) => {
+    event.preventDefault();
+    sessionStorage.setItem(KEY_STORAGE, $("api-key").value.trim());
+    $("api-key").value = "";
+    start();
+  });
+
+  $("forget-key").addEventListener("click", () => {
+    sessionStorage.removeItem(KEY_STORAGE);
+    state.machines = [];
+    state.results.clear();
+    state.lastEventID = "";
+    render();
+    askForKey("");
+  });
+
+  $("confirm-cancel").addEventListener("click", () => $("confirm-wake").close());
+  $("confirm-ok").addEventListener("click", () => {
+    const machine = state.confirming;
+    $("confirm-wake").close();
+    if (machine) {
+      wake(machine);
+    }
+  });
+  $("confirm-wake").addEventListener("close", () => {
+    state.confirming = null;
+  });
+
+  start();
+});
@@ -0,0 +1,85 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/josimar-silva/gwaihir/internal/config"
+)
+
+func doUIRequest(t *testing.T, cfg *config.Config, path string) *httptest.ResponseRecorder {
+	t.Helper()
+	handler, _, _ := newHandlerForTesting(nil)
+	router := NewRouterWithAuthAndConfig(handler, testAPIKey, cfg)
+
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestServeUI(t *testing.T) {
+	tests := []struct {
+		path        string
+		contentType string
+		contains    string
+	}{
+		{"/ui/", "text/html; charset=utf-8", `<script src="app.js" defer></script>`},
+		{"/ui/index.html", "text/html; charset=utf-8", "<title>Gwaihir</title>"},
+		{"/ui/app.js", "text/javascript; charset=utf-8", "sessionStorage"},
+		{"/ui/style.css", "text/css; charset=utf-8", ":root"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			// The dashboard is public even when the API requires a key.
+			w := doUIRequest(t, nil, tt.path)
+
+			require.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, tt.contentType, w.Header().Get("Content-Type"))
+			assert.Equal(t, uiContentSecurityPolicy, w.Header().Get("Content-Security-Policy"))
+			assert.Equal(t, "nosniff", w.Header().Get("X-Content-Type-Options"))
+			assert.Equal(t, "DENY", w.Header().Get("X-Frame-Options"))
+			assert.Contains(t, w.Body.String(), tt.contains)
+		})
+	}
+}
+
+func TestServeUI_RedirectsToIndex(t *testing.T) {
+	w := doUIRequest(t, nil, "/ui")
+
+	assert.Equal(t, http.StatusMovedPermanently, w.Code)
+	assert.Equal(t, "/ui/", w.Header().Get("Location"))
+}
+
+func TestServeUI_NotFound(t *testing.T) {
+	for _, path := range []string{"/ui/missing.js", "/ui/../openapi.json"} {
+		w := doUIRequest(t, nil, path)
+
+		assert.Equal(t, http.StatusNotFound, w.Code, path)
+		assert.Equal(t, ProblemContentType, w.Header().Get("Content-Type"), path)
+	}
+}
+
+func TestServeUI_Disabled(t *testing.T) {
+	disabled := false
+	w := doUIRequest(t, &config.Config{UI: config.UIConfig{Enabled: &disabled}}, "/ui/")
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+// TestServeUI_CompatibleWithContentSecurityPolicy guards against inline scripts,
+// styles and event handlers, which the policy blocks.
+func TestServeUI_CompatibleWithContentSecurityPolicy(t *testing.T) {
+	index, err := uiFiles.ReadFile("ui/index.html")
+	require.NoError(t, err)
+
+	assert.NotRegexp(t, regexp.MustCompile(`<script(\s[^>]*)?>\s*[^<\s]`), string(index), "inline script")
+	assert.NotRegexp(t, regexp.MustCompile(`<style`), string(index), "inline style element")
+	assert.NotRegexp(t, regexp.MustCompile(`\sstyle=`), string(index), "inline style attribute")
+	assert.NotRegexp(t, regexp.MustCompile(`\son[a-z]+=`), string(index), "inline event handler")
+}
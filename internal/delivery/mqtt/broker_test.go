@@ -0,0 +1,262 @@
+package mqtt
+
+import (
+	"net"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/eclipse/paho.mqtt.golang/packets"
+)
+
+// testBroker is a minimal in-process MQTT 3.1.1 broker supporting QoS 0 and 1,
+// retained messages and wills, enough to run the client against a real connection.
+type testBroker struct {
+	listener net.Listener
+
+	mu       sync.Mutex
+	sessions map[*brokerSession]bool
+	retained map[string]*packets.PublishPacket
+	history  map[string][]string
+	nextID   uint16
+}
+
+type brokerSession struct {
+	conn net.Conn
+
+	willMu sync.Mutex
+	will   *packets.PublishPacket
+
+	writeMu sync.Mutex
+	subs    []string
+}
+
+// takeWill returns the will of the session, at most once.
+func (s *brokerSession) takeWill() *packets.PublishPacket {
+	s.willMu.Lock()
+	defer s.willMu.Unlock()
+	will := s.will
+	s.will = nil
+	return will
+}
+
+func newTestBroker(t *testing.T) *testBroker {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+
+	b := &testBroker{
+		listener: listener,
+		sessions: make(map[*brokerSession]bool),
+		retained: make(map[string]*packets.PublishPacket),
+		history:  make(map[string][]string),
+	}
+	go b.serve()
+	t.Cleanup(b.close)
+	return b
+}
+
+func (b *testBroker) url() string {
+	return "tcp://" + b.listener.Addr().String()
+}
+
+func (b *testBroker) close() {
+	_ = b.listener.Close()
+	b.dropConnections()
+}
+
+// dropConnections closes all client connections without a DISCONNECT, as a network
+// failure would, and publishes the wills of the clients before they can reconnect.
+func (b *testBroker) dropConnections() {
+	b.mu.Lock()
+	sessions := make([]*brokerSession, 0, len(b.sessions))
+	for session := range b.sessions {
+		sessions = append(sessions, session)
+	}
+	b.mu.Unlock()
+
+	for _, session := range sessions {
+		will := session.takeWill()
+		_ = session.conn.Close()
+		if will != nil {
+			b.route(will)
+		}
+	}
+}
+
+// payloads returns the payloads published to topic, in order.
+func (b *testBroker) payloads(topic string) []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]string(nil), b.history[topic]...)
+}
+
+// retainedPayload returns the retained payload of topic and whether one exists.
+func (b *testBroker) retainedPayload(topic string) (string, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	msg, ok := b.retained[topic]
+	if !ok {
+		return "", false
+	}
+	return string(msg.Payload), true
+}
+
+func (b *testBroker) serve() {
+	for {
+		conn, err := b.listener.Accept()
+		if err != nil {
+			return
+		}
+		go b.handle(&brokerSession{conn: conn})
+	}
+}
+
+func (b *testBroker) handle(s *brokerSession) {
+	defer func() {
+		_ = s.conn.Close()
+		b.mu.Lock()
+		delete(b.sessions, s)
+		b.mu.Unlock()
+	}()
+
+	packet, err := packets.ReadPacket(s.conn)
+	if err != nil {
+		return
+	}
+	connect, ok := packet.(*packets.ConnectPacket)
+	if !ok {
+		return
+	}
+	if connect.WillFlag {
+		will := packets.NewControlPacket(packets.Publish).(*packets.PublishPacket)
+		will.TopicName = connect.WillTopic
+		will.Payload = connect.WillMessage
+		will.Retain = connect.WillRetain
+		s.willMu.Lock()
+		s.will = will
+		s.willMu.Unlock()
+	}
+	b.mu.Lock()
+	b.sessions[s] = true
+	b.mu.Unlock()
+
+	connack := packets.NewControlPacket(packets.Connack).(*packets.ConnackPacket)
+	if err := s.write(connack); err != nil {
+		return
+	}
+
+	for {
+		packet, err := packets.ReadPacket(s.conn)
+		if err != nil {
+			if will := s.takeWill(); will != nil {
+				b.route(will)
+			}
+			return
+		}
+
+		switch p := packet.(type) {
+		case *packets.SubscribePacket:
+			suback := packets.NewControlPacket(packets.Suback).(*packets.SubackPacket)
+			suback.MessageID = p.MessageID
+			suback.ReturnCodes = make([]byte, len(p.Topics))
+			b.mu.Lock()
+			s.subs = append(s.subs, p.Topics...)
+			var retained []*packets.PublishPacket
+			for topic, msg := range b.retained {
+				for _, filter := range p.Topics {
+					if topicMatches(filter, topic) {
+						retained = append(retained, msg)
+						break
+					}
+				}
+			}
+			b.mu.Unlock()
+			_ = s.write(suback)
+			for _, msg := range retained {
+				b.deliver(s, msg)
+			}
+		case *packets.PublishPacket:
+			if p.Qos > 0 {
+				puback := packets.NewControlPacket(packets.Puback).(*packets.PubackPacket)
+				puback.MessageID = p.MessageID
+				_ = s.write(puback)
+			}
+			b.route(p)
+		case *packets.PingreqPacket:
+			_ = s.write(packets.NewControlPacket(packets.Pingresp))
+		case *packets.UnsubscribePacket:
+			unsuback := packets.NewControlPacket(packets.Unsuback).(*packets.UnsubackPacket)
+			unsuback.MessageID = p.MessageID
+			_ = s.write(unsuback)
+		case *packets.DisconnectPacket:
+			return
+		}
+	}
+}
+
+// route stores retained messages and delivers msg to the matching subscriptions.
+func (b *testBroker) route(msg *packets.PublishPacket) {
+	b.mu.Lock()
+	b.history[msg.TopicName] = append(b.history[msg.TopicName], string(msg.Payload))
+	if msg.Retain {
+		if len(msg.Payload) == 0 {
+			delete(b.retained, msg.TopicName)
+		} else {
+			b.retained[msg.TopicName] = msg
+		}
+	}
+	var targets []*brokerSession
+	for session := range b.sessions {
+		for _, filter := range session.subs {
+			if topicMatches(filter, msg.TopicName) {
+				targets = append(targets, session)
+				break
+			}
+		}
+	}
+	b.mu.Unlock()
+
+	for _, session := range targets {
+		b.deliver(session, msg)
+	}
+}
+
+func (b *testBroker) deliver(s *brokerSession, msg *packets.PublishPacket) {
+	out := packets.NewControlPacket(packets.Publish).(*packets.PublishPacket)
+	out.TopicName = msg.TopicName
+	out.Payload = msg.Payload
+	out.Qos = msg.Qos
+	out.Retain = msg.Retain
+	if out.Qos > 0 {
+		b.mu.Lock()
+		b.nextID++
+		out.MessageID = b.nextID
+		b.mu.Unlock()
+	}
+	_ = s.write(out)
+}
+
+func (s *brokerSession) write(packet packets.ControlPacket) error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	return packet.Write(s.conn)
+}
+
+// topicMatches reports whether topic matches filter, which may contain the + and #
+// wildcards.
+func topicMatches(filter, topic string) bool {
+	filterLevels := strings.Split(filter, "/")
+	topicLevels := strings.Split(topic, "/")
+	for i, level := range filterLevels {
+		if level == "#" {
+			return true
+		}
+		if i >= len(topicLevels) || (level != "+" && level != topicLevels[i]) {
+			return false
+		}
+	}
+	return len(filterLevels) == len(topicLevels)
+}
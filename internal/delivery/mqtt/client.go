@@ -0,0 +1,449 @@
+// Package mqtt provides the MQTT delivery layer, including Home Assistant discovery.
+package mqtt
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	paho "github.com/eclipse/paho.mqtt.golang"
+	"github.com/google/uuid"
+
+	"github.com/josimar-silva/gwaihir/internal/config"
+	"github.com/josimar-silva/gwaihir/internal/domain"
+	"github.com/josimar-silva/gwaihir/internal/infrastructure"
+)
+
+// Availability payloads published to the status topic.
+const (
+	PayloadOnline  = "online"
+	PayloadOffline = "offline"
+)
+
+// Results of wake commands, used as metric labels.
+const (
+	resultSent     = "sent"
+	resultFailed   = "failed"
+	resultNotFound = "not_found"
+	resultInvalid  = "invalid"
+)
+
+// auditCaller identifies wake commands received over MQTT in the audit trail.
+const auditCaller = "mqtt"
+
+// DefaultSyncInterval is how often the Home Assistant discovery payloads are compared
+// against the machine allowlist when no interval is set.
+const DefaultSyncInterval = time.Minute
+
+// operationTimeout bounds how long publishing and subscribing wait for the broker.
+const operationTimeout = 10 * time.Second
+
+// wakeTimeout bounds how long a wake command may take.
+const wakeTimeout = 10 * time.Second
+
+// connectRetryInterval is the longest wait between attempts of the initial connection.
+const connectRetryInterval = 10 * time.Second
+
+// disconnectQuiesce is how long, in milliseconds, Stop waits for pending work.
+const disconnectQuiesce = 250
+
+// WakeUseCase wakes and lists the allowlisted machines.
+type WakeUseCase interface {
+	SendWakePacket(ctx context.Context, machineID string) error
+	ListMachines() ([]*domain.Machine, error)
+}
+
+// EventSource provides the machine events published as wake results.
+type EventSource interface {
+	// Subscribe returns a channel receiving the events published from now on and a
+	// function that ends the subscription.
+	Subscribe() (<-chan domain.Event, func())
+}
+
+// Client connects to an MQTT broker, wakes machines on messages to
+// <prefix>/<machine_id>/wake and publishes wake results to <prefix>/<machine_id>/result
+// and its availability to <prefix>/status.
+type Client struct {
+	cfg          config.MQTTConfig
+	wolUseCase   WakeUseCase
+	logger       *infrastructure.Logger
+	metrics      *infrastructure.Metrics
+	events       EventSource
+	audit        domain.AuditLog
+	syncInterval time.Duration
+
+	client paho.Client
+	done   chan struct{}
+	wg     sync.WaitGroup
+
+	mu sync.Mutex
+	// announced maps the discovery topics published for the current machines to their payloads.
+	announced map[string][]byte
+}
+
+// NewClient creates an MQTT client for the WoL use case configured by cfg.
+func NewClient(cfg config.MQTTConfig, wolUseCase WakeUseCase, logger *infrastructure.Logger, metrics *infrastructure.Metrics) *Client {
+	return &Client{
+		cfg:          cfg,
+		wolUseCase:   wolUseCase,
+		logger:       logger,
+		metrics:      metrics,
+		syncInterval: DefaultSyncInterval,
+		done:         make(chan struct{}),
+		announced:    make(map[string][]byte),
+	}
+}
+
+// WithEvents publishes the wake results of events, including wakes requested through
+// the HTTP and gRPC APIs.
+func (c *Client) WithEvents(source EventSource) *Client {
+	c.events = source
+	return c
+}
+
+// WithAuditLog enables recording of wake commands to the audit trail.
+func (c *Client) WithAuditLog(audit domain.AuditLog) *Client {
+	c.audit = audit
+	return c
+}
+
+// WithSyncInterval sets how often the Home Assistant discovery payloads are updated
+// for machines added, changed or removed while connected.
+func (c *Client) WithSyncInterval(interval time.Duration) *Client {
+	c.syncInterval = interval
+	return c
+}
+
+// Start connects to the broker in the background. Connection failures are retried
+// with exponential backoff, so Start only fails on invalid TLS settings.
+func (c *Client) Start() error {
+	opts, err := c.clientOptions()
+	if err != nil {
+		return err
+	}
+
+	c.metrics.MQTTConnected.Set(0)
+	c.client = paho.NewClient(opts)
+	c.client.Connect()
+
+	if c.events != nil {
+		events, unsubscribe := c.events.Subscribe()
+		c.wg.Add(1)
+		go c.publishResults(events, unsubscribe)
+	}
+	if c.homeAssistant() && c.syncInterval > 0 {
+		c.wg.Add(1)
+		go c.syncLoop()
+	}
+	return nil
+}
+
+// Stop publishes the offline status and disconnects from the broker.
+func (c *Client) Stop() {
+	close(c.done)
+	c.wg.Wait()
+
+	if c.client.IsConnectionOpen() {
+		c.publish(c.statusTopic(), true, []byte(PayloadOffline))
+	}
+	c.client.Disconnect(disconnectQuiesce)
+	c.metrics.MQTTConnected.Set(0)
+}
+
+func (c *Client) clientOptions() (*paho.ClientOptions, error) {
+	opts := paho.NewClientOptions().
+		AddBroker(c.cfg.Broker).
+		SetClientID(c.cfg.ClientID).
+		SetUsername(c.cfg.Username).
+		SetPassword(c.cfg.Password).
+		SetCleanSession(true).
+		SetOrderMatters(false).
+		SetConnectTimeout(c.cfg.ConnectTimeout).
+		SetAutoReconnect(true).
+		SetMaxReconnectInterval(c.cfg.MaxReconnectInterval).
+		SetConnectRetry(true).
+		SetConnectRetryInterval(min(connectRetryInterval, c.cfg.MaxReconnectInterval)).
+		SetWill(c.statusTopic(), PayloadOffline, c.qos(), true).
+		SetOnConnectHandler(c.onConnect).
+		SetConnectionLostHandler(c.onConnectionLost).
+		SetReconnectingHandler(func(paho.Client, *paho.ClientOptions) {
+			c.logger.Info("Reconnecting to MQTT broker", infrastructure.String("broker", config.RedactURL(c.cfg.Broker)))
+		})
+
+	tlsConfig, err := newTLSConfig(c.cfg.TLS)
+	if err != nil {
+		return nil, err
+	}
+	if tlsConfig != nil {
+		opts.SetTLSConfig(tlsConfig)
+	}
+	return opts, nil
+}
+
+// newTLSConfig returns the TLS settings of the broker connection, or nil when none
+// are configured and the defaults of the broker URL scheme apply.
+func newTLSConfig(cfg config.MQTTTLSConfig) (*tls.Config, error) {
+	if cfg == (config.MQTTTLSConfig{}) {
+		return nil, nil
+	}
+
+	// #nosec G402 - skipping verification is an explicit opt-in for self-signed brokers
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: cfg.InsecureSkipVerify,
+	}
+
+	if cfg.CAFile != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		// #nosec G304 - path is controlled by application configuration, not user input
+		pem, err := os.ReadFile(cfg.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read mqtt.tls.ca_file: %w", err)
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("failed to read mqtt.tls.ca_file: no certificates found in %s", cfg.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if cfg.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load mqtt.tls client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
+
+func (c *Client) onConnect(client paho.Client) {
+	c.metrics.MQTTConnected.Set(1)
+	c.logger.Info("Connected to MQTT broker", infrastructure.String("broker", config.RedactURL(c.cfg.Broker)))
+
+	token := client.Subscribe(c.commandTopic("+"), c.qos(), c.handleWake)
+	if err := wait(token); err != nil {
+		c.logger.Error("Failed to subscribe to MQTT wake commands",
+			infrastructure.String("topic", c.commandTopic("+")),
+			infrastructure.Any("error", err),
+		)
+	}
+
+	c.publish(c.statusTopic(), true, []byte(PayloadOnline))
+
+	// The broker may have lost the retained payloads, e.g. after a restart without persistence.
+	c.syncDiscovery(true)
+}
+
+func (c *Client) onConnectionLost(_ paho.Client, err error) {
+	c.metrics.MQTTConnected.Set(0)
+	c.logger.Warn("Lost connection to MQTT broker",
+		infrastructure.String("broker", config.RedactURL(c.cfg.Broker)),
+		infrastructure.Any("error", err),
+	)
+}
+
+// handleWake wakes the machine named by the topic of a command. The payload is
+// ignored, so Home Assistant's PRESS works as well as any other message. Each command
+// gets a request ID, like HTTP and gRPC calls, to correlate its logs and audit record.
+func (c *Client) handleWake(_ paho.Client, msg paho.Message) {
+	topic := msg.Topic()
+	machineID := strings.TrimSuffix(strings.TrimPrefix(topic, c.cfg.TopicPrefix+"/"), "/wake")
+	requestID := uuid.New().String()
+	logger := c.logger.WithContext(
+		infrastructure.String("request_id", requestID),
+		infrastructure.String("topic", topic),
+	)
+
+	if err := domain.ValidateMachineID(machineID); err != nil {
+		c.metrics.MQTTCommands.WithLabelValues(resultInvalid).Inc()
+		c.recordAudit(requestID, topic, "", domain.AuditOutcomeInvalid)
+		logger.Warn("Invalid MQTT wake command", infrastructure.Any("error", err))
+		return
+	}
+
+	logger.Info("Received MQTT wake command", infrastructure.String("machine_id", machineID))
+
+	ctx, cancel := context.WithTimeout(context.Background(), wakeTimeout)
+	defer cancel()
+
+	err := c.wolUseCase.SendWakePacket(ctx, machineID)
+	switch {
+	case err == nil:
+		c.metrics.MQTTCommands.WithLabelValues(resultSent).Inc()
+		c.recordAudit(requestID, topic, machineID, domain.AuditOutcomeSuccess)
+	case errors.Is(err, domain.ErrMachineNotFound):
+		c.metrics.MQTTCommands.WithLabelValues(resultNotFound).Inc()
+		c.recordAudit(requestID, topic, machineID, domain.AuditOutcomeNotFound)
+		logger.Warn("MQTT wake command for a machine outside the allowlist",
+			infrastructure.String("machine_id", machineID),
+		)
+	default:
+		c.metrics.MQTTCommands.WithLabelValues(resultFailed).Inc()
+		c.recordAudit(requestID, topic, machineID, domain.AuditOutcomeFailure)
+		logger.Error("MQTT wake command failed",
+			infrastructure.String("machine_id", machineID),
+			infrastructure.Any("error", err),
+		)
+	}
+}
+
+// publishResults publishes the sent and failed wake events of allowlisted machines.
+func (c *Client) publishResults(events <-chan domain.Event, unsubscribe func()) {
+	defer c.wg.Done()
+	defer unsubscribe()
+
+	for {
+		select {
+		case <-c.done:
+			return
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			if event.MachineID == "" || (event.Type != domain.EventWakeSent && event.Type != domain.EventWakeFailed) {
+				continue
+			}
+			payload, err := json.Marshal(event)
+			if err != nil {
+				continue
+			}
+			c.publish(c.resultTopic(event.MachineID), false, payload)
+		}
+	}
+}
+
+func (c *Client) syncLoop() {
+	defer c.wg.Done()
+
+	ticker := time.NewTicker(c.syncInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-ticker.C:
+			if c.client.IsConnectionOpen() {
+				c.syncDiscovery(false)
+			}
+		}
+	}
+}
+
+// syncDiscovery publishes the discovery payloads of new and changed machines, or of
+// all machines when force is set, and clears those of removed machines.
+func (c *Client) syncDiscovery(force bool) {
+	if !c.homeAssistant() {
+		return
+	}
+
+	machines, err := c.wolUseCase.ListMachines()
+	if err != nil {
+		c.logger.Error("Failed to list machines for Home Assistant discovery", infrastructure.Any("error", err))
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	current := make(map[string][]byte, len(machines))
+	for _, machine := range machines {
+		topic, payload, err := c.discovery(machine)
+		if err != nil {
+			continue
+		}
+		current[topic] = payload
+		if !force && string(c.announced[topic]) == string(payload) {
+			continue
+		}
+		if !c.publish(topic, true, payload) {
+			// Retried on the next sync.
+			delete(current, topic)
+		}
+	}
+
+	for topic := range c.announced {
+		if _, exists := current[topic]; !exists {
+			// An empty retained payload removes the entity from Home Assistant.
+			c.publish(topic, true, nil)
+		}
+	}
+	c.announced = current
+}
+
+// publish publishes payload to topic and reports whether the broker accepted it.
+func (c *Client) publish(topic string, retained bool, payload []byte) bool {
+	if err := wait(c.client.Publish(topic, c.qos(), retained, payload)); err != nil {
+		c.logger.Warn("Failed to publish MQTT message",
+			infrastructure.String("topic", topic),
+			infrastructure.Any("error", err),
+		)
+		return false
+	}
+	return true
+}
+
+// recordAudit records a wake command received on topic; the topic is kept as the
+// detail of the audit event.
+func (c *Client) recordAudit(requestID, topic, machineID, outcome string) {
+	if c.audit == nil {
+		return
+	}
+
+	err := c.audit.Record(domain.AuditEvent{
+		Action:    domain.AuditActionWake,
+		Caller:    auditCaller,
+		RequestID: requestID,
+		MachineID: machineID,
+		Outcome:   outcome,
+		Detail:    topic,
+	})
+	if err != nil {
+		c.logger.Error("Failed to record audit event",
+			infrastructure.String("request_id", requestID),
+			infrastructure.String("action", domain.AuditActionWake),
+			infrastructure.Any("error", err),
+		)
+	}
+}
+
+func (c *Client) homeAssistant() bool {
+	return c.cfg.HomeAssistant.Enabled != nil && *c.cfg.HomeAssistant.Enabled
+}
+
+func (c *Client) qos() byte {
+	if c.cfg.QoS == nil {
+		return 1
+	}
+	return byte(*c.cfg.QoS) // #nosec G115 - validated to be 0, 1 or 2
+}
+
+func (c *Client) statusTopic() string {
+	return c.cfg.TopicPrefix + "/status"
+}
+
+func (c *Client) commandTopic(machineID string) string {
+	return c.cfg.TopicPrefix + "/" + machineID + "/wake"
+}
+
+func (c *Client) resultTopic(machineID string) string {
+	return c.cfg.TopicPrefix + "/" + machineID + "/result"
+}
+
+func wait(token paho.Token) error {
+	if !token.WaitTimeout(operationTimeout) {
+		return fmt.Errorf("timed out after %s", operationTimeout)
+	}
+	return token.Error()
+}
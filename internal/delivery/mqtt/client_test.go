@@ -0,0 +1,407 @@
+package mqtt
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	paho "github.com/eclipse/paho.mqtt.golang"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/josimar-silva/gwaihir/internal/config"
+	"github.com/josimar-silva/gwaihir/internal/domain"
+	"github.com/josimar-silva/gwaihir/internal/infrastructure"
+	"github.com/josimar-silva/gwaihir/internal/usecase"
+)
+
+const waitFor = 5 * time.Second
+
+type mockRepository struct {
+	mu       sync.Mutex
+	machines map[string]*domain.Machine
+}
+
+func (m *mockRepository) GetByID(id string) (*domain.Machine, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	machine, ok := m.machines[id]
+	if !ok {
+		return nil, domain.ErrMachineNotFound
+	}
+	return machine, nil
+}
+
+func (m *mockRepository) GetAll() ([]*domain.Machine, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	machines := make([]*domain.Machine, 0, len(m.machines))
+	for _, machine := range m.machines {
+		machines = append(machines, machine)
+	}
+	return machines, nil
+}
+
+func (m *mockRepository) Exists(id string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, ok := m.machines[id]
+	return ok
+}
+
+func (m *mockRepository) set(machines ...*domain.Machine) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.machines = make(map[string]*domain.Machine, len(machines))
+	for _, machine := range machines {
+		m.machines[machine.ID] = machine
+	}
+}
+
+type mockPacketSender struct {
+	mu    sync.Mutex
+	err   error
+	sends []string
+}
+
+func (m *mockPacketSender) SendMagicPacket(_ context.Context, mac, _ string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sends = append(m.sends, mac)
+	return m.err
+}
+
+func (m *mockPacketSender) sent() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]string(nil), m.sends...)
+}
+
+type mockAuditLog struct {
+	mu     sync.Mutex
+	events []domain.AuditEvent
+}
+
+func (m *mockAuditLog) Record(event domain.AuditEvent) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.events = append(m.events, event)
+	return nil
+}
+
+func (m *mockAuditLog) Query(domain.AuditFilter) ([]domain.AuditRecord, error) {
+	return nil, nil
+}
+
+func (m *mockAuditLog) recorded() []domain.AuditEvent {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]domain.AuditEvent(nil), m.events...)
+}
+
+var (
+	saruman = &domain.Machine{ID: "saruman", Name: "Saruman", MAC: "AA:BB:CC:DD:EE:FF", Broadcast: "192.168.1.255"}
+	gandalf = &domain.Machine{ID: "gandalf", Name: "Gandalf", MAC: "11:22:33:44:55:66", Broadcast: "192.168.1.255"}
+)
+
+type testEnv struct {
+	broker  *testBroker
+	client  *Client
+	repo    *mockRepository
+	sender  *mockPacketSender
+	audit   *mockAuditLog
+	metrics *infrastructure.Metrics
+}
+
+func testConfig(broker string) config.MQTTConfig {
+	enabled := true
+	qos := 1
+	return config.MQTTConfig{
+		Enabled:              &enabled,
+		Broker:               broker,
+		ClientID:             "gwaihir-test",
+		TopicPrefix:          "gwaihir",
+		QoS:                  &qos,
+		ConnectTimeout:       time.Second,
+		MaxReconnectInterval: time.Second,
+		HomeAssistant:        config.HomeAssistantConfig{Enabled: &enabled, DiscoveryPrefix: "homeassistant"},
+	}
+}
+
+// newTestEnv starts a client against an in-process broker.
+func newTestEnv(t *testing.T) *testEnv {
+	t.Helper()
+	metrics, err := infrastructure.NewMetrics()
+	require.NoError(t, err)
+	logger := infrastructure.NewLogger("text", "error")
+
+	env := &testEnv{
+		broker:  newTestBroker(t),
+		repo:    &mockRepository{},
+		sender:  &mockPacketSender{},
+		audit:   &mockAuditLog{},
+		metrics: metrics,
+	}
+	env.repo.set(saruman, gandalf)
+
+	events := infrastructure.NewEventBus(16)
+	useCase := usecase.NewWoLUseCase(env.repo, env.sender, logger, metrics).WithEvents(events)
+	env.client = NewClient(testConfig(env.broker.url()), useCase, logger, metrics).
+		WithEvents(events).
+		WithAuditLog(env.audit).
+		WithSyncInterval(50 * time.Millisecond)
+	require.NoError(t, env.client.Start())
+
+	require.Eventually(t, func() bool {
+		return testutil.ToFloat64(metrics.MQTTConnected) == 1
+	}, waitFor, 10*time.Millisecond)
+	return env
+}
+
+func (e *testEnv) retained(t *testing.T, topic string) string {
+	t.Helper()
+	var payload string
+	require.Eventually(t, func() bool {
+		var ok bool
+		payload, ok = e.broker.retainedPayload(topic)
+		return ok
+	}, waitFor, 10*time.Millisecond, "no retained message on %s", topic)
+	return payload
+}
+
+// subscribe connects a second client to the broker and returns the messages it
+// receives on filter.
+func (e *testEnv) subscribe(t *testing.T, filter string) (paho.Client, <-chan paho.Message) {
+	t.Helper()
+	messages := make(chan paho.Message, 16)
+	observer := paho.NewClient(paho.NewClientOptions().AddBroker(e.broker.url()).SetClientID("observer"))
+	require.NoError(t, wait(observer.Connect()))
+	t.Cleanup(func() {
+		observer.Disconnect(0)
+	})
+	require.NoError(t, wait(observer.Subscribe(filter, 1, func(_ paho.Client, msg paho.Message) {
+		messages <- msg
+	})))
+	return observer, messages
+}
+
+func receive(t *testing.T, messages <-chan paho.Message) paho.Message {
+	t.Helper()
+	select {
+	case msg := <-messages:
+		return msg
+	case <-time.After(waitFor):
+		t.Fatal("timed out waiting for an MQTT message")
+		return nil
+	}
+}
+
+func TestClient_AnnouncesAvailabilityAndMachines(t *testing.T) {
+	env := newTestEnv(t)
+	defer env.client.Stop()
+
+	assert.Equal(t, PayloadOnline, env.retained(t, "gwaihir/status"))
+
+	var button map[string]any
+	require.NoError(t, json.Unmarshal([]byte(env.retained(t, "homeassistant/button/gwaihir-test/saruman/config")), &button))
+	assert.Equal(t, "Wake", button["name"])
+	assert.Equal(t, "gwaihir-test_saruman_wake", button["unique_id"])
+	assert.Equal(t, "gwaihir/saruman/wake", button["command_topic"])
+	assert.Equal(t, PayloadPress, button["payload_press"])
+	assert.Equal(t, "gwaihir/status", button["availability_topic"])
+	assert.Equal(t, map[string]any{
+		"identifiers": []any{"gwaihir-test_saruman"},
+		"name":        "Saruman",
+		"connections": []any{[]any{"mac", "aa:bb:cc:dd:ee:ff"}},
+		"model":       "Wake-on-LAN",
+	}, button["device"])
+
+	env.retained(t, "homeassistant/button/gwaihir-test/gandalf/config")
+}
+
+func TestClient_WakeCommand(t *testing.T) {
+	env := newTestEnv(t)
+	defer env.client.Stop()
+
+	observer, results := env.subscribe(t, "gwaihir/+/result")
+	require.NoError(t, wait(observer.Publish("gwaihir/saruman/wake", 1, false, PayloadPress)))
+
+	msg := receive(t, results)
+	assert.Equal(t, "gwaihir/saruman/result", msg.Topic())
+	var event domain.Event
+	require.NoError(t, json.Unmarshal(msg.Payload(), &event))
+	assert.Equal(t, domain.EventWakeSent, event.Type)
+	assert.Equal(t, "saruman", event.MachineID)
+
+	assert.Equal(t, []string{"AA:BB:CC:DD:EE:FF"}, env.sender.sent())
+	assert.Equal(t, 1.0, testutil.ToFloat64(env.metrics.MQTTCommands.WithLabelValues(resultSent)))
+	require.Len(t, env.audit.recorded(), 1)
+	recorded := env.audit.recorded()[0]
+	assert.NotEmpty(t, recorded.RequestID)
+	recorded.RequestID = ""
+	assert.Equal(t, domain.AuditEvent{
+		Action:    domain.AuditActionWake,
+		Caller:    auditCaller,
+		MachineID: "saruman",
+		Outcome:   domain.AuditOutcomeSuccess,
+		Detail:    "gwaihir/saruman/wake",
+	}, recorded)
+}
+
+func TestClient_WakeCommandFailures(t *testing.T) {
+	env := newTestEnv(t)
+	defer env.client.Stop()
+
+	observer, results := env.subscribe(t, "gwaihir/+/result")
+
+	require.NoError(t, wait(observer.Publish("gwaihir/sauron/wake", 1, false, PayloadPress)))
+	assert.Eventually(t, func() bool {
+		return testutil.ToFloat64(env.metrics.MQTTCommands.WithLabelValues(resultNotFound)) == 1
+	}, waitFor, 10*time.Millisecond)
+	assert.Empty(t, env.sender.sent())
+
+	env.sender.mu.Lock()
+	env.sender.err = errors.New("network unreachable")
+	env.sender.mu.Unlock()
+	require.NoError(t, wait(observer.Publish("gwaihir/gandalf/wake", 1, false, PayloadPress)))
+
+	msg := receive(t, results)
+	var event domain.Event
+	require.NoError(t, json.Unmarshal(msg.Payload(), &event))
+	assert.Equal(t, domain.EventWakeFailed, event.Type)
+	assert.Equal(t, "gandalf", event.MachineID)
+	assert.Eventually(t, func() bool {
+		return testutil.ToFloat64(env.metrics.MQTTCommands.WithLabelValues(resultFailed)) == 1
+	}, waitFor, 10*time.Millisecond)
+
+	outcomes := []string{}
+	for _, event := range env.audit.recorded() {
+		outcomes = append(outcomes, event.Outcome)
+	}
+	assert.Equal(t, []string{domain.AuditOutcomeNotFound, domain.AuditOutcomeFailure}, outcomes)
+}
+
+func TestClient_WakeCommandInvalidMachineID(t *testing.T) {
+	env := newTestEnv(t)
+	defer env.client.Stop()
+
+	observer, _ := env.subscribe(t, "gwaihir/+/result")
+	require.NoError(t, wait(observer.Publish("gwaihir/-sauron/wake", 1, false, PayloadPress)))
+	require.NoError(t, wait(observer.Publish("gwaihir/saruman/wake", 1, false, PayloadPress)))
+
+	require.Eventually(t, func() bool {
+		return len(env.audit.recorded()) == 2
+	}, waitFor, 10*time.Millisecond)
+	assert.Equal(t, 1.0, testutil.ToFloat64(env.metrics.MQTTCommands.WithLabelValues(resultInvalid)))
+	assert.Equal(t, []string{"AA:BB:CC:DD:EE:FF"}, env.sender.sent())
+
+	invalid, valid := env.audit.recorded()[0], env.audit.recorded()[1]
+	if invalid.Outcome != domain.AuditOutcomeInvalid {
+		invalid, valid = valid, invalid
+	}
+	assert.Equal(t, domain.AuditOutcomeInvalid, invalid.Outcome)
+	assert.Empty(t, invalid.MachineID)
+	assert.Equal(t, "gwaihir/-sauron/wake", invalid.Detail)
+	assert.NotEqual(t, invalid.RequestID, valid.RequestID)
+}
+
+func TestClient_SyncsDiscoveryWithMachines(t *testing.T) {
+	env := newTestEnv(t)
+	defer env.client.Stop()
+
+	env.retained(t, "homeassistant/button/gwaihir-test/gandalf/config")
+
+	env.repo.set(saruman, &domain.Machine{ID: "radagast.brown", Name: "Radagast", MAC: "22:33:44:55:66:77"})
+
+	payload := env.retained(t, "homeassistant/button/gwaihir-test/radagast_brown/config")
+	assert.Contains(t, payload, `"command_topic":"gwaihir/radagast.brown/wake"`)
+	assert.Eventually(t, func() bool {
+		_, ok := env.broker.retainedPayload("homeassistant/button/gwaihir-test/gandalf/config")
+		return !ok
+	}, waitFor, 10*time.Millisecond, "removed machine is still announced")
+	env.retained(t, "homeassistant/button/gwaihir-test/saruman/config")
+}
+
+func TestClient_ReconnectsAfterConnectionLoss(t *testing.T) {
+	env := newTestEnv(t)
+	defer env.client.Stop()
+
+	env.retained(t, "gwaihir/status")
+	env.broker.dropConnections()
+
+	// The will announces the client offline until it reconnects and resubscribes.
+	assert.Eventually(t, func() bool {
+		return assert.ObjectsAreEqual([]string{PayloadOnline, PayloadOffline, PayloadOnline}, env.broker.payloads("gwaihir/status"))
+	}, waitFor, 10*time.Millisecond, "status history: %v", env.broker.payloads("gwaihir/status"))
+	assert.Equal(t, 1.0, testutil.ToFloat64(env.metrics.MQTTConnected))
+
+	observer, _ := env.subscribe(t, "gwaihir/+/result")
+	require.NoError(t, wait(observer.Publish("gwaihir/saruman/wake", 1, false, PayloadPress)))
+	assert.Eventually(t, func() bool {
+		return len(env.sender.sent()) == 1
+	}, waitFor, 10*time.Millisecond)
+}
+
+func TestClient_StopPublishesOffline(t *testing.T) {
+	env := newTestEnv(t)
+
+	env.retained(t, "gwaihir/status")
+	env.client.Stop()
+
+	assert.Eventually(t, func() bool {
+		payload, _ := env.broker.retainedPayload("gwaihir/status")
+		return payload == PayloadOffline
+	}, waitFor, 10*time.Millisecond)
+	assert.Equal(t, 0.0, testutil.ToFloat64(env.metrics.MQTTConnected))
+}
+
+func TestClient_WithoutHomeAssistant(t *testing.T) {
+	metrics, err := infrastructure.NewMetrics()
+	require.NoError(t, err)
+	logger := infrastructure.NewLogger("text", "error")
+	broker := newTestBroker(t)
+
+	cfg := testConfig(broker.url())
+	disabled := false
+	cfg.HomeAssistant.Enabled = &disabled
+	repo := &mockRepository{}
+	repo.set(saruman)
+
+	client := NewClient(cfg, usecase.NewWoLUseCase(repo, &mockPacketSender{}, logger, metrics), logger, metrics)
+	require.NoError(t, client.Start())
+	defer client.Stop()
+
+	require.Eventually(t, func() bool {
+		payload, _ := broker.retainedPayload("gwaihir/status")
+		return payload == PayloadOnline
+	}, waitFor, 10*time.Millisecond)
+	_, announced := broker.retainedPayload("homeassistant/button/gwaihir-test/saruman/config")
+	assert.False(t, announced)
+}
+
+func TestNewTLSConfig(t *testing.T) {
+	tlsConfig, err := newTLSConfig(config.MQTTTLSConfig{})
+	assert.NoError(t, err)
+	assert.Nil(t, tlsConfig)
+
+	tlsConfig, err = newTLSConfig(config.MQTTTLSConfig{InsecureSkipVerify: true})
+	require.NoError(t, err)
+	assert.True(t, tlsConfig.InsecureSkipVerify)
+
+	_, err = newTLSConfig(config.MQTTTLSConfig{CAFile: "/nonexistent/ca.pem"})
+	assert.ErrorContains(t, err, "failed to read mqtt.tls.ca_file")
+
+	_, err = newTLSConfig(config.MQTTTLSConfig{CertFile: "/nonexistent/cert.pem", KeyFile: "/nonexistent/key.pem"})
+	assert.ErrorContains(t, err, "failed to load mqtt.tls client certificate")
+}
+
+func TestTopicMatches(t *testing.T) {
+	assert.True(t, topicMatches("gwaihir/+/wake", "gwaihir/saruman/wake"))
+	assert.False(t, topicMatches("gwaihir/+/wake", "gwaihir/a/b/wake"))
+	assert.True(t, topicMatches("gwaihir/#", "gwaihir/a/b"))
+	assert.False(t, topicMatches("gwaihir/status", "gwaihir/status/x"))
+}
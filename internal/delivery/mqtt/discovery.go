@@ -0,0 +1,79 @@
+// Package mqtt provides the MQTT delivery layer, including Home Assistant discovery.
+package mqtt
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+
+	"github.com/josimar-silva/gwaihir/internal/domain"
+)
+
+// PayloadPress is the payload Home Assistant publishes when a wake button is pressed.
+const PayloadPress = "PRESS"
+
+// invalidDiscoveryID matches the characters Home Assistant does not accept in the
+// node and object IDs of discovery topics.
+var invalidDiscoveryID = regexp.MustCompile(`[^a-zA-Z0-9_-]`)
+
+// discoveryButton is the Home Assistant MQTT discovery payload of a button entity.
+// See https://www.home-assistant.io/integrations/button.mqtt/.
+type discoveryButton struct {
+	Name                string          `json:"name"`
+	UniqueID            string          `json:"unique_id"`
+	CommandTopic        string          `json:"command_topic"`
+	PayloadPress        string          `json:"payload_press"`
+	AvailabilityTopic   string          `json:"availability_topic"`
+	PayloadAvailable    string          `json:"payload_available"`
+	PayloadNotAvailable string          `json:"payload_not_available"`
+	QoS                 byte            `json:"qos"`
+	Icon                string          `json:"icon"`
+	Device              discoveryDevice `json:"device"`
+}
+
+// discoveryDevice groups the entities of a machine in Home Assistant.
+type discoveryDevice struct {
+	Identifiers []string    `json:"identifiers"`
+	Name        string      `json:"name"`
+	Connections [][2]string `json:"connections,omitempty"`
+	Model       string      `json:"model"`
+}
+
+// discovery returns the topic and payload announcing machine as a button entity
+// pressing which wakes it. IDs are prefixed with the client ID, so several
+// instances can share a broker.
+func (c *Client) discovery(machine *domain.Machine) (string, []byte, error) {
+	nodeID := discoveryID(c.cfg.ClientID)
+	objectID := discoveryID(machine.ID)
+
+	button := discoveryButton{
+		Name:                "Wake",
+		UniqueID:            nodeID + "_" + objectID + "_wake",
+		CommandTopic:        c.commandTopic(machine.ID),
+		PayloadPress:        PayloadPress,
+		AvailabilityTopic:   c.statusTopic(),
+		PayloadAvailable:    PayloadOnline,
+		PayloadNotAvailable: PayloadOffline,
+		QoS:                 c.qos(),
+		Icon:                "mdi:power",
+		Device: discoveryDevice{
+			Identifiers: []string{nodeID + "_" + objectID},
+			Name:        machine.Name,
+			Model:       "Wake-on-LAN",
+		},
+	}
+	if machine.MAC != "" {
+		button.Device.Connections = [][2]string{{"mac", strings.ToLower(machine.MAC)}}
+	}
+
+	payload, err := json.Marshal(button)
+	if err != nil {
+		return "", nil, err
+	}
+	topic := c.cfg.HomeAssistant.DiscoveryPrefix + "/button/" + nodeID + "/" + objectID + "/config"
+	return topic, payload, nil
+}
+
+func discoveryID(id string) string {
+	return invalidDiscoveryID.ReplaceAllString(id, "_")
+}
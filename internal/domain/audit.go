@@ -0,0 +1,79 @@
+package domain
+
+import "time"
+
+// Audit actions.
+const (
+	AuditActionWake          = "wake"
+	AuditActionMachineRead   = "machine_read"
+	AuditActionMachineList   = "machine_list"
+	AuditActionAuthFailure   = "auth_failure"
+	AuditActionMachineCreate = "machine_create"
+	AuditActionMachineUpdate = "machine_update"
+	AuditActionMachineDelete = "machine_delete"
+)
+
+// Audit outcomes.
+const (
+	AuditOutcomeSuccess  = "success"
+	AuditOutcomeNotFound = "not_found"
+	AuditOutcomeFailure  = "failure"
+	AuditOutcomeDenied   = "denied"
+	AuditOutcomeInvalid  = "invalid"
+	AuditOutcomeConflict = "conflict"
+)
+
+// AuditEvent records a single security-relevant action performed against Gwaihir.
+type AuditEvent struct {
+	Timestamp time.Time `json:"timestamp"`
+	Action    string    `json:"action"`
+	Caller    string    `json:"caller"`
+	SourceIP  string    `json:"source_ip"`
+	RequestID string    `json:"request_id,omitempty"`
+	MachineID string    `json:"machine_id,omitempty"`
+	Outcome   string    `json:"outcome"`
+	Detail    string    `json:"detail,omitempty"`
+}
+
+// AuditRecord is an AuditEvent as persisted in the audit trail, linked to the
+// previous record through a hash chain for tamper evidence.
+type AuditRecord struct {
+	AuditEvent
+	PrevHash string `json:"prev_hash"`
+	Hash     string `json:"hash"`
+}
+
+// AuditFilter selects audit records. Zero values match everything.
+type AuditFilter struct {
+	MachineID string
+	Caller    string
+	Since     time.Time
+	Until     time.Time
+	Limit     int
+}
+
+// Matches reports whether the record satisfies the filter (ignoring Limit).
+func (f AuditFilter) Matches(record *AuditRecord) bool {
+	if f.MachineID != "" && record.MachineID != f.MachineID {
+		return false
+	}
+	if f.Caller != "" && record.Caller != f.Caller {
+		return false
+	}
+	if !f.Since.IsZero() && record.Timestamp.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && record.Timestamp.After(f.Until) {
+		return false
+	}
+	return true
+}
+
+// AuditLog defines the interface for recording and querying the audit trail.
+type AuditLog interface {
+	// Record appends an event to the audit trail.
+	Record(event AuditEvent) error
+
+	// Query returns the records matching the filter in chronological order.
+	Query(filter AuditFilter) ([]AuditRecord, error)
+}
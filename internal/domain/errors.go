@@ -2,14 +2,61 @@ package domain
 
 import "errors"
 
+// ErrorCode is a stable, machine-readable identifier of an error. Codes are part of
+// the API contract: clients switch on them, so they must not change once released.
+type ErrorCode string
+
+// Codes of the domain errors.
+const (
+	CodeMachineNotFound      ErrorCode = "machine_not_found"
+	CodeMachineNotAllowed    ErrorCode = "machine_not_allowed"
+	CodeInvalidConfiguration ErrorCode = "invalid_configuration"
+	CodeMachineAlreadyExists ErrorCode = "machine_already_exists"
+	CodeMachineReadOnly      ErrorCode = "machine_read_only"
+	CodeInvalidMachine       ErrorCode = "invalid_machine"
+	CodeNeighborNotFound     ErrorCode = "neighbor_not_found"
+	CodeWakeFailed           ErrorCode = "wake_failed"
+)
+
+// Error is a domain error identified by a stable code. The domain errors below are
+// compared by identity, so wrapped errors still match them with errors.Is.
+type Error struct {
+	Code    ErrorCode
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
 // Domain errors.
 var (
 	// ErrMachineNotFound is returned when a requested machine is not found.
-	ErrMachineNotFound = errors.New("machine not found")
+	ErrMachineNotFound = &Error{Code: CodeMachineNotFound, Message: "machine not found"}
 
 	// ErrMachineNotAllowed is returned when a machine is not in the allowlist.
-	ErrMachineNotAllowed = errors.New("machine not allowed")
+	ErrMachineNotAllowed = &Error{Code: CodeMachineNotAllowed, Message: "machine not allowed"}
 
 	// ErrInvalidConfiguration is returned when configuration is invalid.
-	ErrInvalidConfiguration = errors.New("invalid configuration")
+	ErrInvalidConfiguration = &Error{Code: CodeInvalidConfiguration, Message: "invalid configuration"}
+	// ErrMachineAlreadyExists is returned when creating a machine whose ID is already registered.
+	ErrMachineAlreadyExists = &Error{Code: CodeMachineAlreadyExists, Message: "machine already exists"}
+	// ErrMachineReadOnly is returned when modifying a machine that cannot be changed at runtime.
+	ErrMachineReadOnly = &Error{Code: CodeMachineReadOnly, Message: "machine is read-only"}
+	// ErrInvalidMachine is returned when a machine fails validation.
+	ErrInvalidMachine = &Error{Code: CodeInvalidMachine, Message: "invalid machine"}
+	// ErrNeighborNotFound is returned when a MAC address is not in the neighbor table.
+	ErrNeighborNotFound = &Error{Code: CodeNeighborNotFound, Message: "neighbor not found"}
+	// ErrWakeFailed is returned when a Wake-on-LAN packet could not be sent.
+	ErrWakeFailed = &Error{Code: CodeWakeFailed, Message: "failed to send WoL packet"}
 )
+
+// CodeOf returns the code of the first domain error in err's tree, or an empty code
+// if err is not a domain error.
+func CodeOf(err error) ErrorCode {
+	var domainErr *Error
+	if errors.As(err, &domainErr) {
+		return domainErr.Code
+	}
+	return ""
+}
@@ -0,0 +1,43 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestCodeOf(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want ErrorCode
+	}{
+		{"domain error", ErrMachineNotFound, CodeMachineNotFound},
+		{"wrapped domain error", fmt.Errorf("failed to get machine: %w", ErrMachineReadOnly), CodeMachineReadOnly},
+		{"outermost domain error wins", fmt.Errorf("%w: %w", ErrMachineNotAllowed, ErrMachineNotFound), CodeMachineNotAllowed},
+		{"other error", errors.New("boom"), ""},
+		{"nil", nil, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CodeOf(tt.err); got != tt.want {
+				t.Errorf("CodeOf() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestError_MatchesByIdentity(t *testing.T) {
+	err := fmt.Errorf("%w: saruman", ErrMachineAlreadyExists)
+
+	if !errors.Is(err, ErrMachineAlreadyExists) {
+		t.Error("Expected wrapped error to match ErrMachineAlreadyExists")
+	}
+	if errors.Is(err, &Error{Code: CodeMachineAlreadyExists, Message: "machine already exists"}) {
+		t.Error("Expected a distinct error with the same code not to match")
+	}
+	if err.Error() != "machine already exists: saruman" {
+		t.Errorf("Unexpected message %q", err.Error())
+	}
+}
@@ -0,0 +1,49 @@
+package domain
+
+import "time"
+
+// Event types.
+const (
+	// EventWakeRequested is published before a magic packet is sent.
+	EventWakeRequested = "wake.requested"
+	// EventWakeSent is published once a magic packet was sent.
+	EventWakeSent = "wake.sent"
+	// EventWakeFailed is published when a magic packet could not be sent.
+	EventWakeFailed = "wake.failed"
+	// EventMachineCreated is published when a machine is added at runtime.
+	EventMachineCreated = "machine.created"
+	// EventMachineUpdated is published when a machine is changed at runtime.
+	EventMachineUpdated = "machine.updated"
+	// EventMachineDeleted is published when a machine is removed at runtime.
+	EventMachineDeleted = "machine.deleted"
+	// EventConfigReloaded is published once the configuration was reloaded.
+	EventConfigReloaded = "config.reloaded"
+	// EventConfigReloadFailed is published when a reload was rejected and the previous
+	// configuration kept.
+	EventConfigReloadFailed = "config.reload_failed"
+)
+
+// EventTypes lists every event type, e.g. to validate event filters.
+var EventTypes = []string{
+	EventWakeRequested, EventWakeSent, EventWakeFailed,
+	EventMachineCreated, EventMachineUpdated, EventMachineDeleted,
+	EventConfigReloaded, EventConfigReloadFailed,
+}
+
+// Event is a change in the state of a machine or of the service. MachineID is empty
+// for wakes sent to a MAC address outside the allowlist and for configuration events.
+// Detail carries a human-readable explanation, such as the cause of a failed reload.
+type Event struct {
+	Type      string    `json:"type"`
+	MachineID string    `json:"machine_id,omitempty"`
+	MAC       string    `json:"mac,omitempty"`
+	Broadcast string    `json:"broadcast,omitempty"`
+	Detail    string    `json:"detail,omitempty"`
+	Time      time.Time `json:"time"`
+}
+
+// EventPublisher delivers events to interested subscribers. Publish must not block
+// on slow subscribers.
+type EventPublisher interface {
+	Publish(event Event)
+}
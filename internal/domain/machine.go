@@ -10,17 +10,23 @@ import (
 )
 
 // Machine represents a network machine that can be woken via WoL.
+// Source is the configuration file the machine was defined in, if any.
 type Machine struct {
 	ID        string `yaml:"id" json:"id"`
 	Name      string `yaml:"name" json:"name"`
 	MAC       string `yaml:"mac" json:"mac"`
 	Broadcast string `yaml:"broadcast" json:"broadcast"`
+	Source    string `yaml:"-" json:"source,omitempty"`
 }
 
+// machineIDRegexp matches the IDs that can be used as a URL path segment and an
+// MQTT topic level.
+var machineIDRegexp = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
 // Validate checks if the machine has valid configuration.
 func (m *Machine) Validate() error {
-	if m.ID == "" {
-		return errors.New("machine ID cannot be empty")
+	if err := ValidateMachineID(m.ID); err != nil {
+		return err
 	}
 	if m.Name == "" {
 		return errors.New("machine name cannot be empty")
@@ -34,6 +40,19 @@ func (m *Machine) Validate() error {
 	return nil
 }
 
+// ValidateMachineID validates a machine ID. IDs start with a letter or digit followed
+// by letters, digits, '.', '_' or '-', so that they can be used in API paths and
+// MQTT topics.
+func ValidateMachineID(id string) error {
+	if id == "" {
+		return errors.New("machine ID cannot be empty")
+	}
+	if !machineIDRegexp.MatchString(id) {
+		return fmt.Errorf("machine ID '%s' must start with a letter or digit and contain only letters, digits, '.', '_' and '-'", id)
+	}
+	return nil
+}
+
 // ValidateMAC validates a MAC address format.
 func ValidateMAC(mac string) error {
 	// Common MAC address formats: AA:BB:CC:DD:EE:FF or AA-BB-CC-DD-EE-FF
@@ -61,3 +80,25 @@ func ValidateBroadcast(broadcast string) error {
 func (m *Machine) NormalizeMAC() string {
 	return strings.ReplaceAll(strings.ToUpper(m.MAC), "-", ":")
 }
+
+// MachinePatch describes a partial update of a machine. Nil fields are left unchanged.
+type MachinePatch struct {
+	Name      *string
+	MAC       *string
+	Broadcast *string
+}
+
+// Apply returns a copy of the machine with the patch applied.
+func (p MachinePatch) Apply(m *Machine) *Machine {
+	patched := *m
+	if p.Name != nil {
+		patched.Name = *p.Name
+	}
+	if p.MAC != nil {
+		patched.MAC = *p.MAC
+	}
+	if p.Broadcast != nil {
+		patched.Broadcast = *p.Broadcast
+	}
+	return &patched
+}
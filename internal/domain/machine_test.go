@@ -4,6 +4,19 @@ import (
 	"testing"
 )
 
+func TestValidateMachineID(t *testing.T) {
+	for _, id := range []string{"saruman", "server-1", "lab.rack_2", "9lives"} {
+		if err := ValidateMachineID(id); err != nil {
+			t.Errorf("ValidateMachineID(%q) error = %v", id, err)
+		}
+	}
+	for _, id := range []string{"", "a/b", "home+", "home#", "with space", "-flag", ".hidden", "..", "caf\u00e9"} {
+		if err := ValidateMachineID(id); err == nil {
+			t.Errorf("ValidateMachineID(%q) expected error", id)
+		}
+	}
+}
+
 func TestMachine_Validate(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -112,3 +125,25 @@ func TestMachine_NormalizeMAC(t *testing.T) {
 		})
 	}
 }
+
+func TestMachinePatch_Apply(t *testing.T) {
+	original := &Machine{
+		ID:        "server1",
+		Name:      "Test Server",
+		MAC:       "AA:BB:CC:DD:EE:FF",
+		Broadcast: "192.168.1.255",
+	}
+	name := "Renamed Server"
+
+	patched := MachinePatch{Name: &name}.Apply(original)
+
+	if patched.Name != "Renamed Server" {
+		t.Errorf("MachinePatch.Apply() name = %v, want %v", patched.Name, name)
+	}
+	if patched.MAC != original.MAC || patched.Broadcast != original.Broadcast {
+		t.Errorf("MachinePatch.Apply() changed unset fields: %+v", patched)
+	}
+	if original.Name != "Test Server" {
+		t.Error("MachinePatch.Apply() modified the original machine")
+	}
+}
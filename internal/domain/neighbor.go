@@ -0,0 +1,17 @@
+package domain
+
+// Neighbor is an entry of the host's neighbor (ARP) table.
+// Broadcast is the broadcast address of the local network the neighbor was
+// seen on, or empty if it cannot be determined.
+type Neighbor struct {
+	IP        string `json:"ip"`
+	MAC       string `json:"mac"`
+	Interface string `json:"interface"`
+	Broadcast string `json:"broadcast,omitempty"`
+}
+
+// NeighborTable defines the interface for reading the host's neighbor table.
+type NeighborTable interface {
+	// Neighbors returns the resolved entries of the neighbor table.
+	Neighbors() ([]Neighbor, error)
+}
@@ -1,5 +1,7 @@
 package domain
 
+import "context"
+
 // MachineRepository defines the interface for machine data access.
 type MachineRepository interface {
 	// GetByID retrieves a machine by its ID.
@@ -12,8 +14,27 @@ type MachineRepository interface {
 	Exists(id string) bool
 }
 
+// WritableMachineRepository is a MachineRepository that supports runtime changes.
+// Writes take a context so implementations backed by storage can honor cancellation.
+type WritableMachineRepository interface {
+	MachineRepository
+
+	// Create registers a new machine. Returns ErrMachineAlreadyExists if the ID is taken.
+	Create(ctx context.Context, machine *Machine) error
+
+	// Update replaces an existing machine. Returns ErrMachineNotFound if it does not exist.
+	Update(ctx context.Context, machine *Machine) error
+
+	// Patch applies a partial update to an existing machine atomically and returns
+	// the result. Returns ErrMachineNotFound if it does not exist.
+	Patch(ctx context.Context, id string, patch MachinePatch) (*Machine, error)
+
+	// Delete removes a machine. Returns ErrMachineNotFound if it does not exist.
+	Delete(ctx context.Context, id string) error
+}
+
 // WoLPacketSender defines the interface for sending Wake-on-LAN magic packets.
 type WoLPacketSender interface {
 	// SendMagicPacket sends a WoL magic packet to the specified MAC on the broadcast address.
-	SendMagicPacket(mac, broadcast string) error
+	SendMagicPacket(ctx context.Context, mac, broadcast string) error
 }
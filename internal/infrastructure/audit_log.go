@@ -0,0 +1,344 @@
+// Package infrastructure provides infrastructure layer implementations.
+package infrastructure
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/josimar-silva/gwaihir/internal/domain"
+)
+
+// genesisHash is the previous hash used by the very first record of an audit trail.
+const genesisHash = "0000000000000000000000000000000000000000000000000000000000000000"
+
+// maxAuditLineSize bounds the size of a single audit record when reading the trail back.
+const maxAuditLineSize = 1024 * 1024
+
+// ErrAuditChainBroken is returned when the audit trail hash chain does not verify.
+var ErrAuditChainBroken = errors.New("audit hash chain broken")
+
+// FileAuditLog is an append-only JSON Lines audit trail with size-based rotation.
+// Every record carries the hash of its predecessor so that modifications or
+// deletions inside the trail can be detected with Verify.
+type FileAuditLog struct {
+	path       string
+	maxSize    int64
+	maxBackups int
+
+	mu       sync.Mutex
+	file     *os.File
+	size     int64
+	lastHash string
+	now      func() time.Time
+}
+
+// NewFileAuditLog opens (or creates) the audit trail at path.
+// The file is rotated once it would exceed maxSize bytes, keeping at most maxBackups
+// rotated files (path.1 being the most recent). A maxSize of 0 disables rotation.
+//
+// #nosec G304 - path is controlled by application configuration, not user input
+func NewFileAuditLog(path string, maxSize int64, maxBackups int) (*FileAuditLog, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create audit log directory: %w", err)
+	}
+
+	a := &FileAuditLog{
+		path:       path,
+		maxSize:    maxSize,
+		maxBackups: maxBackups,
+		lastHash:   genesisHash,
+		now:        time.Now,
+	}
+
+	lastHash, err := a.recoverLastHash()
+	if err != nil {
+		return nil, err
+	}
+	if lastHash != "" {
+		a.lastHash = lastHash
+	}
+
+	if err := a.openCurrent(); err != nil {
+		return nil, err
+	}
+
+	return a, nil
+}
+
+// Record appends an event to the audit trail. A zero timestamp is set to the current time.
+func (a *FileAuditLog) Record(event domain.AuditEvent) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if event.Timestamp.IsZero() {
+		event.Timestamp = a.now()
+	}
+	event.Timestamp = event.Timestamp.UTC()
+
+	record := domain.AuditRecord{AuditEvent: event, PrevHash: a.lastHash}
+	hash, err := hashAuditRecord(&record)
+	if err != nil {
+		return err
+	}
+	record.Hash = hash
+
+	line, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit record: %w", err)
+	}
+	line = append(line, '\n')
+
+	if a.maxSize > 0 && a.size > 0 && a.size+int64(len(line)) > a.maxSize {
+		if err := a.rotate(); err != nil {
+			return err
+		}
+	}
+
+	n, err := a.file.Write(line)
+	a.size += int64(n)
+	if err != nil {
+		return fmt.Errorf("failed to write audit record: %w", err)
+	}
+
+	a.lastHash = hash
+	return nil
+}
+
+// Query returns the records matching the filter in chronological order.
+// When filter.Limit is positive only the most recent matching records are returned.
+// The trail is read without blocking Record; records appended meanwhile are not returned.
+func (a *FileAuditLog) Query(filter domain.AuditFilter) ([]domain.AuditRecord, error) {
+	var records []domain.AuditRecord
+	err := a.walk(func(record *domain.AuditRecord) error {
+		if filter.Matches(record) {
+			records = append(records, *record)
+			if filter.Limit > 0 && len(records) > filter.Limit {
+				records = records[1:]
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return records, nil
+}
+
+// Verify walks the whole retained audit trail and checks the hash chain.
+// Returns ErrAuditChainBroken if any record was altered, removed or reordered.
+func (a *FileAuditLog) Verify() error {
+	prevHash := ""
+	index := 0
+	return a.walk(func(record *domain.AuditRecord) error {
+		index++
+		if prevHash != "" && record.PrevHash != prevHash {
+			return fmt.Errorf("%w: record %d does not reference its predecessor", ErrAuditChainBroken, index)
+		}
+
+		hash, err := hashAuditRecord(record)
+		if err != nil {
+			return err
+		}
+		if hash != record.Hash {
+			return fmt.Errorf("%w: record %d hash mismatch", ErrAuditChainBroken, index)
+		}
+
+		prevHash = record.Hash
+		return nil
+	})
+}
+
+// Close closes the underlying file.
+func (a *FileAuditLog) Close() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.file == nil {
+		return nil
+	}
+	err := a.file.Close()
+	a.file = nil
+	return err
+}
+
+// openCurrent opens the active audit file for appending. Must be called with the mutex held.
+func (a *FileAuditLog) openCurrent() error {
+	file, err := os.OpenFile(a.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return fmt.Errorf("failed to stat audit log: %w", err)
+	}
+
+	a.file = file
+	a.size = info.Size()
+	return nil
+}
+
+// rotate shifts path.N-1 to path.N, the active file to path.1 and opens a fresh file.
+// Must be called with the mutex held.
+func (a *FileAuditLog) rotate() error {
+	if err := a.file.Close(); err != nil {
+		return fmt.Errorf("failed to close audit log for rotation: %w", err)
+	}
+
+	if a.maxBackups > 0 {
+		_ = os.Remove(a.backupPath(a.maxBackups))
+		for i := a.maxBackups - 1; i >= 1; i-- {
+			if err := os.Rename(a.backupPath(i), a.backupPath(i+1)); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("failed to rotate audit log: %w", err)
+			}
+		}
+		if err := os.Rename(a.path, a.backupPath(1)); err != nil {
+			return fmt.Errorf("failed to rotate audit log: %w", err)
+		}
+	} else if err := os.Remove(a.path); err != nil {
+		return fmt.Errorf("failed to rotate audit log: %w", err)
+	}
+
+	return a.openCurrent()
+}
+
+func (a *FileAuditLog) backupPath(index int) string {
+	return fmt.Sprintf("%s.%d", a.path, index)
+}
+
+// files returns the retained audit files from oldest to newest.
+func (a *FileAuditLog) files() []string {
+	files := make([]string, 0, a.maxBackups+1)
+	for i := a.maxBackups; i >= 1; i-- {
+		files = append(files, a.backupPath(i))
+	}
+	return append(files, a.path)
+}
+
+// auditSegment is a retained audit file opened for reading, of which the first size
+// bytes are read.
+type auditSegment struct {
+	path string
+	file *os.File
+	size int64
+}
+
+// snapshot opens the retained audit files from oldest to newest. The mutex is only
+// held while opening them: open files stay readable when they are rotated, and
+// reading each one up to its current size leaves out records appended afterwards.
+//
+// #nosec G304 - paths are derived from application configuration
+func (a *FileAuditLog) snapshot() ([]auditSegment, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	var segments []auditSegment
+	for _, path := range a.files() {
+		file, err := os.Open(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err == nil {
+			var info os.FileInfo
+			if info, err = file.Stat(); err == nil {
+				segments = append(segments, auditSegment{path: path, file: file, size: info.Size()})
+				continue
+			}
+			_ = file.Close()
+		}
+
+		closeAuditSegments(segments)
+		return nil, fmt.Errorf("failed to open audit file %s: %w", path, err)
+	}
+	return segments, nil
+}
+
+func closeAuditSegments(segments []auditSegment) {
+	for _, segment := range segments {
+		_ = segment.file.Close()
+	}
+}
+
+// walk calls fn for every retained record in chronological order.
+func (a *FileAuditLog) walk(fn func(record *domain.AuditRecord) error) error {
+	segments, err := a.snapshot()
+	if err != nil {
+		return err
+	}
+	defer closeAuditSegments(segments)
+
+	for _, segment := range segments {
+		if err := walkAuditFile(segment.path, io.LimitReader(segment.file, segment.size), fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// recoverLastHash returns the hash of the newest retained record, or "" if the trail is empty.
+func (a *FileAuditLog) recoverLastHash() (string, error) {
+	lastHash := ""
+	err := a.walk(func(record *domain.AuditRecord) error {
+		lastHash = record.Hash
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to read existing audit log: %w", err)
+	}
+	return lastHash, nil
+}
+
+// walkAuditFile calls fn for every record read from the audit file at path.
+// Records longer than maxAuditLineSize are rejected without being buffered whole.
+func walkAuditFile(path string, reader io.Reader, fn func(record *domain.AuditRecord) error) error {
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxAuditLineSize)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+
+		var record domain.AuditRecord
+		if err := json.Unmarshal(line, &record); err != nil {
+			return fmt.Errorf("invalid audit record in %s line %d: %w", path, lineNumber, err)
+		}
+		if err := fn(&record); err != nil {
+			return err
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		if errors.Is(err, bufio.ErrTooLong) {
+			return fmt.Errorf("audit file %s line %d exceeds maximum size", path, lineNumber+1)
+		}
+		return fmt.Errorf("failed to read audit file %s: %w", path, err)
+	}
+	return nil
+}
+
+// hashAuditRecord computes SHA-256 over the previous hash and the JSON encoding of the event.
+func hashAuditRecord(record *domain.AuditRecord) (string, error) {
+	payload, err := json.Marshal(record.AuditEvent)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode audit event: %w", err)
+	}
+
+	sum := sha256.New()
+	sum.Write([]byte(record.PrevHash))
+	sum.Write(payload)
+	return hex.EncodeToString(sum.Sum(nil)), nil
+}
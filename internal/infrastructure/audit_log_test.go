@@ -0,0 +1,221 @@
+package infrastructure
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/josimar-silva/gwaihir/internal/domain"
+)
+
+func newAuditEvent(machineID, caller string, ts time.Time) domain.AuditEvent {
+	return domain.AuditEvent{
+		Timestamp: ts,
+		Action:    domain.AuditActionWake,
+		Caller:    caller,
+		SourceIP:  "10.0.0.1",
+		RequestID: "req-" + machineID,
+		MachineID: machineID,
+		Outcome:   domain.AuditOutcomeSuccess,
+	}
+}
+
+func TestFileAuditLog_RecordAndQuery(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	auditLog, err := NewFileAuditLog(path, 0, 0)
+	if err != nil {
+		t.Fatalf("Failed to create audit log: %v", err)
+	}
+	defer func() { _ = auditLog.Close() }()
+
+	base := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	events := []domain.AuditEvent{
+		newAuditEvent("saruman", "api-key:aaaa", base),
+		newAuditEvent("gandalf", "api-key:bbbb", base.Add(time.Hour)),
+		newAuditEvent("saruman", "api-key:bbbb", base.Add(2*time.Hour)),
+	}
+	for _, event := range events {
+		if err := auditLog.Record(event); err != nil {
+			t.Fatalf("Failed to record event: %v", err)
+		}
+	}
+
+	all, err := auditLog.Query(domain.AuditFilter{})
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if len(all) != 3 {
+		t.Fatalf("Expected 3 records, got %d", len(all))
+	}
+	if all[0].PrevHash != genesisHash {
+		t.Errorf("Expected first record to reference genesis hash, got %s", all[0].PrevHash)
+	}
+	if all[1].PrevHash != all[0].Hash {
+		t.Error("Expected second record to reference first record hash")
+	}
+
+	byMachine, _ := auditLog.Query(domain.AuditFilter{MachineID: "saruman"})
+	if len(byMachine) != 2 {
+		t.Errorf("Expected 2 records for saruman, got %d", len(byMachine))
+	}
+
+	byCaller, _ := auditLog.Query(domain.AuditFilter{Caller: "api-key:bbbb"})
+	if len(byCaller) != 2 {
+		t.Errorf("Expected 2 records for caller, got %d", len(byCaller))
+	}
+
+	byTime, _ := auditLog.Query(domain.AuditFilter{Since: base.Add(30 * time.Minute), Until: base.Add(90 * time.Minute)})
+	if len(byTime) != 1 || byTime[0].MachineID != "gandalf" {
+		t.Errorf("Expected only gandalf record in time range, got %+v", byTime)
+	}
+
+	limited, _ := auditLog.Query(domain.AuditFilter{Limit: 1})
+	if len(limited) != 1 || !limited[0].Timestamp.Equal(base.Add(2*time.Hour)) {
+		t.Errorf("Expected only the most recent record, got %+v", limited)
+	}
+
+	if err := auditLog.Verify(); err != nil {
+		t.Errorf("Expected chain to verify, got %v", err)
+	}
+}
+
+func TestFileAuditLog_ChainSurvivesReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	auditLog, err := NewFileAuditLog(path, 0, 0)
+	if err != nil {
+		t.Fatalf("Failed to create audit log: %v", err)
+	}
+	_ = auditLog.Record(newAuditEvent("saruman", "anonymous", time.Time{}))
+	_ = auditLog.Close()
+
+	reopened, err := NewFileAuditLog(path, 0, 0)
+	if err != nil {
+		t.Fatalf("Failed to reopen audit log: %v", err)
+	}
+	defer func() { _ = reopened.Close() }()
+	_ = reopened.Record(newAuditEvent("gandalf", "anonymous", time.Time{}))
+
+	records, _ := reopened.Query(domain.AuditFilter{})
+	if len(records) != 2 {
+		t.Fatalf("Expected 2 records, got %d", len(records))
+	}
+	if records[1].PrevHash != records[0].Hash {
+		t.Error("Expected chain to continue across reopen")
+	}
+	if records[0].Timestamp.IsZero() {
+		t.Error("Expected zero timestamp to be filled in")
+	}
+}
+
+func TestFileAuditLog_DetectsTampering(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	auditLog, err := NewFileAuditLog(path, 0, 0)
+	if err != nil {
+		t.Fatalf("Failed to create audit log: %v", err)
+	}
+	defer func() { _ = auditLog.Close() }()
+
+	_ = auditLog.Record(newAuditEvent("saruman", "anonymous", time.Time{}))
+	_ = auditLog.Record(newAuditEvent("gandalf", "anonymous", time.Time{}))
+
+	data, err := os.ReadFile(path) // #nosec G304 - test file
+	if err != nil {
+		t.Fatalf("Failed to read audit file: %v", err)
+	}
+	tampered := strings.Replace(string(data), `"machine_id":"saruman"`, `"machine_id":"radagast"`, 1)
+	if err := os.WriteFile(path, []byte(tampered), 0o600); err != nil {
+		t.Fatalf("Failed to write audit file: %v", err)
+	}
+
+	if err := auditLog.Verify(); !errors.Is(err, ErrAuditChainBroken) {
+		t.Errorf("Expected ErrAuditChainBroken, got %v", err)
+	}
+}
+
+func TestFileAuditLog_Rotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	auditLog, err := NewFileAuditLog(path, 400, 2)
+	if err != nil {
+		t.Fatalf("Failed to create audit log: %v", err)
+	}
+	defer func() { _ = auditLog.Close() }()
+
+	for i := 0; i < 10; i++ {
+		if err := auditLog.Record(newAuditEvent("saruman", "anonymous", time.Time{})); err != nil {
+			t.Fatalf("Failed to record event: %v", err)
+		}
+	}
+
+	if _, err := os.Stat(path + ".1"); err != nil {
+		t.Errorf("Expected first backup to exist: %v", err)
+	}
+	if _, err := os.Stat(path + ".2"); err != nil {
+		t.Errorf("Expected second backup to exist: %v", err)
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Errorf("Expected no third backup, got %v", err)
+	}
+
+	records, err := auditLog.Query(domain.AuditFilter{})
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if len(records) == 0 || len(records) >= 10 {
+		t.Errorf("Expected oldest records to be rotated away, got %d records", len(records))
+	}
+
+	if err := auditLog.Verify(); err != nil {
+		t.Errorf("Expected chain to verify across rotated files, got %v", err)
+	}
+}
+
+func TestFileAuditLog_QueryDuringRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	auditLog, err := NewFileAuditLog(path, 400, 2)
+	if err != nil {
+		t.Fatalf("Failed to create audit log: %v", err)
+	}
+	defer func() { _ = auditLog.Close() }()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 200; i++ {
+			_ = auditLog.Record(newAuditEvent("saruman", "anonymous", time.Time{}))
+		}
+	}()
+
+	for queries := 0; ; queries++ {
+		records, err := auditLog.Query(domain.AuditFilter{})
+		if err != nil {
+			t.Fatalf("Query failed: %v", err)
+		}
+		for i := 1; i < len(records); i++ {
+			if records[i].PrevHash != records[i-1].Hash {
+				t.Fatalf("Query %d returned records %d and %d out of chain", queries, i-1, i)
+			}
+		}
+
+		select {
+		case <-done:
+			return
+		default:
+		}
+	}
+}
+
+func TestFileAuditLog_RejectsOversizedRecord(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	line := `{"machine_id":"` + strings.Repeat("a", maxAuditLineSize) + `"}` + "\n"
+	if err := os.WriteFile(path, []byte(line), 0o600); err != nil {
+		t.Fatalf("Failed to write audit file: %v", err)
+	}
+
+	_, err := NewFileAuditLog(path, 0, 0)
+	if err == nil || !strings.Contains(err.Error(), "line 1 exceeds maximum size") {
+		t.Errorf("Expected oversized record to be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,72 @@
+// Package infrastructure provides infrastructure layer implementations.
+package infrastructure
+
+import (
+	"sync"
+
+	"github.com/josimar-silva/gwaihir/internal/domain"
+)
+
+// DefaultEventBuffer is the number of events buffered per subscriber.
+const DefaultEventBuffer = 64
+
+// EventBus fans machine events out to in-process subscribers. A subscriber whose
+// buffer is full misses the event rather than blocking the publisher, so a slow
+// consumer never delays a wake.
+type EventBus struct {
+	mu          sync.RWMutex
+	subscribers map[chan domain.Event]struct{}
+	buffer      int
+}
+
+// NewEventBus creates an event bus buffering up to buffer events per subscriber.
+func NewEventBus(buffer int) *EventBus {
+	if buffer <= 0 {
+		buffer = DefaultEventBuffer
+	}
+	return &EventBus{
+		subscribers: make(map[chan domain.Event]struct{}),
+		buffer:      buffer,
+	}
+}
+
+// Publish delivers event to every subscriber with room in its buffer.
+func (b *EventBus) Publish(event domain.Event) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for ch := range b.subscribers {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+// Subscribe returns a channel receiving the events published from now on and a
+// function that ends the subscription and closes the channel.
+func (b *EventBus) Subscribe() (<-chan domain.Event, func()) {
+	ch := make(chan domain.Event, b.buffer)
+
+	b.mu.Lock()
+	b.subscribers[ch] = struct{}{}
+	b.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.subscribers, ch)
+			b.mu.Unlock()
+			close(ch)
+		})
+	}
+}
+
+// Subscribers returns the number of active subscriptions.
+func (b *EventBus) Subscribers() int {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	return len(b.subscribers)
+}
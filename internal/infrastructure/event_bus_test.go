@@ -0,0 +1,40 @@
+package infrastructure
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/josimar-silva/gwaihir/internal/domain"
+)
+
+func TestEventBus_PublishToSubscribers(t *testing.T) {
+	bus := NewEventBus(4)
+	first, unsubscribeFirst := bus.Subscribe()
+	second, unsubscribeSecond := bus.Subscribe()
+	defer unsubscribeSecond()
+	assert.Equal(t, 2, bus.Subscribers())
+
+	bus.Publish(domain.Event{Type: domain.EventWakeSent, MachineID: "saruman"})
+	assert.Equal(t, "saruman", (<-first).MachineID)
+	assert.Equal(t, "saruman", (<-second).MachineID)
+
+	unsubscribeFirst()
+	unsubscribeFirst()
+	_, open := <-first
+	assert.False(t, open)
+	assert.Equal(t, 1, bus.Subscribers())
+}
+
+func TestEventBus_SlowSubscriberMissesEvents(t *testing.T) {
+	bus := NewEventBus(1)
+	events, unsubscribe := bus.Subscribe()
+	defer unsubscribe()
+
+	bus.Publish(domain.Event{MachineID: "first"})
+	bus.Publish(domain.Event{MachineID: "second"})
+
+	require.Len(t, events, 1)
+	assert.Equal(t, "first", (<-events).MachineID)
+}
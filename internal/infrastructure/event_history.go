@@ -0,0 +1,148 @@
+// Package infrastructure provides infrastructure layer implementations.
+package infrastructure
+
+import (
+	"sync"
+
+	"github.com/josimar-silva/gwaihir/internal/domain"
+)
+
+// DefaultEventHistorySize is the number of recent events kept for resuming streams.
+const DefaultEventHistorySize = 256
+
+// RecordedEvent is an event numbered in the order it was published. IDs start at 1
+// and restart with the process.
+type RecordedEvent struct {
+	ID uint64
+	domain.Event
+}
+
+// EventHistory numbers published events and keeps the most recent ones in a ring
+// buffer, so a stream interrupted by a reconnect can resume from the last event it
+// received. Subscribers falling behind are disconnected rather than silently missing
+// events; they resume from the history like any other reconnecting client.
+type EventHistory struct {
+	mu          sync.Mutex
+	ring        []RecordedEvent
+	lastID      uint64
+	subscribers map[chan RecordedEvent]struct{}
+	buffer      int
+	closed      bool
+}
+
+// NewEventHistory creates an event history keeping the last size events.
+func NewEventHistory(size int) *EventHistory {
+	if size <= 0 {
+		size = DefaultEventHistorySize
+	}
+	return &EventHistory{
+		ring:        make([]RecordedEvent, size),
+		subscribers: make(map[chan RecordedEvent]struct{}),
+		buffer:      DefaultEventBuffer,
+	}
+}
+
+// Publish records event and delivers it to every subscriber.
+func (h *EventHistory) Publish(event domain.Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		return
+	}
+
+	h.lastID++
+	recorded := RecordedEvent{ID: h.lastID, Event: event}
+	h.ring[h.index(h.lastID)] = recorded
+
+	for ch := range h.subscribers {
+		select {
+		case ch <- recorded:
+		default:
+			delete(h.subscribers, ch)
+			close(ch)
+		}
+	}
+}
+
+// Consume publishes the events received from events until the channel is closed.
+func (h *EventHistory) Consume(events <-chan domain.Event) {
+	for event := range events {
+		h.Publish(event)
+	}
+}
+
+// Subscribe returns the recorded events following lastEventID, a channel receiving
+// the events published from now on and a function ending the subscription. A
+// lastEventID of 0 replays nothing. An ID the history does not know, such as one
+// issued before a restart, replays every recorded event. The channel is closed when
+// the subscriber falls behind or the history is closed.
+func (h *EventHistory) Subscribe(lastEventID uint64) ([]RecordedEvent, <-chan RecordedEvent, func()) {
+	ch := make(chan RecordedEvent, h.buffer)
+
+	h.mu.Lock()
+	replay := h.since(lastEventID)
+	if h.closed {
+		close(ch)
+	} else {
+		h.subscribers[ch] = struct{}{}
+	}
+	h.mu.Unlock()
+
+	var once sync.Once
+	return replay, ch, func() {
+		once.Do(func() {
+			h.mu.Lock()
+			defer h.mu.Unlock()
+			if _, ok := h.subscribers[ch]; ok {
+				delete(h.subscribers, ch)
+				close(ch)
+			}
+		})
+	}
+}
+
+// LastEventID returns the ID of the most recent event, or 0 if none was published.
+func (h *EventHistory) LastEventID() uint64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return h.lastID
+}
+
+// Close ends every subscription. Events published afterwards are discarded.
+func (h *EventHistory) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.closed = true
+	for ch := range h.subscribers {
+		delete(h.subscribers, ch)
+		close(ch)
+	}
+}
+
+// since returns the recorded events following lastEventID. The caller must hold mu.
+func (h *EventHistory) since(lastEventID uint64) []RecordedEvent {
+	if lastEventID == 0 || lastEventID == h.lastID {
+		return nil
+	}
+
+	first := uint64(1)
+	if h.lastID > uint64(len(h.ring)) {
+		first = h.lastID - uint64(len(h.ring)) + 1
+	}
+	if lastEventID < h.lastID && lastEventID+1 > first {
+		first = lastEventID + 1
+	}
+
+	replay := make([]RecordedEvent, 0, h.lastID-first+1)
+	for id := first; id <= h.lastID; id++ {
+		replay = append(replay, h.ring[h.index(id)])
+	}
+	return replay
+}
+
+func (h *EventHistory) index(id uint64) int {
+	return int((id - 1) % uint64(len(h.ring)))
+}
@@ -0,0 +1,121 @@
+package infrastructure
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/josimar-silva/gwaihir/internal/domain"
+)
+
+func publishMachines(h *EventHistory, ids ...string) {
+	for _, id := range ids {
+		h.Publish(domain.Event{Type: domain.EventWakeSent, MachineID: id})
+	}
+}
+
+func recordedIDs(events []RecordedEvent) []uint64 {
+	ids := make([]uint64, 0, len(events))
+	for _, event := range events {
+		ids = append(ids, event.ID)
+	}
+	return ids
+}
+
+func TestEventHistory_Replay(t *testing.T) {
+	history := NewEventHistory(3)
+	publishMachines(history, "a", "b", "c", "d", "e")
+	assert.Equal(t, uint64(5), history.LastEventID())
+
+	tests := []struct {
+		name        string
+		lastEventID uint64
+		want        []uint64
+	}{
+		{"new stream", 0, []uint64{}},
+		{"up to date", 5, []uint64{}},
+		{"resume", 3, []uint64{4, 5}},
+		{"resume past buffer", 1, []uint64{3, 4, 5}},
+		{"unknown ID", 42, []uint64{3, 4, 5}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			replay, _, unsubscribe := history.Subscribe(tt.lastEventID)
+			defer unsubscribe()
+			assert.Equal(t, tt.want, recordedIDs(replay))
+		})
+	}
+
+	replay, _, unsubscribe := history.Subscribe(3)
+	defer unsubscribe()
+	assert.Equal(t, "d", replay[0].MachineID)
+}
+
+func TestEventHistory_Live(t *testing.T) {
+	history := NewEventHistory(3)
+	publishMachines(history, "a")
+
+	replay, live, unsubscribe := history.Subscribe(0)
+	assert.Empty(t, replay)
+
+	publishMachines(history, "b")
+	event := <-live
+	assert.Equal(t, uint64(2), event.ID)
+	assert.Equal(t, "b", event.MachineID)
+
+	unsubscribe()
+	unsubscribe()
+	_, open := <-live
+	assert.False(t, open)
+}
+
+func TestEventHistory_SlowSubscriberIsDisconnected(t *testing.T) {
+	history := NewEventHistory(DefaultEventBuffer * 2)
+	_, live, unsubscribe := history.Subscribe(0)
+	defer unsubscribe()
+
+	for range DefaultEventBuffer + 1 {
+		publishMachines(history, "saruman")
+	}
+
+	require.Len(t, live, DefaultEventBuffer)
+	var last RecordedEvent
+	for event := range live {
+		last = event
+	}
+	// The subscriber resumes from its last event without missing any.
+	replay, _, unsubscribeResumed := history.Subscribe(last.ID)
+	defer unsubscribeResumed()
+	assert.Equal(t, []uint64{DefaultEventBuffer + 1}, recordedIDs(replay))
+}
+
+func TestEventHistory_Close(t *testing.T) {
+	history := NewEventHistory(3)
+	_, live, unsubscribe := history.Subscribe(0)
+	defer unsubscribe()
+
+	history.Close()
+	_, open := <-live
+	assert.False(t, open)
+
+	publishMachines(history, "saruman")
+	assert.Equal(t, uint64(0), history.LastEventID())
+
+	_, late, unsubscribeLate := history.Subscribe(0)
+	defer unsubscribeLate()
+	_, open = <-late
+	assert.False(t, open)
+}
+
+func TestEventHistory_Consume(t *testing.T) {
+	history := NewEventHistory(3)
+	bus := NewEventBus(4)
+	events, unsubscribe := bus.Subscribe()
+
+	bus.Publish(domain.Event{Type: domain.EventConfigReloaded})
+	unsubscribe()
+	history.Consume(events)
+
+	assert.Equal(t, uint64(1), history.LastEventID())
+}
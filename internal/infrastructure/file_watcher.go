@@ -0,0 +1,110 @@
+// Package infrastructure provides infrastructure layer implementations.
+package infrastructure
+
+import (
+	"context"
+	"crypto/sha256"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// FileWatcher polls a file and reports when its content changes.
+// Polling the content (rather than relying on inotify) also detects the symlink
+// swaps Kubernetes performs when updating mounted ConfigMaps and Secrets.
+// Files matching additional glob patterns can be watched too; adding or removing
+// a matching file counts as a change.
+type FileWatcher struct {
+	path     string
+	patterns []string
+	interval time.Duration
+	lastHash [sha256.Size]byte
+	hasHash  bool
+}
+
+// NewFileWatcher creates a watcher for path that checks for changes every interval.
+// The current content is used as the baseline, so only later changes are reported.
+func NewFileWatcher(path string, interval time.Duration) *FileWatcher {
+	w := &FileWatcher{
+		path:     path,
+		interval: interval,
+	}
+	w.lastHash, w.hasHash = w.hash()
+	return w
+}
+
+// WithPatterns additionally watches the files matching the glob patterns and
+// resets the baseline to the current content.
+func (w *FileWatcher) WithPatterns(patterns ...string) *FileWatcher {
+	w.patterns = patterns
+	w.lastHash, w.hasHash = w.hash()
+	return w
+}
+
+// Watch blocks until ctx is done, invoking onChange whenever the file content changes.
+// A file that is temporarily missing or unreadable is not reported as a change.
+func (w *FileWatcher) Watch(ctx context.Context, onChange func()) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if w.Changed() {
+				onChange()
+			}
+		}
+	}
+}
+
+// Changed reports whether the file content differs from the last observed content
+// and records the new content as the baseline.
+func (w *FileWatcher) Changed() bool {
+	hash, ok := w.hash()
+	if !ok {
+		return false
+	}
+
+	if w.hasHash && hash == w.lastHash {
+		return false
+	}
+
+	w.lastHash = hash
+	w.hasHash = true
+	return true
+}
+
+// #nosec G304 - paths are controlled by application configuration, not user input
+func (w *FileWatcher) hash() ([sha256.Size]byte, bool) {
+	data, err := os.ReadFile(w.path)
+	if err != nil {
+		return [sha256.Size]byte{}, false
+	}
+	if len(w.patterns) == 0 {
+		return sha256.Sum256(data), true
+	}
+
+	h := sha256.New()
+	h.Write(data)
+	for _, pattern := range w.patterns {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			continue
+		}
+		for _, match := range matches {
+			content, err := os.ReadFile(match)
+			if err != nil {
+				return [sha256.Size]byte{}, false
+			}
+			h.Write([]byte(match))
+			h.Write([]byte{0})
+			h.Write(content)
+		}
+	}
+
+	var sum [sha256.Size]byte
+	copy(sum[:], h.Sum(nil))
+	return sum, true
+}
@@ -0,0 +1,103 @@
+package infrastructure
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFileWatcher_Changed(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("a: 1"), 0o600); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	watcher := NewFileWatcher(path, time.Second)
+	if watcher.Changed() {
+		t.Error("Expected no change for initial content")
+	}
+
+	if err := os.WriteFile(path, []byte("a: 2"), 0o600); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if !watcher.Changed() {
+		t.Error("Expected change after content update")
+	}
+	if watcher.Changed() {
+		t.Error("Expected change to be reported only once")
+	}
+
+	if err := os.Remove(path); err != nil {
+		t.Fatalf("Failed to remove file: %v", err)
+	}
+	if watcher.Changed() {
+		t.Error("Expected missing file not to be reported as a change")
+	}
+}
+
+func TestFileWatcher_Watch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("a: 1"), 0o600); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	watcher := NewFileWatcher(path, 10*time.Millisecond)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	changed := make(chan struct{}, 1)
+	go watcher.Watch(ctx, func() {
+		changed <- struct{}{}
+	})
+
+	if err := os.WriteFile(path, []byte("a: 2"), 0o600); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	select {
+	case <-changed:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected change notification")
+	}
+}
+
+func TestFileWatcher_WithPatterns(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(path, []byte("a: 1"), 0o600); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	includeDir := filepath.Join(dir, "machines.d")
+	if err := os.Mkdir(includeDir, 0o750); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+
+	watcher := NewFileWatcher(path, time.Second).WithPatterns(filepath.Join(includeDir, "*.yaml"))
+	if watcher.Changed() {
+		t.Error("Expected no change for initial content")
+	}
+
+	included := filepath.Join(includeDir, "team.yaml")
+	if err := os.WriteFile(included, []byte("machines: []"), 0o600); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if !watcher.Changed() {
+		t.Error("Expected change after adding an included file")
+	}
+
+	if err := os.WriteFile(included, []byte("machines: [{}]"), 0o600); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if !watcher.Changed() {
+		t.Error("Expected change after updating an included file")
+	}
+
+	if err := os.Remove(included); err != nil {
+		t.Fatalf("Failed to remove file: %v", err)
+	}
+	if !watcher.Changed() {
+		t.Error("Expected change after removing an included file")
+	}
+}
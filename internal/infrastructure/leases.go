@@ -0,0 +1,170 @@
+// Package infrastructure provides infrastructure layer implementations.
+package infrastructure
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+)
+
+// Lease file formats.
+const (
+	LeaseFormatDnsmasq = "dnsmasq"
+	LeaseFormatDhcpd   = "dhcpd"
+	LeaseFormatEthers  = "ethers"
+)
+
+// LeaseEntry is a MAC address to host mapping read from a lease or ethers file.
+// IP and Hostname are empty when the source does not provide them.
+type LeaseEntry struct {
+	MAC      string
+	IP       string
+	Hostname string
+}
+
+// ParseLeaseFile reads the file at path in the given format.
+//
+// #nosec G304 - path is controlled by application configuration, not user input
+func ParseLeaseFile(format, path string) ([]LeaseEntry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s file: %w", format, err)
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	switch format {
+	case LeaseFormatDnsmasq:
+		return ParseDnsmasqLeases(file)
+	case LeaseFormatDhcpd:
+		return ParseDhcpdLeases(file)
+	case LeaseFormatEthers:
+		return ParseEthers(file)
+	default:
+		return nil, fmt.Errorf("unsupported lease format: %s", format)
+	}
+}
+
+// ParseDnsmasqLeases parses a dnsmasq leases file, where each line reads
+// "<expiry> <mac> <ip> <hostname> <client-id>". A hostname of "*" means unknown.
+// DUID lines and IPv6 leases without a MAC address are skipped.
+func ParseDnsmasqLeases(r io.Reader) ([]LeaseEntry, error) {
+	var entries []LeaseEntry
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 4 {
+			continue
+		}
+
+		if _, err := net.ParseMAC(fields[1]); err != nil {
+			continue
+		}
+
+		entry := LeaseEntry{MAC: fields[1], IP: fields[2]}
+		if fields[3] != "*" {
+			entry.Hostname = fields[3]
+		}
+		entries = append(entries, entry)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read dnsmasq leases: %w", err)
+	}
+
+	return entries, nil
+}
+
+// ParseDhcpdLeases parses an ISC dhcpd.leases file. Each "lease <ip> { ... }" block
+// contributes an entry from its "hardware ethernet" and "client-hostname" statements.
+// Blocks without a hardware address are skipped. Since dhcpd appends updated leases,
+// later blocks for the same address describe the most recent state.
+func ParseDhcpdLeases(r io.Reader) ([]LeaseEntry, error) {
+	var entries []LeaseEntry
+	var current *LeaseEntry
+
+	scanner := bufio.NewScanner(r)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(line, "lease ") && strings.HasSuffix(line, "{"):
+			if current != nil {
+				return nil, fmt.Errorf("line %d: nested lease block", lineNumber)
+			}
+			fields := strings.Fields(line)
+			current = &LeaseEntry{IP: fields[1]}
+		case current == nil:
+			continue
+		case line == "}":
+			if current.MAC != "" {
+				entries = append(entries, *current)
+			}
+			current = nil
+		case strings.HasPrefix(line, "hardware ethernet "):
+			current.MAC = dhcpdValue(strings.TrimPrefix(line, "hardware ethernet "))
+		case strings.HasPrefix(line, "client-hostname "):
+			current.Hostname = dhcpdValue(strings.TrimPrefix(line, "client-hostname "))
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read dhcpd leases: %w", err)
+	}
+
+	if current != nil {
+		return nil, fmt.Errorf("unterminated lease block for %s", current.IP)
+	}
+
+	return entries, nil
+}
+
+// dhcpdValue strips the trailing semicolon and surrounding quotes of a statement value.
+func dhcpdValue(value string) string {
+	value = strings.TrimSuffix(strings.TrimSpace(value), ";")
+	return strings.Trim(value, `"`)
+}
+
+// ParseEthers parses an ethers(5) file, where each line reads "<mac> <hostname-or-ip>".
+// Comments start with "#".
+func ParseEthers(r io.Reader) ([]LeaseEntry, error) {
+	var entries []LeaseEntry
+
+	scanner := bufio.NewScanner(r)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := scanner.Text()
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) < 2 {
+			return nil, fmt.Errorf("line %d: expected '<mac> <hostname-or-ip>'", lineNumber)
+		}
+
+		entry := LeaseEntry{MAC: fields[0]}
+		if net.ParseIP(fields[1]) != nil {
+			entry.IP = fields[1]
+		} else {
+			entry.Hostname = fields[1]
+		}
+		entries = append(entries, entry)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read ethers file: %w", err)
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,119 @@
+package infrastructure
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const dnsmasqLeasesFixture = `1767225600 aa:bb:cc:dd:ee:01 192.168.1.10 saruman 01:aa:bb:cc:dd:ee:01
+1767225600 aa:bb:cc:dd:ee:02 192.168.1.11 * 01:aa:bb:cc:dd:ee:02
+duid 00:01:00:01:2c:5e:7a:1b:aa:bb:cc:dd:ee:ff
+1767225600 1234567 fd00::10 morgoth 00:01:00:01:2c:5e:7a:1b:aa:bb:cc:dd:ee:03
+`
+
+const dhcpdLeasesFixture = `# The format of this file is documented in the dhcpd.leases(5) manual page.
+authoring-byte-order little-endian;
+
+lease 192.168.1.20 {
+  starts 4 2026/01/01 00:00:00;
+  binding state active;
+  hardware ethernet aa:bb:cc:dd:ee:10;
+  client-hostname "gandalf";
+}
+lease 192.168.1.21 {
+  binding state free;
+}
+lease 192.168.1.22 {
+  hardware ethernet aa:bb:cc:dd:ee:11;
+}
+`
+
+const ethersFixture = `# MAC address       host
+aa:bb:cc:dd:ee:20   radagast   # brown wizard
+aa:bb:cc:dd:ee:21   10.0.0.5
+
+`
+
+func TestParseDnsmasqLeases(t *testing.T) {
+	entries, err := ParseDnsmasqLeases(strings.NewReader(dnsmasqLeasesFixture))
+	if err != nil {
+		t.Fatalf("ParseDnsmasqLeases() error = %v", err)
+	}
+
+	expected := []LeaseEntry{
+		{MAC: "aa:bb:cc:dd:ee:01", IP: "192.168.1.10", Hostname: "saruman"},
+		{MAC: "aa:bb:cc:dd:ee:02", IP: "192.168.1.11"},
+	}
+	if !reflect.DeepEqual(entries, expected) {
+		t.Errorf("ParseDnsmasqLeases() = %+v, want %+v", entries, expected)
+	}
+}
+
+func TestParseDhcpdLeases(t *testing.T) {
+	entries, err := ParseDhcpdLeases(strings.NewReader(dhcpdLeasesFixture))
+	if err != nil {
+		t.Fatalf("ParseDhcpdLeases() error = %v", err)
+	}
+
+	expected := []LeaseEntry{
+		{MAC: "aa:bb:cc:dd:ee:10", IP: "192.168.1.20", Hostname: "gandalf"},
+		{MAC: "aa:bb:cc:dd:ee:11", IP: "192.168.1.22"},
+	}
+	if !reflect.DeepEqual(entries, expected) {
+		t.Errorf("ParseDhcpdLeases() = %+v, want %+v", entries, expected)
+	}
+}
+
+func TestParseDhcpdLeases_Unterminated(t *testing.T) {
+	_, err := ParseDhcpdLeases(strings.NewReader("lease 192.168.1.20 {\n  hardware ethernet aa:bb:cc:dd:ee:10;\n"))
+	if err == nil {
+		t.Fatal("Expected error for unterminated lease block")
+	}
+}
+
+func TestParseEthers(t *testing.T) {
+	entries, err := ParseEthers(strings.NewReader(ethersFixture))
+	if err != nil {
+		t.Fatalf("ParseEthers() error = %v", err)
+	}
+
+	expected := []LeaseEntry{
+		{MAC: "aa:bb:cc:dd:ee:20", Hostname: "radagast"},
+		{MAC: "aa:bb:cc:dd:ee:21", IP: "10.0.0.5"},
+	}
+	if !reflect.DeepEqual(entries, expected) {
+		t.Errorf("ParseEthers() = %+v, want %+v", entries, expected)
+	}
+}
+
+func TestParseEthers_InvalidLine(t *testing.T) {
+	_, err := ParseEthers(strings.NewReader("aa:bb:cc:dd:ee:20\n"))
+	if err == nil || !strings.Contains(err.Error(), "line 1") {
+		t.Errorf("Expected error naming line 1, got %v", err)
+	}
+}
+
+func TestParseLeaseFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ethers")
+	if err := os.WriteFile(path, []byte(ethersFixture), 0o600); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	entries, err := ParseLeaseFile(LeaseFormatEthers, path)
+	if err != nil {
+		t.Fatalf("ParseLeaseFile() error = %v", err)
+	}
+	if len(entries) != 2 {
+		t.Errorf("Expected 2 entries, got %d", len(entries))
+	}
+
+	if _, err := ParseLeaseFile("unknown", path); err == nil {
+		t.Error("Expected error for unsupported format")
+	}
+	if _, err := ParseLeaseFile(LeaseFormatEthers, filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("Expected error for missing file")
+	}
+}
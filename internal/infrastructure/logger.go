@@ -3,6 +3,7 @@ package infrastructure
 
 import (
 	"context"
+	"io"
 	"log/slog"
 	"os"
 )
@@ -11,12 +12,19 @@ import (
 type Logger struct {
 	logger *slog.Logger
 	level  string
+	// ctx is passed to the handler, which adds the IDs of the span it carries.
+	ctx context.Context
 }
 
 // NewLogger creates a new structured logger.
 // format: "json" or "text"
 // level: "debug", "info", "warn", or "error"
 func NewLogger(format, level string) *Logger {
+	return NewLoggerWithWriter(os.Stdout, format, level)
+}
+
+// NewLoggerWithWriter creates a new structured logger that writes to w.
+func NewLoggerWithWriter(w io.Writer, format, level string) *Logger {
 	var handler slog.Handler
 	var slogLevel slog.Level
 
@@ -36,39 +44,40 @@ func NewLogger(format, level string) *Logger {
 
 	// Create appropriate handler based on format
 	if format == "json" {
-		handler = slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
+		handler = slog.NewJSONHandler(w, &slog.HandlerOptions{
 			Level: slogLevel,
 		})
 	} else {
-		handler = slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
+		handler = slog.NewTextHandler(w, &slog.HandlerOptions{
 			Level: slogLevel,
 		})
 	}
 
 	return &Logger{
-		logger: slog.New(handler),
+		logger: slog.New(traceHandler{handler}),
 		level:  level,
+		ctx:    context.Background(),
 	}
 }
 
 // Info logs an info-level message with attributes.
 func (l *Logger) Info(msg string, attrs ...slog.Attr) {
-	l.logger.LogAttrs(context.Background(), slog.LevelInfo, msg, attrs...)
+	l.logger.LogAttrs(l.ctx, slog.LevelInfo, msg, attrs...)
 }
 
 // Warn logs a warn-level message with attributes.
 func (l *Logger) Warn(msg string, attrs ...slog.Attr) {
-	l.logger.LogAttrs(context.Background(), slog.LevelWarn, msg, attrs...)
+	l.logger.LogAttrs(l.ctx, slog.LevelWarn, msg, attrs...)
 }
 
 // Error logs an error-level message with attributes.
 func (l *Logger) Error(msg string, attrs ...slog.Attr) {
-	l.logger.LogAttrs(context.Background(), slog.LevelError, msg, attrs...)
+	l.logger.LogAttrs(l.ctx, slog.LevelError, msg, attrs...)
 }
 
 // Debug logs a debug-level message with attributes.
 func (l *Logger) Debug(msg string, attrs ...slog.Attr) {
-	l.logger.LogAttrs(context.Background(), slog.LevelDebug, msg, attrs...)
+	l.logger.LogAttrs(l.ctx, slog.LevelDebug, msg, attrs...)
 }
 
 // WithContext returns a new Logger with additional context attributes.
@@ -79,9 +88,41 @@ func (l *Logger) WithContext(attrs ...slog.Attr) *Logger {
 	}
 	return &Logger{
 		logger: l.logger.With(attrSlice...),
+		level:  l.level,
+		ctx:    l.ctx,
+	}
+}
+
+// WithTrace returns a Logger whose records carry the trace_id and span_id of the
+// span in ctx, so they can be correlated with the trace.
+func (l *Logger) WithTrace(ctx context.Context) *Logger {
+	return &Logger{
+		logger: l.logger,
+		level:  l.level,
+		ctx:    ctx,
 	}
 }
 
+// traceHandler adds the trace and span IDs of the span in the context of a record.
+type traceHandler struct {
+	slog.Handler
+}
+
+func (h traceHandler) Handle(ctx context.Context, record slog.Record) error {
+	if traceID, spanID, ok := traceIDs(ctx); ok {
+		record.AddAttrs(slog.String("trace_id", traceID), slog.String("span_id", spanID))
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h traceHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return traceHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h traceHandler) WithGroup(name string) slog.Handler {
+	return traceHandler{h.Handler.WithGroup(name)}
+}
+
 // String converts an attribute key-value pair.
 func String(key, value string) slog.Attr {
 	return slog.String(key, value)
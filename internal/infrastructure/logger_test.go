@@ -1,6 +1,8 @@
 package infrastructure
 
 import (
+	"bytes"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -162,3 +164,19 @@ func TestIsDebug_False_Error(t *testing.T) {
 	logger := NewLogger("text", "error")
 	assert.False(t, logger.IsDebug())
 }
+
+func TestNewLoggerWithWriter(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewLoggerWithWriter(&buf, "json", "warn")
+
+	logger.Info("hidden")
+	logger.Warn("visible", String("key", "value"))
+
+	output := buf.String()
+	if strings.Contains(output, "hidden") {
+		t.Errorf("Expected info message to be filtered, got %s", output)
+	}
+	if !strings.Contains(output, `"msg":"visible"`) || !strings.Contains(output, `"key":"value"`) {
+		t.Errorf("Expected warn message with attributes, got %s", output)
+	}
+}
@@ -4,33 +4,73 @@ package infrastructure
 import (
 	"fmt"
 	"net/http"
+	"sync"
 
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/josimar-silva/gwaihir/internal/domain"
+)
+
+// Results of the gwaihir_wol_packets_total counter.
+const (
+	WoLResultSent   = "sent"
+	WoLResultFailed = "failed"
 )
 
-// Metrics holds all Prometheus metrics for the application.
+// MachineLabelOther is the machine_id label value of machines outside the allowlist,
+// including wakes of unlisted MAC addresses, so arbitrary IDs cannot create series.
+const MachineLabelOther = "other"
+
+// Metrics holds all Prometheus metrics for the application and the registry they are
+// registered on.
 type Metrics struct {
-	WoLPacketsSent     prometheus.Counter
-	WoLPacketsFailed   prometheus.Counter
+	BuildInfo          *prometheus.GaugeVec
+	WoLPackets         *prometheus.CounterVec
 	MachineNotFound    prometheus.Counter
 	MachinesListed     prometheus.Counter
-	MachinesRetrieved  prometheus.Counter
-	RequestDuration    prometheus.Histogram
+	MachinesRetrieved  *prometheus.CounterVec
+	RequestDuration    *prometheus.HistogramVec
 	ConfiguredMachines prometheus.Gauge
+	AuthFailures       *prometheus.CounterVec
+	AuthLockedOut      prometheus.GaugeFunc
+	ConfigReloads      *prometheus.CounterVec
+	ConfigLastReload   prometheus.Gauge
+	ImportedMachines   *prometheus.GaugeVec
+	LegacyAPIRequests  *prometheus.CounterVec
+	GRPCRequests       *prometheus.CounterVec
+	GRPCDuration       *prometheus.HistogramVec
+	MQTTConnected      prometheus.Gauge
+	MQTTCommands       *prometheus.CounterVec
+	WebhookDeliveries  *prometheus.CounterVec
+	WebhookRetries     *prometheus.CounterVec
+
+	registry  *prometheus.Registry
+	mu        sync.RWMutex
+	machines  map[string]bool
+	lockedOut func() int
 }
 
-// NewMetrics creates and registers all Prometheus metrics.
+// NewMetrics creates all Prometheus metrics and registers them on a new registry, so
+// every instance is independent of the others.
 func NewMetrics() (*Metrics, error) {
+	return NewMetricsWithRegistry(prometheus.NewRegistry())
+}
+
+// NewMetricsWithRegistry creates all Prometheus metrics and registers them, along with
+// the Go runtime and process collectors, on registry.
+func NewMetricsWithRegistry(registry *prometheus.Registry) (*Metrics, error) {
 	m := &Metrics{
-		WoLPacketsSent: prometheus.NewCounter(prometheus.CounterOpts{
-			Name: "gwaihir_wol_packets_sent_total",
-			Help: "Total number of WoL packets successfully sent",
-		}),
-		WoLPacketsFailed: prometheus.NewCounter(prometheus.CounterOpts{
-			Name: "gwaihir_wol_packets_failed_total",
-			Help: "Total number of WoL packet send failures",
-		}),
+		registry: registry,
+		BuildInfo: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "gwaihir_build_info",
+			Help: "Build information of the running binary, always 1",
+		}, []string{"version", "commit"}),
+		WoLPackets: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "gwaihir_wol_packets_total",
+			Help: "Total number of WoL packets by machine and result (sent, failed)",
+		}, []string{"machine_id", "result"}),
 		MachineNotFound: prometheus.NewCounter(prometheus.CounterOpts{
 			Name: "gwaihir_machine_not_found_total",
 			Help: "Total number of machine not found errors",
@@ -39,48 +79,203 @@ func NewMetrics() (*Metrics, error) {
 			Name: "gwaihir_machines_listed_total",
 			Help: "Total number of times machines list was requested",
 		}),
-		MachinesRetrieved: prometheus.NewCounter(prometheus.CounterOpts{
+		MachinesRetrieved: prometheus.NewCounterVec(prometheus.CounterOpts{
 			Name: "gwaihir_machines_retrieved_total",
 			Help: "Total number of times a machine was retrieved by ID",
-		}),
-		RequestDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
+		}, []string{"machine_id"}),
+		RequestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
 			Name:    "gwaihir_request_duration_seconds",
-			Help:    "Request latency in seconds",
+			Help:    "HTTP request latency in seconds by method, route template and status code",
 			Buckets: prometheus.DefBuckets,
-		}),
+		}, []string{"method", "route", "status"}),
 		ConfiguredMachines: prometheus.NewGauge(prometheus.GaugeOpts{
 			Name: "gwaihir_configured_machines_total",
 			Help: "Total number of configured machines in allowlist",
 		}),
+		AuthFailures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "gwaihir_auth_failures_total",
+			Help: "Total number of failed authentication attempts by reason",
+		}, []string{"reason"}),
+		ConfigReloads: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "gwaihir_config_reloads_total",
+			Help: "Total number of configuration reload attempts by result",
+		}, []string{"result"}),
+		ConfigLastReload: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "gwaihir_config_last_reload_success_timestamp_seconds",
+			Help: "Unix timestamp of the last successful configuration load",
+		}),
+		ImportedMachines: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "gwaihir_imported_machines",
+			Help: "Number of machines imported from each lease or ethers source",
+		}, []string{"source"}),
+		LegacyAPIRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "gwaihir_legacy_api_requests_total",
+			Help: "Total number of requests to deprecated unversioned API paths by route",
+		}, []string{"route"}),
+		GRPCRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "gwaihir_grpc_requests_total",
+			Help: "Total number of gRPC calls by method and status code",
+		}, []string{"method", "code"}),
+		GRPCDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "gwaihir_grpc_request_duration_seconds",
+			Help:    "gRPC call latency in seconds by method",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"method"}),
+		MQTTConnected: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "gwaihir_mqtt_connected",
+			Help: "Whether the MQTT client is connected to the broker (1) or not (0)",
+		}),
+		MQTTCommands: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "gwaihir_mqtt_commands_total",
+			Help: "Total number of MQTT wake commands by result (sent, failed, not_found, invalid)",
+		}, []string{"result"}),
+		WebhookDeliveries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "gwaihir_webhook_deliveries_total",
+			Help: "Total number of webhook deliveries by subscription and result (success, failure, dropped)",
+		}, []string{"subscription", "result"}),
+		WebhookRetries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "gwaihir_webhook_retries_total",
+			Help: "Total number of webhook delivery attempts retried after a failure by subscription",
+		}, []string{"subscription"}),
 	}
 
 	// Register all metrics
-	if err := prometheus.Register(m.WoLPacketsSent); err != nil {
-		return nil, fmt.Errorf("failed to register WoLPacketsSent: %w", err)
+	if err := registry.Register(collectors.NewGoCollector()); err != nil {
+		return nil, fmt.Errorf("failed to register Go collector: %w", err)
+	}
+	if err := registry.Register(collectors.NewProcessCollector(collectors.ProcessCollectorOpts{})); err != nil {
+		return nil, fmt.Errorf("failed to register process collector: %w", err)
 	}
-	if err := prometheus.Register(m.WoLPacketsFailed); err != nil {
-		return nil, fmt.Errorf("failed to register WoLPacketsFailed: %w", err)
+	if err := registry.Register(m.BuildInfo); err != nil {
+		return nil, fmt.Errorf("failed to register BuildInfo: %w", err)
 	}
-	if err := prometheus.Register(m.MachineNotFound); err != nil {
+	if err := registry.Register(m.WoLPackets); err != nil {
+		return nil, fmt.Errorf("failed to register WoLPackets: %w", err)
+	}
+	if err := registry.Register(m.MachineNotFound); err != nil {
 		return nil, fmt.Errorf("failed to register MachineNotFound: %w", err)
 	}
-	if err := prometheus.Register(m.MachinesListed); err != nil {
+	if err := registry.Register(m.MachinesListed); err != nil {
 		return nil, fmt.Errorf("failed to register MachinesListed: %w", err)
 	}
-	if err := prometheus.Register(m.MachinesRetrieved); err != nil {
+	if err := registry.Register(m.MachinesRetrieved); err != nil {
 		return nil, fmt.Errorf("failed to register MachinesRetrieved: %w", err)
 	}
-	if err := prometheus.Register(m.RequestDuration); err != nil {
+	if err := registry.Register(m.RequestDuration); err != nil {
 		return nil, fmt.Errorf("failed to register RequestDuration: %w", err)
 	}
-	if err := prometheus.Register(m.ConfiguredMachines); err != nil {
+	if err := registry.Register(m.ConfiguredMachines); err != nil {
 		return nil, fmt.Errorf("failed to register ConfiguredMachines: %w", err)
 	}
+	if err := registry.Register(m.AuthFailures); err != nil {
+		return nil, fmt.Errorf("failed to register AuthFailures: %w", err)
+	}
+	m.AuthLockedOut = prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "gwaihir_auth_locked_out_clients",
+		Help: "Current number of clients locked out after repeated authentication failures",
+	}, m.lockedOutClients)
+	if err := registry.Register(m.AuthLockedOut); err != nil {
+		return nil, fmt.Errorf("failed to register AuthLockedOut: %w", err)
+	}
+	if err := registry.Register(m.ConfigReloads); err != nil {
+		return nil, fmt.Errorf("failed to register ConfigReloads: %w", err)
+	}
+	if err := registry.Register(m.ConfigLastReload); err != nil {
+		return nil, fmt.Errorf("failed to register ConfigLastReload: %w", err)
+	}
+	if err := registry.Register(m.ImportedMachines); err != nil {
+		return nil, fmt.Errorf("failed to register ImportedMachines: %w", err)
+	}
+	if err := registry.Register(m.LegacyAPIRequests); err != nil {
+		return nil, fmt.Errorf("failed to register LegacyAPIRequests: %w", err)
+	}
+	if err := registry.Register(m.GRPCRequests); err != nil {
+		return nil, fmt.Errorf("failed to register GRPCRequests: %w", err)
+	}
+	if err := registry.Register(m.GRPCDuration); err != nil {
+		return nil, fmt.Errorf("failed to register GRPCDuration: %w", err)
+	}
+	if err := registry.Register(m.MQTTConnected); err != nil {
+		return nil, fmt.Errorf("failed to register MQTTConnected: %w", err)
+	}
+	if err := registry.Register(m.MQTTCommands); err != nil {
+		return nil, fmt.Errorf("failed to register MQTTCommands: %w", err)
+	}
+	if err := registry.Register(m.WebhookDeliveries); err != nil {
+		return nil, fmt.Errorf("failed to register WebhookDeliveries: %w", err)
+	}
+	if err := registry.Register(m.WebhookRetries); err != nil {
+		return nil, fmt.Errorf("failed to register WebhookRetries: %w", err)
+	}
 
 	return m, nil
 }
 
-// MetricsHandler returns an HTTP handler that serves Prometheus metrics.
-func MetricsHandler() http.Handler {
-	return promhttp.Handler()
+// SetBuildInfo records the version and commit of the running binary.
+func (m *Metrics) SetBuildInfo(version, commit string) {
+	m.BuildInfo.Reset()
+	m.BuildInfo.WithLabelValues(version, commit).Set(1)
+}
+
+// SetConfiguredMachines records the machines of the allowlist, which are the only
+// values of the machine_id label besides MachineLabelOther. The series of machines
+// removed from the allowlist are deleted.
+func (m *Metrics) SetConfiguredMachines(machines []*domain.Machine) {
+	configured := make(map[string]bool, len(machines))
+	for _, machine := range machines {
+		configured[machine.ID] = true
+	}
+
+	m.mu.Lock()
+	removed := m.machines
+	m.machines = configured
+	m.mu.Unlock()
+
+	for id := range removed {
+		if !configured[id] {
+			labels := prometheus.Labels{"machine_id": id}
+			m.WoLPackets.DeletePartialMatch(labels)
+			m.MachinesRetrieved.DeletePartialMatch(labels)
+		}
+	}
+	m.ConfiguredMachines.Set(float64(len(machines)))
+}
+
+// MachineLabel returns the machine_id label value for the machine id.
+func (m *Metrics) MachineLabel(id string) string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if m.machines[id] {
+		return id
+	}
+	return MachineLabelOther
+}
+
+// TrackLockedOutClients makes the AuthLockedOut gauge report count, which returns the
+// number of clients currently locked out, whenever the metrics are collected.
+func (m *Metrics) TrackLockedOutClients(count func() int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.lockedOut = count
+}
+
+// lockedOutClients returns the value of the AuthLockedOut gauge.
+func (m *Metrics) lockedOutClients() float64 {
+	m.mu.RLock()
+	count := m.lockedOut
+	m.mu.RUnlock()
+	if count == nil {
+		return 0
+	}
+	return float64(count())
+}
+
+// Registry returns the registry the metrics are registered on.
+func (m *Metrics) Registry() *prometheus.Registry {
+	return m.registry
+}
+
+// Handler returns an HTTP handler that serves the metrics of the registry.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
 }
@@ -1,14 +1,18 @@
 package infrastructure
 
 import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/josimar-silva/gwaihir/internal/domain"
 )
 
 func TestNewMetrics(t *testing.T) {
-	// Clear default registry to avoid "already registered" errors
-	prometheus.DefaultRegisterer = prometheus.NewRegistry()
 
 	metrics, err := NewMetrics()
 	if err != nil {
@@ -20,11 +24,8 @@ func TestNewMetrics(t *testing.T) {
 		return
 	}
 
-	if metrics.WoLPacketsSent == nil {
-		t.Fatal("Expected non-nil WoLPacketsSent")
-	}
-	if metrics.WoLPacketsFailed == nil {
-		t.Fatal("Expected non-nil WoLPacketsFailed")
+	if metrics.WoLPackets == nil {
+		t.Fatal("Expected non-nil WoLPackets")
 	}
 	if metrics.MachineNotFound == nil {
 		t.Fatal("Expected non-nil MachineNotFound")
@@ -41,10 +42,36 @@ func TestNewMetrics(t *testing.T) {
 	if metrics.ConfiguredMachines == nil {
 		t.Fatal("Expected non-nil ConfiguredMachines")
 	}
+	if metrics.AuthFailures == nil {
+		t.Fatal("Expected non-nil AuthFailures")
+	}
+	if metrics.AuthLockedOut == nil {
+		t.Fatal("Expected non-nil AuthLockedOut")
+	}
+	if metrics.ConfigReloads == nil {
+		t.Fatal("Expected non-nil ConfigReloads")
+	}
+	if metrics.ConfigLastReload == nil {
+		t.Fatal("Expected non-nil ConfigLastReload")
+	}
+	if metrics.ImportedMachines == nil {
+		t.Fatal("Expected non-nil ImportedMachines")
+	}
+	if metrics.LegacyAPIRequests == nil {
+		t.Fatal("Expected non-nil LegacyAPIRequests")
+	}
+	if metrics.GRPCRequests == nil || metrics.GRPCDuration == nil {
+		t.Fatal("Expected non-nil gRPC metrics")
+	}
+	if metrics.MQTTConnected == nil || metrics.MQTTCommands == nil {
+		t.Fatal("Expected non-nil MQTT metrics")
+	}
+	if metrics.WebhookDeliveries == nil || metrics.WebhookRetries == nil {
+		t.Fatal("Expected non-nil webhook metrics")
+	}
 }
 
 func TestMetricsCounterIncrement(t *testing.T) {
-	prometheus.DefaultRegisterer = prometheus.NewRegistry()
 
 	metrics, err := NewMetrics()
 	if err != nil {
@@ -52,18 +79,17 @@ func TestMetricsCounterIncrement(t *testing.T) {
 	}
 
 	// Test counter increments
-	metrics.WoLPacketsSent.Inc()
-	metrics.WoLPacketsSent.Inc()
-	metrics.WoLPacketsFailed.Inc()
+	metrics.WoLPackets.WithLabelValues("saruman", WoLResultSent).Inc()
+	metrics.WoLPackets.WithLabelValues("saruman", WoLResultSent).Inc()
+	metrics.WoLPackets.WithLabelValues("saruman", WoLResultFailed).Inc()
 	metrics.MachineNotFound.Inc()
 	metrics.MachinesListed.Inc()
-	metrics.MachinesRetrieved.Inc()
+	metrics.MachinesRetrieved.WithLabelValues("saruman").Inc()
 
 	// Should not panic - counters are incremented
 }
 
 func TestMetricsGaugeSet(t *testing.T) {
-	prometheus.DefaultRegisterer = prometheus.NewRegistry()
 
 	metrics, err := NewMetrics()
 	if err != nil {
@@ -80,7 +106,6 @@ func TestMetricsGaugeSet(t *testing.T) {
 }
 
 func TestMetricsHistogramObserve(t *testing.T) {
-	prometheus.DefaultRegisterer = prometheus.NewRegistry()
 
 	metrics, err := NewMetrics()
 	if err != nil {
@@ -88,32 +113,152 @@ func TestMetricsHistogramObserve(t *testing.T) {
 	}
 
 	// Test histogram observe
-	metrics.RequestDuration.Observe(0.1)
-	metrics.RequestDuration.Observe(0.5)
-	metrics.RequestDuration.Observe(1.0)
+	metrics.RequestDuration.WithLabelValues("POST", "/api/v1/wol", "202").Observe(0.1)
+	metrics.RequestDuration.WithLabelValues("POST", "/api/v1/wol", "202").Observe(0.5)
+	metrics.RequestDuration.WithLabelValues("GET", "/api/v1/machines", "200").Observe(1.0)
 
 	// Should not panic - histogram is recorded
 }
 
+func TestMetrics_MachineLabelBoundedToConfiguredMachines(t *testing.T) {
+
+	metrics, err := NewMetrics()
+	if err != nil {
+		t.Fatalf("Failed to create metrics: %v", err)
+	}
+
+	if got := metrics.MachineLabel("saruman"); got != MachineLabelOther {
+		t.Errorf("Expected %q before machines are configured, got %q", MachineLabelOther, got)
+	}
+
+	metrics.SetConfiguredMachines([]*domain.Machine{{ID: "saruman"}, {ID: "morgoth"}})
+	if got := testutil.ToFloat64(metrics.ConfiguredMachines); got != 2 {
+		t.Errorf("Expected 2 configured machines, got %v", got)
+	}
+	if got := metrics.MachineLabel("saruman"); got != "saruman" {
+		t.Errorf("Expected configured machine label, got %q", got)
+	}
+	if got := metrics.MachineLabel("sauron"); got != MachineLabelOther {
+		t.Errorf("Expected %q for an unknown machine, got %q", MachineLabelOther, got)
+	}
+
+	metrics.WoLPackets.WithLabelValues("saruman", WoLResultSent).Inc()
+	metrics.WoLPackets.WithLabelValues("morgoth", WoLResultSent).Inc()
+	metrics.MachinesRetrieved.WithLabelValues("morgoth").Inc()
+
+	// Removing a machine from the allowlist deletes its series.
+	metrics.SetConfiguredMachines([]*domain.Machine{{ID: "saruman"}})
+	if got := testutil.CollectAndCount(metrics.WoLPackets); got != 1 {
+		t.Errorf("Expected 1 WoL packet series, got %d", got)
+	}
+	if got := testutil.CollectAndCount(metrics.MachinesRetrieved); got != 0 {
+		t.Errorf("Expected no retrieved series, got %d", got)
+	}
+	if got := metrics.MachineLabel("morgoth"); got != MachineLabelOther {
+		t.Errorf("Expected %q for a removed machine, got %q", MachineLabelOther, got)
+	}
+}
+
 func TestMetricsHandler(t *testing.T) {
-	handler := MetricsHandler()
-	if handler == nil {
-		t.Fatal("Expected non-nil handler")
+	metrics, err := NewMetrics()
+	if err != nil {
+		t.Fatalf("Failed to create metrics: %v", err)
+	}
+	metrics.SetBuildInfo("1.2.3", "abc123")
+	metrics.MachinesListed.Inc()
+
+	w := httptest.NewRecorder()
+	metrics.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	body := w.Body.String()
+	for _, want := range []string{
+		`gwaihir_build_info{commit="abc123",version="1.2.3"} 1`,
+		"gwaihir_machines_listed_total 1",
+		"go_goroutines",
+		"process_cpu_seconds_total",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("Expected metrics to contain %q", want)
+		}
 	}
 }
 
-func TestMetricsRegistrationError(t *testing.T) {
-	prometheus.DefaultRegisterer = prometheus.NewRegistry()
+func TestSetBuildInfo_ReplacesPreviousValue(t *testing.T) {
+	metrics, err := NewMetrics()
+	if err != nil {
+		t.Fatalf("Failed to create metrics: %v", err)
+	}
+
+	metrics.SetBuildInfo("1.0.0", "old")
+	metrics.SetBuildInfo("1.1.0", "new")
+
+	if got := testutil.CollectAndCount(metrics.BuildInfo); got != 1 {
+		t.Errorf("Expected 1 build info series, got %d", got)
+	}
+	if got := testutil.ToFloat64(metrics.BuildInfo.WithLabelValues("1.1.0", "new")); got != 1 {
+		t.Errorf("Expected build info 1, got %v", got)
+	}
+}
 
-	// Create first set of metrics successfully
-	_, err := NewMetrics()
+func TestTrackLockedOutClients(t *testing.T) {
+	metrics, err := NewMetrics()
+	if err != nil {
+		t.Fatalf("Failed to create metrics: %v", err)
+	}
+
+	if got := testutil.ToFloat64(metrics.AuthLockedOut); got != 0 {
+		t.Errorf("Expected 0 locked out clients without a tracker, got %v", got)
+	}
+
+	count := 3
+	metrics.TrackLockedOutClients(func() int { return count })
+	if got := testutil.ToFloat64(metrics.AuthLockedOut); got != 3 {
+		t.Errorf("Expected 3 locked out clients, got %v", got)
+	}
+
+	count = 0
+	if got := testutil.ToFloat64(metrics.AuthLockedOut); got != 0 {
+		t.Errorf("Expected the gauge to drop to 0, got %v", got)
+	}
+}
+
+func TestNewMetrics_IndependentRegistries(t *testing.T) {
+	first, err := NewMetrics()
 	if err != nil {
 		t.Fatalf("Failed to create initial metrics: %v", err)
 	}
 
-	// Try to create again - should fail because metrics are already registered
-	_, err = NewMetrics()
-	if err == nil {
+	// Every instance owns its registry, so creating metrics again succeeds.
+	second, err := NewMetrics()
+	if err != nil {
+		t.Fatalf("Failed to create second metrics: %v", err)
+	}
+	if first.Registry() == second.Registry() {
+		t.Fatal("Expected each instance to own a registry")
+	}
+
+	first.MachinesListed.Inc()
+	if got := testutil.ToFloat64(second.MachinesListed); got != 0 {
+		t.Errorf("Expected independent counters, got %v", got)
+	}
+}
+
+func TestNewMetricsWithRegistry(t *testing.T) {
+	registry := prometheus.NewRegistry()
+
+	metrics, err := NewMetricsWithRegistry(registry)
+	if err != nil {
+		t.Fatalf("Failed to create metrics: %v", err)
+	}
+	if metrics.Registry() != registry {
+		t.Fatal("Expected metrics to use the given registry")
+	}
+
+	// Registering a second set on the same registry fails.
+	if _, err := NewMetricsWithRegistry(registry); err == nil {
 		t.Fatal("Expected error when registering duplicate metrics")
 	}
 }
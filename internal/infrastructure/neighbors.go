@@ -0,0 +1,132 @@
+// Package infrastructure provides infrastructure layer implementations.
+package infrastructure
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/josimar-silva/gwaihir/internal/domain"
+)
+
+// arpFlagComplete marks a resolved entry in /proc/net/arp (ATF_COM).
+const arpFlagComplete = 0x2
+
+// ARPTable reads the neighbor table from a /proc/net/arp formatted file.
+type ARPTable struct {
+	path string
+}
+
+// NewARPTable creates a neighbor table reader for the file at path.
+func NewARPTable(path string) *ARPTable {
+	return &ARPTable{path: path}
+}
+
+// Neighbors returns the resolved entries of the neighbor table, with the broadcast
+// address of the local interface network each neighbor belongs to when known.
+//
+// #nosec G304 - path is controlled by application configuration, not user input
+func (t *ARPTable) Neighbors() ([]domain.Neighbor, error) {
+	file, err := os.Open(t.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open neighbor table: %w", err)
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	neighbors, err := ParseARPTable(file)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range neighbors {
+		neighbors[i].Broadcast = interfaceBroadcast(neighbors[i].Interface, neighbors[i].IP)
+	}
+
+	return neighbors, nil
+}
+
+// ParseARPTable parses the /proc/net/arp format:
+//
+//	IP address       HW type     Flags       HW address            Mask     Device
+//	192.168.1.1      0x1         0x2         aa:bb:cc:dd:ee:ff     *        eth0
+//
+// Incomplete entries (without the ATF_COM flag) are skipped.
+func ParseARPTable(r io.Reader) ([]domain.Neighbor, error) {
+	var neighbors []domain.Neighbor
+
+	scanner := bufio.NewScanner(r)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		if lineNumber == 1 {
+			continue // header
+		}
+
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) < 6 {
+			return nil, fmt.Errorf("neighbor table line %d: expected 6 fields, got %d", lineNumber, len(fields))
+		}
+
+		flags, err := strconv.ParseUint(strings.TrimPrefix(fields[2], "0x"), 16, 32)
+		if err != nil {
+			return nil, fmt.Errorf("neighbor table line %d: invalid flags %q", lineNumber, fields[2])
+		}
+		if flags&arpFlagComplete == 0 {
+			continue
+		}
+
+		neighbors = append(neighbors, domain.Neighbor{
+			IP:        fields[0],
+			MAC:       strings.ToUpper(fields[3]),
+			Interface: fields[5],
+		})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read neighbor table: %w", err)
+	}
+
+	return neighbors, nil
+}
+
+// interfaceBroadcast returns the IPv4 broadcast address of the network on iface
+// that contains ip, or "" if it cannot be determined.
+func interfaceBroadcast(iface, ip string) string {
+	parsed := net.ParseIP(ip).To4()
+	if parsed == nil {
+		return ""
+	}
+
+	link, err := net.InterfaceByName(iface)
+	if err != nil {
+		return ""
+	}
+	addrs, err := link.Addrs()
+	if err != nil {
+		return ""
+	}
+
+	for _, addr := range addrs {
+		network, ok := addr.(*net.IPNet)
+		if !ok || network.IP.To4() == nil || !network.Contains(parsed) {
+			continue
+		}
+		mask := net.IP(network.Mask).To4()
+		if mask == nil {
+			continue
+		}
+		broadcast := make(net.IP, net.IPv4len)
+		binary.BigEndian.PutUint32(broadcast, binary.BigEndian.Uint32(network.IP.To4())|^binary.BigEndian.Uint32(mask))
+		return broadcast.String()
+	}
+
+	return ""
+}
@@ -0,0 +1,60 @@
+package infrastructure
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/josimar-silva/gwaihir/internal/domain"
+)
+
+func TestParseARPTable_Fixture(t *testing.T) {
+	file, err := os.Open(filepath.Join("testdata", "proc_net_arp"))
+	if err != nil {
+		t.Fatalf("Failed to open fixture: %v", err)
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	neighbors, err := ParseARPTable(file)
+	if err != nil {
+		t.Fatalf("ParseARPTable() error = %v", err)
+	}
+
+	expected := []domain.Neighbor{
+		{IP: "192.168.1.1", MAC: "AA:BB:CC:DD:EE:01", Interface: "eth0"},
+		{IP: "192.168.1.20", MAC: "AA:BB:CC:DD:EE:02", Interface: "eth0"},
+		{IP: "10.0.0.5", MAC: "AA:BB:CC:DD:EE:03", Interface: "wlan0"},
+	}
+	if !reflect.DeepEqual(neighbors, expected) {
+		t.Errorf("ParseARPTable() = %+v, want %+v", neighbors, expected)
+	}
+}
+
+func TestParseARPTable_Malformed(t *testing.T) {
+	input := "IP address HW type Flags HW address Mask Device\n192.168.1.1 0x1 0x2\n"
+
+	_, err := ParseARPTable(strings.NewReader(input))
+	if err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("Expected error naming line 2, got %v", err)
+	}
+}
+
+func TestARPTable_Neighbors(t *testing.T) {
+	table := NewARPTable(filepath.Join("testdata", "proc_net_arp"))
+
+	neighbors, err := table.Neighbors()
+	if err != nil {
+		t.Fatalf("Neighbors() error = %v", err)
+	}
+	if len(neighbors) != 3 {
+		t.Errorf("Expected 3 neighbors, got %d", len(neighbors))
+	}
+
+	if _, err := NewARPTable(filepath.Join(t.TempDir(), "missing")).Neighbors(); err == nil {
+		t.Error("Expected error for missing neighbor table")
+	}
+}
@@ -0,0 +1,53 @@
+// Package infrastructure provides infrastructure layer implementations.
+package infrastructure
+
+import (
+	"sync"
+	"time"
+)
+
+// ReloadStatus tracks the outcome of configuration reloads for health reporting.
+type ReloadStatus struct {
+	mu          sync.RWMutex
+	lastSuccess time.Time
+	lastError   string
+}
+
+// NewReloadStatus creates a reload status whose last successful load is loadedAt
+// (typically the time the initial configuration was loaded).
+func NewReloadStatus(loadedAt time.Time) *ReloadStatus {
+	return &ReloadStatus{lastSuccess: loadedAt}
+}
+
+// RecordSuccess marks a successful reload at the given time.
+func (s *ReloadStatus) RecordSuccess(at time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lastSuccess = at
+	s.lastError = ""
+}
+
+// RecordFailure marks a failed reload. The previous configuration remains active.
+func (s *ReloadStatus) RecordFailure(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lastError = err.Error()
+}
+
+// LastSuccess returns the time of the last successful configuration load.
+func (s *ReloadStatus) LastSuccess() time.Time {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.lastSuccess
+}
+
+// LastError returns the error of the most recent reload attempt, or "" if it succeeded.
+func (s *ReloadStatus) LastError() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.lastError
+}
@@ -0,0 +1,30 @@
+package infrastructure
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestReloadStatus(t *testing.T) {
+	loadedAt := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	status := NewReloadStatus(loadedAt)
+
+	if !status.LastSuccess().Equal(loadedAt) || status.LastError() != "" {
+		t.Error("Expected initial load to be the last success")
+	}
+
+	status.RecordFailure(errors.New("invalid configuration"))
+	if status.LastError() == "" {
+		t.Error("Expected failure to be recorded")
+	}
+	if !status.LastSuccess().Equal(loadedAt) {
+		t.Error("Expected failure not to change last success")
+	}
+
+	reloadedAt := loadedAt.Add(time.Hour)
+	status.RecordSuccess(reloadedAt)
+	if !status.LastSuccess().Equal(reloadedAt) || status.LastError() != "" {
+		t.Error("Expected success to clear the error")
+	}
+}
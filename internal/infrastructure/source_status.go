@@ -0,0 +1,75 @@
+// Package infrastructure provides infrastructure layer implementations.
+package infrastructure
+
+import (
+	"sync"
+	"time"
+)
+
+// SourceState is the outcome of the most recent import from a machine source.
+type SourceState struct {
+	Name      string
+	Format    string
+	Path      string
+	Machines  int
+	LastError string
+	UpdatedAt time.Time
+}
+
+// SourceStatus tracks imports from external machine sources for health reporting.
+type SourceStatus struct {
+	mu     sync.RWMutex
+	states map[string]*SourceState
+	order  []string
+}
+
+// NewSourceStatus creates an empty source status.
+func NewSourceStatus() *SourceStatus {
+	return &SourceStatus{states: make(map[string]*SourceState)}
+}
+
+// Register adds a source. Sources are reported in registration order.
+func (s *SourceStatus) Register(name, format, path string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.states[name]; exists {
+		return
+	}
+	s.states[name] = &SourceState{Name: name, Format: format, Path: path}
+	s.order = append(s.order, name)
+}
+
+// RecordSuccess records that machines were imported from the source at the given time.
+func (s *SourceStatus) RecordSuccess(name string, machines int, at time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if state, exists := s.states[name]; exists {
+		state.Machines = machines
+		state.LastError = ""
+		state.UpdatedAt = at
+	}
+}
+
+// RecordFailure records a failed import. Previously imported machines remain active.
+func (s *SourceStatus) RecordFailure(name string, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if state, exists := s.states[name]; exists {
+		state.LastError = err.Error()
+	}
+}
+
+// Snapshot returns a copy of the state of every registered source.
+func (s *SourceStatus) Snapshot() []SourceState {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	states := make([]SourceState, 0, len(s.order))
+	for _, name := range s.order {
+		states = append(states, *s.states[name])
+	}
+	return states
+}
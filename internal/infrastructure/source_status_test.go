@@ -0,0 +1,38 @@
+package infrastructure
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSourceStatus(t *testing.T) {
+	status := NewSourceStatus()
+	status.Register("dnsmasq", LeaseFormatDnsmasq, "/var/lib/misc/dnsmasq.leases")
+	status.Register("ethers", LeaseFormatEthers, "/etc/ethers")
+	status.Register("dnsmasq", LeaseFormatDnsmasq, "/ignored")
+
+	at := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	status.RecordSuccess("dnsmasq", 3, at)
+	status.RecordFailure("ethers", errors.New("file not found"))
+	status.RecordSuccess("unknown", 1, at)
+
+	states := status.Snapshot()
+	if len(states) != 2 {
+		t.Fatalf("Expected 2 sources, got %d", len(states))
+	}
+	if states[0].Name != "dnsmasq" || states[0].Machines != 3 || !states[0].UpdatedAt.Equal(at) {
+		t.Errorf("Unexpected dnsmasq state: %+v", states[0])
+	}
+	if states[0].Path != "/var/lib/misc/dnsmasq.leases" {
+		t.Errorf("Expected first registration to be kept, got path %s", states[0].Path)
+	}
+	if states[1].Name != "ethers" || states[1].LastError == "" {
+		t.Errorf("Expected ethers failure to be recorded, got %+v", states[1])
+	}
+
+	status.RecordSuccess("ethers", 2, at)
+	if states := status.Snapshot(); states[1].LastError != "" || states[1].Machines != 2 {
+		t.Errorf("Expected success to clear the error, got %+v", states[1])
+	}
+}
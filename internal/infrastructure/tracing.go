@@ -0,0 +1,93 @@
+// Package infrastructure provides infrastructure layer implementations.
+package infrastructure
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.41.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/josimar-silva/gwaihir/internal/domain"
+)
+
+// TracerName is the instrumentation scope of the spans started by Gwaihir.
+const TracerName = "github.com/josimar-silva/gwaihir"
+
+// Span attributes describing the machine a span acts on.
+const (
+	AttrMachineID        = attribute.Key("gwaihir.machine.id")
+	AttrMachineName      = attribute.Key("gwaihir.machine.name")
+	AttrMachineMAC       = attribute.Key("gwaihir.machine.mac")
+	AttrMachineBroadcast = attribute.Key("gwaihir.machine.broadcast")
+)
+
+// Tracer returns the tracer of the global tracer provider, which does not record
+// spans until tracing is set up with SetupTracing.
+func Tracer() trace.Tracer {
+	return otel.Tracer(TracerName)
+}
+
+// MachineAttributes returns the span attributes describing machine. Empty fields
+// are omitted.
+func MachineAttributes(machine *domain.Machine) []attribute.KeyValue {
+	attrs := make([]attribute.KeyValue, 0, 4)
+	if machine.ID != "" {
+		attrs = append(attrs, AttrMachineID.String(machine.ID))
+	}
+	if machine.Name != "" {
+		attrs = append(attrs, AttrMachineName.String(machine.Name))
+	}
+	if machine.MAC != "" {
+		attrs = append(attrs, AttrMachineMAC.String(machine.NormalizeMAC()))
+	}
+	if machine.Broadcast != "" {
+		attrs = append(attrs, AttrMachineBroadcast.String(machine.Broadcast))
+	}
+	return attrs
+}
+
+// RecordSpanError marks span as failed with err.
+func RecordSpanError(span trace.Span, err error) {
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
+
+// SetupTracing installs a tracer provider exporting spans to exporter as the global
+// provider, along with W3C trace context and baggage propagation. sampleRatio is the
+// fraction of new traces recorded; requests carrying a traceparent follow the
+// sampling decision of their caller. The returned provider must be shut down to
+// flush pending spans.
+func SetupTracing(exporter sdktrace.SpanExporter, serviceName, version string, sampleRatio float64) (*sdktrace.TracerProvider, error) {
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(semconv.SchemaURL,
+		semconv.ServiceName(serviceName),
+		semconv.ServiceVersion(version),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build tracing resource: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(sampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	return provider, nil
+}
+
+// traceIDs returns the trace and span IDs of the span in ctx, if any.
+func traceIDs(ctx context.Context) (string, string, bool) {
+	spanContext := trace.SpanContextFromContext(ctx)
+	if !spanContext.IsValid() {
+		return "", "", false
+	}
+	return spanContext.TraceID().String(), spanContext.SpanID().String(), true
+}
@@ -0,0 +1,177 @@
+package infrastructure
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/josimar-silva/gwaihir/internal/domain"
+)
+
+// recordSpans installs a tracer provider recording spans in memory for the duration
+// of the test.
+func recordSpans(t *testing.T) *tracetest.InMemoryExporter {
+	t.Helper()
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() {
+		_ = provider.Shutdown(context.Background())
+		otel.SetTracerProvider(previous)
+	})
+	return exporter
+}
+
+func spanAttributes(span tracetest.SpanStub) map[attribute.Key]attribute.Value {
+	attrs := make(map[attribute.Key]attribute.Value, len(span.Attributes))
+	for _, attr := range span.Attributes {
+		attrs[attr.Key] = attr.Value
+	}
+	return attrs
+}
+
+func TestMachineAttributes(t *testing.T) {
+	attrs := MachineAttributes(&domain.Machine{ID: "saruman", Name: "Saruman", MAC: "aa-bb-cc-dd-ee-ff", Broadcast: "192.168.1.255"})
+
+	assert.Equal(t, []attribute.KeyValue{
+		AttrMachineID.String("saruman"),
+		AttrMachineName.String("Saruman"),
+		AttrMachineMAC.String("AA:BB:CC:DD:EE:FF"),
+		AttrMachineBroadcast.String("192.168.1.255"),
+	}, attrs)
+	assert.Empty(t, MachineAttributes(&domain.Machine{}))
+}
+
+func TestSendMagicPacket_RecordsSpan(t *testing.T) {
+	exporter := recordSpans(t)
+	sender := NewWoLPacketSenderWithDialer(func(_, _ string) (net.PacketConn, error) {
+		return &mockPacketConn{writeToFunc: func(b []byte, _ net.Addr) (int, error) { return len(b), nil }}, nil
+	}).WithPort(7)
+
+	ctx, parent := Tracer().Start(context.Background(), "parent")
+	require.NoError(t, sender.SendMagicPacket(ctx, "AA:BB:CC:DD:EE:FF", "192.168.1.255"))
+	parent.End()
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 2)
+	span := spans[0]
+	assert.Equal(t, "WoLPacketSender.SendMagicPacket", span.Name)
+	assert.Equal(t, trace.SpanKindClient, span.SpanKind)
+	assert.Equal(t, parent.SpanContext().SpanID(), span.Parent.SpanID())
+
+	attrs := spanAttributes(span)
+	assert.Equal(t, "AA:BB:CC:DD:EE:FF", attrs[AttrMachineMAC].AsString())
+	assert.Equal(t, "192.168.1.255", attrs["server.address"].AsString())
+	assert.Equal(t, int64(7), attrs["server.port"].AsInt64())
+	assert.Equal(t, "udp", attrs["network.transport"].AsString())
+}
+
+func TestSendMagicPacket_RecordsSpanError(t *testing.T) {
+	exporter := recordSpans(t)
+	sender := NewWoLPacketSenderWithDialer(func(_, _ string) (net.PacketConn, error) {
+		return nil, errors.New("network unreachable")
+	})
+
+	require.Error(t, sender.SendMagicPacket(context.Background(), "AA:BB:CC:DD:EE:FF", "192.168.1.255"))
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 1)
+	assert.Equal(t, codes.Error, spans[0].Status.Code)
+	require.Len(t, spans[0].Events, 1)
+	assert.Equal(t, "exception", spans[0].Events[0].Name)
+}
+
+func TestSetupTracing(t *testing.T) {
+	previousProvider, previousPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previousProvider)
+		otel.SetTextMapPropagator(previousPropagator)
+	})
+
+	exporter := tracetest.NewInMemoryExporter()
+	provider, err := SetupTracing(exporter, "gwaihir-test", "1.2.3", 1)
+	require.NoError(t, err)
+	defer provider.Shutdown(context.Background())
+
+	_, span := Tracer().Start(context.Background(), "operation")
+	span.End()
+	require.NoError(t, provider.ForceFlush(context.Background()))
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 1)
+	resource := make(map[attribute.Key]string)
+	for _, attr := range spans[0].Resource.Attributes() {
+		resource[attr.Key] = attr.Value.Emit()
+	}
+	assert.Equal(t, "gwaihir-test", resource["service.name"])
+	assert.Equal(t, "1.2.3", resource["service.version"])
+
+	assert.ElementsMatch(t, []string{"traceparent", "tracestate", "baggage"}, otel.GetTextMapPropagator().Fields())
+}
+
+func TestSetupTracing_FollowsParentSamplingDecision(t *testing.T) {
+	previousProvider, previousPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previousProvider)
+		otel.SetTextMapPropagator(previousPropagator)
+	})
+
+	exporter := tracetest.NewInMemoryExporter()
+	provider, err := SetupTracing(exporter, "gwaihir", "dev", 0)
+	require.NoError(t, err)
+	defer provider.Shutdown(context.Background())
+
+	_, unsampled := Tracer().Start(context.Background(), "new trace")
+	unsampled.End()
+
+	carrier := propagation.MapCarrier{"traceparent": "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"}
+	ctx := otel.GetTextMapPropagator().Extract(context.Background(), carrier)
+	_, sampled := Tracer().Start(ctx, "continued trace")
+	sampled.End()
+	require.NoError(t, provider.ForceFlush(context.Background()))
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "continued trace", spans[0].Name)
+}
+
+func TestLogger_AddsTraceIDs(t *testing.T) {
+	recordSpans(t)
+	var buf bytes.Buffer
+	logger := NewLoggerWithWriter(&buf, "json", "info")
+
+	ctx, span := Tracer().Start(context.Background(), "operation")
+	defer span.End()
+	logger.WithTrace(ctx).Info("traced", String("machine_id", "saruman"))
+
+	var record map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	assert.Equal(t, span.SpanContext().TraceID().String(), record["trace_id"])
+	assert.Equal(t, span.SpanContext().SpanID().String(), record["span_id"])
+	assert.Equal(t, "saruman", record["machine_id"])
+}
+
+func TestLogger_OmitsTraceIDsWithoutSpan(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewLoggerWithWriter(&buf, "json", "info")
+
+	logger.WithTrace(context.Background()).Info("untraced")
+	logger.WithContext(String("component", "test")).Info("untraced")
+
+	assert.NotContains(t, buf.String(), "trace_id")
+}
@@ -0,0 +1,403 @@
+// Package infrastructure provides infrastructure layer implementations.
+package infrastructure
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/josimar-silva/gwaihir/internal/domain"
+)
+
+// Webhook request headers.
+const (
+	// WebhookEventHeader carries the event type of a delivery.
+	WebhookEventHeader = "X-Gwaihir-Event"
+	// WebhookDeliveryHeader carries the delivery ID, which stays the same across retries.
+	WebhookDeliveryHeader = "X-Gwaihir-Delivery"
+	// WebhookTimestampHeader carries the Unix time at which a delivery attempt was signed.
+	WebhookTimestampHeader = "X-Gwaihir-Timestamp"
+	// WebhookSignatureHeader carries "sha256=" followed by the hex-encoded
+	// HMAC-SHA256 of "<timestamp>.<body>" keyed with the subscription secret.
+	WebhookSignatureHeader = "X-Gwaihir-Signature"
+)
+
+// Webhook delivery results, used as metric labels.
+const (
+	webhookResultSuccess = "success"
+	webhookResultFailure = "failure"
+	webhookResultDropped = "dropped"
+)
+
+// webhookUserAgent identifies webhook requests.
+const webhookUserAgent = "gwaihir-webhooks"
+
+// WebhookSubscription is an endpoint notified of machine events. Payloads are signed
+// with Secret; Events filters the event types delivered, all of them when empty.
+type WebhookSubscription struct {
+	Name   string
+	URL    string
+	Events []string
+	Secret string
+}
+
+// accepts reports whether the subscription receives events of eventType.
+func (s WebhookSubscription) accepts(eventType string) bool {
+	return len(s.Events) == 0 || slices.Contains(s.Events, eventType)
+}
+
+// WebhookPayload is the JSON body of a webhook delivery.
+type WebhookPayload struct {
+	ID string `json:"id"`
+	domain.Event
+}
+
+// webhookDelivery is a payload queued for a subscription.
+type webhookDelivery struct {
+	id        string
+	eventType string
+	body      []byte
+}
+
+// WebhookDispatcher delivers machine events to webhook subscriptions. Every
+// subscription has its own bounded queue and worker, so a slow receiver delays
+// neither the publisher nor the other subscriptions. Failed attempts are retried
+// with exponential backoff; deliveries that exhaust their attempts, or do not fit
+// in the queue, are logged and written to the dead-letter log.
+type WebhookDispatcher struct {
+	subscriptions []WebhookSubscription
+	logger        *Logger
+	metrics       *Metrics
+
+	client         *http.Client
+	maxAttempts    int
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+	queueSize      int
+	deadLetters    *WebhookDeadLetterLog
+
+	queues []chan webhookDelivery
+	done   chan struct{}
+	// drainUntil bounds how long queued deliveries are still attempted once done is closed.
+	drainUntil time.Time
+	wg         sync.WaitGroup
+}
+
+// NewWebhookDispatcher creates a dispatcher for subscriptions with five attempts,
+// backoff from one second to one minute, a five second timeout and room for 100
+// queued deliveries per subscription.
+func NewWebhookDispatcher(subscriptions []WebhookSubscription, logger *Logger, metrics *Metrics) *WebhookDispatcher {
+	return &WebhookDispatcher{
+		subscriptions:  subscriptions,
+		logger:         logger,
+		metrics:        metrics,
+		client:         &http.Client{Timeout: 5 * time.Second},
+		maxAttempts:    5,
+		initialBackoff: time.Second,
+		maxBackoff:     time.Minute,
+		queueSize:      100,
+		done:           make(chan struct{}),
+	}
+}
+
+// WithRetries sets the number of delivery attempts and the backoff between them,
+// which doubles after every failed attempt from initial up to max.
+func (d *WebhookDispatcher) WithRetries(maxAttempts int, initial, max time.Duration) *WebhookDispatcher {
+	d.maxAttempts = maxAttempts
+	d.initialBackoff = initial
+	d.maxBackoff = max
+	return d
+}
+
+// WithTimeout sets the timeout of a single delivery attempt.
+func (d *WebhookDispatcher) WithTimeout(timeout time.Duration) *WebhookDispatcher {
+	d.client.Timeout = timeout
+	return d
+}
+
+// WithQueueSize sets how many deliveries may wait per subscription.
+func (d *WebhookDispatcher) WithQueueSize(size int) *WebhookDispatcher {
+	d.queueSize = size
+	return d
+}
+
+// WithDeadLetterLog records failed deliveries to deadLetters.
+func (d *WebhookDispatcher) WithDeadLetterLog(deadLetters *WebhookDeadLetterLog) *WebhookDispatcher {
+	d.deadLetters = deadLetters
+	return d
+}
+
+// Start starts the delivery workers of the subscriptions.
+func (d *WebhookDispatcher) Start() {
+	d.queues = make([]chan webhookDelivery, len(d.subscriptions))
+	for i, subscription := range d.subscriptions {
+		d.queues[i] = make(chan webhookDelivery, d.queueSize)
+		d.wg.Add(1)
+		go d.worker(subscription, d.queues[i])
+	}
+}
+
+// Stop stops the workers. Queued deliveries are attempted once more for up to
+// timeout; those that fail, remain queued or wait for a retry are dead-lettered
+// rather than lost silently.
+func (d *WebhookDispatcher) Stop(timeout time.Duration) {
+	d.drainUntil = time.Now().Add(timeout)
+	close(d.done)
+	d.wg.Wait()
+}
+
+// Publish queues event for the subscriptions accepting it without blocking. It
+// implements domain.EventPublisher.
+func (d *WebhookDispatcher) Publish(event domain.Event) {
+	id := uuid.New().String()
+	body, err := json.Marshal(WebhookPayload{ID: id, Event: event})
+	if err != nil {
+		d.logger.Error("Failed to encode webhook payload", Any("error", err))
+		return
+	}
+
+	delivery := webhookDelivery{id: id, eventType: event.Type, body: body}
+	for i, subscription := range d.subscriptions {
+		if !subscription.accepts(event.Type) {
+			continue
+		}
+		select {
+		case d.queues[i] <- delivery:
+		default:
+			d.deadLetter(subscription, delivery, 0, errors.New("delivery queue is full"), webhookResultDropped)
+		}
+	}
+}
+
+// Consume publishes the events received from events until the channel is closed or
+// the dispatcher stops.
+func (d *WebhookDispatcher) Consume(events <-chan domain.Event) {
+	for {
+		select {
+		case <-d.done:
+			return
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			d.Publish(event)
+		}
+	}
+}
+
+func (d *WebhookDispatcher) worker(subscription WebhookSubscription, queue chan webhookDelivery) {
+	defer d.wg.Done()
+
+	for {
+		select {
+		case <-d.done:
+			for {
+				select {
+				case delivery := <-queue:
+					if time.Now().Before(d.drainUntil) {
+						d.deliver(subscription, delivery)
+					} else {
+						d.deadLetter(subscription, delivery, 0, errors.New("dispatcher stopped"), webhookResultDropped)
+					}
+				default:
+					return
+				}
+			}
+		case delivery := <-queue:
+			d.deliver(subscription, delivery)
+		}
+	}
+}
+
+// deliver attempts delivery until it succeeds, fails permanently, runs out of
+// attempts or the dispatcher stops.
+func (d *WebhookDispatcher) deliver(subscription WebhookSubscription, delivery webhookDelivery) {
+	backoff := d.initialBackoff
+	for attempt := 1; ; attempt++ {
+		retryable, err := d.attempt(subscription, delivery)
+		if err == nil {
+			d.metrics.WebhookDeliveries.WithLabelValues(subscription.Name, webhookResultSuccess).Inc()
+			d.logger.Debug("Webhook delivered",
+				String("subscription", subscription.Name),
+				String("delivery_id", delivery.id),
+				String("event", delivery.eventType),
+				Int("attempt", attempt),
+			)
+			return
+		}
+
+		if !retryable || attempt >= d.maxAttempts {
+			d.deadLetter(subscription, delivery, attempt, err, webhookResultFailure)
+			return
+		}
+
+		d.metrics.WebhookRetries.WithLabelValues(subscription.Name).Inc()
+		d.logger.Warn("Webhook delivery failed, retrying",
+			String("subscription", subscription.Name),
+			String("delivery_id", delivery.id),
+			Int("attempt", attempt),
+			Duration("backoff", backoff),
+			Any("error", err),
+		)
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-d.done:
+			timer.Stop()
+			d.deadLetter(subscription, delivery, attempt, fmt.Errorf("dispatcher stopped before retry: %w", err), webhookResultDropped)
+			return
+		case <-timer.C:
+		}
+		backoff = min(backoff*2, d.maxBackoff)
+	}
+}
+
+// attempt sends a signed delivery once and reports whether a failure may be retried.
+// Network errors, 408, 429 and 5xx responses are retried; other responses are final.
+func (d *WebhookDispatcher) attempt(subscription WebhookSubscription, delivery webhookDelivery) (bool, error) {
+	req, err := http.NewRequest(http.MethodPost, subscription.URL, bytes.NewReader(delivery.body))
+	if err != nil {
+		return false, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", webhookUserAgent)
+	req.Header.Set(WebhookEventHeader, delivery.eventType)
+	req.Header.Set(WebhookDeliveryHeader, delivery.id)
+	req.Header.Set(WebhookTimestampHeader, timestamp)
+	req.Header.Set(WebhookSignatureHeader, SignWebhook(subscription.Secret, timestamp, delivery.body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		// The URL often carries a token, so it is left out of logs and dead letters.
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return true, err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	retryable := resp.StatusCode == http.StatusRequestTimeout ||
+		resp.StatusCode == http.StatusTooManyRequests ||
+		resp.StatusCode >= 500
+	return retryable, fmt.Errorf("unexpected status %d", resp.StatusCode)
+}
+
+func (d *WebhookDispatcher) deadLetter(subscription WebhookSubscription, delivery webhookDelivery, attempts int, cause error, result string) {
+	d.metrics.WebhookDeliveries.WithLabelValues(subscription.Name, result).Inc()
+	d.logger.Error("Webhook delivery failed",
+		String("subscription", subscription.Name),
+		String("delivery_id", delivery.id),
+		String("event", delivery.eventType),
+		Int("attempts", attempts),
+		Any("error", cause),
+	)
+
+	if d.deadLetters == nil {
+		return
+	}
+	err := d.deadLetters.Record(WebhookDeadLetter{
+		Subscription: subscription.Name,
+		DeliveryID:   delivery.id,
+		Event:        delivery.eventType,
+		Attempts:     attempts,
+		Error:        cause.Error(),
+		Payload:      delivery.body,
+	})
+	if err != nil {
+		d.logger.Error("Failed to record webhook dead letter", Any("error", err))
+	}
+}
+
+// SignWebhook returns the signature of a webhook body sent at timestamp, as set in
+// the X-Gwaihir-Signature header.
+func SignWebhook(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// WebhookDeadLetter is a webhook delivery that could not be completed. It names the
+// subscription rather than its URL, which often embeds a token.
+type WebhookDeadLetter struct {
+	Time         time.Time       `json:"time"`
+	Subscription string          `json:"subscription"`
+	DeliveryID   string          `json:"delivery_id"`
+	Event        string          `json:"event"`
+	Attempts     int             `json:"attempts"`
+	Error        string          `json:"error"`
+	Payload      json.RawMessage `json:"payload"`
+}
+
+// WebhookDeadLetterLog is an append-only JSON Lines file of failed webhook
+// deliveries, from which they can be inspected or replayed.
+type WebhookDeadLetterLog struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// NewWebhookDeadLetterLog opens (or creates) the dead-letter log at path.
+func NewWebhookDeadLetterLog(path string) (*WebhookDeadLetterLog, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create dead-letter log directory: %w", err)
+	}
+
+	// #nosec G304 - path is controlled by application configuration, not user input
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open dead-letter log: %w", err)
+	}
+	return &WebhookDeadLetterLog{file: file}, nil
+}
+
+// Record appends a failed delivery. A zero time is set to the current time.
+func (l *WebhookDeadLetterLog) Record(letter WebhookDeadLetter) error {
+	if letter.Time.IsZero() {
+		letter.Time = time.Now()
+	}
+	letter.Time = letter.Time.UTC()
+
+	line, err := json.Marshal(letter)
+	if err != nil {
+		return fmt.Errorf("failed to encode dead letter: %w", err)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, err := l.file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write dead letter: %w", err)
+	}
+	return nil
+}
+
+// Close closes the dead-letter log.
+func (l *WebhookDeadLetterLog) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.file.Close()
+}
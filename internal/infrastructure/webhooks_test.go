@@ -0,0 +1,285 @@
+package infrastructure
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/josimar-silva/gwaihir/internal/domain"
+)
+
+// webhookReceiver records the requests of a webhook endpoint answering with the
+// statuses in order, then 200.
+type webhookReceiver struct {
+	mu       sync.Mutex
+	requests []*http.Request
+	bodies   [][]byte
+	statuses []int
+}
+
+func (r *webhookReceiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+
+	r.mu.Lock()
+	r.requests = append(r.requests, req)
+	r.bodies = append(r.bodies, body)
+	status := http.StatusOK
+	if len(r.statuses) > 0 {
+		status, r.statuses = r.statuses[0], r.statuses[1:]
+	}
+	r.mu.Unlock()
+
+	w.WriteHeader(status)
+}
+
+func (r *webhookReceiver) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.requests)
+}
+
+func newWebhookTest(t *testing.T, subscriptions ...WebhookSubscription) (*WebhookDispatcher, *Metrics) {
+	t.Helper()
+	metrics, err := NewMetrics()
+	require.NoError(t, err)
+
+	dispatcher := NewWebhookDispatcher(subscriptions, NewLogger("text", "error"), metrics).
+		WithRetries(3, time.Millisecond, 4*time.Millisecond).
+		WithTimeout(time.Second)
+	return dispatcher, metrics
+}
+
+func readDeadLetters(t *testing.T, path string) []WebhookDeadLetter {
+	t.Helper()
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer func() {
+		_ = file.Close()
+	}()
+
+	var letters []WebhookDeadLetter
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var letter WebhookDeadLetter
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &letter))
+		letters = append(letters, letter)
+	}
+	return letters
+}
+
+func TestWebhookDispatcher_DeliversSignedPayloads(t *testing.T) {
+	all := &webhookReceiver{}
+	allServer := httptest.NewServer(all)
+	defer allServer.Close()
+	failures := &webhookReceiver{}
+	failuresServer := httptest.NewServer(failures)
+	defer failuresServer.Close()
+
+	dispatcher, metrics := newWebhookTest(t,
+		WebhookSubscription{Name: "all", URL: allServer.URL, Secret: "all-secret"},
+		WebhookSubscription{Name: "failures", URL: failuresServer.URL, Events: []string{domain.EventWakeFailed}, Secret: "failures-secret"},
+	)
+	dispatcher.Start()
+
+	wokenAt := time.Date(2026, 2, 9, 15, 30, 45, 0, time.UTC)
+	dispatcher.Publish(domain.Event{Type: domain.EventWakeRequested, MachineID: "saruman", MAC: "AA:BB:CC:DD:EE:FF", Broadcast: "192.168.1.255", Time: wokenAt})
+	dispatcher.Publish(domain.Event{Type: domain.EventWakeSent, MachineID: "saruman", MAC: "AA:BB:CC:DD:EE:FF", Broadcast: "192.168.1.255", Time: wokenAt})
+	dispatcher.Stop(time.Second)
+
+	require.Equal(t, 2, all.count())
+	assert.Equal(t, 0, failures.count())
+	assert.Equal(t, 2.0, testutil.ToFloat64(metrics.WebhookDeliveries.WithLabelValues("all", webhookResultSuccess)))
+
+	req, body := all.requests[1], all.bodies[1]
+	assert.Equal(t, http.MethodPost, req.Method)
+	assert.Equal(t, "application/json", req.Header.Get("Content-Type"))
+	assert.Equal(t, domain.EventWakeSent, req.Header.Get(WebhookEventHeader))
+	assert.Equal(t, SignWebhook("all-secret", req.Header.Get(WebhookTimestampHeader), body), req.Header.Get(WebhookSignatureHeader))
+
+	var payload map[string]any
+	require.NoError(t, json.Unmarshal(body, &payload))
+	assert.Equal(t, map[string]any{
+		"id":         req.Header.Get(WebhookDeliveryHeader),
+		"type":       domain.EventWakeSent,
+		"machine_id": "saruman",
+		"mac":        "AA:BB:CC:DD:EE:FF",
+		"broadcast":  "192.168.1.255",
+		"time":       "2026-02-09T15:30:45Z",
+	}, payload)
+}
+
+func TestSignWebhook(t *testing.T) {
+	// echo -n '1700000000.{}' | openssl dgst -sha256 -hmac secret
+	assert.Equal(t,
+		"sha256=b8569b78799ff9e3cbff0fc2d63a33a2b57f3282abd07c37ae5e8e7d79a5f163",
+		SignWebhook("secret", "1700000000", []byte("{}")),
+	)
+}
+
+func TestWebhookDispatcher_RetriesWithBackoff(t *testing.T) {
+	receiver := &webhookReceiver{statuses: []int{http.StatusServiceUnavailable, http.StatusTooManyRequests}}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	dispatcher, metrics := newWebhookTest(t, WebhookSubscription{Name: "chat", URL: server.URL, Secret: "s"})
+	dispatcher.Start()
+	dispatcher.Publish(domain.Event{Type: domain.EventWakeSent, MachineID: "saruman"})
+
+	require.Eventually(t, func() bool {
+		return testutil.ToFloat64(metrics.WebhookDeliveries.WithLabelValues("chat", webhookResultSuccess)) == 1
+	}, time.Second, time.Millisecond)
+	dispatcher.Stop(time.Second)
+
+	assert.Equal(t, 3, receiver.count())
+	assert.Equal(t, 2.0, testutil.ToFloat64(metrics.WebhookRetries.WithLabelValues("chat")))
+	// Every attempt carries the same delivery ID, so receivers can deduplicate.
+	assert.Equal(t, receiver.requests[0].Header.Get(WebhookDeliveryHeader), receiver.requests[2].Header.Get(WebhookDeliveryHeader))
+}
+
+func TestWebhookDispatcher_DeadLetters(t *testing.T) {
+	rejecting := &webhookReceiver{statuses: []int{http.StatusBadRequest}}
+	rejectingServer := httptest.NewServer(rejecting)
+	defer rejectingServer.Close()
+	failing := &webhookReceiver{statuses: []int{500, 500, 500}}
+	failingServer := httptest.NewServer(failing)
+	defer failingServer.Close()
+
+	path := filepath.Join(t.TempDir(), "webhooks", "dead.jsonl")
+	deadLetters, err := NewWebhookDeadLetterLog(path)
+	require.NoError(t, err)
+	defer func() {
+		_ = deadLetters.Close()
+	}()
+
+	dispatcher, metrics := newWebhookTest(t,
+		WebhookSubscription{Name: "rejecting", URL: rejectingServer.URL, Secret: "s"},
+		WebhookSubscription{Name: "failing", URL: failingServer.URL, Secret: "s"},
+	)
+	dispatcher.WithDeadLetterLog(deadLetters).Start()
+	dispatcher.Publish(domain.Event{Type: domain.EventWakeFailed, MachineID: "saruman"})
+
+	require.Eventually(t, func() bool {
+		return testutil.ToFloat64(metrics.WebhookDeliveries.WithLabelValues("rejecting", webhookResultFailure)) == 1 &&
+			testutil.ToFloat64(metrics.WebhookDeliveries.WithLabelValues("failing", webhookResultFailure)) == 1
+	}, time.Second, time.Millisecond)
+	dispatcher.Stop(time.Second)
+
+	// Client errors are not retried.
+	assert.Equal(t, 1, rejecting.count())
+	assert.Equal(t, 3, failing.count())
+
+	letters := readDeadLetters(t, path)
+	require.Len(t, letters, 2)
+	attempts := map[string]int{}
+	for _, letter := range letters {
+		attempts[letter.Subscription] = letter.Attempts
+		assert.Equal(t, domain.EventWakeFailed, letter.Event)
+		assert.Equal(t, "unexpected status "+map[string]string{"rejecting": "400", "failing": "500"}[letter.Subscription], letter.Error)
+
+		var payload WebhookPayload
+		require.NoError(t, json.Unmarshal(letter.Payload, &payload))
+		assert.Equal(t, letter.DeliveryID, payload.ID)
+		assert.Equal(t, "saruman", payload.MachineID)
+	}
+	assert.Equal(t, map[string]int{"rejecting": 1, "failing": 3}, attempts)
+}
+
+func TestWebhookDispatcher_DeadLettersOmitURL(t *testing.T) {
+	unreachable := httptest.NewServer(http.NotFoundHandler())
+	unreachable.Close()
+
+	path := filepath.Join(t.TempDir(), "dead.jsonl")
+	deadLetters, err := NewWebhookDeadLetterLog(path)
+	require.NoError(t, err)
+	defer func() {
+		_ = deadLetters.Close()
+	}()
+
+	dispatcher, metrics := newWebhookTest(t,
+		WebhookSubscription{Name: "chat", URL: unreachable.URL + "/hooks/top-secret-token?key=abc", Secret: "s"},
+	)
+	dispatcher.WithDeadLetterLog(deadLetters).Start()
+	dispatcher.Publish(domain.Event{Type: domain.EventWakeSent, MachineID: "saruman"})
+
+	require.Eventually(t, func() bool {
+		return testutil.ToFloat64(metrics.WebhookDeliveries.WithLabelValues("chat", webhookResultFailure)) == 1
+	}, time.Second, time.Millisecond)
+	dispatcher.Stop(time.Second)
+
+	raw, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(raw), "top-secret-token")
+	assert.NotContains(t, string(raw), "key=abc")
+
+	letters := readDeadLetters(t, path)
+	require.Len(t, letters, 1)
+	assert.Equal(t, "chat", letters[0].Subscription)
+	assert.Equal(t, 3, letters[0].Attempts)
+	assert.NotEmpty(t, letters[0].Error)
+}
+
+func TestWebhookDispatcher_SlowReceiverDoesNotBlockPublish(t *testing.T) {
+	release := make(chan struct{})
+	var received atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		received.Add(1)
+		<-release
+	}))
+	defer server.Close()
+
+	dispatcher, metrics := newWebhookTest(t, WebhookSubscription{Name: "slow", URL: server.URL, Secret: "s"})
+	dispatcher.WithQueueSize(1).Start()
+
+	dispatcher.Publish(domain.Event{Type: domain.EventWakeSent, MachineID: "first"})
+	require.Eventually(t, func() bool { return received.Load() == 1 }, time.Second, time.Millisecond)
+
+	start := time.Now()
+	for range 5 {
+		dispatcher.Publish(domain.Event{Type: domain.EventWakeSent, MachineID: "queued"})
+	}
+	assert.Less(t, time.Since(start), 100*time.Millisecond)
+	// One delivery is in flight and one queued; the others do not fit.
+	assert.Equal(t, 4.0, testutil.ToFloat64(metrics.WebhookDeliveries.WithLabelValues("slow", webhookResultDropped)))
+
+	close(release)
+	require.Eventually(t, func() bool {
+		return testutil.ToFloat64(metrics.WebhookDeliveries.WithLabelValues("slow", webhookResultSuccess)) == 2
+	}, time.Second, time.Millisecond)
+	dispatcher.Stop(time.Second)
+}
+
+func TestWebhookDispatcher_Consume(t *testing.T) {
+	receiver := &webhookReceiver{}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	dispatcher, _ := newWebhookTest(t, WebhookSubscription{Name: "chat", URL: server.URL, Secret: "s"})
+	dispatcher.Start()
+
+	bus := NewEventBus(4)
+	events, unsubscribe := bus.Subscribe()
+	consumed := make(chan struct{})
+	go func() {
+		dispatcher.Consume(events)
+		close(consumed)
+	}()
+
+	bus.Publish(domain.Event{Type: domain.EventWakeSent, MachineID: "saruman"})
+	require.Eventually(t, func() bool { return receiver.count() == 1 }, time.Second, time.Millisecond)
+
+	unsubscribe()
+	<-consumed
+	dispatcher.Stop(time.Second)
+}
@@ -2,21 +2,33 @@
 package infrastructure
 
 import (
+	"context"
 	"fmt"
 	"net"
+	"strconv"
 	"strings"
+
+	semconv "go.opentelemetry.io/otel/semconv/v1.41.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/josimar-silva/gwaihir/internal/domain"
 )
 
+// DefaultWoLPort is the standard UDP port for Wake-on-LAN magic packets.
+const DefaultWoLPort = 9
+
 // WoLPacketSender handles sending Wake-on-LAN magic packets.
 type WoLPacketSender struct {
 	// For testing purposes, allows mocking the UDP connection
 	dialFunc func(network, addr string) (net.PacketConn, error)
+	port     int
 }
 
 // NewWoLPacketSender creates a new WoL packet sender with default UDP dialer.
 func NewWoLPacketSender() *WoLPacketSender {
 	return &WoLPacketSender{
 		dialFunc: net.ListenPacket,
+		port:     DefaultWoLPort,
 	}
 }
 
@@ -24,12 +36,38 @@ func NewWoLPacketSender() *WoLPacketSender {
 func NewWoLPacketSenderWithDialer(dialFunc func(network, addr string) (net.PacketConn, error)) *WoLPacketSender {
 	return &WoLPacketSender{
 		dialFunc: dialFunc,
+		port:     DefaultWoLPort,
 	}
 }
 
+// WithPort sets the UDP port magic packets are sent to.
+func (s *WoLPacketSender) WithPort(port int) *WoLPacketSender {
+	s.port = port
+	return s
+}
+
 // SendMagicPacket sends a Wake-on-LAN magic packet to the specified MAC address on the broadcast address.
 // The magic packet format: 6 bytes of 0xFF followed by 16 repetitions of the 6-byte MAC address.
-func (s *WoLPacketSender) SendMagicPacket(mac, broadcast string) error {
+func (s *WoLPacketSender) SendMagicPacket(ctx context.Context, mac, broadcast string) error {
+	attrs := append(MachineAttributes(&domain.Machine{MAC: mac, Broadcast: broadcast}),
+		semconv.NetworkTransportUDP,
+		semconv.ServerAddress(broadcast),
+		semconv.ServerPort(s.port),
+	)
+	_, span := Tracer().Start(ctx, "WoLPacketSender.SendMagicPacket",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...),
+	)
+	defer span.End()
+
+	if err := s.sendMagicPacket(mac, broadcast); err != nil {
+		RecordSpanError(span, err)
+		return err
+	}
+	return nil
+}
+
+func (s *WoLPacketSender) sendMagicPacket(mac, broadcast string) error {
 	// Normalize MAC address to colon-separated format
 	normalizedMAC := normalizeMACAddress(mac)
 
@@ -42,8 +80,8 @@ func (s *WoLPacketSender) SendMagicPacket(mac, broadcast string) error {
 	// Create magic packet: 6 bytes of 0xFF + 16x MAC address
 	packet := buildMagicPacket(macBytes)
 
-	// Add port 9 (standard WoL port) to broadcast address
-	broadcastAddr := net.JoinHostPort(broadcast, "9")
+	// Add the WoL port (9 unless configured) to broadcast address
+	broadcastAddr := net.JoinHostPort(broadcast, strconv.Itoa(s.port))
 
 	// Listen on UDP to send the packet
 	conn, err := s.dialFunc("udp", "0.0.0.0:0")
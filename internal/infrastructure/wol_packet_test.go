@@ -1,6 +1,7 @@
 package infrastructure
 
 import (
+	"context"
 	"fmt"
 	"net"
 	"testing"
@@ -155,7 +156,7 @@ func TestSendMagicPacketValidation(t *testing.T) {
 			}
 
 			sender := NewWoLPacketSenderWithDialer(mockDialer)
-			err := sender.SendMagicPacket(tt.mac, tt.broadcast)
+			err := sender.SendMagicPacket(context.Background(), tt.mac, tt.broadcast)
 
 			if tt.shouldFail {
 				if err == nil {
@@ -190,7 +191,7 @@ func TestSendMagicPacketPacketContent(t *testing.T) {
 	}
 
 	sender := NewWoLPacketSenderWithDialer(mockDialer)
-	err := sender.SendMagicPacket(mac, broadcast)
+	err := sender.SendMagicPacket(context.Background(), mac, broadcast)
 
 	if err != nil {
 		t.Fatalf("Failed to send packet: %v", err)
@@ -228,6 +229,27 @@ func TestSendMagicPacketPacketContent(t *testing.T) {
 	}
 }
 
+func TestSendMagicPacketWithPort(t *testing.T) {
+	var capturedAddr net.Addr
+	mockDialer := func(_, _ string) (net.PacketConn, error) {
+		return &mockPacketConn{
+			writeToFunc: func(b []byte, addr net.Addr) (int, error) {
+				capturedAddr = addr
+				return len(b), nil
+			},
+		}, nil
+	}
+
+	sender := NewWoLPacketSenderWithDialer(mockDialer).WithPort(7)
+	if err := sender.SendMagicPacket(context.Background(), "AA:BB:CC:DD:EE:FF", "10.0.0.255"); err != nil {
+		t.Fatalf("Failed to send packet: %v", err)
+	}
+
+	if capturedAddr.String() != "10.0.0.255:7" {
+		t.Errorf("Expected address 10.0.0.255:7, got %s", capturedAddr.String())
+	}
+}
+
 func TestSendMagicPacketNetworkError(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -266,7 +288,7 @@ func TestSendMagicPacketNetworkError(t *testing.T) {
 			}
 
 			sender := NewWoLPacketSenderWithDialer(mockDialer)
-			err := sender.SendMagicPacket("AA:BB:CC:DD:EE:FF", "192.168.1.255")
+			err := sender.SendMagicPacket(context.Background(), "AA:BB:CC:DD:EE:FF", "192.168.1.255")
 
 			if err == nil {
 				t.Errorf("Expected error, got nil")
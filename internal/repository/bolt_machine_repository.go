@@ -0,0 +1,302 @@
+// Package repository provides data access implementations.
+package repository
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/josimar-silva/gwaihir/internal/config"
+	"github.com/josimar-silva/gwaihir/internal/domain"
+)
+
+var (
+	metaBucket     = []byte("meta")
+	machinesBucket = []byte("machines")
+
+	schemaVersionKey = []byte("schema_version")
+	seededKey        = []byte("seeded")
+)
+
+// migration upgrades the database schema by one version.
+type migration struct {
+	description string
+	apply       func(tx *bolt.Tx) error
+}
+
+// migrations are applied in order; migrations[i] upgrades the schema to version i+1.
+// Append new migrations to the end and never change released ones.
+var migrations = []migration{
+	{
+		description: "create machines bucket",
+		apply: func(tx *bolt.Tx) error {
+			_, err := tx.CreateBucketIfNotExists(machinesBucket)
+			return err
+		},
+	},
+}
+
+// BoltMachineRepository implements WritableMachineRepository on top of an embedded
+// bbolt database. All machines are stored in the database and can be managed at runtime.
+type BoltMachineRepository struct {
+	db *bolt.DB
+}
+
+// NewBoltMachineRepository opens (or creates) the database at path and migrates it to
+// the latest schema. When seeding is enabled in cfg, the machines from the configuration
+// file are imported the first time the database is opened.
+func NewBoltMachineRepository(cfg *config.Config, path string) (*BoltMachineRepository, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create database directory: %w", err)
+	}
+
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open machine database: %w", err)
+	}
+
+	repo := &BoltMachineRepository{db: db}
+
+	if err := repo.migrate(); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
+	if cfg.Storage.Seed == nil || *cfg.Storage.Seed {
+		if err := repo.seed(cfg); err != nil {
+			_ = db.Close()
+			return nil, err
+		}
+	}
+
+	return repo, nil
+}
+
+// GetByID retrieves a machine by its ID.
+func (r *BoltMachineRepository) GetByID(id string) (*domain.Machine, error) {
+	var machine *domain.Machine
+	err := r.db.View(func(tx *bolt.Tx) error {
+		var err error
+		machine, err = getMachine(tx, id)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return machine, nil
+}
+
+// GetAll retrieves all registered machines.
+func (r *BoltMachineRepository) GetAll() ([]*domain.Machine, error) {
+	machines := make([]*domain.Machine, 0)
+	err := r.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(machinesBucket).ForEach(func(k, v []byte) error {
+			var machine domain.Machine
+			if err := json.Unmarshal(v, &machine); err != nil {
+				return fmt.Errorf("failed to decode machine %s: %w", k, err)
+			}
+			machines = append(machines, &machine)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return machines, nil
+}
+
+// Exists checks if a machine with the given ID exists.
+func (r *BoltMachineRepository) Exists(id string) bool {
+	exists := false
+	_ = r.db.View(func(tx *bolt.Tx) error {
+		exists = tx.Bucket(machinesBucket).Get([]byte(id)) != nil
+		return nil
+	})
+	return exists
+}
+
+// Create stores a new machine.
+func (r *BoltMachineRepository) Create(ctx context.Context, machine *domain.Machine) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := machine.Validate(); err != nil {
+		return fmt.Errorf("%w: %w", domain.ErrInvalidMachine, err)
+	}
+
+	return r.db.Update(func(tx *bolt.Tx) error {
+		if tx.Bucket(machinesBucket).Get([]byte(machine.ID)) != nil {
+			return fmt.Errorf("%w: %s", domain.ErrMachineAlreadyExists, machine.ID)
+		}
+		return putMachine(tx, machine)
+	})
+}
+
+// Update replaces an existing machine.
+func (r *BoltMachineRepository) Update(ctx context.Context, machine *domain.Machine) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := machine.Validate(); err != nil {
+		return fmt.Errorf("%w: %w", domain.ErrInvalidMachine, err)
+	}
+
+	return r.db.Update(func(tx *bolt.Tx) error {
+		if tx.Bucket(machinesBucket).Get([]byte(machine.ID)) == nil {
+			return domain.ErrMachineNotFound
+		}
+		return putMachine(tx, machine)
+	})
+}
+
+// Patch applies a partial update to an existing machine in a single transaction.
+func (r *BoltMachineRepository) Patch(ctx context.Context, id string, patch domain.MachinePatch) (*domain.Machine, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var patched *domain.Machine
+	err := r.db.Update(func(tx *bolt.Tx) error {
+		current, err := getMachine(tx, id)
+		if err != nil {
+			return err
+		}
+
+		patched = patch.Apply(current)
+		if err := patched.Validate(); err != nil {
+			return fmt.Errorf("%w: %w", domain.ErrInvalidMachine, err)
+		}
+		return putMachine(tx, patched)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return patched, nil
+}
+
+// Delete removes a machine.
+func (r *BoltMachineRepository) Delete(ctx context.Context, id string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return r.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(machinesBucket)
+		if bucket.Get([]byte(id)) == nil {
+			return domain.ErrMachineNotFound
+		}
+		return bucket.Delete([]byte(id))
+	})
+}
+
+// SchemaVersion returns the current schema version of the database.
+func (r *BoltMachineRepository) SchemaVersion() (int, error) {
+	var version int
+	err := r.db.View(func(tx *bolt.Tx) error {
+		version = schemaVersion(tx)
+		return nil
+	})
+	return version, err
+}
+
+// Close closes the underlying database.
+func (r *BoltMachineRepository) Close() error {
+	return r.db.Close()
+}
+
+// migrate applies all pending migrations in a single transaction.
+func (r *BoltMachineRepository) migrate() error {
+	return r.db.Update(func(tx *bolt.Tx) error {
+		if _, err := tx.CreateBucketIfNotExists(metaBucket); err != nil {
+			return fmt.Errorf("failed to create meta bucket: %w", err)
+		}
+
+		current := schemaVersion(tx)
+		if current > len(migrations) {
+			return fmt.Errorf("machine database schema version %d is newer than supported version %d", current, len(migrations))
+		}
+
+		for version := current; version < len(migrations); version++ {
+			if err := migrations[version].apply(tx); err != nil {
+				return fmt.Errorf("migration %d (%s) failed: %w", version+1, migrations[version].description, err)
+			}
+		}
+
+		return setSchemaVersion(tx, len(migrations))
+	})
+}
+
+// seed imports the configuration machines once, on the first start of a new database.
+// The machines are validated with the same rules as the in-memory repository.
+func (r *BoltMachineRepository) seed(cfg *config.Config) error {
+	machines, err := buildMachines(cfg)
+	if err != nil {
+		return err
+	}
+
+	return r.db.Update(func(tx *bolt.Tx) error {
+		meta := tx.Bucket(metaBucket)
+		if meta.Get(seededKey) != nil {
+			return nil
+		}
+
+		for _, machine := range machines {
+			// The database becomes the source of truth once seeded.
+			machine.Source = ""
+			if err := putMachine(tx, machine); err != nil {
+				return err
+			}
+		}
+
+		return meta.Put(seededKey, []byte(time.Now().UTC().Format(time.RFC3339)))
+	})
+}
+
+func getMachine(tx *bolt.Tx, id string) (*domain.Machine, error) {
+	data := tx.Bucket(machinesBucket).Get([]byte(id))
+	if data == nil {
+		return nil, domain.ErrMachineNotFound
+	}
+
+	var machine domain.Machine
+	if err := json.Unmarshal(data, &machine); err != nil {
+		return nil, fmt.Errorf("failed to decode machine %s: %w", id, err)
+	}
+
+	return &machine, nil
+}
+
+func putMachine(tx *bolt.Tx, machine *domain.Machine) error {
+	data, err := json.Marshal(machine)
+	if err != nil {
+		return fmt.Errorf("failed to encode machine %s: %w", machine.ID, err)
+	}
+
+	if err := tx.Bucket(machinesBucket).Put([]byte(machine.ID), data); err != nil {
+		return fmt.Errorf("failed to store machine %s: %w", machine.ID, err)
+	}
+
+	return nil
+}
+
+func schemaVersion(tx *bolt.Tx) int {
+	data := tx.Bucket(metaBucket).Get(schemaVersionKey)
+	if len(data) != 8 {
+		return 0
+	}
+	return int(binary.BigEndian.Uint64(data)) // #nosec G115 - schema versions are small
+}
+
+func setSchemaVersion(tx *bolt.Tx, version int) error {
+	data := make([]byte, 8)
+	binary.BigEndian.PutUint64(data, uint64(version)) // #nosec G115 - schema versions are small
+	return tx.Bucket(metaBucket).Put(schemaVersionKey, data)
+}
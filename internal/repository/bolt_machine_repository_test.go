@@ -0,0 +1,203 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/josimar-silva/gwaihir/internal/config"
+	"github.com/josimar-silva/gwaihir/internal/domain"
+)
+
+func newBoltConfigForTesting(seed bool) *config.Config {
+	return &config.Config{
+		Machines: []config.MachineConfig{
+			{ID: "seed1", Name: "Seed 1", MAC: "AA:BB:CC:DD:EE:FF", Broadcast: "192.168.1.255"},
+		},
+		Storage: config.StorageConfig{Seed: &seed},
+	}
+}
+
+func openBoltRepoForTesting(t *testing.T, cfg *config.Config, path string) *BoltMachineRepository {
+	t.Helper()
+	repo, err := NewBoltMachineRepository(cfg, path)
+	if err != nil {
+		t.Fatalf("NewBoltMachineRepository() error = %v", err)
+	}
+	t.Cleanup(func() {
+		_ = repo.Close()
+	})
+	return repo
+}
+
+func TestBoltMachineRepository_SeedsOnFirstStart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "machines.db")
+	repo := openBoltRepoForTesting(t, newBoltConfigForTesting(true), path)
+
+	machine, err := repo.GetByID("seed1")
+	if err != nil {
+		t.Fatalf("Expected seeded machine, got error %v", err)
+	}
+	if machine.Name != "Seed 1" {
+		t.Errorf("Expected name 'Seed 1', got %s", machine.Name)
+	}
+
+	// Deleting a seeded machine survives a restart: seeding only happens once
+	if err := repo.Delete(context.Background(), "seed1"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if err := repo.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	reopened := openBoltRepoForTesting(t, newBoltConfigForTesting(true), path)
+	if reopened.Exists("seed1") {
+		t.Error("Expected seed not to be re-applied on subsequent starts")
+	}
+}
+
+func TestBoltMachineRepository_SeedDisabled(t *testing.T) {
+	repo := openBoltRepoForTesting(t, newBoltConfigForTesting(false), filepath.Join(t.TempDir(), "machines.db"))
+
+	machines, err := repo.GetAll()
+	if err != nil {
+		t.Fatalf("GetAll() error = %v", err)
+	}
+	if len(machines) != 0 {
+		t.Errorf("Expected no machines without seeding, got %d", len(machines))
+	}
+}
+
+func TestBoltMachineRepository_SeedValidation(t *testing.T) {
+	cfg := newBoltConfigForTesting(true)
+	cfg.Machines[0].MAC = "invalid"
+
+	_, err := NewBoltMachineRepository(cfg, filepath.Join(t.TempDir(), "machines.db"))
+	if err == nil {
+		t.Fatal("Expected error for invalid seed machine")
+	}
+}
+
+func TestBoltMachineRepository_CRUD(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "machines.db")
+	repo := openBoltRepoForTesting(t, newBoltConfigForTesting(false), path)
+	ctx := context.Background()
+
+	machine := &domain.Machine{ID: "runtime1", Name: "Runtime 1", MAC: "11:22:33:44:55:66", Broadcast: "10.0.0.255"}
+	if err := repo.Create(ctx, machine); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if err := repo.Create(ctx, machine); !errors.Is(err, domain.ErrMachineAlreadyExists) {
+		t.Errorf("Expected ErrMachineAlreadyExists, got %v", err)
+	}
+
+	updated := &domain.Machine{ID: "runtime1", Name: "Renamed", MAC: "11:22:33:44:55:66", Broadcast: "10.0.0.255"}
+	if err := repo.Update(ctx, updated); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	got, err := repo.GetByID("runtime1")
+	if err != nil || got.Name != "Renamed" {
+		t.Errorf("Expected updated machine, got %+v, %v", got, err)
+	}
+
+	missing := &domain.Machine{ID: "missing", Name: "Missing", MAC: "11:22:33:44:55:66", Broadcast: "10.0.0.255"}
+	if err := repo.Update(ctx, missing); !errors.Is(err, domain.ErrMachineNotFound) {
+		t.Errorf("Expected ErrMachineNotFound, got %v", err)
+	}
+
+	invalid := &domain.Machine{ID: "bad", Name: "Bad", MAC: "invalid", Broadcast: "10.0.0.255"}
+	if err := repo.Create(ctx, invalid); !errors.Is(err, domain.ErrInvalidMachine) {
+		t.Errorf("Expected ErrInvalidMachine, got %v", err)
+	}
+
+	if err := repo.Delete(ctx, "runtime1"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if err := repo.Delete(ctx, "runtime1"); !errors.Is(err, domain.ErrMachineNotFound) {
+		t.Errorf("Expected ErrMachineNotFound, got %v", err)
+	}
+	if _, err := repo.GetByID("runtime1"); !errors.Is(err, domain.ErrMachineNotFound) {
+		t.Errorf("Expected ErrMachineNotFound, got %v", err)
+	}
+}
+
+func TestBoltMachineRepository_Patch(t *testing.T) {
+	repo := openBoltRepoForTesting(t, newBoltConfigForTesting(true), filepath.Join(t.TempDir(), "machines.db"))
+
+	assertConcurrentPatchesKept(t, repo, "seed1")
+
+	invalid := "invalid"
+	if _, err := repo.Patch(context.Background(), "seed1", domain.MachinePatch{MAC: &invalid}); !errors.Is(err, domain.ErrInvalidMachine) {
+		t.Errorf("Expected ErrInvalidMachine, got %v", err)
+	}
+	if got, _ := repo.GetByID("seed1"); got.MAC == invalid {
+		t.Error("Expected invalid patch not to be stored")
+	}
+	if _, err := repo.Patch(context.Background(), "missing", domain.MachinePatch{}); !errors.Is(err, domain.ErrMachineNotFound) {
+		t.Errorf("Expected ErrMachineNotFound, got %v", err)
+	}
+}
+
+func TestBoltMachineRepository_CanceledContext(t *testing.T) {
+	repo := openBoltRepoForTesting(t, newBoltConfigForTesting(false), filepath.Join(t.TempDir(), "machines.db"))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	machine := &domain.Machine{ID: "runtime1", Name: "Runtime 1", MAC: "11:22:33:44:55:66", Broadcast: "10.0.0.255"}
+	if err := repo.Create(ctx, machine); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	if repo.Exists("runtime1") {
+		t.Error("Expected canceled create not to store the machine")
+	}
+}
+
+func TestBoltMachineRepository_PersistsAcrossRestarts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "machines.db")
+	repo := openBoltRepoForTesting(t, newBoltConfigForTesting(false), path)
+
+	machine := &domain.Machine{ID: "runtime1", Name: "Runtime 1", MAC: "11:22:33:44:55:66", Broadcast: "10.0.0.255"}
+	if err := repo.Create(context.Background(), machine); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	_ = repo.Close()
+
+	reopened := openBoltRepoForTesting(t, newBoltConfigForTesting(false), path)
+	if !reopened.Exists("runtime1") {
+		t.Error("Expected machine to persist across restarts")
+	}
+}
+
+func TestBoltMachineRepository_Migrations(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "machines.db")
+	repo := openBoltRepoForTesting(t, newBoltConfigForTesting(false), path)
+
+	version, err := repo.SchemaVersion()
+	if err != nil {
+		t.Fatalf("SchemaVersion() error = %v", err)
+	}
+	if version != len(migrations) {
+		t.Errorf("Expected schema version %d, got %d", len(migrations), version)
+	}
+	_ = repo.Close()
+
+	// Simulate a database written by a newer release
+	db, err := bolt.Open(path, 0o600, nil)
+	if err != nil {
+		t.Fatalf("bolt.Open() error = %v", err)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		return setSchemaVersion(tx, len(migrations)+1)
+	})
+	if err != nil {
+		t.Fatalf("setSchemaVersion() error = %v", err)
+	}
+	_ = db.Close()
+
+	if _, err := NewBoltMachineRepository(newBoltConfigForTesting(false), path); err == nil {
+		t.Fatal("Expected error for newer schema version")
+	}
+}
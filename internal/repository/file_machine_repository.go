@@ -0,0 +1,332 @@
+// Package repository provides data access implementations.
+package repository
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/josimar-silva/gwaihir/internal/config"
+	"github.com/josimar-silva/gwaihir/internal/domain"
+)
+
+// machinesFile is the on-disk format of the runtime machines file.
+type machinesFile struct {
+	Machines []*domain.Machine `yaml:"machines"`
+}
+
+// FileMachineRepository implements WritableMachineRepository on top of a dedicated
+// machines file. Machines from the configuration file are served read-only alongside
+// the machines managed at runtime, which are persisted back to the machines file.
+type FileMachineRepository struct {
+	path    string
+	static  map[string]*domain.Machine
+	managed map[string]*domain.Machine
+	mu      sync.RWMutex
+}
+
+// NewFileMachineRepository creates a repository serving the machines from cfg and the
+// machines file at path. A missing machines file is treated as empty.
+func NewFileMachineRepository(cfg *config.Config, path string) (*FileMachineRepository, error) {
+	static, err := buildMachines(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	managed, err := readMachinesFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkNoOverlap(static, managed); err != nil {
+		return nil, err
+	}
+
+	return &FileMachineRepository{
+		path:    path,
+		static:  static,
+		managed: managed,
+	}, nil
+}
+
+// GetByID retrieves a machine by its ID.
+func (r *FileMachineRepository) GetByID(id string) (*domain.Machine, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if machine, exists := r.static[id]; exists {
+		return machine, nil
+	}
+	if machine, exists := r.managed[id]; exists {
+		return machine, nil
+	}
+
+	return nil, domain.ErrMachineNotFound
+}
+
+// GetAll retrieves all registered machines.
+func (r *FileMachineRepository) GetAll() ([]*domain.Machine, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	machines := make([]*domain.Machine, 0, len(r.static)+len(r.managed))
+	for _, machine := range r.static {
+		machines = append(machines, machine)
+	}
+	for _, machine := range r.managed {
+		machines = append(machines, machine)
+	}
+
+	return machines, nil
+}
+
+// Exists checks if a machine with the given ID exists.
+func (r *FileMachineRepository) Exists(id string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.exists(id)
+}
+
+// Create registers a new machine and persists it to the machines file.
+func (r *FileMachineRepository) Create(ctx context.Context, machine *domain.Machine) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := machine.Validate(); err != nil {
+		return fmt.Errorf("%w: %w", domain.ErrInvalidMachine, err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.exists(machine.ID) {
+		return fmt.Errorf("%w: %s", domain.ErrMachineAlreadyExists, machine.ID)
+	}
+
+	return r.apply(func(managed map[string]*domain.Machine) {
+		managed[machine.ID] = machine
+	})
+}
+
+// Update replaces a runtime-managed machine and persists the change.
+// Machines defined in the configuration file cannot be updated.
+func (r *FileMachineRepository) Update(ctx context.Context, machine *domain.Machine) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := machine.Validate(); err != nil {
+		return fmt.Errorf("%w: %w", domain.ErrInvalidMachine, err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.checkWritable(machine.ID); err != nil {
+		return err
+	}
+
+	return r.apply(func(managed map[string]*domain.Machine) {
+		managed[machine.ID] = machine
+	})
+}
+
+// Patch applies a partial update to a runtime-managed machine and persists the change.
+// The machine is read, patched and written under the lock, so concurrent patches of
+// the same machine cannot overwrite each other.
+func (r *FileMachineRepository) Patch(ctx context.Context, id string, patch domain.MachinePatch) (*domain.Machine, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.checkWritable(id); err != nil {
+		return nil, err
+	}
+
+	patched := patch.Apply(r.managed[id])
+	if err := patched.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %w", domain.ErrInvalidMachine, err)
+	}
+
+	err := r.apply(func(managed map[string]*domain.Machine) {
+		managed[id] = patched
+	})
+	if err != nil {
+		return nil, err
+	}
+	return patched, nil
+}
+
+// Delete removes a runtime-managed machine and persists the change.
+// Machines defined in the configuration file cannot be deleted.
+func (r *FileMachineRepository) Delete(ctx context.Context, id string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.checkWritable(id); err != nil {
+		return err
+	}
+
+	return r.apply(func(managed map[string]*domain.Machine) {
+		delete(managed, id)
+	})
+}
+
+// Reload atomically replaces the configuration-file machines with the machines from cfg.
+// Runtime-managed machines are kept. If any machine is invalid or an ID is defined both in
+// the configuration and the machines file, the current machine set is kept and an error is returned.
+func (r *FileMachineRepository) Reload(cfg *config.Config) error {
+	static, err := buildMachines(cfg)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := checkNoOverlap(static, r.managed); err != nil {
+		return err
+	}
+
+	r.static = static
+	return nil
+}
+
+// exists must be called with the mutex held.
+func (r *FileMachineRepository) exists(id string) bool {
+	_, inStatic := r.static[id]
+	_, inManaged := r.managed[id]
+	return inStatic || inManaged
+}
+
+// checkWritable must be called with the mutex held.
+func (r *FileMachineRepository) checkWritable(id string) error {
+	if _, exists := r.static[id]; exists {
+		return fmt.Errorf("%w: %s is defined in the configuration file", domain.ErrMachineReadOnly, id)
+	}
+	if _, exists := r.managed[id]; !exists {
+		return domain.ErrMachineNotFound
+	}
+	return nil
+}
+
+// apply runs change against a copy of the managed machines, persists the result and
+// only then swaps it in, so a failed write leaves the repository unchanged.
+// Must be called with the mutex held.
+func (r *FileMachineRepository) apply(change func(managed map[string]*domain.Machine)) error {
+	managed := make(map[string]*domain.Machine, len(r.managed)+1)
+	for id, machine := range r.managed {
+		managed[id] = machine
+	}
+	change(managed)
+
+	if err := writeMachinesFile(r.path, managed); err != nil {
+		return err
+	}
+
+	r.managed = managed
+	return nil
+}
+
+// #nosec G304 - path is controlled by application configuration, not user input
+func readMachinesFile(path string) (map[string]*domain.Machine, error) {
+	machines := make(map[string]*domain.Machine)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return machines, nil
+		}
+		return nil, fmt.Errorf("failed to read machines file: %w", err)
+	}
+
+	var file machinesFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse machines file: %w", err)
+	}
+
+	for _, machine := range file.Machines {
+		if machine == nil {
+			continue
+		}
+		if err := machine.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid machine %s in machines file: %w", machine.ID, err)
+		}
+		if _, exists := machines[machine.ID]; exists {
+			return nil, fmt.Errorf("duplicate machine ID in machines file: %s", machine.ID)
+		}
+		machines[machine.ID] = machine
+	}
+
+	return machines, nil
+}
+
+// writeMachinesFile atomically replaces the machines file by writing a temporary
+// file in the same directory and renaming it over the original.
+func writeMachinesFile(path string, machines map[string]*domain.Machine) error {
+	file := machinesFile{Machines: make([]*domain.Machine, 0, len(machines))}
+	for _, machine := range machines {
+		file.Machines = append(file.Machines, machine)
+	}
+	sort.Slice(file.Machines, func(i, j int) bool {
+		return file.Machines[i].ID < file.Machines[j].ID
+	})
+
+	data, err := yaml.Marshal(file)
+	if err != nil {
+		return fmt.Errorf("failed to encode machines file: %w", err)
+	}
+
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return fmt.Errorf("failed to create machines file directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary machines file: %w", err)
+	}
+	tmpName := tmp.Name()
+	defer func() {
+		_ = os.Remove(tmpName)
+	}()
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write temporary machines file: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to sync temporary machines file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close temporary machines file: %w", err)
+	}
+
+	if err := os.Rename(tmpName, path); err != nil {
+		return fmt.Errorf("failed to replace machines file: %w", err)
+	}
+
+	return nil
+}
+
+func checkNoOverlap(static, managed map[string]*domain.Machine) error {
+	for id := range managed {
+		if _, exists := static[id]; exists {
+			return fmt.Errorf("duplicate machine ID: %s is defined in both the configuration and the machines file", id)
+		}
+	}
+	return nil
+}
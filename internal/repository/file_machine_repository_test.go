@@ -0,0 +1,231 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/josimar-silva/gwaihir/internal/config"
+	"github.com/josimar-silva/gwaihir/internal/domain"
+)
+
+func newFileRepoForTesting(t *testing.T) (*FileMachineRepository, string) {
+	t.Helper()
+	cfg := &config.Config{
+		Machines: []config.MachineConfig{
+			{ID: "static1", Name: "Static 1", MAC: "AA:BB:CC:DD:EE:FF", Broadcast: "192.168.1.255"},
+		},
+	}
+	path := filepath.Join(t.TempDir(), "machines.yaml")
+	repo, err := NewFileMachineRepository(cfg, path)
+	if err != nil {
+		t.Fatalf("NewFileMachineRepository() error = %v", err)
+	}
+	return repo, path
+}
+
+func TestFileMachineRepository_CreatePersists(t *testing.T) {
+	repo, path := newFileRepoForTesting(t)
+
+	machine := &domain.Machine{ID: "runtime1", Name: "Runtime 1", MAC: "11:22:33:44:55:66", Broadcast: "10.0.0.255"}
+	if err := repo.Create(context.Background(), machine); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	if !repo.Exists("runtime1") {
+		t.Error("Expected runtime1 to exist")
+	}
+	machines, _ := repo.GetAll()
+	if len(machines) != 2 {
+		t.Errorf("Expected 2 machines, got %d", len(machines))
+	}
+
+	data, err := os.ReadFile(path) // #nosec G304 - test file
+	if err != nil {
+		t.Fatalf("Expected machines file to be written: %v", err)
+	}
+	if !strings.Contains(string(data), "runtime1") || strings.Contains(string(data), "static1") {
+		t.Errorf("Expected only runtime machines in file, got:\n%s", data)
+	}
+
+	// A new repository picks up the persisted machine
+	cfg := &config.Config{Machines: []config.MachineConfig{
+		{ID: "static1", Name: "Static 1", MAC: "AA:BB:CC:DD:EE:FF", Broadcast: "192.168.1.255"},
+	}}
+	reopened, err := NewFileMachineRepository(cfg, path)
+	if err != nil {
+		t.Fatalf("NewFileMachineRepository() error = %v", err)
+	}
+	got, err := reopened.GetByID("runtime1")
+	if err != nil || got.Name != "Runtime 1" {
+		t.Errorf("Expected persisted machine, got %+v, %v", got, err)
+	}
+}
+
+func TestFileMachineRepository_CreateValidation(t *testing.T) {
+	repo, _ := newFileRepoForTesting(t)
+
+	err := repo.Create(context.Background(), &domain.Machine{ID: "bad", Name: "Bad", MAC: "invalid", Broadcast: "10.0.0.255"})
+	if !errors.Is(err, domain.ErrInvalidMachine) {
+		t.Errorf("Expected ErrInvalidMachine, got %v", err)
+	}
+
+	err = repo.Create(context.Background(), &domain.Machine{ID: "static1", Name: "Dup", MAC: "11:22:33:44:55:66", Broadcast: "10.0.0.255"})
+	if !errors.Is(err, domain.ErrMachineAlreadyExists) {
+		t.Errorf("Expected ErrMachineAlreadyExists, got %v", err)
+	}
+}
+
+func TestFileMachineRepository_UpdateAndDelete(t *testing.T) {
+	repo, _ := newFileRepoForTesting(t)
+	_ = repo.Create(context.Background(), &domain.Machine{ID: "runtime1", Name: "Runtime 1", MAC: "11:22:33:44:55:66", Broadcast: "10.0.0.255"})
+
+	if err := repo.Update(context.Background(), &domain.Machine{ID: "runtime1", Name: "Renamed", MAC: "11:22:33:44:55:66", Broadcast: "10.0.0.255"}); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	got, _ := repo.GetByID("runtime1")
+	if got.Name != "Renamed" {
+		t.Errorf("Expected updated name, got %s", got.Name)
+	}
+
+	if err := repo.Delete(context.Background(), "runtime1"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if repo.Exists("runtime1") {
+		t.Error("Expected runtime1 to be deleted")
+	}
+
+	if err := repo.Delete(context.Background(), "runtime1"); !errors.Is(err, domain.ErrMachineNotFound) {
+		t.Errorf("Expected ErrMachineNotFound, got %v", err)
+	}
+}
+
+// assertConcurrentPatchesKept patches a different field of machine id from each of
+// several goroutines and checks that every patch was kept.
+func assertConcurrentPatchesKept(t *testing.T, repo domain.WritableMachineRepository, id string) {
+	t.Helper()
+	name := "Patched"
+	mac := "22:33:44:55:66:77"
+	broadcast := "10.1.0.255"
+	patches := []domain.MachinePatch{{Name: &name}, {MAC: &mac}, {Broadcast: &broadcast}}
+
+	var wg sync.WaitGroup
+	for range 20 {
+		for _, patch := range patches {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if _, err := repo.Patch(context.Background(), id, patch); err != nil {
+					t.Errorf("Patch() error = %v", err)
+				}
+			}()
+		}
+	}
+	wg.Wait()
+
+	got, err := repo.GetByID(id)
+	if err != nil {
+		t.Fatalf("GetByID() error = %v", err)
+	}
+	if got.Name != name || got.MAC != mac || got.Broadcast != broadcast {
+		t.Errorf("Expected every concurrent patch to be kept, got %+v", got)
+	}
+}
+
+func TestFileMachineRepository_Patch(t *testing.T) {
+	repo, path := newFileRepoForTesting(t)
+	_ = repo.Create(context.Background(), &domain.Machine{ID: "runtime1", Name: "Runtime 1", MAC: "11:22:33:44:55:66", Broadcast: "10.0.0.255"})
+
+	assertConcurrentPatchesKept(t, repo, "runtime1")
+
+	data, err := os.ReadFile(path) // #nosec G304 - test file
+	if err != nil || !strings.Contains(string(data), "Patched") {
+		t.Errorf("Expected patched machine to be persisted, got %s, %v", data, err)
+	}
+
+	invalid := "invalid"
+	if _, err := repo.Patch(context.Background(), "runtime1", domain.MachinePatch{MAC: &invalid}); !errors.Is(err, domain.ErrInvalidMachine) {
+		t.Errorf("Expected ErrInvalidMachine, got %v", err)
+	}
+	if _, err := repo.Patch(context.Background(), "static1", domain.MachinePatch{}); !errors.Is(err, domain.ErrMachineReadOnly) {
+		t.Errorf("Expected ErrMachineReadOnly, got %v", err)
+	}
+	if _, err := repo.Patch(context.Background(), "missing", domain.MachinePatch{}); !errors.Is(err, domain.ErrMachineNotFound) {
+		t.Errorf("Expected ErrMachineNotFound, got %v", err)
+	}
+}
+
+func TestFileMachineRepository_StaticMachinesReadOnly(t *testing.T) {
+	repo, _ := newFileRepoForTesting(t)
+
+	err := repo.Update(context.Background(), &domain.Machine{ID: "static1", Name: "Changed", MAC: "AA:BB:CC:DD:EE:FF", Broadcast: "192.168.1.255"})
+	if !errors.Is(err, domain.ErrMachineReadOnly) {
+		t.Errorf("Expected ErrMachineReadOnly on update, got %v", err)
+	}
+
+	if err := repo.Delete(context.Background(), "static1"); !errors.Is(err, domain.ErrMachineReadOnly) {
+		t.Errorf("Expected ErrMachineReadOnly on delete, got %v", err)
+	}
+}
+
+func TestFileMachineRepository_WriteFailureLeavesStateUnchanged(t *testing.T) {
+	dir := t.TempDir()
+	// Use a directory as the target so the rename fails
+	path := filepath.Join(dir, "machines.yaml")
+	if err := os.Mkdir(path, 0o750); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	repo := &FileMachineRepository{path: path, static: map[string]*domain.Machine{}, managed: map[string]*domain.Machine{}}
+
+	err := repo.Create(context.Background(), &domain.Machine{ID: "runtime1", Name: "Runtime 1", MAC: "11:22:33:44:55:66", Broadcast: "10.0.0.255"})
+	if err == nil {
+		t.Fatal("Expected write error")
+	}
+	if repo.Exists("runtime1") {
+		t.Error("Expected failed write not to change the repository")
+	}
+}
+
+func TestFileMachineRepository_ReloadKeepsManagedMachines(t *testing.T) {
+	repo, _ := newFileRepoForTesting(t)
+	_ = repo.Create(context.Background(), &domain.Machine{ID: "runtime1", Name: "Runtime 1", MAC: "11:22:33:44:55:66", Broadcast: "10.0.0.255"})
+
+	err := repo.Reload(&config.Config{Machines: []config.MachineConfig{
+		{ID: "static2", Name: "Static 2", MAC: "22:33:44:55:66:77", Broadcast: "192.168.1.255"},
+	}})
+	if err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	if repo.Exists("static1") || !repo.Exists("static2") || !repo.Exists("runtime1") {
+		t.Error("Expected static machines swapped and runtime machines kept")
+	}
+
+	err = repo.Reload(&config.Config{Machines: []config.MachineConfig{
+		{ID: "runtime1", Name: "Clash", MAC: "22:33:44:55:66:77", Broadcast: "192.168.1.255"},
+	}})
+	if err == nil {
+		t.Fatal("Expected error for ID defined in both sources")
+	}
+	if !repo.Exists("static2") {
+		t.Error("Expected previous machine set to be kept")
+	}
+}
+
+func TestNewFileMachineRepository_RejectsOverlap(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "machines.yaml")
+	content := "machines:\n  - id: static1\n    name: Dup\n    mac: \"11:22:33:44:55:66\"\n    broadcast: \"10.0.0.255\"\n"
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("Failed to write machines file: %v", err)
+	}
+
+	cfg := &config.Config{Machines: []config.MachineConfig{
+		{ID: "static1", Name: "Static 1", MAC: "AA:BB:CC:DD:EE:FF", Broadcast: "192.168.1.255"},
+	}}
+	if _, err := NewFileMachineRepository(cfg, path); err == nil {
+		t.Fatal("Expected duplicate ID error")
+	}
+}
@@ -0,0 +1,96 @@
+// Package repository provides data access implementations.
+package repository
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/josimar-silva/gwaihir/internal/domain"
+)
+
+// ImportingMachineRepository serves the machines of a base repository together with
+// machines imported from external sources such as DHCP lease files. Machines of the
+// base repository take precedence; among sources, the one registered first wins.
+type ImportingMachineRepository struct {
+	base     domain.MachineRepository
+	imported map[string]map[string]*domain.Machine
+	order    []string
+	mu       sync.RWMutex
+}
+
+// NewImportingMachineRepository wraps base with support for imported machines.
+func NewImportingMachineRepository(base domain.MachineRepository) *ImportingMachineRepository {
+	return &ImportingMachineRepository{
+		base:     base,
+		imported: make(map[string]map[string]*domain.Machine),
+	}
+}
+
+// SetImported replaces the machines imported from the named source.
+func (r *ImportingMachineRepository) SetImported(source string, machines []*domain.Machine) {
+	byID := make(map[string]*domain.Machine, len(machines))
+	for _, machine := range machines {
+		byID[machine.ID] = machine
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.imported[source]; !exists {
+		r.order = append(r.order, source)
+	}
+	r.imported[source] = byID
+}
+
+// GetByID retrieves a machine by its ID.
+func (r *ImportingMachineRepository) GetByID(id string) (*domain.Machine, error) {
+	machine, err := r.base.GetByID(id)
+	if !errors.Is(err, domain.ErrMachineNotFound) {
+		return machine, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, source := range r.order {
+		if machine, exists := r.imported[source][id]; exists {
+			return machine, nil
+		}
+	}
+
+	return nil, domain.ErrMachineNotFound
+}
+
+// GetAll retrieves all machines, omitting imported machines shadowed by another machine.
+func (r *ImportingMachineRepository) GetAll() ([]*domain.Machine, error) {
+	machines, err := r.base.GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool, len(machines))
+	for _, machine := range machines {
+		seen[machine.ID] = true
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, source := range r.order {
+		for id, machine := range r.imported[source] {
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+			machines = append(machines, machine)
+		}
+	}
+
+	return machines, nil
+}
+
+// Exists checks if a machine with the given ID exists.
+func (r *ImportingMachineRepository) Exists(id string) bool {
+	_, err := r.GetByID(id)
+	return err == nil
+}
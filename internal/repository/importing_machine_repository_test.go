@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/josimar-silva/gwaihir/internal/config"
+	"github.com/josimar-silva/gwaihir/internal/domain"
+)
+
+func TestImportingMachineRepository(t *testing.T) {
+	base, err := NewInMemoryMachineRepository(&config.Config{Machines: []config.MachineConfig{
+		{ID: "static", Name: "Static", MAC: "AA:BB:CC:DD:EE:FF", Broadcast: "192.168.1.255"},
+	}})
+	if err != nil {
+		t.Fatalf("NewInMemoryMachineRepository() error = %v", err)
+	}
+	repo := NewImportingMachineRepository(base)
+
+	repo.SetImported("office", []*domain.Machine{
+		{ID: "static", Name: "Shadowed", MAC: "11:22:33:44:55:66", Broadcast: "10.0.0.255"},
+		{ID: "leased", Name: "Leased", MAC: "11:22:33:44:55:77", Broadcast: "10.0.0.255"},
+	})
+	repo.SetImported("lab", []*domain.Machine{
+		{ID: "leased", Name: "Lab copy", MAC: "11:22:33:44:55:88", Broadcast: "10.0.0.255"},
+	})
+
+	machine, err := repo.GetByID("static")
+	if err != nil || machine.Name != "Static" {
+		t.Errorf("Expected base machine to take precedence, got %+v, %v", machine, err)
+	}
+	machine, err = repo.GetByID("leased")
+	if err != nil || machine.Name != "Leased" {
+		t.Errorf("Expected first source to take precedence, got %+v, %v", machine, err)
+	}
+	if !repo.Exists("leased") || repo.Exists("unknown") {
+		t.Error("Unexpected Exists result")
+	}
+
+	machines, _ := repo.GetAll()
+	if len(machines) != 2 {
+		t.Errorf("Expected 2 machines without duplicates, got %d", len(machines))
+	}
+
+	repo.SetImported("office", nil)
+	machine, err = repo.GetByID("leased")
+	if err != nil || machine.Name != "Lab copy" {
+		t.Errorf("Expected remaining source to serve the machine, got %+v, %v", machine, err)
+	}
+}
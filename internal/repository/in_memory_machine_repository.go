@@ -18,7 +18,33 @@ type InMemoryMachineRepository struct {
 
 // NewInMemoryMachineRepository creates a new machine repository from config.
 func NewInMemoryMachineRepository(cfg *config.Config) (*InMemoryMachineRepository, error) {
-	// Build and validate machines from config
+	machines, err := buildMachines(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &InMemoryMachineRepository{
+		machines: machines,
+	}, nil
+}
+
+// Reload atomically replaces the machine set with the machines from cfg.
+// If any machine is invalid the current machine set is kept and an error is returned.
+func (r *InMemoryMachineRepository) Reload(cfg *config.Config) error {
+	machines, err := buildMachines(cfg)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.machines = machines
+	return nil
+}
+
+// buildMachines converts and validates the machines from config.
+func buildMachines(cfg *config.Config) (map[string]*domain.Machine, error) {
 	machines := make(map[string]*domain.Machine)
 	for i := range cfg.Machines {
 		machineConfig := &cfg.Machines[i]
@@ -29,6 +55,7 @@ func NewInMemoryMachineRepository(cfg *config.Config) (*InMemoryMachineRepositor
 			Name:      machineConfig.Name,
 			MAC:       machineConfig.MAC,
 			Broadcast: machineConfig.Broadcast,
+			Source:    machineConfig.Source,
 		}
 
 		// Validate using domain validation
@@ -44,9 +71,7 @@ func NewInMemoryMachineRepository(cfg *config.Config) (*InMemoryMachineRepositor
 		machines[machine.ID] = machine
 	}
 
-	return &InMemoryMachineRepository{
-		machines: machines,
-	}, nil
+	return machines, nil
 }
 
 // GetByID retrieves a machine by its ID.
@@ -487,3 +487,60 @@ func findProjectRoot(t *testing.T) string {
 		cwd = parent
 	}
 }
+
+func TestInMemoryMachineRepository_Reload(t *testing.T) {
+	cfg := &config.Config{
+		Machines: []config.MachineConfig{
+			{ID: "server1", Name: "Server 1", MAC: "AA:BB:CC:DD:EE:FF", Broadcast: "192.168.1.255"},
+		},
+	}
+	repo, err := NewInMemoryMachineRepository(cfg)
+	if err != nil {
+		t.Fatalf("NewInMemoryMachineRepository() error = %v", err)
+	}
+
+	newCfg := &config.Config{
+		Machines: []config.MachineConfig{
+			{ID: "server2", Name: "Server 2", MAC: "11:22:33:44:55:66", Broadcast: "10.0.0.255"},
+			{ID: "server3", Name: "Server 3", MAC: "22:33:44:55:66:77", Broadcast: "10.0.0.255"},
+		},
+	}
+	if err := repo.Reload(newCfg); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+
+	if repo.Exists("server1") {
+		t.Error("Expected server1 to be removed after reload")
+	}
+	if !repo.Exists("server2") || !repo.Exists("server3") {
+		t.Error("Expected server2 and server3 after reload")
+	}
+}
+
+func TestInMemoryMachineRepository_ReloadInvalidKeepsPrevious(t *testing.T) {
+	cfg := &config.Config{
+		Machines: []config.MachineConfig{
+			{ID: "server1", Name: "Server 1", MAC: "AA:BB:CC:DD:EE:FF", Broadcast: "192.168.1.255"},
+		},
+	}
+	repo, err := NewInMemoryMachineRepository(cfg)
+	if err != nil {
+		t.Fatalf("NewInMemoryMachineRepository() error = %v", err)
+	}
+
+	invalidCfg := &config.Config{
+		Machines: []config.MachineConfig{
+			{ID: "server2", Name: "Server 2", MAC: "invalid", Broadcast: "10.0.0.255"},
+		},
+	}
+	if err := repo.Reload(invalidCfg); err == nil {
+		t.Fatal("Expected error for invalid machine")
+	}
+
+	if !repo.Exists("server1") {
+		t.Error("Expected previous machine set to be kept")
+	}
+	if repo.Exists("server2") {
+		t.Error("Expected invalid machine set not to be applied")
+	}
+}
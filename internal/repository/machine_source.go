@@ -0,0 +1,163 @@
+// Package repository provides data access implementations.
+package repository
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+
+	"github.com/josimar-silva/gwaihir/internal/config"
+	"github.com/josimar-silva/gwaihir/internal/domain"
+	"github.com/josimar-silva/gwaihir/internal/infrastructure"
+)
+
+// subnetBroadcast maps machines in network to broadcast.
+type subnetBroadcast struct {
+	network   *net.IPNet
+	broadcast string
+}
+
+// MachineSource turns the entries of a DHCP lease or ethers file into machines.
+type MachineSource struct {
+	name      string
+	format    string
+	path      string
+	hostnames map[string]bool
+	pattern   *regexp.Regexp
+	subnets   []subnetBroadcast
+	broadcast string
+}
+
+// NewMachineSource creates a machine source from its configuration.
+func NewMachineSource(cfg config.MachineSourceConfig) (*MachineSource, error) {
+	source := &MachineSource{
+		name:      cfg.Name,
+		format:    cfg.Format,
+		path:      cfg.Path,
+		hostnames: make(map[string]bool, len(cfg.Hostnames)),
+		broadcast: cfg.Broadcast,
+	}
+
+	for _, hostname := range cfg.Hostnames {
+		source.hostnames[strings.ToLower(hostname)] = true
+	}
+
+	if cfg.HostnamePattern != "" {
+		pattern, err := regexp.Compile(cfg.HostnamePattern)
+		if err != nil {
+			return nil, fmt.Errorf("source %s: invalid hostname pattern: %w", cfg.Name, err)
+		}
+		source.pattern = pattern
+	}
+
+	for _, subnet := range cfg.Subnets {
+		_, network, err := net.ParseCIDR(subnet.Subnet)
+		if err != nil {
+			return nil, fmt.Errorf("source %s: invalid subnet: %w", cfg.Name, err)
+		}
+		broadcast := subnet.Broadcast
+		if broadcast == "" {
+			broadcast = broadcastAddress(network)
+		}
+		source.subnets = append(source.subnets, subnetBroadcast{network: network, broadcast: broadcast})
+	}
+
+	return source, nil
+}
+
+// Name returns the configured name of the source.
+func (s *MachineSource) Name() string {
+	return s.name
+}
+
+// Format returns the file format of the source.
+func (s *MachineSource) Format() string {
+	return s.format
+}
+
+// Path returns the file the source reads.
+func (s *MachineSource) Path() string {
+	return s.path
+}
+
+// Load parses the source file and returns the selected machines. Entries without a
+// hostname, not matched by the selector, without a broadcast address or with an
+// invalid MAC address are skipped. When a hostname appears several times, the last
+// entry wins.
+func (s *MachineSource) Load() ([]*domain.Machine, error) {
+	entries, err := infrastructure.ParseLeaseFile(s.format, s.path)
+	if err != nil {
+		return nil, fmt.Errorf("source %s: %w", s.name, err)
+	}
+
+	byID := make(map[string]*domain.Machine)
+	order := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if entry.Hostname == "" || !s.selects(entry.Hostname) {
+			continue
+		}
+
+		broadcast := s.broadcastFor(entry.IP)
+		if broadcast == "" {
+			continue
+		}
+
+		machine := &domain.Machine{
+			ID:        strings.ToLower(entry.Hostname),
+			Name:      entry.Hostname,
+			MAC:       entry.MAC,
+			Broadcast: broadcast,
+			Source:    s.path,
+		}
+		if err := machine.Validate(); err != nil {
+			continue
+		}
+
+		if _, exists := byID[machine.ID]; !exists {
+			order = append(order, machine.ID)
+		}
+		byID[machine.ID] = machine
+	}
+
+	machines := make([]*domain.Machine, 0, len(order))
+	for _, id := range order {
+		machines = append(machines, byID[id])
+	}
+	return machines, nil
+}
+
+// selects reports whether the hostname is chosen by the explicit list or the pattern.
+func (s *MachineSource) selects(hostname string) bool {
+	if s.hostnames[strings.ToLower(hostname)] {
+		return true
+	}
+	return s.pattern != nil && s.pattern.MatchString(hostname)
+}
+
+// broadcastFor returns the broadcast address of the first subnet containing ip,
+// or the source's default broadcast address.
+func (s *MachineSource) broadcastFor(ip string) string {
+	if parsed := net.ParseIP(ip); parsed != nil {
+		for _, subnet := range s.subnets {
+			if subnet.network.Contains(parsed) {
+				return subnet.broadcast
+			}
+		}
+	}
+	return s.broadcast
+}
+
+// broadcastAddress returns the IPv4 broadcast address of network.
+func broadcastAddress(network *net.IPNet) string {
+	ip := network.IP.To4()
+	mask := net.IP(network.Mask).To4()
+	if ip == nil || mask == nil {
+		return ""
+	}
+
+	broadcast := make(net.IP, net.IPv4len)
+	binary.BigEndian.PutUint32(broadcast, binary.BigEndian.Uint32(ip)|^binary.BigEndian.Uint32(mask))
+	return broadcast.String()
+}
@@ -0,0 +1,132 @@
+package repository
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/josimar-silva/gwaihir/internal/config"
+)
+
+const dnsmasqLeases = `1767225600 aa:bb:cc:dd:ee:01 192.168.1.10 ws-saruman *
+1767225600 aa:bb:cc:dd:ee:02 10.0.0.11 ws-morgoth *
+1767225600 aa:bb:cc:dd:ee:03 172.16.0.12 ws-gandalf *
+1767225600 aa:bb:cc:dd:ee:04 192.168.1.13 printer *
+1767225600 aa:bb:cc:dd:ee:05 192.168.1.14 * *
+1767225600 aa:bb:cc:dd:ee:06 192.168.1.15 Radagast *
+`
+
+func writeLeaseFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "dnsmasq.leases")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("Failed to write lease file: %v", err)
+	}
+	return path
+}
+
+func TestMachineSource_Load(t *testing.T) {
+	path := writeLeaseFile(t, dnsmasqLeases)
+	source, err := NewMachineSource(config.MachineSourceConfig{
+		Name:            "office",
+		Format:          "dnsmasq",
+		Path:            path,
+		Hostnames:       []string{"radagast"},
+		HostnamePattern: "^ws-",
+		Subnets: []config.SubnetBroadcastConfig{
+			{Subnet: "192.168.1.0/24"},
+			{Subnet: "10.0.0.0/8", Broadcast: "10.0.0.255"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewMachineSource() error = %v", err)
+	}
+
+	machines, err := source.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	got := make(map[string]string)
+	for _, machine := range machines {
+		got[machine.ID] = machine.Broadcast
+		if machine.Source != path {
+			t.Errorf("Expected source %s, got %s", path, machine.Source)
+		}
+	}
+
+	expected := map[string]string{
+		"ws-saruman": "192.168.1.255",
+		"ws-morgoth": "10.0.0.255",
+		"radagast":   "192.168.1.255",
+	}
+	if len(got) != len(expected) {
+		t.Fatalf("Expected machines %v, got %v", expected, got)
+	}
+	for id, broadcast := range expected {
+		if got[id] != broadcast {
+			t.Errorf("Expected %s to use broadcast %s, got %q", id, broadcast, got[id])
+		}
+	}
+}
+
+func TestMachineSource_DefaultBroadcast(t *testing.T) {
+	path := writeLeaseFile(t, dnsmasqLeases)
+	source, err := NewMachineSource(config.MachineSourceConfig{
+		Name:      "office",
+		Format:    "dnsmasq",
+		Path:      path,
+		Hostnames: []string{"ws-gandalf"},
+		Subnets:   []config.SubnetBroadcastConfig{{Subnet: "192.168.1.0/24"}},
+		Broadcast: "172.16.255.255",
+	})
+	if err != nil {
+		t.Fatalf("NewMachineSource() error = %v", err)
+	}
+
+	machines, err := source.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(machines) != 1 || machines[0].Broadcast != "172.16.255.255" {
+		t.Errorf("Expected ws-gandalf with default broadcast, got %+v", machines)
+	}
+}
+
+func TestMachineSource_MissingFile(t *testing.T) {
+	source, err := NewMachineSource(config.MachineSourceConfig{
+		Name:      "office",
+		Format:    "dnsmasq",
+		Path:      filepath.Join(t.TempDir(), "missing.leases"),
+		Hostnames: []string{"ws-saruman"},
+		Broadcast: "192.168.1.255",
+	})
+	if err != nil {
+		t.Fatalf("NewMachineSource() error = %v", err)
+	}
+
+	if _, err := source.Load(); err == nil {
+		t.Fatal("Expected error for missing lease file")
+	}
+}
+
+func TestBroadcastAddress(t *testing.T) {
+	tests := map[string]string{
+		"192.168.1.0/24": "192.168.1.255",
+		"10.0.0.0/8":     "10.255.255.255",
+		"172.16.4.0/22":  "172.16.7.255",
+	}
+
+	for cidr, want := range tests {
+		source, err := NewMachineSource(config.MachineSourceConfig{
+			Name:    "test",
+			Subnets: []config.SubnetBroadcastConfig{{Subnet: cidr}},
+		})
+		if err != nil {
+			t.Fatalf("NewMachineSource() error = %v", err)
+		}
+		if got := source.subnets[0].broadcast; got != want {
+			t.Errorf("broadcast of %s = %s, want %s", cidr, got, want)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+// Package usecase contains business logic use cases.
+package usecase
+
+import (
+	"fmt"
+
+	"github.com/josimar-silva/gwaihir/internal/domain"
+	"github.com/josimar-silva/gwaihir/internal/infrastructure"
+)
+
+// DiscoveredNeighbor is a neighbor table entry together with the allowlisted machine
+// that has the same MAC address, if any.
+type DiscoveredNeighbor struct {
+	domain.Neighbor
+	MachineID string
+}
+
+// Allowlisted reports whether the neighbor's MAC address belongs to a known machine.
+func (n DiscoveredNeighbor) Allowlisted() bool {
+	return n.MachineID != ""
+}
+
+// DiscoveryUseCase lists the hosts seen on the local network.
+type DiscoveryUseCase struct {
+	neighbors   domain.NeighborTable
+	machineRepo domain.MachineRepository
+	logger      *infrastructure.Logger
+}
+
+// NewDiscoveryUseCase creates a new neighbor discovery use case.
+func NewDiscoveryUseCase(neighbors domain.NeighborTable, machineRepo domain.MachineRepository, logger *infrastructure.Logger) *DiscoveryUseCase {
+	return &DiscoveryUseCase{
+		neighbors:   neighbors,
+		machineRepo: machineRepo,
+		logger:      logger,
+	}
+}
+
+// ListNeighbors returns the neighbor table entries, marking those already allowlisted.
+func (uc *DiscoveryUseCase) ListNeighbors() ([]DiscoveredNeighbor, error) {
+	neighbors, err := uc.neighbors.Neighbors()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read neighbor table: %w", err)
+	}
+
+	machines, err := uc.machineRepo.GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve machines: %w", err)
+	}
+
+	byMAC := make(map[string]string, len(machines))
+	for _, machine := range machines {
+		byMAC[machine.NormalizeMAC()] = machine.ID
+	}
+
+	discovered := make([]DiscoveredNeighbor, 0, len(neighbors))
+	for _, neighbor := range neighbors {
+		discovered = append(discovered, DiscoveredNeighbor{
+			Neighbor:  neighbor,
+			MachineID: byMAC[normalizeMAC(neighbor.MAC)],
+		})
+	}
+
+	uc.logger.Debug("Listed neighbors", infrastructure.Int("count", len(discovered)))
+	return discovered, nil
+}
+
+// FindNeighbor returns the neighbor with the given MAC address.
+func (uc *DiscoveryUseCase) FindNeighbor(mac string) (*DiscoveredNeighbor, error) {
+	neighbors, err := uc.ListNeighbors()
+	if err != nil {
+		return nil, err
+	}
+
+	mac = normalizeMAC(mac)
+	for i := range neighbors {
+		if normalizeMAC(neighbors[i].MAC) == mac {
+			return &neighbors[i], nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: %s", domain.ErrNeighborNotFound, mac)
+}
+
+// normalizeMAC formats a MAC address the same way as allowlisted machines.
+func normalizeMAC(mac string) string {
+	return (&domain.Machine{MAC: mac}).NormalizeMAC()
+}
@@ -0,0 +1,72 @@
+package usecase
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/josimar-silva/gwaihir/internal/domain"
+)
+
+type mockNeighborTable struct {
+	neighbors []domain.Neighbor
+	err       error
+}
+
+func (m *mockNeighborTable) Neighbors() ([]domain.Neighbor, error) {
+	return m.neighbors, m.err
+}
+
+func newTestDiscoveryUseCase(table *mockNeighborTable) *DiscoveryUseCase {
+	repo := newMockMachineRepository(map[string]*domain.Machine{
+		"saruman": {ID: "saruman", Name: "Saruman", MAC: "aa-bb-cc-dd-ee-ff", Broadcast: "192.168.1.255"},
+	})
+	return NewDiscoveryUseCase(table, repo, newTestLogger())
+}
+
+func TestListNeighbors_MarksAllowlisted(t *testing.T) {
+	useCase := newTestDiscoveryUseCase(&mockNeighborTable{neighbors: []domain.Neighbor{
+		{IP: "192.168.1.10", MAC: "AA:BB:CC:DD:EE:FF", Interface: "eth0"},
+		{IP: "192.168.1.11", MAC: "11:22:33:44:55:66", Interface: "eth0"},
+	}})
+
+	neighbors, err := useCase.ListNeighbors()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(neighbors) != 2 {
+		t.Fatalf("Expected 2 neighbors, got %d", len(neighbors))
+	}
+	if !neighbors[0].Allowlisted() || neighbors[0].MachineID != "saruman" {
+		t.Errorf("Expected first neighbor to be allowlisted as saruman, got %+v", neighbors[0])
+	}
+	if neighbors[1].Allowlisted() {
+		t.Errorf("Expected second neighbor not to be allowlisted, got %+v", neighbors[1])
+	}
+}
+
+func TestListNeighbors_TableError(t *testing.T) {
+	useCase := newTestDiscoveryUseCase(&mockNeighborTable{err: errors.New("permission denied")})
+
+	if _, err := useCase.ListNeighbors(); err == nil {
+		t.Error("Expected error, got nil")
+	}
+}
+
+func TestFindNeighbor(t *testing.T) {
+	useCase := newTestDiscoveryUseCase(&mockNeighborTable{neighbors: []domain.Neighbor{
+		{IP: "192.168.1.11", MAC: "11:22:33:44:55:66", Interface: "eth0"},
+	}})
+
+	neighbor, err := useCase.FindNeighbor("11-22-33-44-55-66")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if neighbor.IP != "192.168.1.11" {
+		t.Errorf("Expected IP 192.168.1.11, got %s", neighbor.IP)
+	}
+
+	_, err = useCase.FindNeighbor("de:ad:be:ef:00:01")
+	if !errors.Is(err, domain.ErrNeighborNotFound) {
+		t.Errorf("Expected ErrNeighborNotFound, got %v", err)
+	}
+}
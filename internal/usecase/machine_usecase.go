@@ -0,0 +1,127 @@
+// Package usecase contains business logic use cases.
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/josimar-silva/gwaihir/internal/domain"
+	"github.com/josimar-silva/gwaihir/internal/infrastructure"
+)
+
+// MachineUseCase handles runtime management of the machine allowlist.
+type MachineUseCase struct {
+	machineRepo domain.WritableMachineRepository
+	allowlist   domain.MachineRepository
+	logger      *infrastructure.Logger
+	metrics     *infrastructure.Metrics
+	events      domain.EventPublisher
+}
+
+// NewMachineUseCase creates a new machine management use case.
+func NewMachineUseCase(machineRepo domain.WritableMachineRepository, logger *infrastructure.Logger, metrics *infrastructure.Metrics) *MachineUseCase {
+	return &MachineUseCase{
+		machineRepo: machineRepo,
+		allowlist:   machineRepo,
+		logger:      logger,
+		metrics:     metrics,
+	}
+}
+
+// WithAllowlist reports the machines of allowlist, which may add imported machines to
+// those of the managed repository, as the configured machines in the metrics.
+func (uc *MachineUseCase) WithAllowlist(allowlist domain.MachineRepository) *MachineUseCase {
+	uc.allowlist = allowlist
+	return uc
+}
+
+// WithEvents publishes the created, updated and deleted events of every change to events.
+func (uc *MachineUseCase) WithEvents(events domain.EventPublisher) *MachineUseCase {
+	uc.events = events
+	return uc
+}
+
+// CreateMachine adds a new machine to the allowlist.
+func (uc *MachineUseCase) CreateMachine(ctx context.Context, machine *domain.Machine) error {
+	if err := uc.machineRepo.Create(ctx, machine); err != nil {
+		return fmt.Errorf("failed to create machine: %w", err)
+	}
+
+	uc.logger.WithTrace(ctx).Info("Machine created",
+		infrastructure.String("machine_id", machine.ID),
+		infrastructure.String("machine_name", machine.Name),
+		infrastructure.String("mac", machine.NormalizeMAC()),
+		infrastructure.String("broadcast", machine.Broadcast),
+	)
+	uc.updateConfiguredMachines()
+	uc.publish(domain.EventMachineCreated, machine)
+	return nil
+}
+
+// UpdateMachine replaces an existing machine.
+func (uc *MachineUseCase) UpdateMachine(ctx context.Context, machine *domain.Machine) error {
+	if err := uc.machineRepo.Update(ctx, machine); err != nil {
+		return fmt.Errorf("failed to update machine: %w", err)
+	}
+
+	uc.updated(ctx, machine)
+	return nil
+}
+
+// PatchMachine applies a partial update to an existing machine and returns the result.
+// The repository applies the patch atomically, so concurrent patches are not lost.
+func (uc *MachineUseCase) PatchMachine(ctx context.Context, machineID string, patch domain.MachinePatch) (*domain.Machine, error) {
+	patched, err := uc.machineRepo.Patch(ctx, machineID, patch)
+	if err != nil {
+		return nil, fmt.Errorf("failed to patch machine: %w", err)
+	}
+
+	uc.updated(ctx, patched)
+	return patched, nil
+}
+
+func (uc *MachineUseCase) updated(ctx context.Context, machine *domain.Machine) {
+	uc.logger.WithTrace(ctx).Info("Machine updated",
+		infrastructure.String("machine_id", machine.ID),
+		infrastructure.String("machine_name", machine.Name),
+		infrastructure.String("mac", machine.NormalizeMAC()),
+		infrastructure.String("broadcast", machine.Broadcast),
+	)
+	uc.publish(domain.EventMachineUpdated, machine)
+}
+
+// DeleteMachine removes a machine from the allowlist.
+func (uc *MachineUseCase) DeleteMachine(ctx context.Context, machineID string) error {
+	if err := uc.machineRepo.Delete(ctx, machineID); err != nil {
+		return fmt.Errorf("failed to delete machine: %w", err)
+	}
+
+	uc.logger.WithTrace(ctx).Info("Machine deleted",
+		infrastructure.String("machine_id", machineID),
+	)
+	uc.updateConfiguredMachines()
+	uc.publish(domain.EventMachineDeleted, &domain.Machine{ID: machineID})
+	return nil
+}
+
+func (uc *MachineUseCase) publish(eventType string, machine *domain.Machine) {
+	if uc.events == nil {
+		return
+	}
+	uc.events.Publish(domain.Event{
+		Type:      eventType,
+		MachineID: machine.ID,
+		MAC:       machine.NormalizeMAC(),
+		Broadcast: machine.Broadcast,
+		Time:      time.Now().UTC(),
+	})
+}
+
+func (uc *MachineUseCase) updateConfiguredMachines() {
+	machines, err := uc.allowlist.GetAll()
+	if err != nil {
+		return
+	}
+	uc.metrics.SetConfiguredMachines(machines)
+}
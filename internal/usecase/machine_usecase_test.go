@@ -0,0 +1,197 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/josimar-silva/gwaihir/internal/domain"
+	"github.com/josimar-silva/gwaihir/internal/repository"
+)
+
+type mockWritableMachineRepository struct {
+	*mockMachineRepository
+	writeError error
+}
+
+func newMockWritableMachineRepository(machines map[string]*domain.Machine) *mockWritableMachineRepository {
+	return &mockWritableMachineRepository{mockMachineRepository: newMockMachineRepository(machines)}
+}
+
+func (m *mockWritableMachineRepository) Create(_ context.Context, machine *domain.Machine) error {
+	if m.writeError != nil {
+		return m.writeError
+	}
+	if m.Exists(machine.ID) {
+		return domain.ErrMachineAlreadyExists
+	}
+	m.machines[machine.ID] = machine
+	return nil
+}
+
+func (m *mockWritableMachineRepository) Update(_ context.Context, machine *domain.Machine) error {
+	if m.writeError != nil {
+		return m.writeError
+	}
+	if !m.Exists(machine.ID) {
+		return domain.ErrMachineNotFound
+	}
+	m.machines[machine.ID] = machine
+	return nil
+}
+
+func (m *mockWritableMachineRepository) Patch(_ context.Context, id string, patch domain.MachinePatch) (*domain.Machine, error) {
+	if m.writeError != nil {
+		return nil, m.writeError
+	}
+	current, exists := m.machines[id]
+	if !exists {
+		return nil, domain.ErrMachineNotFound
+	}
+	patched := patch.Apply(current)
+	m.machines[id] = patched
+	return patched, nil
+}
+
+func (m *mockWritableMachineRepository) Delete(_ context.Context, id string) error {
+	if m.writeError != nil {
+		return m.writeError
+	}
+	if !m.Exists(id) {
+		return domain.ErrMachineNotFound
+	}
+	delete(m.machines, id)
+	return nil
+}
+
+func TestMachineUseCase_CreateAndDeleteUpdateGauge(t *testing.T) {
+	repo := newMockWritableMachineRepository(map[string]*domain.Machine{})
+	metrics := newTestMetrics()
+	useCase := NewMachineUseCase(repo, newTestLogger(), metrics)
+
+	err := useCase.CreateMachine(context.Background(), &domain.Machine{ID: "saruman", Name: "Saruman", MAC: "AA:BB:CC:DD:EE:FF", Broadcast: "192.168.1.255"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if got := testutil.ToFloat64(metrics.ConfiguredMachines); got != 1 {
+		t.Errorf("Expected 1 configured machine, got %v", got)
+	}
+
+	if err := useCase.DeleteMachine(context.Background(), "saruman"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if got := testutil.ToFloat64(metrics.ConfiguredMachines); got != 0 {
+		t.Errorf("Expected 0 configured machines, got %v", got)
+	}
+}
+
+func TestMachineUseCase_WithAllowlistKeepsImportedMachineLabels(t *testing.T) {
+	repo := newMockWritableMachineRepository(map[string]*domain.Machine{})
+	allowlist := repository.NewImportingMachineRepository(repo)
+	allowlist.SetImported("leases", []*domain.Machine{{ID: "imported", Name: "Imported", MAC: "11:22:33:44:55:66", Broadcast: "192.168.1.255"}})
+	metrics := newTestMetrics()
+	useCase := NewMachineUseCase(repo, newTestLogger(), metrics).WithAllowlist(allowlist)
+
+	err := useCase.CreateMachine(context.Background(), &domain.Machine{ID: "saruman", Name: "Saruman", MAC: "AA:BB:CC:DD:EE:FF", Broadcast: "192.168.1.255"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if got := metrics.MachineLabel("imported"); got != "imported" {
+		t.Errorf("Expected imported machine to keep its label, got %q", got)
+	}
+	if got := testutil.ToFloat64(metrics.ConfiguredMachines); got != 2 {
+		t.Errorf("Expected 2 configured machines, got %v", got)
+	}
+}
+
+func TestMachineUseCase_CreateDuplicate(t *testing.T) {
+	repo := newMockWritableMachineRepository(map[string]*domain.Machine{
+		"saruman": {ID: "saruman", Name: "Saruman", MAC: "AA:BB:CC:DD:EE:FF", Broadcast: "192.168.1.255"},
+	})
+	useCase := NewMachineUseCase(repo, newTestLogger(), newTestMetrics())
+
+	err := useCase.CreateMachine(context.Background(), &domain.Machine{ID: "saruman", Name: "Saruman", MAC: "AA:BB:CC:DD:EE:FF", Broadcast: "192.168.1.255"})
+	if !errors.Is(err, domain.ErrMachineAlreadyExists) {
+		t.Errorf("Expected ErrMachineAlreadyExists, got %v", err)
+	}
+}
+
+func TestMachineUseCase_PatchMachine(t *testing.T) {
+	repo := newMockWritableMachineRepository(map[string]*domain.Machine{
+		"saruman": {ID: "saruman", Name: "Saruman", MAC: "AA:BB:CC:DD:EE:FF", Broadcast: "192.168.1.255"},
+	})
+	useCase := NewMachineUseCase(repo, newTestLogger(), newTestMetrics())
+
+	name := "Orthanc"
+	patched, err := useCase.PatchMachine(context.Background(), "saruman", domain.MachinePatch{Name: &name})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if patched.Name != "Orthanc" || patched.MAC != "AA:BB:CC:DD:EE:FF" {
+		t.Errorf("Expected only name to change, got %+v", patched)
+	}
+	if repo.machines["saruman"].Name != "Orthanc" {
+		t.Error("Expected patched machine to be stored")
+	}
+}
+
+func TestMachineUseCase_PatchMachine_NotFound(t *testing.T) {
+	repo := newMockWritableMachineRepository(map[string]*domain.Machine{})
+	useCase := NewMachineUseCase(repo, newTestLogger(), newTestMetrics())
+
+	_, err := useCase.PatchMachine(context.Background(), "unknown", domain.MachinePatch{})
+	if !errors.Is(err, domain.ErrMachineNotFound) {
+		t.Errorf("Expected ErrMachineNotFound, got %v", err)
+	}
+}
+
+func TestMachineUseCase_UpdateWriteError(t *testing.T) {
+	repo := newMockWritableMachineRepository(map[string]*domain.Machine{
+		"saruman": {ID: "saruman", Name: "Saruman", MAC: "AA:BB:CC:DD:EE:FF", Broadcast: "192.168.1.255"},
+	})
+	repo.writeError = errors.New("disk full")
+	useCase := NewMachineUseCase(repo, newTestLogger(), newTestMetrics())
+
+	err := useCase.UpdateMachine(context.Background(), &domain.Machine{ID: "saruman", Name: "Saruman", MAC: "AA:BB:CC:DD:EE:FF", Broadcast: "192.168.1.255"})
+	if err == nil {
+		t.Fatal("Expected error, got nil")
+	}
+}
+
+func TestMachineUseCase_PublishesEvents(t *testing.T) {
+	repo := newMockWritableMachineRepository(map[string]*domain.Machine{})
+	events := &recordingPublisher{}
+	useCase := NewMachineUseCase(repo, newTestLogger(), newTestMetrics()).WithEvents(events)
+	ctx := context.Background()
+
+	if err := useCase.CreateMachine(ctx, &domain.Machine{ID: "saruman", Name: "Saruman", MAC: "aa-bb-cc-dd-ee-ff", Broadcast: "192.168.1.255"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	name := "Saruman the White"
+	if _, err := useCase.PatchMachine(ctx, "saruman", domain.MachinePatch{Name: &name}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := useCase.DeleteMachine(ctx, "saruman"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	// Failed changes publish nothing.
+	if err := useCase.DeleteMachine(ctx, "saruman"); err == nil {
+		t.Fatal("Expected error, got nil")
+	}
+
+	want := []string{domain.EventMachineCreated, domain.EventMachineUpdated, domain.EventMachineDeleted}
+	if len(events.events) != len(want) {
+		t.Fatalf("Expected %d events, got %+v", len(want), events.events)
+	}
+	for i, event := range events.events {
+		if event.Type != want[i] || event.MachineID != "saruman" || event.Time.IsZero() {
+			t.Errorf("Event %d: unexpected %+v", i, event)
+		}
+	}
+	if events.events[0].MAC != "AA:BB:CC:DD:EE:FF" {
+		t.Errorf("Expected normalized MAC, got %s", events.events[0].MAC)
+	}
+}
@@ -2,7 +2,12 @@
 package usecase
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
 
 	"github.com/josimar-silva/gwaihir/internal/domain"
 	"github.com/josimar-silva/gwaihir/internal/infrastructure"
@@ -14,6 +19,7 @@ type WoLUseCase struct {
 	packetSender domain.WoLPacketSender
 	logger       *infrastructure.Logger
 	metrics      *infrastructure.Metrics
+	events       domain.EventPublisher
 }
 
 // NewWoLUseCase creates a new WoL use case.
@@ -26,38 +32,128 @@ func NewWoLUseCase(machineRepo domain.MachineRepository, packetSender domain.WoL
 	}
 }
 
+// WithEvents publishes the wake requested, sent and failed events of every wake to events.
+func (uc *WoLUseCase) WithEvents(events domain.EventPublisher) *WoLUseCase {
+	uc.events = events
+	return uc
+}
+
 // SendWakePacket sends a WoL packet to the specified machine.
 // It validates that the machine is in the allowlist before sending.
-func (uc *WoLUseCase) SendWakePacket(machineID string) error {
+func (uc *WoLUseCase) SendWakePacket(ctx context.Context, machineID string) error {
+	return uc.SendWakePacketVia(ctx, machineID, "")
+}
+
+// SendWakePacketVia sends a WoL packet to the specified allowlisted machine using
+// broadcast instead of the machine's configured broadcast address, unless it is empty.
+// A machine missing from the allowlist is reported as domain.ErrMachineNotAllowed,
+// which also matches domain.ErrMachineNotFound.
+func (uc *WoLUseCase) SendWakePacketVia(ctx context.Context, machineID, broadcast string) error {
+	ctx, span := infrastructure.Tracer().Start(ctx, "WoLUseCase.SendWakePacket",
+		trace.WithAttributes(infrastructure.AttrMachineID.String(machineID)),
+	)
+	defer span.End()
+
 	machine, err := uc.machineRepo.GetByID(machineID)
 	if err != nil {
 		uc.metrics.MachineNotFound.Inc()
-		return fmt.Errorf("failed to get machine: %w", err)
+		if errors.Is(err, domain.ErrMachineNotFound) {
+			err = fmt.Errorf("%w: %w", domain.ErrMachineNotAllowed, err)
+		} else {
+			err = fmt.Errorf("failed to get machine: %w", err)
+		}
+		infrastructure.RecordSpanError(span, err)
+		return err
 	}
 
-	uc.logger.Info("Sending WoL packet",
+	if broadcast == "" {
+		broadcast = machine.Broadcast
+	}
+	span.SetAttributes(infrastructure.MachineAttributes(&domain.Machine{
+		ID: machine.ID, Name: machine.Name, MAC: machine.MAC, Broadcast: broadcast,
+	})...)
+
+	uc.logger.WithTrace(ctx).Info("Sending WoL packet",
 		infrastructure.String("machine_id", machine.ID),
 		infrastructure.String("machine_name", machine.Name),
 		infrastructure.String("mac", machine.NormalizeMAC()),
-		infrastructure.String("broadcast", machine.Broadcast),
+		infrastructure.String("broadcast", broadcast),
+	)
+
+	if err := uc.send(ctx, machine.ID, machine.MAC, broadcast); err != nil {
+		infrastructure.RecordSpanError(span, err)
+		return err
+	}
+	return nil
+}
+
+// SendUnlistedWakePacket sends a WoL packet to a MAC address without consulting the
+// allowlist. Callers are responsible for authorizing the request.
+func (uc *WoLUseCase) SendUnlistedWakePacket(ctx context.Context, mac, broadcast string) error {
+	target := &domain.Machine{MAC: mac, Broadcast: broadcast}
+	ctx, span := infrastructure.Tracer().Start(ctx, "WoLUseCase.SendUnlistedWakePacket",
+		trace.WithAttributes(infrastructure.MachineAttributes(target)...),
 	)
+	defer span.End()
 
-	if err := uc.packetSender.SendMagicPacket(machine.MAC, machine.Broadcast); err != nil {
-		uc.metrics.WoLPacketsFailed.Inc()
-		uc.logger.Error("Failed to send WoL packet",
-			infrastructure.String("machine_id", machine.ID),
+	if err := domain.ValidateMAC(mac); err != nil {
+		err = fmt.Errorf("invalid MAC address: %w", err)
+		infrastructure.RecordSpanError(span, err)
+		return err
+	}
+	if err := domain.ValidateBroadcast(broadcast); err != nil {
+		err = fmt.Errorf("invalid broadcast address: %w", err)
+		infrastructure.RecordSpanError(span, err)
+		return err
+	}
+
+	uc.logger.WithTrace(ctx).Warn("Sending WoL packet to a MAC address outside the allowlist",
+		infrastructure.String("mac", target.NormalizeMAC()),
+		infrastructure.String("broadcast", broadcast),
+	)
+
+	if err := uc.send(ctx, "", mac, broadcast); err != nil {
+		infrastructure.RecordSpanError(span, err)
+		return err
+	}
+	return nil
+}
+
+func (uc *WoLUseCase) send(ctx context.Context, machineID, mac, broadcast string) error {
+	logger := uc.logger.WithTrace(ctx)
+	uc.publish(domain.EventWakeRequested, machineID, mac, broadcast)
+
+	if err := uc.packetSender.SendMagicPacket(ctx, mac, broadcast); err != nil {
+		uc.metrics.WoLPackets.WithLabelValues(uc.metrics.MachineLabel(machineID), infrastructure.WoLResultFailed).Inc()
+		uc.publish(domain.EventWakeFailed, machineID, mac, broadcast)
+		logger.Error("Failed to send WoL packet",
+			infrastructure.String("machine_id", machineID),
 			infrastructure.Any("error", err),
 		)
-		return fmt.Errorf("failed to send WoL packet: %w", err)
+		return fmt.Errorf("%w: %w", domain.ErrWakeFailed, err)
 	}
 
-	uc.metrics.WoLPacketsSent.Inc()
-	uc.logger.Info("WoL packet sent successfully",
-		infrastructure.String("machine_id", machine.ID),
+	uc.metrics.WoLPackets.WithLabelValues(uc.metrics.MachineLabel(machineID), infrastructure.WoLResultSent).Inc()
+	uc.publish(domain.EventWakeSent, machineID, mac, broadcast)
+	logger.Info("WoL packet sent successfully",
+		infrastructure.String("machine_id", machineID),
 	)
 	return nil
 }
 
+func (uc *WoLUseCase) publish(eventType, machineID, mac, broadcast string) {
+	if uc.events == nil {
+		return
+	}
+	uc.events.Publish(domain.Event{
+		Type:      eventType,
+		MachineID: machineID,
+		MAC:       (&domain.Machine{MAC: mac}).NormalizeMAC(),
+		Broadcast: broadcast,
+		Time:      time.Now().UTC(),
+	})
+}
+
 // ListMachines returns all registered machines.
 func (uc *WoLUseCase) ListMachines() ([]*domain.Machine, error) {
 	return uc.machineRepo.GetAll()
@@ -1,10 +1,17 @@
 package usecase
 
 import (
+	"context"
 	"errors"
+	"net"
 	"testing"
 
-	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 
 	"github.com/josimar-silva/gwaihir/internal/domain"
 	"github.com/josimar-silva/gwaihir/internal/infrastructure"
@@ -69,7 +76,7 @@ func newMockWoLPacketSender() *mockWoLPacketSender {
 	}
 }
 
-func (m *mockWoLPacketSender) SendMagicPacket(mac, broadcast string) error {
+func (m *mockWoLPacketSender) SendMagicPacket(_ context.Context, mac, broadcast string) error {
 	m.callCount++
 	m.sendPackets = append(m.sendPackets, sentPacket{mac: mac, broadcast: broadcast})
 
@@ -99,7 +106,7 @@ func TestSendWakePacket_Success(t *testing.T) {
 	useCase := NewWoLUseCase(repo, sender, logger, metrics)
 
 	// Act
-	err := useCase.SendWakePacket("saruman")
+	err := useCase.SendWakePacket(context.Background(), "saruman")
 
 	// Assert
 	if err != nil {
@@ -128,7 +135,7 @@ func TestSendWakePacket_MachineNotFound(t *testing.T) {
 	useCase := NewWoLUseCase(repo, sender, logger, metrics)
 
 	// Act
-	err := useCase.SendWakePacket("nonexistent")
+	err := useCase.SendWakePacket(context.Background(), "nonexistent")
 
 	// Assert
 	if err == nil {
@@ -137,6 +144,9 @@ func TestSendWakePacket_MachineNotFound(t *testing.T) {
 	if !errors.Is(err, domain.ErrMachineNotFound) {
 		t.Errorf("Expected ErrMachineNotFound, got %v", err)
 	}
+	if code := domain.CodeOf(err); code != domain.CodeMachineNotAllowed {
+		t.Errorf("Expected code %s, got %s", domain.CodeMachineNotAllowed, code)
+	}
 	if sender.callCount != 0 {
 		t.Errorf("Expected SendMagicPacket not to be called, got %d calls", sender.callCount)
 	}
@@ -162,7 +172,7 @@ func TestSendWakePacket_SendError(t *testing.T) {
 	useCase := NewWoLUseCase(repo, sender, logger, metrics)
 
 	// Act
-	err := useCase.SendWakePacket("saruman")
+	err := useCase.SendWakePacket(context.Background(), "saruman")
 
 	// Assert
 	if err == nil {
@@ -171,6 +181,9 @@ func TestSendWakePacket_SendError(t *testing.T) {
 	if !contains(err.Error(), "failed to send WoL packet") {
 		t.Errorf("Expected error to contain 'failed to send WoL packet', got %s", err.Error())
 	}
+	if !errors.Is(err, domain.ErrWakeFailed) {
+		t.Errorf("Expected ErrWakeFailed, got %v", err)
+	}
 }
 
 func TestSendWakePacket_MultipleMachines(t *testing.T) {
@@ -196,8 +209,8 @@ func TestSendWakePacket_MultipleMachines(t *testing.T) {
 	useCase := NewWoLUseCase(repo, sender, logger, metrics)
 
 	// Act
-	err1 := useCase.SendWakePacket("saruman")
-	err2 := useCase.SendWakePacket("morgoth")
+	err1 := useCase.SendWakePacket(context.Background(), "saruman")
+	err2 := useCase.SendWakePacket(context.Background(), "morgoth")
 
 	// Assert
 	if err1 != nil || err2 != nil {
@@ -214,6 +227,61 @@ func TestSendWakePacket_MultipleMachines(t *testing.T) {
 	}
 }
 
+func TestSendWakePacketVia_OverridesBroadcast(t *testing.T) {
+	machines := map[string]*domain.Machine{
+		"saruman": {ID: "saruman", Name: "Saruman Server", MAC: "AA:BB:CC:DD:EE:FF", Broadcast: "192.168.1.255"},
+	}
+	sender := newMockWoLPacketSender()
+	useCase := NewWoLUseCase(newMockMachineRepository(machines), sender, newTestLogger(), newTestMetrics())
+
+	if err := useCase.SendWakePacketVia(context.Background(), "saruman", "10.0.0.255"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(sender.sendPackets) != 1 || sender.sendPackets[0].broadcast != "10.0.0.255" {
+		t.Errorf("Expected packet sent via 10.0.0.255, got %+v", sender.sendPackets)
+	}
+}
+
+func TestSendUnlistedWakePacket(t *testing.T) {
+	sender := newMockWoLPacketSender()
+	useCase := NewWoLUseCase(newMockMachineRepository(map[string]*domain.Machine{}), sender, newTestLogger(), newTestMetrics())
+
+	if err := useCase.SendUnlistedWakePacket(context.Background(), "aa-bb-cc-dd-ee-01", "192.168.1.255"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(sender.sendPackets) != 1 || sender.sendPackets[0].mac != "aa-bb-cc-dd-ee-01" {
+		t.Errorf("Expected packet sent to aa-bb-cc-dd-ee-01, got %+v", sender.sendPackets)
+	}
+}
+
+func TestSendUnlistedWakePacket_InvalidAddress(t *testing.T) {
+	tests := []struct {
+		name      string
+		mac       string
+		broadcast string
+		wantErr   string
+	}{
+		{"invalid MAC", "not-a-mac", "192.168.1.255", "invalid MAC address"},
+		{"invalid broadcast", "AA:BB:CC:DD:EE:FF", "", "invalid broadcast address"},
+		{"IPv6 broadcast", "AA:BB:CC:DD:EE:FF", "ff02::1", "invalid broadcast address"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sender := newMockWoLPacketSender()
+			useCase := NewWoLUseCase(newMockMachineRepository(map[string]*domain.Machine{}), sender, newTestLogger(), newTestMetrics())
+
+			err := useCase.SendUnlistedWakePacket(context.Background(), tt.mac, tt.broadcast)
+			if err == nil || !contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+			if sender.callCount != 0 {
+				t.Errorf("Expected SendMagicPacket not to be called, got %d calls", sender.callCount)
+			}
+		})
+	}
+}
+
 func TestListMachines_Success(t *testing.T) {
 	// Arrange
 	machines := map[string]*domain.Machine{
@@ -371,6 +439,134 @@ func TestNewWoLUseCase(t *testing.T) {
 	}
 }
 
+type recordingPublisher struct {
+	events []domain.Event
+}
+
+func (p *recordingPublisher) Publish(event domain.Event) {
+	p.events = append(p.events, event)
+}
+
+func TestSendWakePacket_PublishesEvents(t *testing.T) {
+	repo := newMockMachineRepository(map[string]*domain.Machine{
+		"saruman": {ID: "saruman", Name: "Saruman", MAC: "aa-bb-cc-dd-ee-ff", Broadcast: "192.168.1.255"},
+	})
+	sender := newMockWoLPacketSender()
+	events := &recordingPublisher{}
+	useCase := NewWoLUseCase(repo, sender, newTestLogger(), newTestMetrics()).WithEvents(events)
+
+	if err := useCase.SendWakePacket(context.Background(), "saruman"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	sender.sendError = errors.New("network error")
+	sender.sendErrorCount = 1
+	if err := useCase.SendWakePacket(context.Background(), "saruman"); err == nil {
+		t.Fatal("Expected error, got nil")
+	}
+
+	if err := useCase.SendWakePacket(context.Background(), "nonexistent"); err == nil {
+		t.Fatal("Expected error, got nil")
+	}
+
+	want := []string{domain.EventWakeRequested, domain.EventWakeSent, domain.EventWakeRequested, domain.EventWakeFailed}
+	if len(events.events) != len(want) {
+		t.Fatalf("Expected %d events, got %+v", len(want), events.events)
+	}
+	for i, event := range events.events {
+		if event.Type != want[i] {
+			t.Errorf("Event %d: expected type %s, got %s", i, want[i], event.Type)
+		}
+		if event.MachineID != "saruman" || event.MAC != "AA:BB:CC:DD:EE:FF" || event.Time.IsZero() {
+			t.Errorf("Event %d: unexpected %+v", i, event)
+		}
+	}
+}
+
+func TestSendWakePacket_RecordsMetricsPerMachine(t *testing.T) {
+	saruman := &domain.Machine{ID: "saruman", Name: "Saruman", MAC: "aa-bb-cc-dd-ee-ff", Broadcast: "192.168.1.255"}
+	repo := newMockMachineRepository(map[string]*domain.Machine{"saruman": saruman})
+	sender := newMockWoLPacketSender()
+	metrics := newTestMetrics()
+	metrics.SetConfiguredMachines([]*domain.Machine{saruman})
+	useCase := NewWoLUseCase(repo, sender, newTestLogger(), metrics)
+
+	_ = useCase.SendWakePacket(context.Background(), "saruman")
+	sender.sendError = errors.New("network error")
+	sender.sendErrorCount = 1
+	_ = useCase.SendWakePacket(context.Background(), "saruman")
+	_ = useCase.SendUnlistedWakePacket(context.Background(), "11:22:33:44:55:66", "192.168.1.255")
+	_ = useCase.SendWakePacket(context.Background(), "nonexistent")
+
+	tests := []struct {
+		machineID, result string
+		want              float64
+	}{
+		{"saruman", infrastructure.WoLResultSent, 1},
+		{"saruman", infrastructure.WoLResultFailed, 1},
+		{infrastructure.MachineLabelOther, infrastructure.WoLResultSent, 1},
+	}
+	for _, tt := range tests {
+		if got := testutil.ToFloat64(metrics.WoLPackets.WithLabelValues(tt.machineID, tt.result)); got != tt.want {
+			t.Errorf("%s/%s: expected %v, got %v", tt.machineID, tt.result, tt.want, got)
+		}
+	}
+	if got := testutil.ToFloat64(metrics.MachineNotFound); got != 1 {
+		t.Errorf("Expected 1 machine not found, got %v", got)
+	}
+}
+
+func TestSendWakePacket_RecordsSpans(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	repo := newMockMachineRepository(map[string]*domain.Machine{
+		"saruman": {ID: "saruman", Name: "Saruman", MAC: "aa-bb-cc-dd-ee-ff", Broadcast: "192.168.1.255"},
+	})
+	sender := infrastructure.NewWoLPacketSenderWithDialer(func(_, _ string) (net.PacketConn, error) {
+		return nil, errors.New("network unreachable")
+	})
+	useCase := NewWoLUseCase(repo, sender, newTestLogger(), newTestMetrics())
+
+	if err := useCase.SendWakePacketVia(context.Background(), "saruman", "10.0.0.255"); err == nil {
+		t.Fatal("Expected error, got nil")
+	}
+
+	spans := exporter.GetSpans()
+	if len(spans) != 2 {
+		t.Fatalf("Expected 2 spans, got %d", len(spans))
+	}
+	packet, wake := spans[0], spans[1]
+	if wake.Name != "WoLUseCase.SendWakePacket" || packet.Name != "WoLPacketSender.SendMagicPacket" {
+		t.Fatalf("Unexpected spans %s and %s", wake.Name, packet.Name)
+	}
+	if packet.Parent.SpanID() != wake.SpanContext.SpanID() {
+		t.Error("Expected the packet span to be a child of the wake span")
+	}
+	if wake.Status.Code != codes.Error || packet.Status.Code != codes.Error {
+		t.Errorf("Expected both spans to fail, got %v and %v", wake.Status.Code, packet.Status.Code)
+	}
+
+	attrs := make(map[attribute.Key]string)
+	for _, attr := range wake.Attributes {
+		attrs[attr.Key] = attr.Value.AsString()
+	}
+	want := map[attribute.Key]string{
+		infrastructure.AttrMachineID:        "saruman",
+		infrastructure.AttrMachineName:      "Saruman",
+		infrastructure.AttrMachineMAC:       "AA:BB:CC:DD:EE:FF",
+		infrastructure.AttrMachineBroadcast: "10.0.0.255",
+	}
+	for key, value := range want {
+		if attrs[key] != value {
+			t.Errorf("Attribute %s: expected %q, got %q", key, value, attrs[key])
+		}
+	}
+}
+
 // Helper functions
 func contains(s, substr string) bool {
 	for i := 0; i <= len(s)-len(substr); i++ {
@@ -386,7 +582,6 @@ func newTestLogger() *infrastructure.Logger {
 }
 
 func newTestMetrics() *infrastructure.Metrics {
-	prometheus.DefaultRegisterer = prometheus.NewRegistry()
 	metrics, _ := infrastructure.NewMetrics()
 	return metrics
 }
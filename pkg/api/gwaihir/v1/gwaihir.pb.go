@@ -0,0 +1,589 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
+// source: gwaihir/v1/gwaihir.proto
+
+package gwaihirv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// MachineState is the wake state of a machine.
+type MachineState int32
+
+const (
+	MachineState_MACHINE_STATE_UNSPECIFIED MachineState = 0
+	// A magic packet is about to be sent.
+	MachineState_MACHINE_STATE_WAKE_REQUESTED MachineState = 1
+	// A magic packet was sent.
+	MachineState_MACHINE_STATE_WAKE_SENT MachineState = 2
+	// The magic packet could not be sent.
+	MachineState_MACHINE_STATE_WAKE_FAILED MachineState = 3
+)
+
+// Enum value maps for MachineState.
+var (
+	MachineState_name = map[int32]string{
+		0: "MACHINE_STATE_UNSPECIFIED",
+		1: "MACHINE_STATE_WAKE_REQUESTED",
+		2: "MACHINE_STATE_WAKE_SENT",
+		3: "MACHINE_STATE_WAKE_FAILED",
+	}
+	MachineState_value = map[string]int32{
+		"MACHINE_STATE_UNSPECIFIED":    0,
+		"MACHINE_STATE_WAKE_REQUESTED": 1,
+		"MACHINE_STATE_WAKE_SENT":      2,
+		"MACHINE_STATE_WAKE_FAILED":    3,
+	}
+)
+
+func (x MachineState) Enum() *MachineState {
+	p := new(MachineState)
+	*p = x
+	return p
+}
+
+func (x MachineState) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (MachineState) Descriptor() protoreflect.EnumDescriptor {
+	return file_gwaihir_v1_gwaihir_proto_enumTypes[0].Descriptor()
+}
+
+func (MachineState) Type() protoreflect.EnumType {
+	return &file_gwaihir_v1_gwaihir_proto_enumTypes[0]
+}
+
+func (x MachineState) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use MachineState.Descriptor instead.
+func (MachineState) EnumDescriptor() ([]byte, []int) {
+	return file_gwaihir_v1_gwaihir_proto_rawDescGZIP(), []int{0}
+}
+
+// Machine is a machine in the allowlist.
+type Machine struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Id        string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name      string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Mac       string                 `protobuf:"bytes,3,opt,name=mac,proto3" json:"mac,omitempty"`
+	Broadcast string                 `protobuf:"bytes,4,opt,name=broadcast,proto3" json:"broadcast,omitempty"`
+	// Source is the machine import source the machine came from, if any.
+	Source        string `protobuf:"bytes,5,opt,name=source,proto3" json:"source,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Machine) Reset() {
+	*x = Machine{}
+	mi := &file_gwaihir_v1_gwaihir_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Machine) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Machine) ProtoMessage() {}
+
+func (x *Machine) ProtoReflect() protoreflect.Message {
+	mi := &file_gwaihir_v1_gwaihir_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Machine.ProtoReflect.Descriptor instead.
+func (*Machine) Descriptor() ([]byte, []int) {
+	return file_gwaihir_v1_gwaihir_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Machine) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Machine) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Machine) GetMac() string {
+	if x != nil {
+		return x.Mac
+	}
+	return ""
+}
+
+func (x *Machine) GetBroadcast() string {
+	if x != nil {
+		return x.Broadcast
+	}
+	return ""
+}
+
+func (x *Machine) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+type WakeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MachineId     string                 `protobuf:"bytes,1,opt,name=machine_id,json=machineId,proto3" json:"machine_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WakeRequest) Reset() {
+	*x = WakeRequest{}
+	mi := &file_gwaihir_v1_gwaihir_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WakeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WakeRequest) ProtoMessage() {}
+
+func (x *WakeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gwaihir_v1_gwaihir_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WakeRequest.ProtoReflect.Descriptor instead.
+func (*WakeRequest) Descriptor() ([]byte, []int) {
+	return file_gwaihir_v1_gwaihir_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *WakeRequest) GetMachineId() string {
+	if x != nil {
+		return x.MachineId
+	}
+	return ""
+}
+
+type WakeResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MachineId     string                 `protobuf:"bytes,1,opt,name=machine_id,json=machineId,proto3" json:"machine_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WakeResponse) Reset() {
+	*x = WakeResponse{}
+	mi := &file_gwaihir_v1_gwaihir_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WakeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WakeResponse) ProtoMessage() {}
+
+func (x *WakeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_gwaihir_v1_gwaihir_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WakeResponse.ProtoReflect.Descriptor instead.
+func (*WakeResponse) Descriptor() ([]byte, []int) {
+	return file_gwaihir_v1_gwaihir_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *WakeResponse) GetMachineId() string {
+	if x != nil {
+		return x.MachineId
+	}
+	return ""
+}
+
+type ListMachinesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListMachinesRequest) Reset() {
+	*x = ListMachinesRequest{}
+	mi := &file_gwaihir_v1_gwaihir_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListMachinesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListMachinesRequest) ProtoMessage() {}
+
+func (x *ListMachinesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gwaihir_v1_gwaihir_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListMachinesRequest.ProtoReflect.Descriptor instead.
+func (*ListMachinesRequest) Descriptor() ([]byte, []int) {
+	return file_gwaihir_v1_gwaihir_proto_rawDescGZIP(), []int{3}
+}
+
+type ListMachinesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Machines      []*Machine             `protobuf:"bytes,1,rep,name=machines,proto3" json:"machines,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListMachinesResponse) Reset() {
+	*x = ListMachinesResponse{}
+	mi := &file_gwaihir_v1_gwaihir_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListMachinesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListMachinesResponse) ProtoMessage() {}
+
+func (x *ListMachinesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_gwaihir_v1_gwaihir_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListMachinesResponse.ProtoReflect.Descriptor instead.
+func (*ListMachinesResponse) Descriptor() ([]byte, []int) {
+	return file_gwaihir_v1_gwaihir_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ListMachinesResponse) GetMachines() []*Machine {
+	if x != nil {
+		return x.Machines
+	}
+	return nil
+}
+
+type GetMachineRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MachineId     string                 `protobuf:"bytes,1,opt,name=machine_id,json=machineId,proto3" json:"machine_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetMachineRequest) Reset() {
+	*x = GetMachineRequest{}
+	mi := &file_gwaihir_v1_gwaihir_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetMachineRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetMachineRequest) ProtoMessage() {}
+
+func (x *GetMachineRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gwaihir_v1_gwaihir_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetMachineRequest.ProtoReflect.Descriptor instead.
+func (*GetMachineRequest) Descriptor() ([]byte, []int) {
+	return file_gwaihir_v1_gwaihir_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *GetMachineRequest) GetMachineId() string {
+	if x != nil {
+		return x.MachineId
+	}
+	return ""
+}
+
+type WatchMachineStateRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// MachineIds restricts the stream to these machines. Empty streams every machine.
+	MachineIds    []string `protobuf:"bytes,1,rep,name=machine_ids,json=machineIds,proto3" json:"machine_ids,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchMachineStateRequest) Reset() {
+	*x = WatchMachineStateRequest{}
+	mi := &file_gwaihir_v1_gwaihir_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchMachineStateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchMachineStateRequest) ProtoMessage() {}
+
+func (x *WatchMachineStateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gwaihir_v1_gwaihir_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchMachineStateRequest.ProtoReflect.Descriptor instead.
+func (*WatchMachineStateRequest) Descriptor() ([]byte, []int) {
+	return file_gwaihir_v1_gwaihir_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *WatchMachineStateRequest) GetMachineIds() []string {
+	if x != nil {
+		return x.MachineIds
+	}
+	return nil
+}
+
+type MachineStateEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MachineId     string                 `protobuf:"bytes,1,opt,name=machine_id,json=machineId,proto3" json:"machine_id,omitempty"`
+	State         MachineState           `protobuf:"varint,2,opt,name=state,proto3,enum=gwaihir.v1.MachineState" json:"state,omitempty"`
+	Time          *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=time,proto3" json:"time,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MachineStateEvent) Reset() {
+	*x = MachineStateEvent{}
+	mi := &file_gwaihir_v1_gwaihir_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MachineStateEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MachineStateEvent) ProtoMessage() {}
+
+func (x *MachineStateEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_gwaihir_v1_gwaihir_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MachineStateEvent.ProtoReflect.Descriptor instead.
+func (*MachineStateEvent) Descriptor() ([]byte, []int) {
+	return file_gwaihir_v1_gwaihir_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *MachineStateEvent) GetMachineId() string {
+	if x != nil {
+		return x.MachineId
+	}
+	return ""
+}
+
+func (x *MachineStateEvent) GetState() MachineState {
+	if x != nil {
+		return x.State
+	}
+	return MachineState_MACHINE_STATE_UNSPECIFIED
+}
+
+func (x *MachineStateEvent) GetTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Time
+	}
+	return nil
+}
+
+var File_gwaihir_v1_gwaihir_proto protoreflect.FileDescriptor
+
+const file_gwaihir_v1_gwaihir_proto_rawDesc = "" +
+	"\n" +
+	"\x18gwaihir/v1/gwaihir.proto\x12\n" +
+	"gwaihir.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"u\n" +
+	"\aMachine\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x10\n" +
+	"\x03mac\x18\x03 \x01(\tR\x03mac\x12\x1c\n" +
+	"\tbroadcast\x18\x04 \x01(\tR\tbroadcast\x12\x16\n" +
+	"\x06source\x18\x05 \x01(\tR\x06source\",\n" +
+	"\vWakeRequest\x12\x1d\n" +
+	"\n" +
+	"machine_id\x18\x01 \x01(\tR\tmachineId\"-\n" +
+	"\fWakeResponse\x12\x1d\n" +
+	"\n" +
+	"machine_id\x18\x01 \x01(\tR\tmachineId\"\x15\n" +
+	"\x13ListMachinesRequest\"G\n" +
+	"\x14ListMachinesResponse\x12/\n" +
+	"\bmachines\x18\x01 \x03(\v2\x13.gwaihir.v1.MachineR\bmachines\"2\n" +
+	"\x11GetMachineRequest\x12\x1d\n" +
+	"\n" +
+	"machine_id\x18\x01 \x01(\tR\tmachineId\";\n" +
+	"\x18WatchMachineStateRequest\x12\x1f\n" +
+	"\vmachine_ids\x18\x01 \x03(\tR\n" +
+	"machineIds\"\x92\x01\n" +
+	"\x11MachineStateEvent\x12\x1d\n" +
+	"\n" +
+	"machine_id\x18\x01 \x01(\tR\tmachineId\x12.\n" +
+	"\x05state\x18\x02 \x01(\x0e2\x18.gwaihir.v1.MachineStateR\x05state\x12.\n" +
+	"\x04time\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\x04time*\x8b\x01\n" +
+	"\fMachineState\x12\x1d\n" +
+	"\x19MACHINE_STATE_UNSPECIFIED\x10\x00\x12 \n" +
+	"\x1cMACHINE_STATE_WAKE_REQUESTED\x10\x01\x12\x1b\n" +
+	"\x17MACHINE_STATE_WAKE_SENT\x10\x02\x12\x1d\n" +
+	"\x19MACHINE_STATE_WAKE_FAILED\x10\x032\xb8\x02\n" +
+	"\n" +
+	"WoLService\x129\n" +
+	"\x04Wake\x12\x17.gwaihir.v1.WakeRequest\x1a\x18.gwaihir.v1.WakeResponse\x12Q\n" +
+	"\fListMachines\x12\x1f.gwaihir.v1.ListMachinesRequest\x1a .gwaihir.v1.ListMachinesResponse\x12@\n" +
+	"\n" +
+	"GetMachine\x12\x1d.gwaihir.v1.GetMachineRequest\x1a\x13.gwaihir.v1.Machine\x12Z\n" +
+	"\x11WatchMachineState\x12$.gwaihir.v1.WatchMachineStateRequest\x1a\x1d.gwaihir.v1.MachineStateEvent0\x01B?Z=github.com/josimar-silva/gwaihir/pkg/api/gwaihir/v1;gwaihirv1b\x06proto3"
+
+var (
+	file_gwaihir_v1_gwaihir_proto_rawDescOnce sync.Once
+	file_gwaihir_v1_gwaihir_proto_rawDescData []byte
+)
+
+func file_gwaihir_v1_gwaihir_proto_rawDescGZIP() []byte {
+	file_gwaihir_v1_gwaihir_proto_rawDescOnce.Do(func() {
+		file_gwaihir_v1_gwaihir_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_gwaihir_v1_gwaihir_proto_rawDesc), len(file_gwaihir_v1_gwaihir_proto_rawDesc)))
+	})
+	return file_gwaihir_v1_gwaihir_proto_rawDescData
+}
+
+var file_gwaihir_v1_gwaihir_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_gwaihir_v1_gwaihir_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
+var file_gwaihir_v1_gwaihir_proto_goTypes = []any{
+	(MachineState)(0),                // 0: gwaihir.v1.MachineState
+	(*Machine)(nil),                  // 1: gwaihir.v1.Machine
+	(*WakeRequest)(nil),              // 2: gwaihir.v1.WakeRequest
+	(*WakeResponse)(nil),             // 3: gwaihir.v1.WakeResponse
+	(*ListMachinesRequest)(nil),      // 4: gwaihir.v1.ListMachinesRequest
+	(*ListMachinesResponse)(nil),     // 5: gwaihir.v1.ListMachinesResponse
+	(*GetMachineRequest)(nil),        // 6: gwaihir.v1.GetMachineRequest
+	(*WatchMachineStateRequest)(nil), // 7: gwaihir.v1.WatchMachineStateRequest
+	(*MachineStateEvent)(nil),        // 8: gwaihir.v1.MachineStateEvent
+	(*timestamppb.Timestamp)(nil),    // 9: google.protobuf.Timestamp
+}
+var file_gwaihir_v1_gwaihir_proto_depIdxs = []int32{
+	1, // 0: gwaihir.v1.ListMachinesResponse.machines:type_name -> gwaihir.v1.Machine
+	0, // 1: gwaihir.v1.MachineStateEvent.state:type_name -> gwaihir.v1.MachineState
+	9, // 2: gwaihir.v1.MachineStateEvent.time:type_name -> google.protobuf.Timestamp
+	2, // 3: gwaihir.v1.WoLService.Wake:input_type -> gwaihir.v1.WakeRequest
+	4, // 4: gwaihir.v1.WoLService.ListMachines:input_type -> gwaihir.v1.ListMachinesRequest
+	6, // 5: gwaihir.v1.WoLService.GetMachine:input_type -> gwaihir.v1.GetMachineRequest
+	7, // 6: gwaihir.v1.WoLService.WatchMachineState:input_type -> gwaihir.v1.WatchMachineStateRequest
+	3, // 7: gwaihir.v1.WoLService.Wake:output_type -> gwaihir.v1.WakeResponse
+	5, // 8: gwaihir.v1.WoLService.ListMachines:output_type -> gwaihir.v1.ListMachinesResponse
+	1, // 9: gwaihir.v1.WoLService.GetMachine:output_type -> gwaihir.v1.Machine
+	8, // 10: gwaihir.v1.WoLService.WatchMachineState:output_type -> gwaihir.v1.MachineStateEvent
+	7, // [7:11] is the sub-list for method output_type
+	3, // [3:7] is the sub-list for method input_type
+	3, // [3:3] is the sub-list for extension type_name
+	3, // [3:3] is the sub-list for extension extendee
+	0, // [0:3] is the sub-list for field type_name
+}
+
+func init() { file_gwaihir_v1_gwaihir_proto_init() }
+func file_gwaihir_v1_gwaihir_proto_init() {
+	if File_gwaihir_v1_gwaihir_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_gwaihir_v1_gwaihir_proto_rawDesc), len(file_gwaihir_v1_gwaihir_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   8,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_gwaihir_v1_gwaihir_proto_goTypes,
+		DependencyIndexes: file_gwaihir_v1_gwaihir_proto_depIdxs,
+		EnumInfos:         file_gwaihir_v1_gwaihir_proto_enumTypes,
+		MessageInfos:      file_gwaihir_v1_gwaihir_proto_msgTypes,
+	}.Build()
+	File_gwaihir_v1_gwaihir_proto = out.File
+	file_gwaihir_v1_gwaihir_proto_goTypes = nil
+	file_gwaihir_v1_gwaihir_proto_depIdxs = nil
+}
@@ -0,0 +1,263 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: gwaihir/v1/gwaihir.proto
+
+package gwaihirv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	WoLService_Wake_FullMethodName              = "/gwaihir.v1.WoLService/Wake"
+	WoLService_ListMachines_FullMethodName      = "/gwaihir.v1.WoLService/ListMachines"
+	WoLService_GetMachine_FullMethodName        = "/gwaihir.v1.WoLService/GetMachine"
+	WoLService_WatchMachineState_FullMethodName = "/gwaihir.v1.WoLService/WatchMachineState"
+)
+
+// WoLServiceClient is the client API for WoLService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// WoLService sends Wake-on-LAN packets to the machines in the allowlist.
+//
+// Requests are authenticated with the API key in the x-api-key metadata when
+// authentication.api_key is configured. Errors carry a google.rpc.ErrorInfo detail
+// whose reason is the error code also used by the HTTP API, e.g. "machine_not_allowed".
+type WoLServiceClient interface {
+	// Wake sends a Wake-on-LAN packet to an allowlisted machine.
+	Wake(ctx context.Context, in *WakeRequest, opts ...grpc.CallOption) (*WakeResponse, error)
+	// ListMachines returns the machines in the allowlist.
+	ListMachines(ctx context.Context, in *ListMachinesRequest, opts ...grpc.CallOption) (*ListMachinesResponse, error)
+	// GetMachine returns a machine of the allowlist.
+	GetMachine(ctx context.Context, in *GetMachineRequest, opts ...grpc.CallOption) (*Machine, error)
+	// WatchMachineState streams the wake events of machines as they happen, whether
+	// the wake was requested over gRPC, HTTP or another channel. Events published
+	// before the call are not replayed.
+	WatchMachineState(ctx context.Context, in *WatchMachineStateRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[MachineStateEvent], error)
+}
+
+type woLServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewWoLServiceClient(cc grpc.ClientConnInterface) WoLServiceClient {
+	return &woLServiceClient{cc}
+}
+
+func (c *woLServiceClient) Wake(ctx context.Context, in *WakeRequest, opts ...grpc.CallOption) (*WakeResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(WakeResponse)
+	err := c.cc.Invoke(ctx, WoLService_Wake_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *woLServiceClient) ListMachines(ctx context.Context, in *ListMachinesRequest, opts ...grpc.CallOption) (*ListMachinesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListMachinesResponse)
+	err := c.cc.Invoke(ctx, WoLService_ListMachines_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *woLServiceClient) GetMachine(ctx context.Context, in *GetMachineRequest, opts ...grpc.CallOption) (*Machine, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Machine)
+	err := c.cc.Invoke(ctx, WoLService_GetMachine_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *woLServiceClient) WatchMachineState(ctx context.Context, in *WatchMachineStateRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[MachineStateEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &WoLService_ServiceDesc.Streams[0], WoLService_WatchMachineState_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchMachineStateRequest, MachineStateEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type WoLService_WatchMachineStateClient = grpc.ServerStreamingClient[MachineStateEvent]
+
+// WoLServiceServer is the server API for WoLService service.
+// All implementations must embed UnimplementedWoLServiceServer
+// for forward compatibility.
+//
+// WoLService sends Wake-on-LAN packets to the machines in the allowlist.
+//
+// Requests are authenticated with the API key in the x-api-key metadata when
+// authentication.api_key is configured. Errors carry a google.rpc.ErrorInfo detail
+// whose reason is the error code also used by the HTTP API, e.g. "machine_not_allowed".
+type WoLServiceServer interface {
+	// Wake sends a Wake-on-LAN packet to an allowlisted machine.
+	Wake(context.Context, *WakeRequest) (*WakeResponse, error)
+	// ListMachines returns the machines in the allowlist.
+	ListMachines(context.Context, *ListMachinesRequest) (*ListMachinesResponse, error)
+	// GetMachine returns a machine of the allowlist.
+	GetMachine(context.Context, *GetMachineRequest) (*Machine, error)
+	// WatchMachineState streams the wake events of machines as they happen, whether
+	// the wake was requested over gRPC, HTTP or another channel. Events published
+	// before the call are not replayed.
+	WatchMachineState(*WatchMachineStateRequest, grpc.ServerStreamingServer[MachineStateEvent]) error
+	mustEmbedUnimplementedWoLServiceServer()
+}
+
+// UnimplementedWoLServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedWoLServiceServer struct{}
+
+func (UnimplementedWoLServiceServer) Wake(context.Context, *WakeRequest) (*WakeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Wake not implemented")
+}
+func (UnimplementedWoLServiceServer) ListMachines(context.Context, *ListMachinesRequest) (*ListMachinesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListMachines not implemented")
+}
+func (UnimplementedWoLServiceServer) GetMachine(context.Context, *GetMachineRequest) (*Machine, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetMachine not implemented")
+}
+func (UnimplementedWoLServiceServer) WatchMachineState(*WatchMachineStateRequest, grpc.ServerStreamingServer[MachineStateEvent]) error {
+	return status.Errorf(codes.Unimplemented, "method WatchMachineState not implemented")
+}
+func (UnimplementedWoLServiceServer) mustEmbedUnimplementedWoLServiceServer() {}
+func (UnimplementedWoLServiceServer) testEmbeddedByValue()                    {}
+
+// UnsafeWoLServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to WoLServiceServer will
+// result in compilation errors.
+type UnsafeWoLServiceServer interface {
+	mustEmbedUnimplementedWoLServiceServer()
+}
+
+func RegisterWoLServiceServer(s grpc.ServiceRegistrar, srv WoLServiceServer) {
+	// If the following call pancis, it indicates UnimplementedWoLServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&WoLService_ServiceDesc, srv)
+}
+
+func _WoLService_Wake_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(WakeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WoLServiceServer).Wake(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WoLService_Wake_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WoLServiceServer).Wake(ctx, req.(*WakeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WoLService_ListMachines_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListMachinesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WoLServiceServer).ListMachines(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WoLService_ListMachines_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WoLServiceServer).ListMachines(ctx, req.(*ListMachinesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WoLService_GetMachine_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetMachineRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WoLServiceServer).GetMachine(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WoLService_GetMachine_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WoLServiceServer).GetMachine(ctx, req.(*GetMachineRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WoLService_WatchMachineState_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchMachineStateRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(WoLServiceServer).WatchMachineState(m, &grpc.GenericServerStream[WatchMachineStateRequest, MachineStateEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type WoLService_WatchMachineStateServer = grpc.ServerStreamingServer[MachineStateEvent]
+
+// WoLService_ServiceDesc is the grpc.ServiceDesc for WoLService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var WoLService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "gwaihir.v1.WoLService",
+	HandlerType: (*WoLServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Wake",
+			Handler:    _WoLService_Wake_Handler,
+		},
+		{
+			MethodName: "ListMachines",
+			Handler:    _WoLService_ListMachines_Handler,
+		},
+		{
+			MethodName: "GetMachine",
+			Handler:    _WoLService_GetMachine_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchMachineState",
+			Handler:       _WoLService_WatchMachineState_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "gwaihir/v1/gwaihir.proto",
+}
@@ -0,0 +1,332 @@
+// Package client provides a typed Go client for the Gwaihir HTTP API.
+//
+//	c, err := client.New("https://gwaihir.home.lan")
+//	if err != nil {
+//		return err
+//	}
+//	c.WithAPIKey(os.Getenv("GWAIHIR_API_KEY"))
+//
+//	if err := c.Wake(ctx, "saruman"); errors.Is(err, client.ErrMachineNotFound) {
+//		...
+//	}
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Defaults used by New.
+const (
+	DefaultTimeout    = 10 * time.Second
+	DefaultMaxRetries = 2
+	DefaultBackoff    = 200 * time.Millisecond
+)
+
+// maxBackoff caps the delay between retries.
+const maxBackoff = 5 * time.Second
+
+// healthPath is the path of the full health check.
+const healthPath = "/api/v1/health"
+
+// maxResponseSize bounds the response bodies read from the server.
+const maxResponseSize = 1 << 20
+
+// Machine is a machine in the server's allowlist.
+type Machine struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	MAC       string `json:"mac"`
+	Broadcast string `json:"broadcast"`
+	Source    string `json:"source,omitempty"`
+}
+
+// Health is the response of the full health check.
+type Health struct {
+	Status             string            `json:"status"`
+	Version            string            `json:"version"`
+	BuildTime          string            `json:"build_time"`
+	GitCommit          string            `json:"git_commit"`
+	Timestamp          string            `json:"timestamp"`
+	UptimeSeconds      int64             `json:"uptime_seconds"`
+	ConfiguredMachines int               `json:"configured_machines"`
+	LastConfigReload   string            `json:"last_config_reload,omitempty"`
+	Sources            []SourceHealth    `json:"sources,omitempty"`
+	Checks             map[string]string `json:"checks"`
+}
+
+// SourceHealth reports the state of a machine import source.
+type SourceHealth struct {
+	Name        string `json:"name"`
+	Format      string `json:"format"`
+	Machines    int    `json:"machines"`
+	LastUpdated string `json:"last_updated,omitempty"`
+}
+
+// VersionInfo is the version of the server.
+type VersionInfo struct {
+	Version   string `json:"version"`
+	BuildTime string `json:"build_time"`
+	GitCommit string `json:"git_commit"`
+}
+
+type wakeRequest struct {
+	MachineID string `json:"machine_id"`
+}
+
+// problemDetails is an error response (RFC 7807). Servers before problem details
+// were introduced reported errors in the error member instead.
+type problemDetails struct {
+	Title  string `json:"title"`
+	Detail string `json:"detail"`
+	Code   string `json:"code"`
+	Error  string `json:"error"`
+}
+
+// Client calls the Gwaihir HTTP API. It is safe for concurrent use once configured.
+type Client struct {
+	baseURL    string
+	apiKey     string
+	userAgent  string
+	httpClient *http.Client
+	timeout    time.Duration
+	maxRetries int
+	backoff    time.Duration
+}
+
+// New creates a client for the server at baseURL, e.g. "https://gwaihir.home.lan".
+func New(baseURL string) (*Client, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("server URL must be an absolute http or https URL, got '%s'", baseURL)
+	}
+
+	return &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		userAgent:  "gwaihir-client",
+		httpClient: &http.Client{},
+		timeout:    DefaultTimeout,
+		maxRetries: DefaultMaxRetries,
+		backoff:    DefaultBackoff,
+	}, nil
+}
+
+// WithAPIKey sets the key sent in the X-API-Key header.
+func (c *Client) WithAPIKey(apiKey string) *Client {
+	c.apiKey = apiKey
+	return c
+}
+
+// WithHTTPClient sets the HTTP client used for requests.
+func (c *Client) WithHTTPClient(httpClient *http.Client) *Client {
+	c.httpClient = httpClient
+	return c
+}
+
+// WithTimeout sets the time limit of each attempt, including reading the response.
+// Zero leaves only the deadline of the request context.
+func (c *Client) WithTimeout(timeout time.Duration) *Client {
+	c.timeout = timeout
+	return c
+}
+
+// WithRetries sets how many times a read failing with a 5xx status is retried.
+// The delay starts at backoff and doubles after each attempt. Wake is never retried.
+func (c *Client) WithRetries(maxRetries int, backoff time.Duration) *Client {
+	c.maxRetries = maxRetries
+	c.backoff = backoff
+	return c
+}
+
+// WithUserAgent sets the User-Agent header.
+func (c *Client) WithUserAgent(userAgent string) *Client {
+	c.userAgent = userAgent
+	return c
+}
+
+// Wake asks the server to send a Wake-on-LAN packet to the machine. It returns
+// ErrMachineNotFound if the machine is not in the allowlist. It is not retried, as a
+// 5xx response does not prove that no packet was sent, and a retry would send another
+// packet and record another audit record, event and webhook delivery.
+func (c *Client) Wake(ctx context.Context, machineID string) error {
+	return c.do(ctx, http.MethodPost, "/api/v1/wol", wakeRequest{MachineID: machineID}, nil, ErrMachineNotFound)
+}
+
+// ListMachines returns the machines in the allowlist.
+func (c *Client) ListMachines(ctx context.Context) ([]Machine, error) {
+	machines := []Machine{}
+	if err := c.do(ctx, http.MethodGet, "/api/v1/machines", nil, &machines, nil); err != nil {
+		return nil, err
+	}
+	return machines, nil
+}
+
+// GetMachine returns a machine by ID. It returns ErrMachineNotFound if the machine
+// is not in the allowlist.
+func (c *Client) GetMachine(ctx context.Context, machineID string) (*Machine, error) {
+	var machine Machine
+	if err := c.do(ctx, http.MethodGet, "/api/v1/machines/"+url.PathEscape(machineID), nil, &machine, ErrMachineNotFound); err != nil {
+		return nil, err
+	}
+	return &machine, nil
+}
+
+// Health returns the full health check. When the server reports itself unhealthy,
+// both the health report and an error matching ErrUnhealthy are returned.
+func (c *Client) Health(ctx context.Context) (*Health, error) {
+	var health Health
+	err := c.do(ctx, http.MethodGet, healthPath, nil, &health, nil)
+	if err == nil {
+		return &health, nil
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusServiceUnavailable && health.Status != "" {
+		apiErr.sentinel = ErrUnhealthy
+		return &health, apiErr
+	}
+	return nil, err
+}
+
+// Version returns the version of the server.
+func (c *Client) Version(ctx context.Context) (*VersionInfo, error) {
+	var version VersionInfo
+	if err := c.do(ctx, http.MethodGet, "/api/v1/version", nil, &version, nil); err != nil {
+		return nil, err
+	}
+	return &version, nil
+}
+
+// do sends the request, retrying retryable failures, and decodes the response into out.
+// A 404 response is reported with notFound when it is set.
+func (c *Client) do(ctx context.Context, method, path string, body, out any, notFound error) error {
+	var payload []byte
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+		payload = data
+	}
+
+	delay := c.backoff
+	for attempt := 0; ; attempt++ {
+		err := c.send(ctx, method, path, payload, out, notFound)
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) || !retryable(method, path, apiErr.StatusCode) || attempt >= c.maxRetries {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%w (giving up after %d attempts: %w)", err, attempt+1, ctx.Err())
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, maxBackoff)
+	}
+}
+
+// retryable reports whether a request that failed with statusCode may be sent again.
+// Only reads are retried, and a 503 from the health check reports an unhealthy
+// server rather than a transient failure.
+func retryable(method, path string, statusCode int) bool {
+	if method != http.MethodGet || statusCode < http.StatusInternalServerError {
+		return false
+	}
+	return path != healthPath || statusCode != http.StatusServiceUnavailable
+}
+
+func (c *Client) send(ctx context.Context, method, path string, payload []byte, out any, notFound error) error {
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	var reader io.Reader
+	if payload != nil {
+		reader = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", c.userAgent)
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.apiKey != "" {
+		req.Header.Set("X-API-Key", c.apiKey)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("request to %s failed: %w", c.baseURL, err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		apiErr := responseError(resp.StatusCode, data, out)
+		if resp.StatusCode == http.StatusNotFound && notFound != nil {
+			apiErr.sentinel = notFound
+		}
+		return apiErr
+	}
+
+	if out != nil {
+		if err := json.Unmarshal(data, out); err != nil {
+			return fmt.Errorf("invalid response from server: %w", err)
+		}
+	}
+	return nil
+}
+
+// responseError builds the error for a failed response. A body that is not an error
+// response is decoded into out, as /health reports an unhealthy server that way.
+func responseError(statusCode int, data []byte, out any) *APIError {
+	apiErr := &APIError{StatusCode: statusCode, sentinel: statusSentinel(statusCode)}
+
+	var problem problemDetails
+	if err := json.Unmarshal(data, &problem); err == nil {
+		if message := firstNonEmpty(problem.Detail, problem.Title, problem.Error); message != "" {
+			apiErr.Message = message
+			apiErr.Code = problem.Code
+			return apiErr
+		}
+	}
+
+	apiErr.Message = http.StatusText(statusCode)
+	if out != nil && json.Unmarshal(data, out) == nil {
+		return apiErr
+	}
+	if message := strings.TrimSpace(string(data)); message != "" && len(message) <= 200 {
+		apiErr.Message = message
+	}
+	return apiErr
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
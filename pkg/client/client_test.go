@@ -0,0 +1,240 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/josimar-silva/gwaihir/internal/config"
+	httpdelivery "github.com/josimar-silva/gwaihir/internal/delivery/http"
+	"github.com/josimar-silva/gwaihir/internal/domain"
+	"github.com/josimar-silva/gwaihir/internal/infrastructure"
+	"github.com/josimar-silva/gwaihir/internal/repository"
+	"github.com/josimar-silva/gwaihir/internal/usecase"
+	"github.com/josimar-silva/gwaihir/pkg/client"
+)
+
+const testAPIKey = "client-test-key"
+
+type recordingSender struct {
+	sent atomic.Int32
+	err  error
+}
+
+func (s *recordingSender) SendMagicPacket(_ context.Context, _, _ string) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.sent.Add(1)
+	return nil
+}
+
+// newTestServer serves the real router with two allowlisted machines.
+func newTestServer(t *testing.T, sender domain.WoLPacketSender) *httptest.Server {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	cfg := &config.Config{
+		Authentication: config.AuthenticationConfig{APIKey: testAPIKey},
+		Machines: []config.MachineConfig{
+			{ID: "saruman", Name: "Saruman", MAC: "AA:BB:CC:DD:EE:FF", Broadcast: "192.168.1.255"},
+			{ID: "gandalf", Name: "Gandalf", MAC: "11:22:33:44:55:66", Broadcast: "192.168.1.255"},
+		},
+	}
+
+	repo, err := repository.NewInMemoryMachineRepository(cfg)
+	require.NoError(t, err)
+	metrics, err := infrastructure.NewMetrics()
+	require.NoError(t, err)
+	logger := infrastructure.NewLogger("text", "error")
+
+	handler := httpdelivery.NewHandler(usecase.NewWoLUseCase(repo, sender, logger, metrics), logger, metrics, "1.2.3", "2026-01-01", "abc123")
+	server := httptest.NewServer(httpdelivery.NewRouterWithConfig(handler, cfg))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newTestClient(t *testing.T, url string) *client.Client {
+	t.Helper()
+	c, err := client.New(url)
+	require.NoError(t, err)
+	return c.WithAPIKey(testAPIKey).WithRetries(2, time.Millisecond)
+}
+
+func TestNew_InvalidURL(t *testing.T) {
+	for _, url := range []string{"", "gwaihir.local", "ftp://gwaihir.local", "http://"} {
+		_, err := client.New(url)
+		assert.Error(t, err, url)
+	}
+}
+
+func TestClient_Wake(t *testing.T) {
+	sender := &recordingSender{}
+	server := newTestServer(t, sender)
+	c := newTestClient(t, server.URL)
+
+	require.NoError(t, c.Wake(context.Background(), "saruman"))
+	assert.Equal(t, int32(1), sender.sent.Load())
+
+	err := c.Wake(context.Background(), "sauron")
+	assert.ErrorIs(t, err, client.ErrMachineNotFound)
+
+	var apiErr *client.APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusNotFound, apiErr.StatusCode)
+	assert.Equal(t, "Machine sauron is not in the allowlist", apiErr.Message)
+	assert.Equal(t, "machine_not_allowed", apiErr.Code)
+}
+
+func TestClient_WakeSendFailureIsNotRetried(t *testing.T) {
+	server := newTestServer(t, &recordingSender{err: errors.New("network unreachable")})
+
+	var requests atomic.Int32
+	c := newTestClient(t, server.URL).WithHTTPClient(&http.Client{
+		Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			requests.Add(1)
+			return http.DefaultTransport.RoundTrip(req)
+		}),
+	})
+
+	err := c.Wake(context.Background(), "saruman")
+	var apiErr *client.APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusInternalServerError, apiErr.StatusCode)
+	assert.Equal(t, "wake_failed", apiErr.Code)
+	assert.NotContains(t, apiErr.Message, "network unreachable")
+	assert.Equal(t, int32(1), requests.Load())
+}
+
+func TestClient_ListAndGetMachine(t *testing.T) {
+	server := newTestServer(t, &recordingSender{})
+	c := newTestClient(t, server.URL)
+
+	machines, err := c.ListMachines(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, machines, 2)
+
+	machine, err := c.GetMachine(context.Background(), "gandalf")
+	require.NoError(t, err)
+	assert.Equal(t, client.Machine{ID: "gandalf", Name: "Gandalf", MAC: "11:22:33:44:55:66", Broadcast: "192.168.1.255"}, *machine)
+
+	_, err = c.GetMachine(context.Background(), "sauron")
+	assert.ErrorIs(t, err, client.ErrMachineNotFound)
+}
+
+func TestClient_HealthAndVersion(t *testing.T) {
+	server := newTestServer(t, &recordingSender{})
+	c := newTestClient(t, server.URL)
+
+	health, err := c.Health(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "healthy", health.Status)
+	assert.Equal(t, 2, health.ConfiguredMachines)
+
+	version, err := c.Version(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, client.VersionInfo{Version: "1.2.3", BuildTime: "2026-01-01", GitCommit: "abc123"}, *version)
+}
+
+func TestClient_Unauthorized(t *testing.T) {
+	server := newTestServer(t, &recordingSender{})
+	c := newTestClient(t, server.URL).WithAPIKey("wrong")
+
+	_, err := c.ListMachines(context.Background())
+	assert.ErrorIs(t, err, client.ErrUnauthorized)
+	assert.NotErrorIs(t, err, client.ErrMachineNotFound)
+
+	_, err = newTestClient(t, server.URL).WithAPIKey("").ListMachines(context.Background())
+	assert.ErrorIs(t, err, client.ErrUnauthorized)
+}
+
+func TestClient_UnhealthyServer(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = w.Write([]byte(`{"status":"unhealthy","checks":{"machines":"error"}}`))
+	}))
+	defer server.Close()
+
+	health, err := newTestClient(t, server.URL).Health(context.Background())
+	assert.ErrorIs(t, err, client.ErrUnhealthy)
+	require.NotNil(t, health)
+	assert.Equal(t, "error", health.Checks["machines"])
+	assert.Equal(t, int32(1), requests.Load())
+}
+
+func TestClient_RetriesUntilSuccess(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if requests.Add(1) < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		_, _ = w.Write([]byte(`{"version":"1.2.3"}`))
+	}))
+	defer server.Close()
+
+	version, err := newTestClient(t, server.URL).Version(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "1.2.3", version.Version)
+	assert.Equal(t, int32(3), requests.Load())
+}
+
+func TestClient_DoesNotRetryClientErrors(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"error":"Invalid request"}`))
+	}))
+	defer server.Close()
+
+	err := newTestClient(t, server.URL).Wake(context.Background(), "saruman")
+	assert.EqualError(t, err, "server returned 400 Bad Request: Invalid request")
+	assert.Equal(t, int32(1), requests.Load())
+}
+
+func TestClient_ContextCancelsRetries(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	c := newTestClient(t, server.URL).WithRetries(100, time.Hour)
+	_, err := c.Version(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestClient_WithTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+		_, _ = w.Write([]byte(`{"version":"1.2.3"}`))
+	}))
+	defer server.Close()
+
+	_, err := newTestClient(t, server.URL).WithRetries(0, 0).WithTimeout(10 * time.Millisecond).Version(context.Background())
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	version, err := newTestClient(t, server.URL).WithTimeout(time.Second).Version(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "1.2.3", version.Version)
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
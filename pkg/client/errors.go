@@ -0,0 +1,59 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Sentinel errors returned by the client. Use errors.Is to check for them; the
+// underlying *APIError carries the HTTP status and the server's message.
+var (
+	// ErrMachineNotFound is returned when the machine is not in the server's allowlist.
+	ErrMachineNotFound = errors.New("machine not found")
+	// ErrUnauthorized is returned when the API key is missing or invalid.
+	ErrUnauthorized = errors.New("unauthorized")
+	// ErrForbidden is returned when the API key does not grant access to the endpoint.
+	ErrForbidden = errors.New("forbidden")
+	// ErrRateLimited is returned while the client is locked out after repeated
+	// authentication failures.
+	ErrRateLimited = errors.New("rate limited")
+	// ErrUnhealthy is returned by Health when the server reports itself unhealthy.
+	ErrUnhealthy = errors.New("server unhealthy")
+)
+
+// APIError is an error response from the server.
+type APIError struct {
+	// StatusCode is the HTTP status code of the response.
+	StatusCode int
+	// Message is the error message returned by the server.
+	Message string
+	// Code is the server's machine-readable error code, e.g. "machine_not_allowed".
+	// It is empty when the server did not report one.
+	Code string
+
+	sentinel error
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("server returned %d %s: %s", e.StatusCode, http.StatusText(e.StatusCode), e.Message)
+}
+
+// Unwrap returns the sentinel error matching the response, if any.
+func (e *APIError) Unwrap() error {
+	return e.sentinel
+}
+
+// statusSentinel returns the sentinel error for status codes that mean the same on every endpoint.
+func statusSentinel(statusCode int) error {
+	switch statusCode {
+	case http.StatusUnauthorized:
+		return ErrUnauthorized
+	case http.StatusForbidden:
+		return ErrForbidden
+	case http.StatusTooManyRequests:
+		return ErrRateLimited
+	default:
+		return nil
+	}
+}
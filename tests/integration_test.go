@@ -15,8 +15,6 @@ import (
 	"testing"
 	"time"
 
-	"github.com/prometheus/client_golang/prometheus"
-
 	"github.com/josimar-silva/gwaihir/internal/config"
 	httpdelivery "github.com/josimar-silva/gwaihir/internal/delivery/http"
 	"github.com/josimar-silva/gwaihir/internal/infrastructure"
@@ -83,37 +81,10 @@ func startServer(t *testing.T, port string, configPath string) (string, func())
 
 	packetSender := repository.NewWoLPacketSender()
 
-	// Use a custom metrics structure to avoid conflicts
-	metrics := &infrastructure.Metrics{
-		WoLPacketsSent: prometheus.NewCounter(prometheus.CounterOpts{
-			Name: "gwaihir_wol_packets_sent_total",
-			Help: "Total number of WoL packets successfully sent",
-		}),
-		WoLPacketsFailed: prometheus.NewCounter(prometheus.CounterOpts{
-			Name: "gwaihir_wol_packets_failed_total",
-			Help: "Total number of WoL packet send failures",
-		}),
-		MachineNotFound: prometheus.NewCounter(prometheus.CounterOpts{
-			Name: "gwaihir_machine_not_found_total",
-			Help: "Total number of machine not found errors",
-		}),
-		MachinesListed: prometheus.NewCounter(prometheus.CounterOpts{
-			Name: "gwaihir_machines_listed_total",
-			Help: "Total number of times machines list was requested",
-		}),
-		MachinesRetrieved: prometheus.NewCounter(prometheus.CounterOpts{
-			Name: "gwaihir_machines_retrieved_total",
-			Help: "Total number of times a machine was retrieved by ID",
-		}),
-		RequestDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
-			Name:    "gwaihir_request_duration_seconds",
-			Help:    "Request latency in seconds",
-			Buckets: prometheus.DefBuckets,
-		}),
-		ConfiguredMachines: prometheus.NewGauge(prometheus.GaugeOpts{
-			Name: "gwaihir_configured_machines_total",
-			Help: "Total number of configured machines in allowlist",
-		}),
+	// Every metrics instance owns its registry, so servers do not conflict
+	metrics, err := infrastructure.NewMetrics()
+	if err != nil {
+		t.Fatalf("Failed to initialize metrics: %v", err)
 	}
 
 	wolUseCase := usecase.NewWoLUseCase(machineRepo, packetSender, logger, metrics)
@@ -322,37 +293,10 @@ func TestIntegration_APIKeyAuthentication(t *testing.T) {
 	apiKey := "test-api-key"
 	packetSender := repository.NewWoLPacketSender()
 
-	// Create isolated metrics
-	metrics := &infrastructure.Metrics{
-		WoLPacketsSent: prometheus.NewCounter(prometheus.CounterOpts{
-			Name: "gwaihir_wol_packets_sent_total",
-			Help: "Total number of WoL packets successfully sent",
-		}),
-		WoLPacketsFailed: prometheus.NewCounter(prometheus.CounterOpts{
-			Name: "gwaihir_wol_packets_failed_total",
-			Help: "Total number of WoL packet send failures",
-		}),
-		MachineNotFound: prometheus.NewCounter(prometheus.CounterOpts{
-			Name: "gwaihir_machine_not_found_total",
-			Help: "Total number of machine not found errors",
-		}),
-		MachinesListed: prometheus.NewCounter(prometheus.CounterOpts{
-			Name: "gwaihir_machines_listed_total",
-			Help: "Total number of times machines list was requested",
-		}),
-		MachinesRetrieved: prometheus.NewCounter(prometheus.CounterOpts{
-			Name: "gwaihir_machines_retrieved_total",
-			Help: "Total number of times a machine was retrieved by ID",
-		}),
-		RequestDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
-			Name:    "gwaihir_request_duration_seconds",
-			Help:    "Request latency in seconds",
-			Buckets: prometheus.DefBuckets,
-		}),
-		ConfiguredMachines: prometheus.NewGauge(prometheus.GaugeOpts{
-			Name: "gwaihir_configured_machines_total",
-			Help: "Total number of configured machines in allowlist",
-		}),
+	// Every metrics instance owns its registry, so servers do not conflict
+	metrics, err := infrastructure.NewMetrics()
+	if err != nil {
+		t.Fatalf("Failed to initialize metrics: %v", err)
 	}
 
 	wolUseCase := usecase.NewWoLUseCase(machineRepo, packetSender, logger, metrics)